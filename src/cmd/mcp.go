@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/campaign"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/eventstream"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/plugin"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/sendqueue"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/mcp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
 	"github.com/mark3labs/mcp-go/server"
@@ -24,6 +34,7 @@ func init() {
 	rootCmd.AddCommand(mcpCmd)
 	mcpCmd.Flags().StringVar(&config.McpPort, "port", "8080", "Port for the SSE MCP server")
 	mcpCmd.Flags().StringVar(&config.McpHost, "host", "localhost", "Host for the SSE MCP server")
+	mcpCmd.Flags().StringVar(&config.McpRole, "role", config.McpRole, "Role of MCP clients that connect without credentials, empty denies every tool")
 }
 
 func mcpServer(_ *cobra.Command, _ []string) {
@@ -31,6 +42,22 @@ func mcpServer(_ *cobra.Command, _ []string) {
 	go helpers.SetAutoConnectAfterBooting(appUsecase)
 	// Set auto reconnect checking
 	go helpers.SetAutoReconnectChecking(whatsappCli)
+	// Connect the additional sessions restored from the device store
+	go whatsapp.ConnectSessions()
+	// Deliver webhooks from the outbox
+	go webhook.RunDispatcher(context.Background())
+	// Remove audit entries older than the retention
+	go audit.RunPurger(context.Background())
+	// Remove streamed events older than the event retention
+	go eventstream.RunPurger(context.Background())
+	// Remove idle rate limit buckets and past send quota usage
+	go ratelimit.RunPurger(context.Background())
+	// Send the queued messages, including the ones left over from a previous run
+	go sendqueue.Run(context.Background())
+	// Send the running campaigns, including the ones left running by a previous run
+	go campaign.Run(context.Background())
+	// Start the plugins and restart the ones that exit
+	go plugin.Run(context.Background())
 
 	// Create MCP server with capabilities
 	mcpServer := server.NewMCPServer(
@@ -38,6 +65,11 @@ func mcpServer(_ *cobra.Command, _ []string) {
 		config.AppVersion,
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(true, true),
+		server.WithToolHandlerMiddleware(mcp.AuditToolMiddleware),
+		server.WithToolHandlerMiddleware(mcp.AuthToolMiddleware(authPolicy)),
+		server.WithToolHandlerMiddleware(mcp.RateLimitToolMiddleware),
+		server.WithToolHandlerMiddleware(mcp.DeviceToolMiddleware),
+		server.WithToolFilter(mcp.AuthToolFilter(authPolicy)),
 	)
 
 	// Add all WhatsApp tools
@@ -53,11 +85,19 @@ func mcpServer(_ *cobra.Command, _ []string) {
 	groupHandler := mcp.InitMcpGroup(groupUsecase)
 	groupHandler.AddGroupTools(mcpServer)
 
+	if config.McpRole != "" && !authPolicy.HasRole(config.McpRole) {
+		logrus.Fatalf("unknown MCP role %q, available roles: %v", config.McpRole, authPolicy.Roles())
+	}
+	authContext := mcp.AuthContextFunc(userRepo)
+
 	// Create SSE server
 	sseServer := server.NewSSEServer(
 		mcpServer,
 		server.WithBaseURL(fmt.Sprintf("http://%s:%s", config.McpHost, config.McpPort)),
 		server.WithKeepAlive(true),
+		server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			return mcp.DeviceContextFunc(authContext(ctx, r), r)
+		}),
 	)
 
 	// Start the SSE server
@@ -1,11 +1,20 @@
 package cmd
 
 import (
+    "context"
     "fmt"
     "net/http"
     "os"
 
     "github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/audit"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/campaign"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/eventstream"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/plugin"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/sendqueue"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+    "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
     "github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest"
     "github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
     "github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
@@ -54,20 +63,25 @@ func restServer(_ *cobra.Command, _ []string) {
 		Browse:     true,
 	}))
 
+    // Audit wraps Recovery so panicking requests are recorded with their error response
+    app.Use(middleware.Audit())
     app.Use(middleware.Recovery())
-    // Enforce SQL-backed Basic Auth using credentials from Postgres if available, fallback to SQLite chat storage
-    if authDB != nil {
-        app.Use(middleware.SQLBasicAuthPostgres(authDB))
-    } else {
-        app.Use(middleware.SQLBasicAuth(chatStorageDB))
-    }
+    // Accept API keys as bearer tokens, otherwise enforce SQL-backed Basic Auth using credentials from Postgres
+    // if available, fallback to the chat storage, then the role of the user must grant the permission of the route group
+    app.Use(middleware.APIKeyAuth(userRepo))
+    app.Use(middleware.SQLBasicAuth(userRepo))
+    app.Use(middleware.Authorize(authPolicy))
     app.Use(middleware.BasicAuth())
+    app.Use(middleware.DeviceSelector())
+    app.Use(middleware.IdempotencyKey())
+    // Charges the calls after the handler, so the ones that fail or replay an Idempotency-Key are free
+    app.Use(middleware.RateLimit())
     if config.AppDebug {
         app.Use(logger.New())
     }
     app.Use(cors.New(cors.Config{
         AllowOrigins: "*",
-        AllowHeaders: "Origin, Content-Type, Accept",
+        AllowHeaders: "Origin, Content-Type, Accept, " + middleware.DeviceHeader + ", " + middleware.IdempotencyHeader,
     }))
 
 	// Create base path group or use app directly
@@ -77,13 +91,18 @@ func restServer(_ *cobra.Command, _ []string) {
 	}
 
 	// Rest
-	rest.InitRestApp(apiGroup, appUsecase)
-	rest.InitRestChat(apiGroup, chatUsecase)
-	rest.InitRestSend(apiGroup, sendUsecase)
-	rest.InitRestUser(apiGroup, userUsecase)
-	rest.InitRestMessage(apiGroup, messageUsecase)
-	rest.InitRestGroup(apiGroup, groupUsecase)
-	rest.InitRestNewsletter(apiGroup, newsletterUsecase)
+	rest.InitRestDevice(apiGroup, appUsecase)
+	rest.InitRestWebhook(apiGroup, webhookUsecase)
+	rest.InitRestAdmin(apiGroup, authUsecase)
+	rest.InitRestAudit(apiGroup, auditUsecase)
+	rest.InitRestRateLimit(apiGroup, rateLimitUsecase)
+	rest.InitRestSendJob(apiGroup, sendJobUsecase)
+	rest.InitRestCampaign(apiGroup, campaignUsecase)
+	rest.InitRestTemplate(apiGroup, templateUsecase)
+	rest.InitRestAutoReply(apiGroup, autoReplyUsecase)
+	registerRestRoutes(apiGroup)
+	// Same routes scoped to a single device, e.g. /devices/:device_id/send/message
+	registerRestRoutes(apiGroup.Group("/devices/:device_id", middleware.DeviceSelector()))
 	rest.InitRestDocs(apiGroup)
 
 	apiGroup.Get("/", func(c *fiber.Ctx) error {
@@ -97,7 +116,7 @@ func restServer(_ *cobra.Command, _ []string) {
 		})
 	})
 
-	websocket.RegisterRoutes(apiGroup, appUsecase)
+	websocket.RegisterRoutes(apiGroup, appUsecase, streamUsecase, authPolicy)
 	go websocket.RunHub()
 
 	// Set auto reconnect to whatsapp server after booting
@@ -105,6 +124,24 @@ func restServer(_ *cobra.Command, _ []string) {
 	// Set auto reconnect checking
 	go helpers.SetAutoReconnectChecking(whatsappCli)
 
+	// Connect the additional sessions restored from the device store
+	go whatsapp.ConnectSessions()
+
+	// Deliver webhooks from the outbox, including the ones left over from a previous run
+	go webhook.RunDispatcher(context.Background())
+	// Remove audit entries older than the retention
+	go audit.RunPurger(context.Background())
+	// Remove streamed events older than the event retention
+	go eventstream.RunPurger(context.Background())
+	// Remove idle rate limit buckets and past send quota usage
+	go ratelimit.RunPurger(context.Background())
+	// Send the queued messages, including the ones left over from a previous run
+	go sendqueue.Run(context.Background())
+	// Send the running campaigns, including the ones left running by a previous run
+	go campaign.Run(context.Background())
+	// Start the plugins and restart the ones that exit
+	go plugin.Run(context.Background())
+
 	// Use PORT environment variable for Railway deployment, fallback to config.AppPort
 	port := config.AppPort
 	if envPort := os.Getenv("PORT"); envPort != "" {
@@ -119,3 +156,15 @@ func restServer(_ *cobra.Command, _ []string) {
 		logrus.Fatalln("Failed to start: ", err.Error())
 	}
 }
+
+// registerRestRoutes registers the device aware REST routes on the given router
+func registerRestRoutes(router fiber.Router) {
+	rest.InitRestApp(router, appUsecase)
+	rest.InitRestChat(router, chatUsecase)
+	rest.InitRestSend(router, sendUsecase)
+	rest.InitRestUser(router, userUsecase)
+	rest.InitRestMessage(router, messageUsecase)
+	rest.InitRestGroup(router, groupUsecase)
+	rest.InitRestNewsletter(router, newsletterUsecase)
+	rest.InitRestEvents(router, streamUsecase)
+}
@@ -8,25 +8,44 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"go.mau.fi/whatsmeow/store/sqlstore"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
 	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
 	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/autoreply"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/campaign"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/eventstream"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/plugin"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/sendqueue"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
 	"golang.org/x/crypto/bcrypt"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -34,6 +53,8 @@ import (
 	"go.mau.fi/whatsmeow"
 )
 
+var deviceStorageKeyPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
 var (
     EmbedIndex embed.FS
     EmbedViews embed.FS
@@ -44,10 +65,22 @@ var (
     // Chat Storage
     chatStorageDB   *sql.DB
     chatStorageRepo domainChatStorage.IChatStorageRepository
+    webhookRepo     domainWebhook.IWebhookRepository
+    auditRepo       domainAudit.IAuditRepository
+    rateLimitRepo   domainRateLimit.IRateLimitRepository
+    sendJobRepo     domainSend.ISendJobRepository
+    campaignRepo    domainCampaign.ICampaignRepository
+    templateRepo    domainTemplate.ITemplateRepository
+    autoReplyRepo   domainAutoReply.IAutoReplyRepository
+    eventStreamRepo domainEventStream.IEventStreamRepository
 
     // Auth (Postgres-backed)
     authDB *sql.DB
 
+	// Authentication and authorization of the app_users
+	userRepo   domainAuth.IUserRepository
+	authPolicy *domainAuth.Policy
+
 	// Usecase
 	appUsecase        domainApp.IAppUsecase
 	chatUsecase       domainChat.IChatUsecase
@@ -56,6 +89,15 @@ var (
 	messageUsecase    domainMessage.IMessageUsecase
 	groupUsecase      domainGroup.IGroupUsecase
 	newsletterUsecase domainNewsletter.INewsletterUsecase
+	webhookUsecase    domainWebhook.IWebhookUsecase
+	authUsecase       domainAuth.IAuthUsecase
+	auditUsecase      domainAudit.IAuditUsecase
+	rateLimitUsecase  domainRateLimit.IRateLimitUsecase
+	sendJobUsecase    domainSend.ISendJobUsecase
+	campaignUsecase   domainCampaign.ICampaignUsecase
+	templateUsecase   domainTemplate.ITemplateUsecase
+	autoReplyUsecase  domainAutoReply.IAutoReplyUsecase
+	streamUsecase     domainEventStream.IEventStreamUsecase
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -76,8 +118,11 @@ func init() {
 	// Initialize flags first, before any subcommands are added
 	initFlags()
 
-	// Then initialize other components
-	cobra.OnInitialize(initEnvConfig, initApp)
+	// Then initialize other components, commands that do not serve WhatsApp replace initApp with their own
+	cobra.OnInitialize(initEnvConfig)
+	rootCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		initApp()
+	}
 }
 
 // initEnvConfig loads configuration from environment variables
@@ -97,9 +142,30 @@ func initEnvConfig() {
 		credential := strings.Split(envBasicAuth, ",")
 		config.AppBasicAuthCredential = credential
 	}
+	if envRoles := viper.GetString("app_roles"); envRoles != "" {
+		config.AppRoles = strings.Split(envRoles, ",")
+	}
 	if envBasePath := viper.GetString("app_base_path"); envBasePath != "" {
 		config.AppBasePath = envBasePath
 	}
+	if viper.IsSet("app_audit_retention") {
+		config.AppAuditRetention = viper.GetDuration("app_audit_retention")
+	}
+	if viper.IsSet("app_event_retention") {
+		config.AppEventRetention = viper.GetDuration("app_event_retention")
+	}
+	if envRateLimits := viper.GetString("app_rate_limits"); envRateLimits != "" {
+		config.AppRateLimits = strings.Split(envRateLimits, ",")
+	}
+	if envRecipientRateLimit := viper.GetString("app_recipient_rate_limit"); envRecipientRateLimit != "" {
+		config.AppRecipientRateLimit = envRecipientRateLimit
+	}
+	if viper.IsSet("app_send_quota_daily") {
+		config.AppSendQuotaDaily = viper.GetInt64("app_send_quota_daily")
+	}
+	if viper.IsSet("app_send_quota_monthly") {
+		config.AppSendQuotaMonthly = viper.GetInt64("app_send_quota_monthly")
+	}
 
 	// Database settings
 	if envDBURI := viper.GetString("db_uri"); envDBURI != "" {
@@ -123,9 +189,36 @@ func initEnvConfig() {
 	if envWebhookSecret := viper.GetString("whatsapp_webhook_secret"); envWebhookSecret != "" {
 		config.WhatsappWebhookSecret = envWebhookSecret
 	}
+	if envRetryHorizon := viper.GetDuration("whatsapp_webhook_retry_horizon"); envRetryHorizon > 0 {
+		config.WhatsappWebhookRetryHorizon = envRetryHorizon
+	}
 	if viper.IsSet("whatsapp_account_validation") {
 		config.WhatsappAccountValidation = viper.GetBool("whatsapp_account_validation")
 	}
+	if viper.IsSet("whatsapp_send_queue") {
+		config.WhatsappSendQueue = viper.GetBool("whatsapp_send_queue")
+	}
+	if envSendQueueRate := viper.GetString("whatsapp_send_queue_rate"); envSendQueueRate != "" {
+		config.WhatsappSendQueueRate = envSendQueueRate
+	}
+	if viper.IsSet("whatsapp_send_queue_jitter") {
+		config.WhatsappSendQueueJitter = viper.GetDuration("whatsapp_send_queue_jitter")
+	}
+	if viper.IsSet("whatsapp_send_queue_recipient_interval") {
+		config.WhatsappSendQueueRecipientInterval = viper.GetDuration("whatsapp_send_queue_recipient_interval")
+	}
+	if viper.IsSet("whatsapp_send_queue_typing") {
+		config.WhatsappSendQueueTyping = viper.GetBool("whatsapp_send_queue_typing")
+	}
+	if viper.IsSet("whatsapp_schedule_catch_up") {
+		config.WhatsappScheduleCatchUp = viper.GetString("whatsapp_schedule_catch_up")
+	}
+	if envCampaignRate := viper.GetString("whatsapp_campaign_rate"); envCampaignRate != "" {
+		config.WhatsappCampaignRate = envCampaignRate
+	}
+	if envPlugins := viper.GetString("whatsapp_plugins"); envPlugins != "" {
+		config.WhatsappPlugins = strings.Split(envPlugins, ",")
+	}
 }
 
 func initFlags() {
@@ -155,12 +248,54 @@ func initFlags() {
 		config.AppBasicAuthCredential,
 		"basic auth credential | -b=yourUsername:yourPassword",
 	)
+	rootCmd.PersistentFlags().StringSliceVarP(
+		&config.AppRoles,
+		"roles", "",
+		config.AppRoles,
+		`custom roles of the users in addition to viewer, sender, group-admin and admin --roles <name=permission|permission> | example: --roles="support=chat:read|user:read|send"`,
+	)
 	rootCmd.PersistentFlags().StringVarP(
 		&config.AppBasePath,
 		"base-path", "",
 		config.AppBasePath,
 		`base path for subpath deployment --base-path <string> | example: --base-path="/gowa"`,
 	)
+	rootCmd.PersistentFlags().DurationVarP(
+		&config.AppAuditRetention,
+		"audit-retention", "",
+		config.AppAuditRetention,
+		`how long the audit log of state-changing calls is kept, 0 keeps it forever --audit-retention <duration> | example: --audit-retention=720h`,
+	)
+	rootCmd.PersistentFlags().DurationVarP(
+		&config.AppEventRetention,
+		"event-retention", "",
+		config.AppEventRetention,
+		`how long the events streamed on /ws are kept for reconnecting clients to catch up, 0 keeps none --event-retention <duration> | example: --event-retention=24h`,
+	)
+	rootCmd.PersistentFlags().StringSliceVarP(
+		&config.AppRateLimits,
+		"rate-limit", "",
+		config.AppRateLimits,
+		`token bucket limits per user or API key and route class (send, chat, user, message, group, newsletter, app, webhook, users, audit, admin), "*" applies to every other class --rate-limit <class=count/duration> | example: --rate-limit="send=30/1m,group=10/1m,*=300/1m"`,
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&config.AppRecipientRateLimit,
+		"recipient-rate-limit", "",
+		config.AppRecipientRateLimit,
+		`limit of the sends to a single recipient JID across all users --recipient-rate-limit <count/duration> | example: --recipient-rate-limit="10/1m"`,
+	)
+	rootCmd.PersistentFlags().Int64VarP(
+		&config.AppSendQuotaDaily,
+		"send-quota-daily", "",
+		config.AppSendQuotaDaily,
+		`sends per user or API key and day (UTC), 0 is unlimited --send-quota-daily <number> | example: --send-quota-daily=1000`,
+	)
+	rootCmd.PersistentFlags().Int64VarP(
+		&config.AppSendQuotaMonthly,
+		"send-quota-monthly", "",
+		config.AppSendQuotaMonthly,
+		`sends per user or API key and month (UTC), 0 is unlimited --send-quota-monthly <number> | example: --send-quota-monthly=20000`,
+	)
 
 	// Database flags
 	rootCmd.PersistentFlags().StringVarP(
@@ -201,16 +336,74 @@ func initFlags() {
 		config.WhatsappWebhookSecret,
 		`secure webhook request --webhook-secret <string> | example: --webhook-secret="super-secret-key"`,
 	)
+	rootCmd.PersistentFlags().DurationVarP(
+		&config.WhatsappWebhookRetryHorizon,
+		"webhook-retry-horizon", "",
+		config.WhatsappWebhookRetryHorizon,
+		`how long failed webhook deliveries are retried before moving to the dead-letter queue --webhook-retry-horizon <duration> | example: --webhook-retry-horizon=6h`,
+	)
 	rootCmd.PersistentFlags().BoolVarP(
 		&config.WhatsappAccountValidation,
 		"account-validation", "",
 		config.WhatsappAccountValidation,
 		`enable or disable account validation --account-validation <true/false> | example: --account-validation=true`,
 	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&config.WhatsappSendQueue,
+		"send-queue", "",
+		config.WhatsappSendQueue,
+		`queue the sends and let a paced worker send them, the response has a job id --send-queue <true/false> | example: --send-queue=true`,
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&config.WhatsappSendQueueRate,
+		"send-queue-rate", "",
+		config.WhatsappSendQueueRate,
+		`messages the send queue sends across all devices --send-queue-rate <count/duration> | example: --send-queue-rate="10/1m"`,
+	)
+	rootCmd.PersistentFlags().DurationVarP(
+		&config.WhatsappSendQueueJitter,
+		"send-queue-jitter", "",
+		config.WhatsappSendQueueJitter,
+		`random delay added to the pause between two queued messages --send-queue-jitter <duration> | example: --send-queue-jitter=5s`,
+	)
+	rootCmd.PersistentFlags().DurationVarP(
+		&config.WhatsappSendQueueRecipientInterval,
+		"send-queue-recipient-interval", "",
+		config.WhatsappSendQueueRecipientInterval,
+		`minimum pause between two queued messages to the same recipient --send-queue-recipient-interval <duration> | example: --send-queue-recipient-interval=30s`,
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&config.WhatsappSendQueueTyping,
+		"send-queue-typing", "",
+		config.WhatsappSendQueueTyping,
+		`show a typing indicator before each queued message --send-queue-typing <true/false> | example: --send-queue-typing=true`,
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&config.WhatsappScheduleCatchUp,
+		"schedule-catch-up", "",
+		config.WhatsappScheduleCatchUp,
+		`scheduled messages missed while the server was not running that are still sent: all, none or the maximum delay --schedule-catch-up <policy> | example: --schedule-catch-up=1h`,
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&config.WhatsappCampaignRate,
+		"campaign-rate", "",
+		config.WhatsappCampaignRate,
+		`messages a campaign sends unless it sets its own rate --campaign-rate <count/duration> | example: --campaign-rate="60/1h"`,
+	)
+	rootCmd.PersistentFlags().StringSliceVarP(
+		&config.WhatsappPlugins,
+		"plugin", "",
+		config.WhatsappPlugins,
+		`executables streamed the events over JSON-RPC on stdin/stdout --plugin <name=command[;events=event|event][;chats=jid|jid][;timeout=duration]> | example: --plugin="echo=python3 bots/echo.py;events=message|receipt;timeout=10s"`,
+	)
 }
 
 func initChatStorage() (*sql.DB, error) {
-    uri := config.ChatStorageURI
+    return openChatStorage(config.ChatStorageURI)
+}
+
+// openChatStorage opens a chat storage database for the given URI
+func openChatStorage(uri string) (*sql.DB, error) {
     // Choose driver based on URI prefix
     if strings.HasPrefix(strings.ToLower(uri), "postgres") {
         db, err := sql.Open("postgres", uri)
@@ -245,6 +438,41 @@ func initChatStorage() (*sql.DB, error) {
     return db, nil
 }
 
+// newDeviceChatStorage opens the chat storage of an additional WhatsApp device.
+// SQLite devices get their own database file next to the main one, Postgres devices get their own schema.
+func newDeviceChatStorage(key string) (domainChatStorage.IChatStorageRepository, error) {
+	key = deviceStorageKeyPattern.ReplaceAllString(key, "_")
+	uri := config.ChatStorageURI
+
+	if strings.HasPrefix(strings.ToLower(uri), "postgres") {
+		schema := "device_" + key
+		if _, err := chatStorageDB.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(schema)); err != nil {
+			return nil, fmt.Errorf("failed to create chat storage schema %s: %w", schema, err)
+		}
+
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat storage URI: %w", err)
+		}
+		query := parsed.Query()
+		query.Set("search_path", schema)
+		parsed.RawQuery = query.Encode()
+
+		db, err := openChatStorage(parsed.String())
+		if err != nil {
+			return nil, err
+		}
+		return chatstorage.NewPostgresRepository(db), nil
+	}
+
+	ext := filepath.Ext(uri)
+	db, err := openChatStorage(fmt.Sprintf("%s-%s%s", strings.TrimSuffix(uri, ext), key, ext))
+	if err != nil {
+		return nil, err
+	}
+	return chatstorage.NewStorageRepository(db), nil
+}
+
 func initApp() {
 	if config.AppDebug {
 		config.WhatsappLogLevel = "DEBUG"
@@ -259,6 +487,81 @@ func initApp() {
 
 	ctx := context.Background()
 
+	initStorage()
+
+	// Seed a default admin user if none exists
+	seedDefaultAdmin(chatStorageDB)
+	if authDB != nil {
+		seedDefaultAdminPG(authDB)
+	}
+
+	// Webhooks of every device go through the endpoints and outbox of the main chat storage
+	if err := webhook.InitDispatcher(ctx, webhookRepo); err != nil {
+		logrus.Errorf("failed to initialize webhook dispatcher: %v", err)
+	}
+
+	// State-changing calls of every device are audited in the main chat storage
+	audit.Init(auditRepo)
+	// Rate limits and send quotas are shared by every device and survive restarts in the main chat storage
+	if err := ratelimit.Init(rateLimitRepo); err != nil {
+		logrus.Fatalf("invalid rate limits: %v", err)
+	}
+	// Queued sends of every device wait in the main chat storage
+	if err := sendqueue.Init(sendJobRepo, chatStorageRepo); err != nil {
+		logrus.Fatalf("invalid send queue settings: %v", err)
+	}
+
+	whatsappDB := whatsapp.InitWaDB(ctx, config.DBURI)
+	var keysDB *sqlstore.Container
+	if config.DBKeysURI != "" {
+		keysDB = whatsapp.InitWaDB(ctx, config.DBKeysURI)
+	}
+
+	whatsapp.InitWaCLI(ctx, whatsappDB, keysDB, chatStorageRepo)
+
+	// Restore additional sessions, each with its own chat storage
+	whatsapp.SetChatStorageFactory(newDeviceChatStorage)
+	whatsapp.LoadSessions(ctx, chatStorageRepo)
+
+	// Usecase
+	appUsecase = usecase.NewAppService(chatStorageRepo)
+	chatUsecase = usecase.NewChatService(chatStorageRepo)
+	sendUsecase = usecase.NewSendService(appUsecase, chatStorageRepo, templateRepo)
+	userUsecase = usecase.NewUserService()
+	messageUsecase = usecase.NewMessageService(chatStorageRepo)
+	groupUsecase = usecase.NewGroupService()
+	newsletterUsecase = usecase.NewNewsletterService()
+	webhookUsecase = usecase.NewWebhookService(webhookRepo)
+	authUsecase = usecase.NewAuthService(userRepo, authPolicy)
+	auditUsecase = usecase.NewAuditService(auditRepo)
+	rateLimitUsecase = usecase.NewRateLimitService(rateLimitRepo, userRepo)
+	sendJobUsecase = usecase.NewSendJobService(sendJobRepo, authPolicy)
+	campaignUsecase = usecase.NewCampaignService(campaignRepo, sendJobRepo, groupUsecase)
+	templateUsecase = usecase.NewTemplateService(templateRepo)
+	autoReplyUsecase = usecase.NewAutoReplyService(autoReplyRepo, templateRepo)
+	streamUsecase = usecase.NewEventStreamService()
+
+	// Campaigns of every device are kept in the main chat storage and send through the send usecase
+	if err := campaign.Init(campaignRepo, sendJobRepo, sendUsecase); err != nil {
+		logrus.Fatalf("invalid campaign settings: %v", err)
+	}
+	// Auto-reply rules answer the messages of every device from the main chat storage
+	if err := autoreply.Init(ctx, autoReplyRepo, sendUsecase); err != nil {
+		logrus.Errorf("failed to initialize auto-reply rules: %v", err)
+	}
+	// Webhook receivers can answer a message event with actions that run through the send and message usecases
+	whatsapp.InitWebhookResponses(sendUsecase, messageUsecase)
+	// Websocket clients subscribe to the events of every device, kept in the main chat storage to catch up
+	eventstream.Init(eventStreamRepo)
+	// Plugins get the events of every device and call back into the usecases
+	if err := plugin.Init(sendUsecase, messageUsecase, groupUsecase); err != nil {
+		logrus.Fatalf("invalid plugin settings: %v", err)
+	}
+}
+
+// initStorage opens the main chat storage with its repositories, and the users with their roles
+func initStorage() {
+	var err error
 	chatStorageDB, err = initChatStorage()
 	if err != nil {
 		// Terminate the application if chat storage fails to initialize to avoid nil pointer panics later.
@@ -268,37 +571,40 @@ func initApp() {
     // Select repository based on ChatStorageURI
     if strings.HasPrefix(strings.ToLower(config.ChatStorageURI), "postgres") {
         chatStorageRepo = chatstorage.NewPostgresRepository(chatStorageDB)
+        webhookRepo = chatstorage.NewPostgresWebhookRepository(chatStorageDB)
+        userRepo = chatstorage.NewPostgresUserRepository(chatStorageDB)
+        auditRepo = chatstorage.NewPostgresAuditRepository(chatStorageDB)
+        rateLimitRepo = chatstorage.NewPostgresRateLimitRepository(chatStorageDB)
+        sendJobRepo = chatstorage.NewPostgresSendJobRepository(chatStorageDB)
+        campaignRepo = chatstorage.NewPostgresCampaignRepository(chatStorageDB)
+        templateRepo = chatstorage.NewPostgresTemplateRepository(chatStorageDB)
+        autoReplyRepo = chatstorage.NewPostgresAutoReplyRepository(chatStorageDB)
+        eventStreamRepo = chatstorage.NewPostgresEventStreamRepository(chatStorageDB)
     } else {
         chatStorageRepo = chatstorage.NewStorageRepository(chatStorageDB)
+        webhookRepo = chatstorage.NewSQLiteWebhookRepository(chatStorageDB)
+        userRepo = chatstorage.NewSQLiteUserRepository(chatStorageDB)
+        auditRepo = chatstorage.NewSQLiteAuditRepository(chatStorageDB)
+        rateLimitRepo = chatstorage.NewSQLiteRateLimitRepository(chatStorageDB)
+        sendJobRepo = chatstorage.NewSQLiteSendJobRepository(chatStorageDB)
+        campaignRepo = chatstorage.NewSQLiteCampaignRepository(chatStorageDB)
+        templateRepo = chatstorage.NewSQLiteTemplateRepository(chatStorageDB)
+        autoReplyRepo = chatstorage.NewSQLiteAutoReplyRepository(chatStorageDB)
+        eventStreamRepo = chatstorage.NewSQLiteEventStreamRepository(chatStorageDB)
     }
 	chatStorageRepo.InitializeSchema()
 
-	// Seed a default admin user if none exists
-	seedDefaultAdmin(chatStorageDB)
-
     // Initialize Postgres auth DB (use main DB_URI if it's postgres)
     if db, ok := initAuthDBPostgres(config.DBURI); ok {
         authDB = db
         ensurePGAppUsers(authDB)
-        seedDefaultAdminPG(authDB)
+        userRepo = chatstorage.NewPostgresUserRepository(authDB)
     }
 
-	whatsappDB := whatsapp.InitWaDB(ctx, config.DBURI)
-	var keysDB *sqlstore.Container
-	if config.DBKeysURI != "" {
-		keysDB = whatsapp.InitWaDB(ctx, config.DBKeysURI)
+	authPolicy, err = domainAuth.NewPolicy(config.AppRoles)
+	if err != nil {
+		logrus.Fatalf("invalid roles: %v", err)
 	}
-
-	whatsapp.InitWaCLI(ctx, whatsappDB, keysDB, chatStorageRepo)
-
-	// Usecase
-	appUsecase = usecase.NewAppService(chatStorageRepo)
-	chatUsecase = usecase.NewChatService(chatStorageRepo)
-	sendUsecase = usecase.NewSendService(appUsecase, chatStorageRepo)
-	userUsecase = usecase.NewUserService()
-	messageUsecase = usecase.NewMessageService(chatStorageRepo)
-	groupUsecase = usecase.NewGroupService()
-	newsletterUsecase = usecase.NewNewsletterService()
 }
 
 // seedDefaultAdmin creates an initial admin user when user table is empty.
@@ -358,7 +664,7 @@ func initAuthDBPostgres(uri string) (*sql.DB, bool) {
     return db, true
 }
 
-// ensurePGAppUsers creates app_users table in Postgres if missing
+// ensurePGAppUsers creates app_users and app_api_keys tables in Postgres if missing
 func ensurePGAppUsers(db *sql.DB) {
     _, err := db.Exec(`
         CREATE TABLE IF NOT EXISTS app_users (
@@ -370,9 +676,21 @@ func ensurePGAppUsers(db *sql.DB) {
             updated_at TIMESTAMP DEFAULT NOW()
         );
         CREATE INDEX IF NOT EXISTS idx_app_users_enabled ON app_users(enabled);
+        CREATE TABLE IF NOT EXISTS app_api_keys (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            username TEXT NOT NULL,
+            key_hash TEXT NOT NULL UNIQUE,
+            prefix TEXT NOT NULL,
+            scopes TEXT NOT NULL DEFAULT '[]',
+            expires_at TIMESTAMP,
+            last_used_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_app_api_keys_username ON app_api_keys(username);
     `)
     if err != nil {
-        logrus.Warnf("Failed to ensure app_users in Postgres: %v", err)
+        logrus.Warnf("Failed to ensure app_users and app_api_keys in Postgres: %v", err)
     }
 }
 
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/spf13/cobra"
+)
+
+// usersCmd manages the app_users accounts directly in the configured database, the server does not need to run
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage the users of the REST API and MCP server",
+	Long:  `Create, list, change and delete the users that authenticate with Basic Auth. The users are read from and written to the configured database (--db-uri for Postgres, otherwise the chat storage), the server does not need to run.`,
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		initUsers()
+	},
+}
+
+var (
+	usersRole     string
+	usersPassword string
+	usersDisabled bool
+)
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the users",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			users, err := authUsecase.ListUsers(context.Background())
+			exitOnError(err)
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "USERNAME\tROLE\tENABLED\tCREATED AT\tUPDATED AT")
+			for _, user := range users {
+				fmt.Fprintf(writer, "%s\t%s\t%t\t%s\t%s\n", user.Username, user.Role, user.Enabled, user.CreatedAt, user.UpdatedAt)
+			}
+			exitOnError(writer.Flush())
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <username>",
+		Short: "Create a user, a random password is generated when --password is not set",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			enabled := !usersDisabled
+			user, err := authUsecase.CreateUser(context.Background(), domainAuth.CreateUserRequest{
+				Username: args[0],
+				Password: usersPassword,
+				Role:     usersRole,
+				Enabled:  &enabled,
+			})
+			exitOnError(err)
+			printUser(user)
+		},
+	}
+	createCmd.Flags().StringVar(&usersRole, "role", domainAuth.RoleViewer, "role of the user")
+	createCmd.Flags().StringVar(&usersPassword, "password", "", "password of the user")
+	createCmd.Flags().BoolVar(&usersDisabled, "disabled", false, "create the user disabled")
+
+	setRoleCmd := &cobra.Command{
+		Use:   "set-role <username> <role>",
+		Short: "Change the role of a user",
+		Args:  cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			user, err := authUsecase.UpdateUser(context.Background(), domainAuth.UpdateUserRequest{Username: args[0], Role: args[1]})
+			exitOnError(err)
+			printUser(user)
+		},
+	}
+
+	enableCmd := &cobra.Command{
+		Use:   "enable <username>",
+		Short: "Enable a user",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			enabled := true
+			user, err := authUsecase.UpdateUser(context.Background(), domainAuth.UpdateUserRequest{Username: args[0], Enabled: &enabled})
+			exitOnError(err)
+			printUser(user)
+		},
+	}
+
+	disableCmd := &cobra.Command{
+		Use:   "disable <username>",
+		Short: "Disable a user, it cannot authenticate until it is enabled again",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			enabled := false
+			user, err := authUsecase.UpdateUser(context.Background(), domainAuth.UpdateUserRequest{Username: args[0], Enabled: &enabled})
+			exitOnError(err)
+			printUser(user)
+		},
+	}
+
+	resetPasswordCmd := &cobra.Command{
+		Use:   "reset-password <username>",
+		Short: "Reset the password of a user, a random password is generated when --password is not set",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			user, err := authUsecase.ResetPassword(context.Background(), domainAuth.ResetPasswordRequest{Username: args[0], Password: usersPassword})
+			exitOnError(err)
+			printUser(user)
+		},
+	}
+	resetPasswordCmd.Flags().StringVar(&usersPassword, "password", "", "new password of the user")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <username>",
+		Short: "Delete a user",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			exitOnError(authUsecase.DeleteUser(context.Background(), domainAuth.UserRequest{Username: args[0]}))
+			fmt.Printf("User %s deleted\n", args[0])
+		},
+	}
+
+	usersCmd.AddCommand(listCmd, createCmd, setRoleCmd, enableCmd, disableCmd, resetPasswordCmd, deleteCmd)
+}
+
+// initUsers opens only the databases holding the users instead of initApp, so the command neither connects WhatsApp
+// nor starts the background workers and is safe to run next to a live server
+func initUsers() {
+	exitOnError(utils.CreateFolder(config.PathStorages))
+	initStorage()
+	authUsecase = usecase.NewAuthService(userRepo, authPolicy)
+}
+
+func printUser(user domainAuth.UserInfo) {
+	fmt.Printf("User %s, role %s, enabled %t\n", user.Username, user.Role, user.Enabled)
+	if user.Password != "" {
+		fmt.Printf("Generated password: %s\n", user.Password)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
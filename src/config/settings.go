@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"go.mau.fi/whatsmeow/proto/waCompanionReg"
 )
 
@@ -12,9 +14,17 @@ var (
 	AppPlatform            = waCompanionReg.DeviceProps_PlatformType(1)
 	AppBasicAuthCredential []string
 	AppBasePath            = ""
+	AppRoles               []string                 // custom roles of the app_users, e.g. "support=chat:read|send"
+	AppAuditRetention      = 90 * 24 * time.Hour    // how long audit entries are kept, zero keeps them forever
+	AppEventRetention      time.Duration            // how long streamed events are kept for clients to catch up, zero keeps none
+	AppRateLimits          = []string{"send=60/1m"} // token buckets per user or API key and route class, e.g. "group=30/1m"
+	AppRecipientRateLimit  = ""                     // sends per recipient JID across all users, e.g. "10/1m"
+	AppSendQuotaDaily      int64                    // sends per user or API key and day (UTC), zero is unlimited
+	AppSendQuotaMonthly    int64                    // sends per user or API key and month (UTC), zero is unlimited
 
 	McpPort = "8080"
 	McpHost = "localhost"
+	McpRole = "" // role of MCP clients that connect without credentials, empty permits nothing
 
 	PathQrCode    = "statics/qrcode"
 	PathSendItems = "statics/senditems"
@@ -24,18 +34,27 @@ var (
 	DBURI     = "file:storages/whatsapp.db?_foreign_keys=on"
 	DBKeysURI = ""
 
-	WhatsappAutoReplyMessage       string
-	WhatsappAutoMarkRead           = false // Auto-mark incoming messages as read
-	WhatsappWebhook                []string
-	WhatsappWebhookSecret                = "secret"
-	WhatsappLogLevel                     = "ERROR"
-	WhatsappSettingMaxImageSize    int64 = 20000000  // 20MB
-	WhatsappSettingMaxFileSize     int64 = 50000000  // 50MB
-	WhatsappSettingMaxVideoSize    int64 = 100000000 // 100MB
-	WhatsappSettingMaxDownloadSize int64 = 500000000 // 500MB
-	WhatsappTypeUser                     = "@s.whatsapp.net"
-	WhatsappTypeGroup                    = "@g.us"
-	WhatsappAccountValidation            = true
+	WhatsappAutoReplyMessage           string
+	WhatsappAutoMarkRead               = false // Auto-mark incoming messages as read
+	WhatsappWebhook                    []string
+	WhatsappPlugins                    []string
+	WhatsappWebhookSecret                    = "secret"
+	WhatsappWebhookRetryHorizon              = 24 * time.Hour  // How long a failing webhook delivery is retried before it is dead-lettered
+	WhatsappSendQueue                        = false           // Queue the sends and let a paced worker send them
+	WhatsappSendQueueRate                    = "20/1m"         // Messages the queue worker sends across all devices
+	WhatsappSendQueueJitter                  = 3 * time.Second // Random delay added to the pause between two queued messages
+	WhatsappSendQueueRecipientInterval       = 5 * time.Second // Minimum pause between two queued messages to the same recipient
+	WhatsappSendQueueTyping                  = false           // Show a typing indicator before each queued message
+	WhatsappScheduleCatchUp                  = "all"           // Scheduled messages missed while offline: all, none or the maximum delay like 1h
+	WhatsappCampaignRate                     = "30/1m"         // Messages a campaign sends unless it sets its own rate
+	WhatsappLogLevel                         = "ERROR"
+	WhatsappSettingMaxImageSize        int64 = 20000000  // 20MB
+	WhatsappSettingMaxFileSize         int64 = 50000000  // 50MB
+	WhatsappSettingMaxVideoSize        int64 = 100000000 // 100MB
+	WhatsappSettingMaxDownloadSize     int64 = 500000000 // 500MB
+	WhatsappTypeUser                         = "@s.whatsapp.net"
+	WhatsappTypeGroup                        = "@g.us"
+	WhatsappAccountValidation                = true
 
 	ChatStorageURI               = "file:storages/chatstorage.db"
 	ChatStorageEnableForeignKeys = true
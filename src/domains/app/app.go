@@ -12,6 +12,9 @@ type IAppUsecase interface {
 	Reconnect(ctx context.Context) (err error)
 	FirstDevice(ctx context.Context) (response DevicesResponse, err error)
 	FetchDevices(ctx context.Context) (response []DevicesResponse, err error)
+	AddDevice(ctx context.Context) (response DeviceSessionResponse, err error)
+	ListDeviceSessions(ctx context.Context) (response []DeviceSessionResponse, err error)
+	RemoveDevice(ctx context.Context, deviceID string) (err error)
 }
 
 type DevicesResponse struct {
@@ -19,6 +22,15 @@ type DevicesResponse struct {
 	Device string `json:"device"`
 }
 
+type DeviceSessionResponse struct {
+	ID          string `json:"id"`
+	Token       string `json:"token"`
+	Name        string `json:"name"`
+	IsDefault   bool   `json:"is_default"`
+	IsConnected bool   `json:"is_connected"`
+	IsLoggedIn  bool   `json:"is_logged_in"`
+}
+
 type LoginResponse struct {
 	ImagePath string        `json:"image_path"`
 	Duration  time.Duration `json:"duration"`
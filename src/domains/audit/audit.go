@@ -0,0 +1,41 @@
+package audit
+
+// Request and Response structures for audit log operations
+
+type ListEntriesRequest struct {
+	Actor  string  `json:"actor" query:"actor"`
+	Action string  `json:"action" query:"action"`
+	Source string  `json:"source" query:"source"`
+	Target string  `json:"target" query:"target"`
+	Result string  `json:"result" query:"result"`
+	From   *string `json:"from" query:"from"`
+	To     *string `json:"to" query:"to"`
+	Limit  int     `json:"limit" query:"limit"`
+	Offset int     `json:"offset" query:"offset"`
+}
+
+type ListEntriesResponse struct {
+	Data       []EntryInfo        `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type EntryInfo struct {
+	ID        int64  `json:"id"`
+	Actor     string `json:"actor"`
+	APIKeyID  string `json:"api_key_id,omitempty"`
+	Source    string `json:"source"`
+	Action    string `json:"action"`
+	DeviceID  string `json:"device_id,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
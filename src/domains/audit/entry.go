@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Sources of an audited call
+const (
+	SourceREST = "rest"
+	SourceMCP  = "mcp"
+)
+
+// Results of an audited call
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Entry records a state-changing REST request or MCP tool call and the user behind it
+type Entry struct {
+	ID        int64     `db:"id"`
+	Actor     string    `db:"actor"`      // username of the authenticated user
+	APIKeyID  string    `db:"api_key_id"` // set when the user authenticated with an API key
+	Source    string    `db:"source"`
+	Action    string    `db:"action"` // route, e.g. "POST /send/message", or MCP tool name
+	DeviceID  string    `db:"device_id"`
+	Target    string    `db:"target"`  // JID the call was aimed at
+	Summary   string    `db:"summary"` // request fields, truncated and without secrets
+	Result    string    `db:"result"`
+	Error     string    `db:"error"`
+	MessageID string    `db:"message_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// EntryFilter represents query filters for audit entries
+type EntryFilter struct {
+	Actor  string
+	Action string // matched as prefix, so "POST /group" covers every group change
+	Source string
+	Target string
+	Result string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+type IAuditRepository interface {
+	InsertEntry(ctx context.Context, entry *Entry) error
+	GetEntries(ctx context.Context, filter *EntryFilter) ([]*Entry, error)
+	CountEntries(ctx context.Context, filter *EntryFilter) (int64, error)
+	PurgeEntriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
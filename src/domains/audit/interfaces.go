@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+	"io"
+)
+
+// IAuditUsecase defines the interface for querying the audit log
+type IAuditUsecase interface {
+	ListEntries(ctx context.Context, request ListEntriesRequest) (response ListEntriesResponse, err error)
+	// ExportEntries writes every entry matching the request as CSV, ignoring limit and offset
+	ExportEntries(ctx context.Context, request ListEntriesRequest, w io.Writer) (err error)
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognize
+const APIKeyPrefix = "gowa_"
+
+// apiKeyTouchInterval limits how often the last used time of a key is written
+const apiKeyTouchInterval = time.Minute
+
+// APIKey is a bearer credential of a user, limited to its scopes. Only the hash of the key is stored.
+type APIKey struct {
+	ID         string     `db:"id"`
+	Name       string     `db:"name"`
+	Username   string     `db:"username"` // owner, the key never grants more than the role of the owner
+	KeyHash    string     `db:"key_hash"`
+	Prefix     string     `db:"prefix"` // first characters of the key to recognize it in listings
+	Scopes     []string   `db:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// Expired reports whether the key can no longer be used
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// HashAPIKey returns the stored hash of an API key. Keys are random, so a fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthenticateAPIKey checks an API key and returns the caller it belongs to. The key must not be expired
+// and its owner must be enabled.
+func AuthenticateAPIKey(ctx context.Context, users IUserRepository, key string, now time.Time) (Principal, bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return Principal{}, false
+	}
+
+	apiKey, err := users.GetAPIKeyByHash(ctx, HashAPIKey(key))
+	if err != nil || apiKey == nil || apiKey.Expired(now) {
+		return Principal{}, false
+	}
+	owner, err := users.GetUser(ctx, apiKey.Username)
+	if err != nil || owner == nil || !owner.Enabled {
+		return Principal{}, false
+	}
+
+	// The last used time is informational, a failed write does not reject the request
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		_ = users.TouchAPIKey(ctx, apiKey.ID, now)
+	}
+
+	return Principal{Username: owner.Username, Role: owner.Role, APIKeyID: apiKey.ID, Scopes: apiKey.Scopes}, true
+}
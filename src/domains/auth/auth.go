@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Permissions granted by roles and API key scopes. Every REST route and MCP tool requires one of them.
+// A permission also grants the permissions below it, e.g. "send" grants "send:text".
+const (
+	PermissionAll        = "*"          // every permission, including the ones added later
+	PermissionChatRead   = "chat:read"  // list chats and read their messages
+	PermissionChatWrite  = "chat:write" // change chats, e.g. pin them
+	PermissionUserRead   = "user:read"  // query contacts, profiles and the own account
+	PermissionUserWrite  = "user:write" // change the profile of the own account
+	PermissionSend       = "send"       // /send/*
+	PermissionJobs       = "jobs"       // jobs of the send queue, separate from send so polling them does not use up the send rate limit
+	PermissionCampaign   = "campaign"   // broadcast campaigns, their recipients and reports
+	PermissionTemplate   = "template"   // message templates, see template:read and template:write
+	PermissionAutoReply  = "auto-reply" // rules answering incoming messages
+	PermissionMessage    = "message"    // /message/*
+	PermissionGroup      = "group"      // /group/*
+	PermissionNewsletter = "newsletter" // /newsletter/*
+	PermissionApp        = "app"        // /app/*, devices and the login QR codes of the websocket
+	PermissionEvents     = "events"     // the event stream on /ws and /events
+	PermissionWebhook    = "webhook"    // webhook endpoints and deliveries
+	PermissionUsers      = "users"      // user and API key management
+	PermissionAudit      = "audit"      // read and export the audit log
+
+	PermissionSendText         = "send:text"
+	PermissionSendImage        = "send:image"
+	PermissionSendFile         = "send:file"
+	PermissionSendVideo        = "send:video"
+	PermissionSendSticker      = "send:sticker"
+	PermissionSendContact      = "send:contact"
+	PermissionSendLink         = "send:link"
+	PermissionSendLocation     = "send:location"
+	PermissionSendAudio        = "send:audio"
+	PermissionSendPoll         = "send:poll"
+	PermissionSendPresence     = "send:presence"
+	PermissionSendChatPresence = "send:chat-presence"
+
+	PermissionGroupRead   = "group:read"   // group information and participants
+	PermissionGroupManage = "group:manage" // create, join, leave and change groups
+
+	PermissionTemplateRead  = "template:read"  // list templates and their versions
+	PermissionTemplateWrite = "template:write" // create, change, delete and preview templates
+)
+
+// Permissions lists every permission that can be granted to a custom role or API key
+var Permissions = []string{
+	PermissionAll,
+	PermissionChatRead, PermissionChatWrite,
+	PermissionUserRead, PermissionUserWrite,
+	PermissionSend, PermissionJobs, PermissionCampaign, PermissionTemplate, PermissionAutoReply, PermissionMessage,
+	PermissionGroup, PermissionNewsletter, PermissionApp, PermissionEvents, PermissionWebhook, PermissionUsers, PermissionAudit,
+	PermissionSendText, PermissionSendImage, PermissionSendFile, PermissionSendVideo, PermissionSendSticker,
+	PermissionSendContact, PermissionSendLink, PermissionSendLocation, PermissionSendAudio, PermissionSendPoll,
+	PermissionSendPresence, PermissionSendChatPresence,
+	PermissionGroupRead, PermissionGroupManage,
+	PermissionTemplateRead, PermissionTemplateWrite,
+}
+
+// Grants reports whether one of the granted permissions covers the required permission
+func Grants(granted []string, required string) bool {
+	for _, permission := range granted {
+		if permission == PermissionAll || permission == required || strings.HasPrefix(required, permission+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Built-in roles, each one includes the permissions of the role before it
+const (
+	RoleViewer     = "viewer"
+	RoleSender     = "sender"
+	RoleGroupAdmin = "group-admin"
+	RoleAdmin      = "admin"
+)
+
+var builtinRoles = map[string][]string{
+	RoleViewer:     {PermissionChatRead, PermissionUserRead, PermissionTemplateRead, PermissionEvents},
+	RoleSender:     {PermissionChatRead, PermissionUserRead, PermissionChatWrite, PermissionSend, PermissionJobs, PermissionCampaign, PermissionTemplate, PermissionAutoReply, PermissionMessage, PermissionEvents},
+	RoleGroupAdmin: {PermissionChatRead, PermissionUserRead, PermissionChatWrite, PermissionSend, PermissionJobs, PermissionCampaign, PermissionTemplate, PermissionAutoReply, PermissionMessage, PermissionGroup, PermissionEvents},
+	RoleAdmin:      {PermissionAll},
+}
+
+// User is an account of the app_users table
+type User struct {
+	Username     string    `db:"username"`
+	PasswordHash string    `db:"password_hash"`
+	Role         string    `db:"role"`
+	Enabled      bool      `db:"enabled"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// CheckPassword reports whether the password matches the bcrypt hash of the user
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// Authenticate checks the credentials against the enabled users and returns the caller they belong to
+func Authenticate(ctx context.Context, users IUserRepository, username, password string) (Principal, bool) {
+	user, err := users.GetUser(ctx, username)
+	if err != nil || user == nil || !user.Enabled || !user.CheckPassword(password) {
+		return Principal{}, false
+	}
+	return Principal{Username: user.Username, Role: user.Role}, true
+}
+
+// Principal is the authenticated caller of a request or tool call
+type Principal struct {
+	Username string
+	Role     string
+	APIKeyID string   // set when the caller authenticated with an API key
+	Scopes   []string // scopes of the API key
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal binds the authenticated caller to the context
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller bound to the context
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
+
+// Policy resolves the permissions of the built-in and custom roles
+type Policy struct {
+	roles map[string][]string
+}
+
+// NewPolicy creates a policy with the built-in roles and the custom roles defined as
+// "name=permission|permission", e.g. "support=chat:read|send".
+func NewPolicy(definitions []string) (*Policy, error) {
+	roles := make(map[string][]string, len(builtinRoles)+len(definitions))
+	for name, permissions := range builtinRoles {
+		roles[name] = permissions
+	}
+
+	for _, definition := range definitions {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		name, list, found := strings.Cut(definition, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid role %q, expected name=permission|permission", definition)
+		}
+		if _, exists := roles[name]; exists {
+			return nil, fmt.Errorf("role %q is already defined", name)
+		}
+
+		var permissions []string
+		for _, permission := range strings.Split(list, "|") {
+			permission = strings.TrimSpace(permission)
+			if permission == "" {
+				continue
+			}
+			if !slices.Contains(Permissions, permission) {
+				return nil, fmt.Errorf("role %q has unknown permission %q", name, permission)
+			}
+			permissions = append(permissions, permission)
+		}
+		if len(permissions) == 0 {
+			return nil, fmt.Errorf("role %q has no permissions", name)
+		}
+		roles[name] = permissions
+	}
+
+	return &Policy{roles: roles}, nil
+}
+
+// Roles returns the names of every role, sorted
+func (p *Policy) Roles() []string {
+	names := make([]string, 0, len(p.roles))
+	for name := range p.roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasRole reports whether the role is a built-in or custom role
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Allows reports whether the role grants the permission. Unknown roles grant nothing,
+// an empty permission is granted to every known role.
+func (p *Policy) Allows(role, permission string) bool {
+	permissions, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	if permission == "" {
+		return true
+	}
+	return Grants(permissions, permission)
+}
+
+// Permits reports whether the caller may use the permission. Callers authenticated with an API key
+// are limited to the scopes of the key on top of the role of its owner.
+func (p *Policy) Permits(principal Principal, permission string) bool {
+	if !p.Allows(principal.Role, permission) {
+		return false
+	}
+	return principal.APIKeyID == "" || permission == "" || Grants(principal.Scopes, permission)
+}
@@ -0,0 +1,185 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyBuiltinRoles(t *testing.T) {
+	policy, err := domainAuth.NewPolicy(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		role       string
+		permission string
+		allowed    bool
+	}{
+		{domainAuth.RoleViewer, domainAuth.PermissionChatRead, true},
+		{domainAuth.RoleViewer, domainAuth.PermissionUserRead, true},
+		{domainAuth.RoleViewer, domainAuth.PermissionSend, false},
+		{domainAuth.RoleViewer, domainAuth.PermissionTemplateRead, true},
+		{domainAuth.RoleViewer, domainAuth.PermissionTemplateWrite, false},
+		{domainAuth.RoleViewer, domainAuth.PermissionEvents, true},
+		{domainAuth.RoleViewer, domainAuth.PermissionApp, false},
+		{domainAuth.RoleSender, domainAuth.PermissionSend, true},
+		{domainAuth.RoleSender, domainAuth.PermissionMessage, true},
+		{domainAuth.RoleSender, domainAuth.PermissionTemplateWrite, true},
+		{domainAuth.RoleViewer, domainAuth.PermissionAutoReply, false},
+		{domainAuth.RoleSender, domainAuth.PermissionAutoReply, true},
+		{domainAuth.RoleSender, domainAuth.PermissionGroup, false},
+		{domainAuth.RoleGroupAdmin, domainAuth.PermissionGroup, true},
+		{domainAuth.RoleGroupAdmin, domainAuth.PermissionChatRead, true},
+		{domainAuth.RoleGroupAdmin, domainAuth.PermissionApp, false},
+		{domainAuth.RoleAdmin, domainAuth.PermissionApp, true},
+		{domainAuth.RoleAdmin, domainAuth.PermissionUsers, true},
+		{domainAuth.RoleAdmin, domainAuth.PermissionAll, true},
+		{domainAuth.RoleViewer, "", true},
+		{"unknown", "", false},
+		{"", domainAuth.PermissionChatRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.permission, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, policy.Allows(tt.role, tt.permission))
+		})
+	}
+}
+
+func TestPolicyCustomRoles(t *testing.T) {
+	policy, err := domainAuth.NewPolicy([]string{"support=chat:read|send", " auditor = chat:read "})
+	require.NoError(t, err)
+
+	assert.True(t, policy.Allows("support", domainAuth.PermissionSend))
+	assert.False(t, policy.Allows("support", domainAuth.PermissionUserRead))
+	assert.True(t, policy.Allows("auditor", domainAuth.PermissionChatRead))
+	assert.False(t, policy.Allows("auditor", domainAuth.PermissionSend))
+	assert.Equal(t, []string{"admin", "auditor", "group-admin", "sender", "support", "viewer"}, policy.Roles())
+}
+
+func TestPolicyInvalidRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+	}{
+		{"missing permissions", "support"},
+		{"missing name", "=send"},
+		{"empty permissions", "support=|"},
+		{"unknown permission", "support=send|fly"},
+		{"overrides builtin role", "admin=chat:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domainAuth.NewPolicy([]string{tt.definition})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGrants(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required string
+		allowed  bool
+	}{
+		{"exact", []string{domainAuth.PermissionSendText}, domainAuth.PermissionSendText, true},
+		{"parent grants child", []string{domainAuth.PermissionSend}, domainAuth.PermissionSendImage, true},
+		{"child does not grant parent", []string{domainAuth.PermissionSendText}, domainAuth.PermissionSend, false},
+		{"sibling", []string{domainAuth.PermissionSendText}, domainAuth.PermissionSendImage, false},
+		{"prefix without separator", []string{domainAuth.PermissionSend}, "sender", false},
+		{"all", []string{domainAuth.PermissionAll}, domainAuth.PermissionGroupManage, true},
+		{"nothing granted", nil, domainAuth.PermissionChatRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, domainAuth.Grants(tt.granted, tt.required))
+		})
+	}
+}
+
+func TestPolicyPermitsAPIKeyScopes(t *testing.T) {
+	policy, err := domainAuth.NewPolicy(nil)
+	require.NoError(t, err)
+
+	user := domainAuth.Principal{Username: "alice", Role: domainAuth.RoleSender}
+	assert.True(t, policy.Permits(user, domainAuth.PermissionSendImage))
+
+	key := domainAuth.Principal{Username: "alice", Role: domainAuth.RoleSender, APIKeyID: "k1", Scopes: []string{domainAuth.PermissionSendText}}
+	assert.True(t, policy.Permits(key, domainAuth.PermissionSendText))
+	assert.False(t, policy.Permits(key, domainAuth.PermissionSendImage))
+	assert.True(t, policy.Permits(key, ""))
+
+	// Scopes never grant more than the role of the owner
+	key.Scopes = []string{domainAuth.PermissionGroupManage}
+	assert.False(t, policy.Permits(key, domainAuth.PermissionGroupManage))
+}
+
+type fakeUserRepository struct {
+	domainAuth.IUserRepository
+	users   map[string]*domainAuth.User
+	keys    map[string]*domainAuth.APIKey
+	touched int
+}
+
+func (f *fakeUserRepository) GetUser(_ context.Context, username string) (*domainAuth.User, error) {
+	return f.users[username], nil
+}
+
+func (f *fakeUserRepository) GetAPIKeyByHash(_ context.Context, keyHash string) (*domainAuth.APIKey, error) {
+	return f.keys[keyHash], nil
+}
+
+func (f *fakeUserRepository) TouchAPIKey(_ context.Context, id string, lastUsedAt time.Time) error {
+	f.touched++
+	for _, key := range f.keys {
+		if key.ID == id {
+			key.LastUsedAt = &lastUsedAt
+		}
+	}
+	return nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	secret := domainAuth.APIKeyPrefix + "secret"
+	expiredSecret := domainAuth.APIKeyPrefix + "expired"
+
+	repo := &fakeUserRepository{
+		users: map[string]*domainAuth.User{
+			"alice": {Username: "alice", Role: domainAuth.RoleSender, Enabled: true},
+		},
+		keys: map[string]*domainAuth.APIKey{
+			domainAuth.HashAPIKey(secret):        {ID: "k1", Username: "alice", Scopes: []string{domainAuth.PermissionSendText}},
+			domainAuth.HashAPIKey(expiredSecret): {ID: "k2", Username: "alice", ExpiresAt: &past},
+		},
+	}
+
+	principal, ok := domainAuth.AuthenticateAPIKey(context.Background(), repo, secret, now)
+	require.True(t, ok)
+	assert.Equal(t, domainAuth.Principal{Username: "alice", Role: domainAuth.RoleSender, APIKeyID: "k1", Scopes: []string{domainAuth.PermissionSendText}}, principal)
+	assert.Equal(t, 1, repo.touched)
+
+	// The last used time is written at most once a minute
+	_, ok = domainAuth.AuthenticateAPIKey(context.Background(), repo, secret, now.Add(time.Second))
+	require.True(t, ok)
+	assert.Equal(t, 1, repo.touched)
+
+	_, ok = domainAuth.AuthenticateAPIKey(context.Background(), repo, expiredSecret, now)
+	assert.False(t, ok, "expired key")
+	_, ok = domainAuth.AuthenticateAPIKey(context.Background(), repo, "secret", now)
+	assert.False(t, ok, "missing prefix")
+	_, ok = domainAuth.AuthenticateAPIKey(context.Background(), repo, domainAuth.APIKeyPrefix+"unknown", now)
+	assert.False(t, ok, "unknown key")
+
+	repo.users["alice"].Enabled = false
+	_, ok = domainAuth.AuthenticateAPIKey(context.Background(), repo, secret, now)
+	assert.False(t, ok, "disabled owner")
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// IAuthUsecase defines the management of the app_users accounts
+type IAuthUsecase interface {
+	ListUsers(ctx context.Context) (response []UserInfo, err error)
+	GetUser(ctx context.Context, request UserRequest) (response UserInfo, err error)
+	CreateUser(ctx context.Context, request CreateUserRequest) (response UserInfo, err error)
+	UpdateUser(ctx context.Context, request UpdateUserRequest) (response UserInfo, err error)
+	ResetPassword(ctx context.Context, request ResetPasswordRequest) (response UserInfo, err error)
+	DeleteUser(ctx context.Context, request UserRequest) (err error)
+
+	ListAPIKeys(ctx context.Context, request ListAPIKeysRequest) (response []APIKeyInfo, err error)
+	GetAPIKey(ctx context.Context, request APIKeyRequest) (response APIKeyInfo, err error)
+	CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (response APIKeyInfo, err error)
+	RevokeAPIKey(ctx context.Context, request APIKeyRequest) (err error)
+}
+
+// IUserRepository defines the storage of the app_users accounts and their API keys used for authentication
+type IUserRepository interface {
+	// GetUser returns the user or nil when it does not exist
+	GetUser(ctx context.Context, username string) (*User, error)
+	GetUsers(ctx context.Context) ([]*User, error)
+	CreateUser(ctx context.Context, user *User) error
+	UpdateUser(ctx context.Context, user *User) error
+	// DeleteUser removes the user together with its API keys
+	DeleteUser(ctx context.Context, username string) error
+
+	// GetAPIKey and GetAPIKeyByHash return the key or nil when it does not exist
+	GetAPIKey(ctx context.Context, id string) (*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	// GetAPIKeys returns the keys of a user, or of every user when username is empty
+	GetAPIKeys(ctx context.Context, username string) ([]*APIKey, error)
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	TouchAPIKey(ctx context.Context, id string, lastUsedAt time.Time) error
+	DeleteAPIKey(ctx context.Context, id string) error
+}
@@ -0,0 +1,61 @@
+package auth
+
+// Request and Response structures for the management of the app_users accounts and their API keys
+
+type UserRequest struct {
+	Username string `json:"username" uri:"username"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"` // empty generates a random password
+	Role     string `json:"role" form:"role"`
+	Enabled  *bool  `json:"enabled" form:"enabled"`
+}
+
+type UpdateUserRequest struct {
+	Username string `json:"username" uri:"username"`
+	Role     string `json:"role" form:"role"` // empty keeps the current role
+	Enabled  *bool  `json:"enabled" form:"enabled"`
+}
+
+type ResetPasswordRequest struct {
+	Username string `json:"username" uri:"username"`
+	Password string `json:"password" form:"password"` // empty generates a random password
+}
+
+type UserInfo struct {
+	Username  string `json:"username"`
+	Password  string `json:"password,omitempty"` // only returned when the password was generated
+	Role      string `json:"role"`
+	Enabled   bool   `json:"enabled"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type APIKeyRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type ListAPIKeysRequest struct {
+	Username string `json:"username" query:"username"` // empty lists the keys of every user
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" form:"name"`
+	Username  string   `json:"username" form:"username"`
+	Scopes    []string `json:"scopes" form:"scopes"`
+	ExpiresAt string   `json:"expires_at" form:"expires_at"` // RFC3339, empty never expires
+}
+
+type APIKeyInfo struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Username   string   `json:"username"`
+	Key        string   `json:"key,omitempty"` // only returned when the key is created
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
@@ -0,0 +1,116 @@
+package autoreply
+
+import (
+	"context"
+	"time"
+)
+
+// Chat types a rule can match
+const (
+	ChatPrivate = "private"
+	ChatGroup   = "group"
+)
+
+// ChatTypes lists every chat type a rule can match
+var ChatTypes = []string{ChatPrivate, ChatGroup}
+
+// Media types of the incoming messages a rule can match
+const (
+	MediaText     = "text"
+	MediaImage    = "image"
+	MediaVideo    = "video"
+	MediaAudio    = "audio"
+	MediaDocument = "document"
+	MediaSticker  = "sticker"
+	MediaLocation = "location"
+	MediaContact  = "contact"
+	MediaPoll     = "poll"
+)
+
+// MediaTypes lists every media type a rule can match
+var MediaTypes = []string{MediaText, MediaImage, MediaVideo, MediaAudio, MediaDocument, MediaSticker, MediaLocation, MediaContact, MediaPoll}
+
+// Actions a rule can run on a matching message
+const (
+	ActionReply    = "reply"     // send a text or a template to the chat
+	ActionReact    = "react"     // react to the message with an emoji
+	ActionMarkRead = "mark_read" // mark the message as read
+	ActionLabel    = "label"     // add a WhatsApp Business label to the chat
+	ActionWebhook  = "webhook"   // deliver the message to a webhook endpoint
+)
+
+// ActionTypes lists every action a rule can run
+var ActionTypes = []string{ActionReply, ActionReact, ActionMarkRead, ActionLabel, ActionWebhook}
+
+// Weekdays are the names of the days of a schedule, indexed by time.Weekday
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Rule runs its actions on the incoming messages that match all of its conditions
+type Rule struct {
+	ID       string
+	Name     string
+	Enabled  bool
+	Priority int    // rules are evaluated from the lowest priority up
+	DeviceID string // empty for the messages of every device
+
+	Conditions Conditions
+	Actions    []Action
+
+	Cooldown time.Duration // a rule runs at most once per chat within the cooldown
+	Continue bool          // evaluate the next rules after this one matched, by default the first match wins
+	ReadOnly bool          // the rule of --autoreply cannot be changed through the API
+
+	Actor     string // user the replies are sent as
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Conditions of a rule, the empty ones match every message
+type Conditions struct {
+	Keywords   []string  `json:"keywords,omitempty"` // one of the words or phrases appears in the text, ignoring case
+	Pattern    string    `json:"pattern,omitempty"`  // regular expression the text matches
+	Senders    []string  `json:"senders,omitempty"`  // phone numbers or JIDs of the senders
+	ChatTypes  []string  `json:"chat_types,omitempty"`
+	MediaTypes []string  `json:"media_types,omitempty"`
+	Schedule   *Schedule `json:"schedule,omitempty"`
+}
+
+// Schedule is a weekly time window, like business hours, in a time zone
+type Schedule struct {
+	Timezone string   `json:"timezone,omitempty"` // IANA name, UTC when empty
+	Days     []string `json:"days,omitempty"`     // one of Weekdays, every day when empty
+	From     string   `json:"from,omitempty"`     // HH:MM, the start of the day when empty
+	To       string   `json:"to,omitempty"`       // HH:MM, the end of the day when empty; before From to span midnight
+	Outside  bool     `json:"outside,omitempty"`  // match outside of the window instead, e.g. after business hours
+}
+
+// Action is run on a message matching a rule
+type Action struct {
+	Type       string         `json:"type"`
+	Text       string         `json:"text,omitempty"`        // reply
+	TemplateID string         `json:"template_id,omitempty"` // reply rendered from a template instead of text
+	Variables  map[string]any `json:"variables,omitempty"`   // reply variables on top of name, phone and message
+	Quote      bool           `json:"quote,omitempty"`       // reply quoting the message
+	Emoji      string         `json:"emoji,omitempty"`       // react
+	LabelID    string         `json:"label_id,omitempty"`    // label
+	WebhookID  string         `json:"webhook_id,omitempty"`  // webhook endpoint
+}
+
+// Message is the part of an incoming message the conditions are matched against
+type Message struct {
+	Text      string
+	SenderJID string
+	ChatType  string
+	MediaType string
+	Time      time.Time
+}
+
+// IAutoReplyRepository stores the auto-reply rules
+type IAutoReplyRepository interface {
+	CreateRule(ctx context.Context, rule *Rule) error
+	UpdateRule(ctx context.Context, rule *Rule) error
+	DeleteRule(ctx context.Context, id string) (bool, error)
+	GetRule(ctx context.Context, id string) (*Rule, error)
+	// GetRules returns every rule in the order they are evaluated in
+	GetRules(ctx context.Context) ([]*Rule, error)
+}
@@ -0,0 +1,14 @@
+package autoreply
+
+import (
+	"context"
+)
+
+// IAutoReplyUsecase manages the auto-reply rules, changes apply to the next incoming message
+type IAutoReplyUsecase interface {
+	ListRules(ctx context.Context) (response []RuleInfo, err error)
+	GetRule(ctx context.Context, request RuleRequest) (response RuleInfo, err error)
+	CreateRule(ctx context.Context, request CreateRuleRequest) (response RuleInfo, err error)
+	UpdateRule(ctx context.Context, request UpdateRuleRequest) (response RuleInfo, err error)
+	DeleteRule(ctx context.Context, request RuleRequest) (err error)
+}
@@ -0,0 +1,149 @@
+package autoreply
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Matcher is a rule with its pattern and schedule parsed, ready to be matched against messages
+type Matcher struct {
+	Rule *Rule
+
+	pattern  *regexp.Regexp
+	location *time.Location
+	from, to int // minutes since midnight, to is exclusive
+}
+
+// NewMatcher parses the pattern and schedule of a rule
+func NewMatcher(rule *Rule) (*Matcher, error) {
+	matcher := &Matcher{Rule: rule}
+
+	if rule.Conditions.Pattern != "" {
+		pattern, err := regexp.Compile(rule.Conditions.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		matcher.pattern = pattern
+	}
+
+	if schedule := rule.Conditions.Schedule; schedule != nil {
+		location, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", schedule.Timezone)
+		}
+		matcher.location = location
+
+		if matcher.from, err = parseClock(schedule.From, 0); err != nil {
+			return nil, err
+		}
+		if matcher.to, err = parseClock(schedule.To, 24*60); err != nil {
+			return nil, err
+		}
+		if matcher.from == matcher.to {
+			return nil, fmt.Errorf("schedule from and to are both %s", schedule.From)
+		}
+		for _, day := range schedule.Days {
+			if !slices.Contains(Weekdays, day) {
+				return nil, fmt.Errorf("invalid day %q, use one of %s", day, strings.Join(Weekdays, ", "))
+			}
+		}
+	}
+
+	return matcher, nil
+}
+
+// Matches reports whether the message meets every condition of the rule
+func (m *Matcher) Matches(message Message) bool {
+	conditions := m.Rule.Conditions
+
+	if len(conditions.ChatTypes) > 0 && !slices.Contains(conditions.ChatTypes, message.ChatType) {
+		return false
+	}
+	if len(conditions.MediaTypes) > 0 && !slices.Contains(conditions.MediaTypes, message.MediaType) {
+		return false
+	}
+	if len(conditions.Senders) > 0 && !matchesSender(conditions.Senders, message.SenderJID) {
+		return false
+	}
+	if len(conditions.Keywords) > 0 && !matchesKeyword(conditions.Keywords, message.Text) {
+		return false
+	}
+	if m.pattern != nil && !m.pattern.MatchString(message.Text) {
+		return false
+	}
+	if schedule := conditions.Schedule; schedule != nil && m.inSchedule(message.Time) == schedule.Outside {
+		return false
+	}
+	return true
+}
+
+// inSchedule reports whether a time falls into the window of the schedule. A window spanning midnight belongs to
+// the day it starts on.
+func (m *Matcher) inSchedule(t time.Time) bool {
+	local := t.In(m.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	if m.from > m.to {
+		switch {
+		case minute >= m.from:
+		case minute < m.to:
+			day = (day + 6) % 7
+		default:
+			return false
+		}
+	} else if minute < m.from || minute >= m.to {
+		return false
+	}
+
+	days := m.Rule.Conditions.Schedule.Days
+	return len(days) == 0 || slices.Contains(days, Weekdays[day])
+}
+
+// parseClock parses a HH:MM time of day into minutes since midnight, empty is the fallback
+func parseClock(clock string, fallback int) (int, error) {
+	if clock == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// matchesKeyword reports whether one of the keywords appears in the text as whole words, ignoring case and
+// punctuation, so "price" matches "Price?" but not "priceless"
+func matchesKeyword(keywords []string, text string) bool {
+	words := " " + normalizeWords(text) + " "
+	for _, keyword := range keywords {
+		if keyword = normalizeWords(keyword); keyword != "" && strings.Contains(words, " "+keyword+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWords lowercases a text and separates its words by single spaces
+func normalizeWords(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// matchesSender compares the sender loosely so "628123" also matches "628123@s.whatsapp.net"
+func matchesSender(senders []string, senderJID string) bool {
+	user, _, _ := strings.Cut(senderJID, "@")
+	user, _, _ = strings.Cut(user, ":")
+	for _, sender := range senders {
+		sender = strings.TrimPrefix(sender, "+")
+		if sender == senderJID || sender == user {
+			return true
+		}
+	}
+	return false
+}
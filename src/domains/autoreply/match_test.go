@@ -0,0 +1,151 @@
+package autoreply_test
+
+import (
+	"testing"
+	"time"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherMatches(t *testing.T) {
+	// A Tuesday, 10:30 in Jakarta
+	tuesday := time.Date(2025, 3, 4, 3, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		conditions domainAutoReply.Conditions
+		message    domainAutoReply.Message
+		expected   bool
+	}{
+		{
+			name:     "no conditions match everything",
+			message:  domainAutoReply.Message{Text: "hello", MediaType: domainAutoReply.MediaText},
+			expected: true,
+		},
+		{
+			name:       "keyword ignores case and punctuation",
+			conditions: domainAutoReply.Conditions{Keywords: []string{"price"}},
+			message:    domainAutoReply.Message{Text: "What is the PRICE?"},
+			expected:   true,
+		},
+		{
+			name:       "keyword matches whole words",
+			conditions: domainAutoReply.Conditions{Keywords: []string{"price"}},
+			message:    domainAutoReply.Message{Text: "that is priceless"},
+			expected:   false,
+		},
+		{
+			name:       "keyword phrase",
+			conditions: domainAutoReply.Conditions{Keywords: []string{"opening hours"}},
+			message:    domainAutoReply.Message{Text: "what are your opening   hours, please"},
+			expected:   true,
+		},
+		{
+			name:       "pattern",
+			conditions: domainAutoReply.Conditions{Pattern: `(?i)^order\s+#?\d+$`},
+			message:    domainAutoReply.Message{Text: "Order #1234"},
+			expected:   true,
+		},
+		{
+			name:       "sender by phone",
+			conditions: domainAutoReply.Conditions{Senders: []string{"+628123456789"}},
+			message:    domainAutoReply.Message{SenderJID: "628123456789:12@s.whatsapp.net"},
+			expected:   true,
+		},
+		{
+			name:       "other sender",
+			conditions: domainAutoReply.Conditions{Senders: []string{"628123456789"}},
+			message:    domainAutoReply.Message{SenderJID: "628999@s.whatsapp.net"},
+			expected:   false,
+		},
+		{
+			name:       "chat and media type",
+			conditions: domainAutoReply.Conditions{ChatTypes: []string{domainAutoReply.ChatPrivate}, MediaTypes: []string{domainAutoReply.MediaImage}},
+			message:    domainAutoReply.Message{ChatType: domainAutoReply.ChatGroup, MediaType: domainAutoReply.MediaImage},
+			expected:   false,
+		},
+		{
+			name: "inside business hours",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{
+				Timezone: "Asia/Jakarta", Days: []string{"mon", "tue", "wed", "thu", "fri"}, From: "09:00", To: "17:00",
+			}},
+			message:  domainAutoReply.Message{Time: tuesday},
+			expected: true,
+		},
+		{
+			name: "outside business hours",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{
+				Timezone: "Asia/Jakarta", Days: []string{"mon", "tue", "wed", "thu", "fri"}, From: "09:00", To: "17:00", Outside: true,
+			}},
+			message:  domainAutoReply.Message{Time: tuesday},
+			expected: false,
+		},
+		{
+			name: "business hours on a weekend day",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{
+				Timezone: "Asia/Jakarta", Days: []string{"sat", "sun"}, From: "09:00", To: "17:00",
+			}},
+			message:  domainAutoReply.Message{Time: tuesday},
+			expected: false,
+		},
+		{
+			name: "window spanning midnight belongs to the day it starts",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{
+				Timezone: "Asia/Jakarta", Days: []string{"mon"}, From: "22:00", To: "06:00",
+			}},
+			message:  domainAutoReply.Message{Time: tuesday.Add(-6 * time.Hour)}, // Tuesday 04:30
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := domainAutoReply.NewMatcher(&domainAutoReply.Rule{Conditions: tt.conditions})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matcher.Matches(tt.message))
+		})
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions domainAutoReply.Conditions
+		err        string
+	}{
+		{
+			name:       "invalid pattern",
+			conditions: domainAutoReply.Conditions{Pattern: "(order"},
+			err:        "invalid pattern: error parsing regexp: missing closing ): `(order`",
+		},
+		{
+			name:       "invalid timezone",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{Timezone: "Mars/Olympus"}},
+			err:        `invalid timezone "Mars/Olympus"`,
+		},
+		{
+			name:       "invalid time",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{From: "9am"}},
+			err:        `invalid time "9am", use HH:MM`,
+		},
+		{
+			name:       "invalid day",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{Days: []string{"monday"}}},
+			err:        `invalid day "monday", use one of sun, mon, tue, wed, thu, fri, sat`,
+		},
+		{
+			name:       "empty window",
+			conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{From: "09:00", To: "09:00"}},
+			err:        "schedule from and to are both 09:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domainAutoReply.NewMatcher(&domainAutoReply.Rule{Conditions: tt.conditions})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
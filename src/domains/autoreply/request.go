@@ -0,0 +1,39 @@
+package autoreply
+
+// Request and Response structures for the auto-reply rules
+
+type RuleRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type CreateRuleRequest struct {
+	Name            string     `json:"name"`
+	Enabled         *bool      `json:"enabled"` // defaults to true
+	Priority        int        `json:"priority"`
+	DeviceID        string     `json:"device_id"`
+	Conditions      Conditions `json:"conditions"`
+	Actions         []Action   `json:"actions"`
+	CooldownSeconds int        `json:"cooldown_seconds"`
+	Continue        bool       `json:"continue"`
+}
+
+type UpdateRuleRequest struct {
+	ID string `json:"id" uri:"id"`
+	CreateRuleRequest
+}
+
+type RuleInfo struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Enabled         bool       `json:"enabled"`
+	Priority        int        `json:"priority"`
+	DeviceID        string     `json:"device_id,omitempty"`
+	Conditions      Conditions `json:"conditions"`
+	Actions         []Action   `json:"actions"`
+	CooldownSeconds int        `json:"cooldown_seconds"`
+	Continue        bool       `json:"continue"`
+	ReadOnly        bool       `json:"read_only"`
+	Actor           string     `json:"actor,omitempty"`
+	CreatedAt       string     `json:"created_at,omitempty"`
+	UpdatedAt       string     `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,115 @@
+package campaign
+
+import (
+	"context"
+	"time"
+)
+
+// Campaign states
+const (
+	StatusDraft     = "draft"     // created without start, recipients can be reviewed before it is started
+	StatusRunning   = "running"   // the runner sends to the pending recipients
+	StatusPaused    = "paused"    // no new sends until it is resumed
+	StatusCompleted = "completed" // every recipient was sent to or failed
+	StatusCancelled = "cancelled" // the pending recipients were skipped
+)
+
+// Recipient states, a recipient only moves forward through sent, delivered and read
+const (
+	RecipientPending   = "pending"
+	RecipientSending   = "sending"
+	RecipientQueued    = "queued" // handed to the send queue, see the job ID
+	RecipientSent      = "sent"
+	RecipientDelivered = "delivered"
+	RecipientRead      = "read"
+	RecipientFailed    = "failed"
+	RecipientSkipped   = "skipped" // the campaign was cancelled before it was sent to
+)
+
+// RecipientStatuses lists every recipient state in the order of the report
+var RecipientStatuses = []string{
+	RecipientPending, RecipientSending, RecipientQueued, RecipientSent, RecipientDelivered, RecipientRead,
+	RecipientFailed, RecipientSkipped,
+}
+
+// Media types a campaign can send with the message as caption
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// Campaign is a message sent to a list of recipients by the campaign runner
+type Campaign struct {
+	ID          string     `db:"id"`
+	DeviceID    string     `db:"device_id"` // device selected by the request, empty for the default device
+	Actor       string     `db:"actor"`     // username of the creator, the sends count against its limits
+	Name        string     `db:"name"`
+	Message     string     `db:"message"` // template rendered with the variables of each recipient
+	MediaType   string     `db:"media_type"`
+	MediaURL    string     `db:"media_url"`
+	Rate        string     `db:"rate"` // e.g. 30/1m, empty uses the configured campaign rate
+	Status      string     `db:"status"`
+	Recipients  int        `db:"recipients"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	StartedAt   *time.Time `db:"started_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// Recipient is a recipient of a campaign with its delivery state
+type Recipient struct {
+	ID          int64             `db:"id"`
+	CampaignID  string            `db:"campaign_id"`
+	Phone       string            `db:"phone"`
+	Variables   map[string]string `db:"variables"`
+	Status      string            `db:"status"`
+	MessageID   string            `db:"message_id"`
+	JobID       string            `db:"job_id"`
+	Error       string            `db:"error"`
+	SentAt      *time.Time        `db:"sent_at"`
+	DeliveredAt *time.Time        `db:"delivered_at"`
+	ReadAt      *time.Time        `db:"read_at"`
+	UpdatedAt   time.Time         `db:"updated_at"`
+}
+
+// CampaignFilter represents query filters for campaigns
+type CampaignFilter struct {
+	Status string
+	Actor  string
+	Limit  int
+	Offset int
+}
+
+// RecipientFilter represents query filters for the recipients of a campaign
+type RecipientFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+type ICampaignRepository interface {
+	// CreateCampaign stores a campaign with its recipients in a single transaction
+	CreateCampaign(ctx context.Context, campaign *Campaign, recipients []*Recipient) error
+	GetCampaign(ctx context.Context, id string) (*Campaign, error)
+	GetCampaigns(ctx context.Context, filter *CampaignFilter) ([]*Campaign, error)
+	CountCampaigns(ctx context.Context, filter *CampaignFilter) (int64, error)
+	// SetCampaignStatus changes the status of a campaign that has one of the from statuses, it returns false
+	// when it has none of them
+	SetCampaignStatus(ctx context.Context, id, status string, from ...string) (bool, error)
+
+	GetRecipients(ctx context.Context, campaignID string, filter *RecipientFilter) ([]*Recipient, error)
+	CountRecipients(ctx context.Context, campaignID string, filter *RecipientFilter) (int64, error)
+	CountRecipientsByStatus(ctx context.Context, campaignID string) (map[string]int, error)
+
+	// Runner operations
+	NextPendingRecipient(ctx context.Context, campaignID string) (*Recipient, error)
+	// UpdateRecipient stores the state of a recipient that still has the from status, it returns false otherwise
+	UpdateRecipient(ctx context.Context, recipient *Recipient, from string) (bool, error)
+	// GetQueuedRecipients returns recipients of every campaign whose message waits in the send queue
+	GetQueuedRecipients(ctx context.Context, limit int) ([]*Recipient, error)
+	// FailSendingRecipients fails the recipients left sending by a previous run, whether they were sent is unknown
+	FailSendingRecipients(ctx context.Context, reason string) (int64, error)
+	SkipPendingRecipients(ctx context.Context, campaignID string) (int64, error)
+	// RecordReceipt moves the recipients of the messages forward to delivered or read
+	RecordReceipt(ctx context.Context, messageIDs []string, status string, at time.Time) (int64, error)
+}
@@ -0,0 +1,19 @@
+package campaign
+
+import (
+	"context"
+)
+
+// ICampaignUsecase handles the campaigns and their recipients
+type ICampaignUsecase interface {
+	CreateCampaign(ctx context.Context, request CreateCampaignRequest) (response CampaignInfo, err error)
+	ListCampaigns(ctx context.Context, request ListCampaignsRequest) (response ListCampaignsResponse, err error)
+	GetCampaign(ctx context.Context, request CampaignRequest) (response CampaignInfo, err error)
+	ListRecipients(ctx context.Context, request ListRecipientsRequest) (response ListRecipientsResponse, err error)
+	GetReport(ctx context.Context, request CampaignRequest) (response ReportResponse, err error)
+
+	StartCampaign(ctx context.Context, request CampaignRequest) (response CampaignInfo, err error)
+	PauseCampaign(ctx context.Context, request CampaignRequest) (response CampaignInfo, err error)
+	ResumeCampaign(ctx context.Context, request CampaignRequest) (response CampaignInfo, err error)
+	CancelCampaign(ctx context.Context, request CampaignRequest) (response CampaignInfo, err error)
+}
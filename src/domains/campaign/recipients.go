@@ -0,0 +1,72 @@
+package campaign
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// VariablePhone is set for every recipient to its phone number
+const VariablePhone = "phone"
+
+// Variables returns the variables the message of a campaign is rendered with for a recipient, see
+// domains/template. A variable of the recipient named phone takes precedence over its number.
+func Variables(phone string, variables map[string]string) map[string]any {
+	values := make(map[string]any, len(variables)+1)
+	values[VariablePhone] = phone
+	for name, value := range variables {
+		values[name] = value
+	}
+	return values
+}
+
+// ParseRecipientsCSV reads recipients from CSV with a header row. The phone column is required, every other
+// column becomes a variable named after its header.
+func ParseRecipientsCSV(r io.Reader) ([]RecipientRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	phoneColumn := -1
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if strings.EqualFold(header[i], VariablePhone) {
+			phoneColumn = i
+		}
+	}
+	if phoneColumn < 0 {
+		return nil, errors.New("the CSV has no phone column")
+	}
+
+	var recipients []RecipientRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		recipient := RecipientRequest{Phone: strings.TrimSpace(record[phoneColumn]), Variables: map[string]string{}}
+		if recipient.Phone == "" {
+			continue
+		}
+		for i, value := range record {
+			if i != phoneColumn && header[i] != "" {
+				recipient.Variables[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,32 @@
+package campaign_test
+
+import (
+	"strings"
+	"testing"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariables(t *testing.T) {
+	assert.Equal(t, map[string]any{"phone": "628123", "name": "Ana"}, domainCampaign.Variables("628123", map[string]string{"name": "Ana"}))
+	assert.Equal(t, map[string]any{"phone": "0812"}, domainCampaign.Variables("628123", map[string]string{"phone": "0812"}))
+	assert.Equal(t, map[string]any{"phone": "628123"}, domainCampaign.Variables("628123", nil))
+}
+
+func TestParseRecipientsCSV(t *testing.T) {
+	recipients, err := domainCampaign.ParseRecipientsCSV(strings.NewReader("\ufeffname, Phone,code\nAna,628123,A1\n,,\nBudi, 628456 ,B2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []domainCampaign.RecipientRequest{
+		{Phone: "628123", Variables: map[string]string{"name": "Ana", "code": "A1"}},
+		{Phone: "628456", Variables: map[string]string{"name": "Budi", "code": "B2"}},
+	}, recipients)
+
+	_, err = domainCampaign.ParseRecipientsCSV(strings.NewReader("name,number\nAna,628123\n"))
+	assert.EqualError(t, err, "the CSV has no phone column")
+	_, err = domainCampaign.ParseRecipientsCSV(strings.NewReader(""))
+	assert.EqualError(t, err, "the CSV is empty")
+	_, err = domainCampaign.ParseRecipientsCSV(strings.NewReader("phone,name\n628123\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package campaign
+
+import (
+	"mime/multipart"
+)
+
+// Request and Response structures for campaigns
+
+type CreateCampaignRequest struct {
+	Name          string                `json:"name" form:"name"`
+	Message       string                `json:"message" form:"message"`
+	MediaType     string                `json:"media_type" form:"media_type"`
+	MediaURL      string                `json:"media_url" form:"media_url"`
+	Rate          string                `json:"rate" form:"rate"`
+	Recipients    []RecipientRequest    `json:"recipients" form:"-"`
+	RecipientsCSV *multipart.FileHeader `json:"-" form:"recipients_csv"`
+	GroupID       string                `json:"group_id" form:"group_id"` // adds the participants of the group
+	Start         bool                  `json:"start" form:"start"`       // start right away instead of creating a draft
+}
+
+type RecipientRequest struct {
+	Phone     string            `json:"phone"`
+	Variables map[string]string `json:"variables"`
+}
+
+type CampaignRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type ListCampaignsRequest struct {
+	Status string `json:"status" query:"status"`
+	Actor  string `json:"actor" query:"actor"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type ListCampaignsResponse struct {
+	Data       []CampaignInfo     `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type ListRecipientsRequest struct {
+	ID     string `json:"id" uri:"id"`
+	Status string `json:"status" query:"status"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type ListRecipientsResponse struct {
+	Data       []RecipientInfo    `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type CampaignInfo struct {
+	ID          string `json:"id"`
+	DeviceID    string `json:"device_id,omitempty"`
+	Actor       string `json:"actor,omitempty"`
+	Name        string `json:"name"`
+	Message     string `json:"message"`
+	MediaType   string `json:"media_type,omitempty"`
+	MediaURL    string `json:"media_url,omitempty"`
+	Rate        string `json:"rate"`
+	Status      string `json:"status"`
+	Recipients  int    `json:"recipients"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	StartedAt   string `json:"started_at,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+type RecipientInfo struct {
+	Phone       string            `json:"phone"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Status      string            `json:"status"`
+	MessageID   string            `json:"message_id,omitempty"`
+	JobID       string            `json:"job_id,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	SentAt      string            `json:"sent_at,omitempty"`
+	DeliveredAt string            `json:"delivered_at,omitempty"`
+	ReadAt      string            `json:"read_at,omitempty"`
+}
+
+// ReportResponse rolls up the recipients of a campaign. Sent counts every recipient the message reached
+// WhatsApp for, including the delivered and read ones, and Delivered includes the read ones.
+type ReportResponse struct {
+	Campaign     CampaignInfo   `json:"campaign"`
+	Statuses     map[string]int `json:"statuses"`
+	Total        int            `json:"total"`
+	Sent         int            `json:"sent"`
+	Delivered    int            `json:"delivered"`
+	Read         int            `json:"read"`
+	Failed       int            `json:"failed"`
+	DeliveryRate float64        `json:"delivery_rate"` // delivered / sent
+	ReadRate     float64        `json:"read_rate"`     // read / sent
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
@@ -54,18 +54,37 @@ type ChatInfo struct {
 }
 
 type MessageInfo struct {
-	ID         string `json:"id"`
-	ChatJID    string `json:"chat_jid"`
-	SenderJID  string `json:"sender_jid"`
-	Content    string `json:"content"`
-	Timestamp  string `json:"timestamp"`
-	IsFromMe   bool   `json:"is_from_me"`
-	MediaType  string `json:"media_type"`
-	Filename   string `json:"filename"`
-	URL        string `json:"url"`
-	FileLength uint64 `json:"file_length"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	ID            string         `json:"id"`
+	ChatJID       string         `json:"chat_jid"`
+	SenderJID     string         `json:"sender_jid"`
+	Content       string         `json:"content"`
+	Timestamp     string         `json:"timestamp"`
+	IsFromMe      bool           `json:"is_from_me"`
+	DeliveryState string         `json:"delivery_state,omitempty"` // sent, delivered, read or played, only for our messages
+	Reactions     []ReactionInfo `json:"reactions,omitempty"`
+	IsRevoked     bool           `json:"is_revoked"` // deleted for everyone, the content is what it was before
+	RevokedAt     string         `json:"revoked_at,omitempty"`
+	EditedAt      string         `json:"edited_at,omitempty"` // last edit, content is the edited content
+	Edits         []MessageEdit  `json:"edits,omitempty"`     // earlier contents, oldest first
+	MediaType     string         `json:"media_type"`
+	Filename      string         `json:"filename"`
+	URL           string         `json:"url"`
+	FileLength    uint64         `json:"file_length"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
+}
+
+// MessageEdit is the content a message had until it was edited
+type MessageEdit struct {
+	Content  string `json:"content"`
+	EditedAt string `json:"edited_at"`
+}
+
+// ReactionInfo is an emoji the message was reacted with and who reacted with it
+type ReactionInfo struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	Senders []string `json:"senders"`
 }
 
 type PaginationResponse struct {
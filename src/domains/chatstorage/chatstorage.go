@@ -1,6 +1,9 @@
 package chatstorage
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Chat represents a WhatsApp chat/conversation
 type Chat struct {
@@ -14,21 +17,131 @@ type Chat struct {
 
 // Message represents a WhatsApp message
 type Message struct {
-	ID            string    `db:"id"`
-	ChatJID       string    `db:"chat_jid"`
-	Sender        string    `db:"sender"`
-	Content       string    `db:"content"`
-	Timestamp     time.Time `db:"timestamp"`
-	IsFromMe      bool      `db:"is_from_me"`
-	MediaType     string    `db:"media_type"`
-	Filename      string    `db:"filename"`
-	URL           string    `db:"url"`
-	MediaKey      []byte    `db:"media_key"`
-	FileSHA256    []byte    `db:"file_sha256"`
-	FileEncSHA256 []byte    `db:"file_enc_sha256"`
-	FileLength    uint64    `db:"file_length"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
+	ID            string     `db:"id"`
+	ChatJID       string     `db:"chat_jid"`
+	Sender        string     `db:"sender"`
+	Content       string     `db:"content"`
+	Timestamp     time.Time  `db:"timestamp"`
+	IsFromMe      bool       `db:"is_from_me"`
+	MediaType     string     `db:"media_type"`
+	Filename      string     `db:"filename"`
+	URL           string     `db:"url"`
+	MediaKey      []byte     `db:"media_key"`
+	FileSHA256    []byte     `db:"file_sha256"`
+	FileEncSHA256 []byte     `db:"file_enc_sha256"`
+	FileLength    uint64     `db:"file_length"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+	EditedAt      *time.Time `db:"edited_at"`  // last edit, the earlier contents are kept as MessageEdit
+	RevokedAt     *time.Time `db:"revoked_at"` // deleted for everyone, the content is kept
+}
+
+// MessageEdit is the content a message had before an edit replaced it
+type MessageEdit struct {
+	MessageID string    `db:"message_id"`
+	ChatJID   string    `db:"chat_jid"`
+	Content   string    `db:"content"`
+	EditedAt  time.Time `db:"edited_at"`
+}
+
+// Delivery states of a message we sent, a recipient only moves forward through them
+const (
+	DeliverySent      = "sent"
+	DeliveryDelivered = "delivered"
+	DeliveryRead      = "read"
+	DeliveryPlayed    = "played"
+)
+
+// deliveryStates lists the delivery states from the least to the furthest
+var deliveryStates = []string{DeliverySent, DeliveryDelivered, DeliveryRead, DeliveryPlayed}
+
+// Receipt is what a recipient reported back about a message, a group message has one per participant
+type Receipt struct {
+	MessageID   string     `db:"message_id"`
+	ChatJID     string     `db:"chat_jid"`
+	Participant string     `db:"participant"`
+	DeliveredAt *time.Time `db:"delivered_at"`
+	ReadAt      *time.Time `db:"read_at"`
+	PlayedAt    *time.Time `db:"played_at"`
+}
+
+// State returns the furthest delivery state the recipient reported, a read receipt can arrive without a delivered one
+func (r Receipt) State() string {
+	switch {
+	case r.PlayedAt != nil:
+		return DeliveryPlayed
+	case r.ReadAt != nil:
+		return DeliveryRead
+	case r.DeliveredAt != nil:
+		return DeliveryDelivered
+	default:
+		return DeliverySent
+	}
+}
+
+// DeliveryState returns the furthest state any recipient of a message reached, sent while nobody reported back.
+// WhatsApp does not tell who has yet to report back, so a group message is read once one participant read it.
+func DeliveryState(receipts []*Receipt) string {
+	state := DeliverySent
+	for _, receipt := range receipts {
+		if slices.Index(deliveryStates, receipt.State()) > slices.Index(deliveryStates, state) {
+			state = receipt.State()
+		}
+	}
+	return state
+}
+
+// Reaction is the emoji a sender reacted to a message with, one per sender. An empty emoji is a removed reaction,
+// kept so an older reaction arriving late does not bring it back.
+type Reaction struct {
+	MessageID string    `db:"message_id"` // the message reacted to
+	ChatJID   string    `db:"chat_jid"`
+	Sender    string    `db:"sender"`
+	Emoji     string    `db:"emoji"`
+	IsFromMe  bool      `db:"is_from_me"`
+	Timestamp time.Time `db:"timestamp"`
+}
+
+// Poll is a poll created in a chat. Votes refer to the options by their hashes and are encrypted with the secret of
+// the poll.
+type Poll struct {
+	MessageID       string       `db:"message_id"`
+	ChatJID         string       `db:"chat_jid"`
+	Sender          string       `db:"sender"`
+	Question        string       `db:"question"`
+	Options         []PollOption `db:"options"`          // stored as JSON
+	SelectableCount uint32       `db:"selectable_count"` // 0 lets a voter select every option
+	Secret          []byte       `db:"secret"`
+	Timestamp       time.Time    `db:"timestamp"`
+}
+
+// PollOption is an option of a poll with the hex SHA-256 hash of its name, which votes select it by
+type PollOption struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// PollVote is the latest vote of a voter on a poll, a vote without options is a vote taken back
+type PollVote struct {
+	PollID    string    `db:"poll_id"` // the message ID of the poll
+	ChatJID   string    `db:"chat_jid"`
+	Voter     string    `db:"voter"`
+	Options   []string  `db:"options"` // hashes of the selected options, stored as JSON
+	Timestamp time.Time `db:"timestamp"`
+}
+
+// Voters returns the voters of every option of the poll, in the order of the options. Selected hashes that are not
+// an option of the poll are ignored.
+func (p Poll) Voters(votes []*PollVote) [][]string {
+	voters := make([][]string, len(p.Options))
+	for _, vote := range votes {
+		for i, option := range p.Options {
+			if slices.Contains(vote.Options, option.Hash) {
+				voters[i] = append(voters[i], vote.Voter)
+			}
+		}
+	}
+	return voters
 }
 
 // MediaInfo represents downloadable media information
@@ -0,0 +1,50 @@
+package chatstorage_test
+
+import (
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiptState(t *testing.T) {
+	at := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, domainChatStorage.DeliverySent, domainChatStorage.Receipt{}.State())
+	assert.Equal(t, domainChatStorage.DeliveryDelivered, domainChatStorage.Receipt{DeliveredAt: &at}.State())
+	// A read receipt can arrive without a delivered one
+	assert.Equal(t, domainChatStorage.DeliveryRead, domainChatStorage.Receipt{ReadAt: &at}.State())
+	assert.Equal(t, domainChatStorage.DeliveryPlayed, domainChatStorage.Receipt{DeliveredAt: &at, ReadAt: &at, PlayedAt: &at}.State())
+}
+
+func TestDeliveryState(t *testing.T) {
+	at := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, domainChatStorage.DeliverySent, domainChatStorage.DeliveryState(nil))
+	assert.Equal(t, domainChatStorage.DeliveryRead, domainChatStorage.DeliveryState([]*domainChatStorage.Receipt{
+		{Participant: "628123@s.whatsapp.net", DeliveredAt: &at},
+		{Participant: "628456@s.whatsapp.net", ReadAt: &at},
+		{Participant: "628789@s.whatsapp.net", DeliveredAt: &at},
+	}))
+}
+
+func TestPollVoters(t *testing.T) {
+	poll := domainChatStorage.Poll{Options: []domainChatStorage.PollOption{
+		{Name: "Noodles", Hash: "a1"},
+		{Name: "Rice", Hash: "b2"},
+		{Name: "Soup", Hash: "c3"},
+	}}
+
+	voters := poll.Voters([]*domainChatStorage.PollVote{
+		{Voter: "628123@s.whatsapp.net", Options: []string{"a1", "b2"}},
+		{Voter: "628456@s.whatsapp.net", Options: []string{"b2", "ff"}},
+		// A vote taken back selects nothing
+		{Voter: "628789@s.whatsapp.net", Options: []string{}},
+	})
+	assert.Equal(t, [][]string{
+		{"628123@s.whatsapp.net"},
+		{"628123@s.whatsapp.net", "628456@s.whatsapp.net"},
+		nil,
+	}, voters)
+}
@@ -24,6 +24,28 @@ type IChatStorageRepository interface {
 	SearchMessages(chatJID, searchText string, limit int) ([]*Message, error) // Database-level search
 	DeleteMessage(id, chatJID string) error
 	StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time) error
+	EditMessage(id, chatJID, content string, editedAt time.Time) error            // Replaces the content, keeping the previous one in the edit history
+	RevokeMessage(id, chatJID string, revokedAt time.Time) error                  // Marks the message as deleted for everyone
+	GetMessageEdits(chatJID string, messageIDs ...string) ([]*MessageEdit, error) // Edit history of the messages of a chat, oldest first
+
+	// Receipt operations
+	StoreReceipts(receipts []*Receipt) error                              // Keeps the first time of every state, a receipt never moves one back
+	GetReceipts(chatJID string, messageIDs ...string) ([]*Receipt, error) // Receipts of the messages of a chat
+
+	// Reaction operations
+	StoreReactions(reactions []*Reaction) error                             // Adds, changes or removes the reaction of every sender, the latest one wins
+	GetReactions(chatJID string, messageIDs ...string) ([]*Reaction, error) // Current reactions to the messages of a chat
+
+	// Poll operations
+	StorePoll(poll *Poll) error
+	GetPoll(chatJID, messageID string) (*Poll, error) // nil when the poll is not stored
+	StorePollVote(vote *PollVote) error               // Replaces the vote of the voter, the latest one wins
+	GetPollVotes(chatJID, pollID string) ([]*PollVote, error)
+
+	// Session operations, kept in the chat storage of the default device
+	StoreSessionToken(deviceJID, token string) error
+	GetSessionTokens() (map[string]string, error) // tokens of the additional sessions by device JID
+	DeleteSessionToken(token string) error
 
 	// Statistics
 	GetChatMessageCount(chatJID string) (int64, error)
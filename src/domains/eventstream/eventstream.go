@@ -0,0 +1,85 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+// ErrLagged ends a subscription whose client did not keep up with the events. The client resumes from the token of
+// the last event it received.
+var ErrLagged = errors.New("the client did not keep up with the events, resume from the last resume token")
+
+// Event is a webhook event streamed to the clients, kept in the event log while the event retention lasts
+type Event struct {
+	ID        int64           `db:"id"` // zero when the event is not kept
+	DeviceID  string          `db:"device_id"`
+	Type      string          `db:"type"`  // subscription type, one of domainWebhook.EventTypes
+	Name      string          `db:"event"` // event name of the envelope, e.g. message.ack
+	ChatJID   string          `db:"chat_jid"`
+	Payload   json.RawMessage `db:"payload"` // the event in the envelope of payload version 2
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+// ResumeToken returns the token a client resumes from to receive the events after this one, empty when the event
+// is not kept
+func (e Event) ResumeToken() string {
+	if e.ID == 0 {
+		return ""
+	}
+	return strconv.FormatInt(e.ID, 10)
+}
+
+// ParseResumeToken returns the ID of the last event a client received, zero for an empty token
+func ParseResumeToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid resume token")
+	}
+	return id, nil
+}
+
+// SubscribeRequest selects the events a client receives. Empty lists select every event type and chat.
+type SubscribeRequest struct {
+	Events      []string `json:"events"`
+	Chats       []string `json:"chats"`
+	DeviceID    string   `json:"device_id"`    // only events of this device, by JID or phone number
+	ResumeToken string   `json:"resume_token"` // catch up on the kept events after this one before the live events
+}
+
+// Filter selects the events of a subscription
+type Filter struct {
+	Events   []string
+	Chats    []string
+	DeviceID string
+}
+
+// Wants reports whether the filter selects events of the type
+func (f Filter) Wants(eventType string) bool {
+	return len(f.Events) == 0 || slices.Contains(f.Events, eventType)
+}
+
+// Accepts reports whether the filter selects the event
+func (f Filter) Accepts(event Event) bool {
+	if !f.Wants(event.Type) || !MatchesDevice(f.DeviceID, event.DeviceID) {
+		return false
+	}
+	return len(f.Chats) == 0 || domainWebhook.MatchesChat(f.Chats, event.ChatJID)
+}
+
+// MatchesDevice reports whether the event of a device belongs to the device a client selected.
+// Devices can be matched by full JID or by phone number, an empty selection matches every device.
+func MatchesDevice(selected, deviceID string) bool {
+	if selected == "" || deviceID == "" || selected == deviceID {
+		return true
+	}
+	return strings.HasPrefix(deviceID, selected+":") || strings.HasPrefix(deviceID, selected+"@")
+}
@@ -0,0 +1,51 @@
+package eventstream_test
+
+import (
+	"testing"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterAccepts(t *testing.T) {
+	event := domainEventStream.Event{
+		DeviceID: "628111@s.whatsapp.net",
+		Type:     "message",
+		ChatJID:  "628222@s.whatsapp.net",
+	}
+
+	tests := []struct {
+		name     string
+		filter   domainEventStream.Filter
+		expected bool
+	}{
+		{name: "empty filter", filter: domainEventStream.Filter{}, expected: true},
+		{name: "subscribed event", filter: domainEventStream.Filter{Events: []string{"receipt", "message"}}, expected: true},
+		{name: "other event", filter: domainEventStream.Filter{Events: []string{"receipt"}}, expected: false},
+		{name: "chat by phone number", filter: domainEventStream.Filter{Chats: []string{"628222"}}, expected: true},
+		{name: "other chat", filter: domainEventStream.Filter{Chats: []string{"628333@s.whatsapp.net"}}, expected: false},
+		{name: "device by phone number", filter: domainEventStream.Filter{DeviceID: "628111"}, expected: true},
+		{name: "other device", filter: domainEventStream.Filter{DeviceID: "628999"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Accepts(event))
+		})
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	assert.Equal(t, "", domainEventStream.Event{}.ResumeToken())
+
+	id, err := domainEventStream.ParseResumeToken(domainEventStream.Event{ID: 42}.ResumeToken())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	id, err = domainEventStream.ParseResumeToken("")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), id)
+
+	_, err = domainEventStream.ParseResumeToken("-1")
+	assert.Error(t, err)
+}
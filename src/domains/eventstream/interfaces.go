@@ -0,0 +1,27 @@
+package eventstream
+
+import (
+	"context"
+	"time"
+)
+
+// Subscription delivers the events of a filter, first the kept events after the resume token and then the live ones
+type Subscription interface {
+	// Events is closed when the subscription ends, Err tells why
+	Events() <-chan Event
+	// Err returns ErrLagged or the error of the catch-up once Events is closed, nil when the subscription was closed
+	Err() error
+	Close()
+}
+
+// IEventStreamUsecase subscribes clients to the events of the devices
+type IEventStreamUsecase interface {
+	Subscribe(ctx context.Context, request SubscribeRequest) (Subscription, error)
+}
+
+type IEventStreamRepository interface {
+	StoreEvent(ctx context.Context, event *Event) error
+	// GetEventsAfter returns the oldest events with an ID above the given one, in order
+	GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*Event, error)
+	PurgeEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -51,3 +51,45 @@ type DownloadMediaResponse struct {
 	FilePath  string `json:"file_path"`
 	FileSize  int64  `json:"file_size"`
 }
+
+type MessageStatusRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" form:"phone"`
+}
+
+// MessageStatusResponse is the delivery state of a message, with the receipt of every recipient that reported back
+type MessageStatusResponse struct {
+	MessageID string           `json:"message_id"`
+	ChatJID   string           `json:"chat_jid"`
+	State     string           `json:"state"` // the furthest state a recipient reached: sent, delivered, read or played
+	Receipts  []MessageReceipt `json:"receipts"`
+}
+
+type MessageReceipt struct {
+	ParticipantJID string `json:"participant_jid"`
+	State          string `json:"state"`
+	DeliveredAt    string `json:"delivered_at,omitempty"`
+	ReadAt         string `json:"read_at,omitempty"`
+	PlayedAt       string `json:"played_at,omitempty"`
+}
+
+type PollResultsRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" form:"phone"`
+}
+
+// PollResultsResponse is the count of the latest vote of every voter on a poll
+type PollResultsResponse struct {
+	MessageID       string       `json:"message_id"`
+	ChatJID         string       `json:"chat_jid"`
+	Question        string       `json:"question"`
+	SelectableCount uint32       `json:"selectable_count"` // 0 lets a voter select every option
+	Options         []PollResult `json:"options"`
+	TotalVoters     int          `json:"total_voters"` // voters with at least one selected option
+}
+
+type PollResult struct {
+	Name   string   `json:"name"`
+	Count  int      `json:"count"`
+	Voters []string `json:"voters"`
+}
@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+// DefaultTimeout bounds the acknowledgement of an event and every call of a plugin unless it sets its own timeout
+const DefaultTimeout = 30 * time.Second
+
+// Config is a plugin executable started by the host, configured like
+// "echo=python3 bots/echo.py;events=message|receipt;chats=628123456789|120363025246125888@g.us;timeout=10s"
+type Config struct {
+	Name    string
+	Command []string      // executable and its arguments, split on spaces
+	Events  []string      // event types streamed to the plugin, message only by default
+	Chats   []string      // when not empty, only events of these chats are streamed
+	Timeout time.Duration // how long an event acknowledgement and a call of the plugin may take
+}
+
+// Accepts reports whether the plugin wants the given event of the given chat.
+// An empty chat JID (events not bound to a chat) only passes when no chat filter is set.
+func (c Config) Accepts(eventType string, chatJID string) bool {
+	if !slices.Contains(c.Events, eventType) {
+		return false
+	}
+	if len(c.Chats) > 0 {
+		return chatJID != "" && domainWebhook.MatchesChat(c.Chats, chatJID)
+	}
+	return true
+}
+
+// ParseConfigs parses the plugins configured with --plugin, every plugin needs its own name
+func ParseConfigs(definitions []string) ([]Config, error) {
+	var configs []Config
+	for _, definition := range definitions {
+		if strings.TrimSpace(definition) == "" {
+			continue
+		}
+		config, err := ParseConfig(definition)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(configs, func(c Config) bool { return c.Name == config.Name }) {
+			return nil, fmt.Errorf("plugin %q is configured twice", config.Name)
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// ParseConfig parses a plugin like "echo=python3 bots/echo.py;events=message|receipt;timeout=10s"
+func ParseConfig(definition string) (Config, error) {
+	options := strings.Split(definition, ";")
+	name, command, ok := strings.Cut(options[0], "=")
+	config := Config{
+		Name:    strings.TrimSpace(name),
+		Command: strings.Fields(command),
+		Events:  []string{domainWebhook.EventMessage},
+		Timeout: DefaultTimeout,
+	}
+	if !ok || config.Name == "" || len(config.Command) == 0 {
+		return Config{}, fmt.Errorf("plugin %q must look like <name>=<command>[;events=<event>|<event>][;chats=<jid>|<jid>][;timeout=<duration>]", definition)
+	}
+
+	for _, option := range options[1:] {
+		key, value, _ := strings.Cut(option, "=")
+		switch strings.TrimSpace(key) {
+		case "events":
+			config.Events = splitValues(value)
+			for _, event := range config.Events {
+				if !slices.Contains(domainWebhook.EventTypes, event) {
+					return Config{}, fmt.Errorf("plugin %s: event %q must be one of %v", config.Name, event, domainWebhook.EventTypes)
+				}
+			}
+			if len(config.Events) == 0 {
+				return Config{}, fmt.Errorf("plugin %s: events must not be empty", config.Name)
+			}
+		case "chats":
+			config.Chats = splitValues(value)
+		case "timeout":
+			timeout, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || timeout <= 0 {
+				return Config{}, fmt.Errorf("plugin %s: timeout %q must be a positive duration, e.g. 10s", config.Name, value)
+			}
+			config.Timeout = timeout
+		case "":
+		default:
+			return Config{}, fmt.Errorf("plugin %s: unknown option %q, use events, chats or timeout", config.Name, key)
+		}
+	}
+	return config, nil
+}
+
+// splitValues splits the values of an option separated by "|"
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
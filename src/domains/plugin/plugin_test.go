@@ -0,0 +1,90 @@
+package plugin_test
+
+import (
+	"testing"
+	"time"
+
+	domainPlugin "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		expected   domainPlugin.Config
+		err        string
+	}{
+		{
+			name:       "command only",
+			definition: "echo=python3 bots/echo.py --verbose",
+			expected: domainPlugin.Config{
+				Name:    "echo",
+				Command: []string{"python3", "bots/echo.py", "--verbose"},
+				Events:  []string{"message"},
+				Timeout: domainPlugin.DefaultTimeout,
+			},
+		},
+		{
+			name:       "every option",
+			definition: "crm = ./crm-bot ; events=message|receipt|group ; chats=628123456789|120363025246125888@g.us ; timeout=5s",
+			expected: domainPlugin.Config{
+				Name:    "crm",
+				Command: []string{"./crm-bot"},
+				Events:  []string{"message", "receipt", "group"},
+				Chats:   []string{"628123456789", "120363025246125888@g.us"},
+				Timeout: 5 * time.Second,
+			},
+		},
+		{
+			name:       "missing command",
+			definition: "echo=",
+			err:        `plugin "echo=" must look like <name>=<command>[;events=<event>|<event>][;chats=<jid>|<jid>][;timeout=<duration>]`,
+		},
+		{
+			name:       "unknown event",
+			definition: "echo=./echo;events=message|typing",
+			err:        "plugin echo: event \"typing\" must be one of [message receipt group delete presence send_job poll]",
+		},
+		{
+			name:       "invalid timeout",
+			definition: "echo=./echo;timeout=-1s",
+			err:        `plugin echo: timeout "-1s" must be a positive duration, e.g. 10s`,
+		},
+		{
+			name:       "unknown option",
+			definition: "echo=./echo;retries=3",
+			err:        `plugin echo: unknown option "retries", use events, chats or timeout`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := domainPlugin.ParseConfig(tt.definition)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+}
+
+func TestParseConfigsRejectsDuplicateNames(t *testing.T) {
+	_, err := domainPlugin.ParseConfigs([]string{"echo=./echo", "", "echo=./other"})
+	assert.EqualError(t, err, `plugin "echo" is configured twice`)
+}
+
+func TestConfigAccepts(t *testing.T) {
+	config := domainPlugin.Config{Events: []string{"message", "presence"}, Chats: []string{"628123456789"}}
+
+	assert.True(t, config.Accepts("message", "628123456789@s.whatsapp.net"))
+	assert.False(t, config.Accepts("receipt", "628123456789@s.whatsapp.net"))
+	assert.False(t, config.Accepts("message", "628999@s.whatsapp.net"))
+	assert.False(t, config.Accepts("presence", ""))
+
+	config.Chats = nil
+	assert.True(t, config.Accepts("presence", ""))
+}
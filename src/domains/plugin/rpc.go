@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"encoding/json"
+)
+
+// Plugins talk JSON-RPC 2.0 with the host, one message per line on stdin and stdout. The host sends the events as
+// requests the plugin acknowledges with any result, the plugin calls the usecases with requests of its own.
+
+// JSONRPCVersion is the version every message carries
+const JSONRPCVersion = "2.0"
+
+// MethodEvent streams an event to the plugin, the params are the event in the envelope of webhook payload version 2
+const MethodEvent = "event"
+
+// Error codes of the JSON-RPC specification, and the code of the errors returned by the usecases
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeUsecaseError   = -32000
+)
+
+// Message is a JSON-RPC request, notification or response. Requests have a method and an ID, notifications only
+// a method, and responses an ID with a result or an error.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// IsRequest reports whether the message is a request or notification of the other side
+func (m *Message) IsRequest() bool {
+	return m.Method != ""
+}
+
+// Error is the error of a JSON-RPC response
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorData tells the error of a usecase apart like the REST API does
+type ErrorData struct {
+	Code   string `json:"code"`   // e.g. VALIDATION_ERROR
+	Status int    `json:"status"` // HTTP status the REST API would answer with
+}
+
+// CallParams selects the device a call of a plugin runs on, the rest of the params is the request of the usecase.
+// Events carry the device in their device_id, so a plugin answers on the device that received the event.
+type CallParams struct {
+	DeviceID string `json:"device_id"`
+}
@@ -0,0 +1,10 @@
+package ratelimit
+
+import (
+	"context"
+)
+
+// IRateLimitUsecase defines the interface for inspecting the rate limits and send quotas
+type IRateLimitUsecase interface {
+	GetUsage(ctx context.Context, request UsageRequest) (response UsageResponse, err error)
+}
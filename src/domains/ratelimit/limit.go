@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+)
+
+// ClassDefault configures the limit of every route class without its own limit
+const ClassDefault = "*"
+
+// ClassSend is the route class of the sends, the only class with recipient limits and quotas
+const ClassSend = domainAuth.PermissionSend
+
+// Limit is a token bucket of Count tokens that refills completely within Per
+type Limit struct {
+	Count int
+	Per   time.Duration
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Count, l.Per)
+}
+
+// rate returns the tokens refilled per second
+func (l Limit) rate() float64 {
+	return float64(l.Count) / l.Per.Seconds()
+}
+
+// ParseLimit parses a limit like "30/1m", 30 requests per minute
+func ParseLimit(value string) (Limit, error) {
+	count, per, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q must look like <count>/<duration>, e.g. 30/1m", value)
+	}
+
+	limit := Limit{}
+	var err error
+	if limit.Count, err = strconv.Atoi(strings.TrimSpace(count)); err != nil || limit.Count < 1 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive count", value)
+	}
+	if limit.Per, err = time.ParseDuration(strings.TrimSpace(per)); err != nil || limit.Per <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive duration", value)
+	}
+	return limit, nil
+}
+
+// ParseClassLimits parses limits per route class like "send=30/1m", the class "*" applies to every other class
+func ParseClassLimits(definitions []string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(definitions))
+	for _, definition := range definitions {
+		if strings.TrimSpace(definition) == "" {
+			continue
+		}
+		class, value, ok := strings.Cut(definition, "=")
+		class = strings.TrimSpace(class)
+		if !ok || class == "" {
+			return nil, fmt.Errorf("rate limit %q must look like <class>=<count>/<duration>, e.g. send=30/1m", definition)
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+// RouteClass returns the class of the routes and tools requiring a permission, e.g. "send" for "send:text".
+// Calls without a permission are not limited and return an empty class.
+func RouteClass(permission string) string {
+	if permission == domainAuth.PermissionAll {
+		return "admin"
+	}
+	class, _, _ := strings.Cut(permission, ":")
+	return class
+}
+
+// SendsMessage reports whether a call requiring a permission sends a message, which the recipient limits and send
+// quotas count. Presence updates share the send route class but deliver no message.
+func SendsMessage(permission string) bool {
+	switch permission {
+	case domainAuth.PermissionSendPresence, domainAuth.PermissionSendChatPresence:
+		return false
+	default:
+		return RouteClass(permission) == ClassSend
+	}
+}
+
+// Subject returns who a limit is counted for: the API key the caller authenticated with, otherwise the user
+func Subject(principal domainAuth.Principal) string {
+	switch {
+	case principal.APIKeyID != "":
+		return "key:" + principal.APIKeyID
+	case principal.Username != "":
+		return "user:" + principal.Username
+	default:
+		return "anonymous"
+	}
+}
+
+// RecipientSubject returns the subject of the limit shared by every sender to a recipient
+func RecipientSubject(recipient string) string {
+	recipient = strings.TrimPrefix(strings.TrimSpace(recipient), "+")
+	recipient = strings.TrimSuffix(recipient, "@s.whatsapp.net")
+	return "recipient:" + recipient
+}
+
+// Bucket is the persisted state of a token bucket
+type Bucket struct {
+	Subject   string    `db:"subject"`
+	Class     string    `db:"class"`
+	Tokens    float64   `db:"tokens"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Refill returns the tokens of the bucket at the given time
+func (b *Bucket) Refill(limit Limit, now time.Time) float64 {
+	elapsed := now.Sub(b.UpdatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Count), b.Tokens+elapsed*limit.rate())
+}
+
+// Take removes a token from the bucket. When it is empty the bucket is unchanged and the time until the
+// next token is returned.
+func (b *Bucket) Take(limit Limit, now time.Time) (time.Duration, bool) {
+	tokens := b.Refill(limit, now)
+	if tokens < 1 {
+		return time.Duration((1 - tokens) / limit.rate() * float64(time.Second)), false
+	}
+	b.Tokens = tokens - 1
+	b.UpdatedAt = now
+	return 0, true
+}
+
+// Return gives a taken token back to the bucket, e.g. for a call that failed
+func (b *Bucket) Return(limit Limit) {
+	b.Tokens = math.Min(float64(limit.Count), b.Tokens+1)
+}
+
+// Quota periods of the send quotas
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Period returns the key of the day or month a time belongs to (UTC) and when it ends
+func Period(period string, now time.Time) (string, time.Time) {
+	now = now.UTC()
+	if period == PeriodMonth {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return "month:" + start.Format("2006-01"), start.AddDate(0, 1, 0)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return "day:" + start.Format("2006-01-02"), start.AddDate(0, 0, 1)
+}
+
+// Usage counts the sends of a subject within a quota period
+type Usage struct {
+	Subject   string    `db:"subject"`
+	Period    string    `db:"period"`
+	Count     int64     `db:"count"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type IRateLimitRepository interface {
+	// GetBucket returns the bucket or nil when it has not been used yet
+	GetBucket(ctx context.Context, subject, class string) (*Bucket, error)
+	// GetBuckets returns the buckets of a subject, or of every user and API key when subject is empty
+	GetBuckets(ctx context.Context, subject string) ([]*Bucket, error)
+	SaveBucket(ctx context.Context, bucket *Bucket) error
+	PurgeBucketsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	GetUsage(ctx context.Context, subject, period string) (int64, error)
+	// GetUsages returns the usage in the period of a subject, or of every subject when subject is empty
+	GetUsages(ctx context.Context, subject, period string) ([]*Usage, error)
+	IncrementUsage(ctx context.Context, subject, period string, now time.Time) error
+	PurgeUsageBefore(ctx context.Context, before time.Time) (int64, error)
+}
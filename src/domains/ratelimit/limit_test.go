@@ -0,0 +1,98 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := domainRateLimit.ParseLimit(" 30 / 1m ")
+	require.NoError(t, err)
+	assert.Equal(t, domainRateLimit.Limit{Count: 30, Per: time.Minute}, limit)
+	assert.Equal(t, "30/1m0s", limit.String())
+
+	for _, value := range []string{"30", "0/1m", "-1/1m", "30/0s", "30/minute", "/1m"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := domainRateLimit.ParseLimit(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseClassLimits(t *testing.T) {
+	limits, err := domainRateLimit.ParseClassLimits([]string{"send=30/1m", " * = 300/1m ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domainRateLimit.Limit{
+		domainRateLimit.ClassSend:    {Count: 30, Per: time.Minute},
+		domainRateLimit.ClassDefault: {Count: 300, Per: time.Minute},
+	}, limits)
+
+	_, err = domainRateLimit.ParseClassLimits([]string{"30/1m"})
+	assert.Error(t, err)
+	_, err = domainRateLimit.ParseClassLimits([]string{"send=30"})
+	assert.Error(t, err)
+}
+
+func TestRouteClass(t *testing.T) {
+	assert.Equal(t, "send", domainRateLimit.RouteClass(domainAuth.PermissionSendText))
+	assert.Equal(t, "send", domainRateLimit.RouteClass(domainAuth.PermissionSend))
+	assert.Equal(t, "group", domainRateLimit.RouteClass(domainAuth.PermissionGroupManage))
+	assert.Equal(t, "admin", domainRateLimit.RouteClass(domainAuth.PermissionAll))
+	assert.Equal(t, "", domainRateLimit.RouteClass(""))
+}
+
+func TestSendsMessage(t *testing.T) {
+	assert.True(t, domainRateLimit.SendsMessage(domainAuth.PermissionSendText))
+	assert.True(t, domainRateLimit.SendsMessage(domainAuth.PermissionSend))
+	assert.False(t, domainRateLimit.SendsMessage(domainAuth.PermissionSendPresence))
+	assert.False(t, domainRateLimit.SendsMessage(domainAuth.PermissionSendChatPresence))
+	assert.False(t, domainRateLimit.SendsMessage(domainAuth.PermissionGroupManage))
+}
+
+func TestSubject(t *testing.T) {
+	assert.Equal(t, "key:abc", domainRateLimit.Subject(domainAuth.Principal{Username: "john", APIKeyID: "abc"}))
+	assert.Equal(t, "user:john", domainRateLimit.Subject(domainAuth.Principal{Username: "john"}))
+	assert.Equal(t, "anonymous", domainRateLimit.Subject(domainAuth.Principal{}))
+	assert.Equal(t, "recipient:628123456789", domainRateLimit.RecipientSubject("+628123456789@s.whatsapp.net"))
+	assert.Equal(t, "recipient:120363@g.us", domainRateLimit.RecipientSubject("120363@g.us"))
+}
+
+func TestBucketTake(t *testing.T) {
+	limit := domainRateLimit.Limit{Count: 2, Per: time.Minute}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	bucket := &domainRateLimit.Bucket{Tokens: float64(limit.Count), UpdatedAt: now}
+
+	_, ok := bucket.Take(limit, now)
+	assert.True(t, ok)
+	_, ok = bucket.Take(limit, now)
+	assert.True(t, ok)
+
+	wait, ok := bucket.Take(limit, now)
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+	assert.Equal(t, 0.0, bucket.Tokens)
+
+	// Half of the limit refills within half of the period
+	assert.InDelta(t, 1.0, bucket.Refill(limit, now.Add(30*time.Second)), 0.0001)
+	assert.Equal(t, 2.0, bucket.Refill(limit, now.Add(time.Hour)))
+
+	_, ok = bucket.Take(limit, now.Add(30*time.Second))
+	assert.True(t, ok)
+}
+
+func TestPeriod(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 30, 0, 0, time.UTC)
+
+	day, end := domainRateLimit.Period(domainRateLimit.PeriodDay, now)
+	assert.Equal(t, "day:2026-12-31", day)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	month, end := domainRateLimit.Period(domainRateLimit.PeriodMonth, now)
+	assert.Equal(t, "month:2026-12", month)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
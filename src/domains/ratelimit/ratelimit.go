@@ -0,0 +1,31 @@
+package ratelimit
+
+// Request and Response structures for rate limit operations
+
+type UsageRequest struct {
+	Username string `json:"username" query:"username"` // empty returns the usage of every user and API key
+}
+
+type UsageResponse struct {
+	Limits         map[string]string `json:"limits"` // per route class, "*" applies to the classes without a limit
+	RecipientLimit string            `json:"recipient_limit,omitempty"`
+	DailyQuota     int64             `json:"daily_quota"`   // zero is unlimited
+	MonthlyQuota   int64             `json:"monthly_quota"` // zero is unlimited
+	Subjects       []SubjectUsage    `json:"subjects"`
+}
+
+type SubjectUsage struct {
+	Subject      string       `json:"subject"` // user:<username> or key:<api key id>
+	Username     string       `json:"username"`
+	APIKeyID     string       `json:"api_key_id,omitempty"`
+	APIKeyName   string       `json:"api_key_name,omitempty"`
+	DailySends   int64        `json:"daily_sends"`
+	MonthlySends int64        `json:"monthly_sends"`
+	Buckets      []BucketInfo `json:"buckets"`
+}
+
+type BucketInfo struct {
+	Class     string  `json:"class"`
+	Limit     string  `json:"limit"`
+	Remaining float64 `json:"remaining"` // tokens left, a request takes one
+}
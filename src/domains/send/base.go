@@ -4,4 +4,10 @@ type BaseRequest struct {
 	Phone       string `json:"phone" form:"phone"`
 	Duration    *int   `json:"duration,omitempty" form:"duration"`
 	IsForwarded bool   `json:"is_forwarded,omitempty" form:"is_forwarded"`
+	SendAt      string `json:"send_at,omitempty" form:"send_at"` // RFC3339 time to send the message at, empty to send right away
+
+	// TemplateID renders the text, and the media of the template if it has one, from a stored template
+	TemplateID      string         `json:"template_id,omitempty" form:"template_id"`
+	TemplateVersion int            `json:"template_version,omitempty" form:"template_version"` // 0 for the current version
+	Variables       map[string]any `json:"variables,omitempty" form:"-"`
 }
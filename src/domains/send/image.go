@@ -4,9 +4,10 @@ import "mime/multipart"
 
 type ImageRequest struct {
 	BaseRequest
-	Caption  string                `json:"caption" form:"caption"`
-	Image    *multipart.FileHeader `json:"image" form:"image"`
-	ImageURL *string               `json:"image_url" form:"image_url"`
-	ViewOnce bool                  `json:"view_once" form:"view_once"`
-	Compress bool                  `json:"compress"`
+	Caption        string                `json:"caption" form:"caption"`
+	Image          *multipart.FileHeader `json:"image" form:"image"`
+	ImageURL       *string               `json:"image_url" form:"image_url"`
+	ReplyMessageID *string               `json:"reply_message_id" form:"reply_message_id"`
+	ViewOnce       bool                  `json:"view_once" form:"view_once"`
+	Compress       bool                  `json:"compress"`
 }
@@ -39,3 +39,11 @@ type ISendUsecase interface {
 	IInteractionSender
 	IPresenceSender
 }
+
+// ISendJobUsecase handles the jobs of the send queue
+type ISendJobUsecase interface {
+	ListJobs(ctx context.Context, request ListJobsRequest) (response ListJobsResponse, err error)
+	GetJob(ctx context.Context, request JobRequest) (response JobInfo, err error)
+	CancelJob(ctx context.Context, request JobRequest) (response JobInfo, err error)
+	UpdateJob(ctx context.Context, request UpdateJobRequest) (response JobInfo, err error)
+}
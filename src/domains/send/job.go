@@ -0,0 +1,157 @@
+package send
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Job states of the send queue
+const (
+	JobStatusQueued    = "queued"
+	JobStatusSending   = "sending"
+	JobStatusSent      = "sent"
+	JobStatusFailed    = "failed" // retries exhausted or interrupted while sending, it is not sent again
+	JobStatusCancelled = "cancelled"
+)
+
+// Job is a prepared message persisted in the send queue until the worker has sent it
+type Job struct {
+	ID             string     `db:"id"`
+	DeviceID       string     `db:"device_id"` // device selected by the request, empty for the default device
+	Actor          string     `db:"actor"`     // username of the caller
+	IdempotencyKey string     `db:"idempotency_key"`
+	Recipient      string     `db:"recipient"`
+	Message        []byte     `db:"message"` // protobuf encoded waE2E.Message
+	Content        string     `db:"content"` // text stored in the chat storage once sent
+	MessageID      string     `db:"message_id"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	LastError      string     `db:"last_error"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	ScheduledAt    *time.Time `db:"scheduled_at"` // requested send time of a scheduled message
+	SentAt         *time.Time `db:"sent_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// Finished reports whether the worker is done with the job
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusSent || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+// JobFilter represents query filters for jobs
+type JobFilter struct {
+	DeviceIDs []string // any of the identifiers of a device, see whatsapp.DeviceAliases
+	Actor     string
+	Status    string
+	Recipient string
+	Scheduled bool // only scheduled jobs
+	Limit     int
+	Offset    int
+}
+
+type ISendJobRepository interface {
+	// EnqueueJob stores a new job. It returns false, and stores nothing, when the device and actor already
+	// have a job with the same idempotency key.
+	EnqueueJob(ctx context.Context, job *Job) (bool, error)
+	GetJob(ctx context.Context, id string) (*Job, error)
+	GetJobByIdempotencyKey(ctx context.Context, deviceID, actor, key string) (*Job, error)
+	GetJobs(ctx context.Context, filter *JobFilter) ([]*Job, error)
+	CountJobs(ctx context.Context, filter *JobFilter) (int64, error)
+
+	// Worker operations
+	GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*Job, error)
+	// MarkSending moves a queued job to sending, it returns false when the job is no longer queued
+	MarkSending(ctx context.Context, id string) (bool, error)
+	MarkSent(ctx context.Context, id string, attempts int, sentAt time.Time) error
+	MarkRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id string, attempts int, lastError string) error
+	// FailSendingJobs fails the jobs left sending by a previous run, whether they were sent is unknown
+	FailSendingJobs(ctx context.Context, lastError string) (int64, error)
+
+	// GetMissedJobs returns the queued scheduled jobs that were due before the given time and never attempted
+	GetMissedJobs(ctx context.Context, before time.Time) ([]*Job, error)
+
+	// CancelJob cancels a queued job, it returns false when the job is no longer queued
+	CancelJob(ctx context.Context, id string) (bool, error)
+	// RescheduleJob changes the send time of a queued job, it returns false when the job is no longer queued
+	RescheduleJob(ctx context.Context, id string, sendAt time.Time) (bool, error)
+	PurgeFinishedJobsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Catch-up policies of the scheduled jobs missed while the server was not running
+const (
+	CatchUpAll  = "all"  // send every missed job
+	CatchUpNone = "none" // fail every missed job
+)
+
+// CatchUp decides whether a scheduled job missed while the server was not running is still sent
+type CatchUp struct {
+	All      bool
+	MaxDelay time.Duration // without All, jobs missed by up to MaxDelay are sent
+}
+
+// ParseCatchUp parses a catch-up policy, "all", "none" or the duration a job may be missed by and still be sent
+func ParseCatchUp(policy string) (CatchUp, error) {
+	switch policy {
+	case "", CatchUpAll:
+		return CatchUp{All: true}, nil
+	case CatchUpNone:
+		return CatchUp{}, nil
+	}
+
+	maxDelay, err := time.ParseDuration(policy)
+	if err != nil || maxDelay < 0 {
+		return CatchUp{}, fmt.Errorf("invalid catch-up policy %q, want %s, %s or a duration like 1h", policy, CatchUpAll, CatchUpNone)
+	}
+	return CatchUp{MaxDelay: maxDelay}, nil
+}
+
+// Sends reports whether a job missed by the given delay is still sent
+func (c CatchUp) Sends(delay time.Duration) bool {
+	return c.All || delay <= c.MaxDelay
+}
+
+type idempotencyKeyContextKey struct{}
+
+// idempotency is the Idempotency-Key of a request and whether it returned the job of an earlier request
+type idempotency struct {
+	key      string
+	replayed bool
+}
+
+// ContextWithIdempotencyKey binds the Idempotency-Key of a request to the context
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, &idempotency{key: key})
+}
+
+// IdempotencyKeyFromContext returns the Idempotency-Key of the request, empty when none was sent
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if value := idempotencyFromContext(ctx); value != nil {
+		return value.key
+	}
+	return ""
+}
+
+// MarkIdempotentReplay records that the Idempotency-Key of the request matched a job queued before, so the
+// request sent nothing
+func MarkIdempotentReplay(ctx context.Context) {
+	if value := idempotencyFromContext(ctx); value != nil {
+		value.replayed = true
+	}
+}
+
+// IsIdempotentReplay reports whether the request returned the job of an earlier request with its Idempotency-Key
+func IsIdempotentReplay(ctx context.Context) bool {
+	value := idempotencyFromContext(ctx)
+	return value != nil && value.replayed
+}
+
+func idempotencyFromContext(ctx context.Context) *idempotency {
+	if ctx == nil {
+		return nil
+	}
+	value, _ := ctx.Value(idempotencyKeyContextKey{}).(*idempotency)
+	return value
+}
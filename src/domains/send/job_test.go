@@ -0,0 +1,33 @@
+package send_test
+
+import (
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatchUp(t *testing.T) {
+	all, err := domainSend.ParseCatchUp(domainSend.CatchUpAll)
+	require.NoError(t, err)
+	assert.True(t, all.Sends(30*24*time.Hour))
+
+	none, err := domainSend.ParseCatchUp(domainSend.CatchUpNone)
+	require.NoError(t, err)
+	assert.False(t, none.Sends(time.Second))
+
+	window, err := domainSend.ParseCatchUp("1h")
+	require.NoError(t, err)
+	assert.True(t, window.Sends(59*time.Minute))
+	assert.True(t, window.Sends(time.Hour))
+	assert.False(t, window.Sends(61*time.Minute))
+
+	for _, value := range []string{"some", "-1h", "1 hour"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := domainSend.ParseCatchUp(value)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -3,4 +3,57 @@ package send
 type GenericResponse struct {
 	MessageID string `json:"message_id"`
 	Status    string `json:"status"`
+	JobID     string `json:"job_id,omitempty"` // set when the message was queued instead of sent right away
+
+	// Set when the message was rendered from a template
+	TemplateID      string `json:"template_id,omitempty"`
+	TemplateVersion int    `json:"template_version,omitempty"`
+}
+
+// Request and Response structures for the jobs of the send queue
+
+type JobRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type ListJobsRequest struct {
+	Actor     string `json:"actor" query:"actor"`
+	Status    string `json:"status" query:"status"`
+	Recipient string `json:"recipient" query:"recipient"`
+	Scheduled bool   `json:"scheduled" query:"scheduled"`
+	Limit     int    `json:"limit" query:"limit"`
+	Offset    int    `json:"offset" query:"offset"`
+}
+
+type UpdateJobRequest struct {
+	ID     string `json:"id" uri:"id"`
+	SendAt string `json:"send_at"`
+}
+
+type ListJobsResponse struct {
+	Data       []JobInfo          `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type JobInfo struct {
+	ID             string `json:"id"`
+	DeviceID       string `json:"device_id,omitempty"`
+	Actor          string `json:"actor,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Recipient      string `json:"recipient"`
+	MessageID      string `json:"message_id"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error,omitempty"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+	ScheduledAt    string `json:"scheduled_at,omitempty"`
+	SentAt         string `json:"sent_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
 }
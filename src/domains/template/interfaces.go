@@ -0,0 +1,19 @@
+package template
+
+import (
+	"context"
+)
+
+// ITemplateUsecase manages the message templates and their versions
+type ITemplateUsecase interface {
+	CreateTemplate(ctx context.Context, request CreateTemplateRequest) (response TemplateInfo, err error)
+	ListTemplates(ctx context.Context, request ListTemplatesRequest) (response ListTemplatesResponse, err error)
+	GetTemplate(ctx context.Context, request TemplateRequest) (response TemplateInfo, err error)
+	UpdateTemplate(ctx context.Context, request UpdateTemplateRequest) (response TemplateInfo, err error)
+	DeleteTemplate(ctx context.Context, request TemplateRequest) error
+
+	ListVersions(ctx context.Context, request TemplateRequest) (response []VersionInfo, err error)
+	GetVersion(ctx context.Context, request VersionRequest) (response VersionInfo, err error)
+	// RenderTemplate previews the message a send with the variables would get, without sending it
+	RenderTemplate(ctx context.Context, request RenderTemplateRequest) (response RenderResponse, err error)
+}
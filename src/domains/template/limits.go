@@ -0,0 +1,149 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	textTemplate "text/template"
+	"text/template/parse"
+)
+
+// Limits of a single render. Template bodies are written by users, so a render must not be able to loop or grow
+// without bound, e.g. {{range 1000000000}}x{{end}}.
+const (
+	maxRenderedBytes = 64 * 1024 // the longest text WhatsApp accepts
+	maxIterations    = 10_000    // range iterations of a render, summed over all (nested) range actions
+	maxPrintfWidth   = 100       // width and precision of printf verbs
+)
+
+// iterationFunc is called at the start of every range iteration, it is not available to the template bodies
+const iterationFunc = "__iteration"
+
+var (
+	errOutputTooLarge    = fmt.Errorf("rendered text is longer than %d bytes", maxRenderedBytes)
+	errTooManyIterations = fmt.Errorf("range runs more than %d iterations", maxIterations)
+	errTemplateCall      = errors.New("template, define and block actions are not supported")
+)
+
+// printfWidth matches the width and precision of a printf verb, e.g. "10" and "2" in %10.2f
+var printfWidth = regexp.MustCompile(`%[-+# 0]*(\d*|\*)(?:\.(\d*|\*))?`)
+
+// iterationNode is the {{__iteration}} action prepended to every range body
+var iterationNode = func() parse.Node {
+	tpl := textTemplate.Must(textTemplate.New("iteration").Funcs(textTemplate.FuncMap{iterationFunc: func() string { return "" }}).
+		Parse("{{" + iterationFunc + "}}"))
+	return tpl.Tree.Root.Nodes[0]
+}()
+
+// limitedWriter fails once more than maxRenderedBytes were written to it
+type limitedWriter struct {
+	strings.Builder
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > maxRenderedBytes {
+		return 0, errOutputTooLarge
+	}
+	return w.Builder.Write(p)
+}
+
+// checkNodes rejects the actions that call other templates, whose calls could multiply without a range
+func checkNodes(tpl *textTemplate.Template) error {
+	if len(tpl.Templates()) > 1 {
+		return errTemplateCall
+	}
+	if tpl.Tree == nil {
+		return nil
+	}
+	return walkLists(tpl.Tree.Root, func(node parse.Node) error {
+		if _, ok := node.(*parse.TemplateNode); ok {
+			return errTemplateCall
+		}
+		return nil
+	})
+}
+
+// countIterations makes every range body of a parsed template count against the iterations of the render
+func countIterations(tpl *textTemplate.Template) {
+	if tpl.Tree == nil {
+		return
+	}
+	_ = walkLists(tpl.Tree.Root, func(node parse.Node) error {
+		if rangeNode, ok := node.(*parse.RangeNode); ok && rangeNode.List != nil {
+			rangeNode.List.Nodes = append([]parse.Node{iterationNode}, rangeNode.List.Nodes...)
+		}
+		return nil
+	})
+}
+
+// walkLists calls visit for every node of the actions and their branches
+func walkLists(node parse.Node, visit func(parse.Node) error) error {
+	if node == nil {
+		return nil
+	}
+	if err := visit(node); err != nil {
+		return err
+	}
+
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		children = n.Nodes
+	case *parse.IfNode:
+		children = branches(&n.BranchNode)
+	case *parse.RangeNode:
+		children = branches(&n.BranchNode)
+	case *parse.WithNode:
+		children = branches(&n.BranchNode)
+	}
+	for _, child := range children {
+		if err := walkLists(child, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func branches(branch *parse.BranchNode) []parse.Node {
+	var nodes []parse.Node
+	if branch.List != nil {
+		nodes = append(nodes, branch.List)
+	}
+	if branch.ElseList != nil {
+		nodes = append(nodes, branch.ElseList)
+	}
+	return nodes
+}
+
+// limitedFuncs returns the functions of a single render: the iteration counter, and a printf whose widths are
+// bounded so a verb like %999999999d does not allocate the padding
+func limitedFuncs() textTemplate.FuncMap {
+	iterations := 0
+	return textTemplate.FuncMap{
+		iterationFunc: func() (string, error) {
+			iterations++
+			if iterations > maxIterations {
+				return "", errTooManyIterations
+			}
+			return "", nil
+		},
+		"printf": func(format string, args ...any) (string, error) {
+			for _, match := range printfWidth.FindAllStringSubmatch(format, -1) {
+				for _, width := range match[1:] {
+					if width == "*" {
+						return "", errors.New("printf: * widths are not supported")
+					}
+					if n, err := strconv.Atoi(width); err == nil && n > maxPrintfWidth {
+						return "", fmt.Errorf("printf: widths and precisions are limited to %d", maxPrintfWidth)
+					}
+				}
+			}
+			return fmt.Sprintf(format, args...), nil
+		},
+	}
+}
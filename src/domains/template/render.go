@@ -0,0 +1,291 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	textTemplate "text/template"
+	"text/template/parse"
+	"time"
+	"unicode"
+)
+
+// treeName names the parsed body in the positions of rendering errors, e.g. "body:1:9"
+const treeName = "body"
+
+// missingKey matches the error of a variable that is neither sent nor defaulted
+var missingKey = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+
+// maxDecimals bounds the precision of the number helper, a large one would allocate the digits of every render
+const maxDecimals = 10
+
+// dateLayouts are the layouts a date variable is parsed with, in order
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// helpers are the formatting functions available to a template besides the builtins of text/template
+// (if, else, eq, lt, len, ...). bold, italic, strike and mono produce WhatsApp formatting.
+var helpers = textTemplate.FuncMap{
+	"upper":  func(value any) string { return strings.ToUpper(toString(value)) },
+	"lower":  func(value any) string { return strings.ToLower(toString(value)) },
+	"title":  func(value any) string { return title(toString(value)) },
+	"trim":   func(value any) string { return strings.TrimSpace(toString(value)) },
+	"bold":   func(value any) string { return "*" + toString(value) + "*" },
+	"italic": func(value any) string { return "_" + toString(value) + "_" },
+	"strike": func(value any) string { return "~" + toString(value) + "~" },
+	"mono":   func(value any) string { return "```" + toString(value) + "```" },
+	"number": formatNumber,
+	"date":   formatDate,
+	"join":   join,
+}
+
+// Parse checks that a template body is valid without rendering it
+func Parse(body string) error {
+	_, err := parseBody(body)
+	return err
+}
+
+// Render renders a template body. The variables take precedence over the defaults, a variable the body uses
+// that is in neither of them is an error.
+func Render(body string, variables, defaults map[string]any) (string, error) {
+	tpl, err := parseBody(body)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]any, len(defaults)+len(variables))
+	for name, value := range defaults {
+		values[name] = normalize(value)
+	}
+	for name, value := range variables {
+		values[name] = normalize(value)
+	}
+
+	countIterations(tpl)
+	tpl.Funcs(limitedFuncs())
+
+	var rendered limitedWriter
+	if err := tpl.Execute(&rendered, values); err != nil {
+		for _, limit := range []error{errOutputTooLarge, errTooManyIterations} {
+			if errors.Is(err, limit) {
+				return "", limit
+			}
+		}
+		if match := missingKey.FindStringSubmatch(err.Error()); match != nil {
+			return "", fmt.Errorf("missing variable %q", match[1])
+		}
+		return "", errors.New(strings.TrimPrefix(err.Error(), "template: "))
+	}
+	return rendered.String(), nil
+}
+
+// Variables returns the variables a template body uses, sorted and without duplicates
+func Variables(body string) []string {
+	tpl, err := parseBody(body)
+	if err != nil || tpl.Tree == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	collectVariables(tpl.Tree.Root, seen, true)
+
+	variables := make([]string, 0, len(seen))
+	for name := range seen {
+		variables = append(variables, name)
+	}
+	slices.Sort(variables)
+	return variables
+}
+
+func parseBody(body string) (*textTemplate.Template, error) {
+	tpl, err := textTemplate.New(treeName).Option("missingkey=error").Funcs(helpers).Parse(body)
+	if err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "template: "))
+	}
+	if err := checkNodes(tpl); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// collectVariables walks a parsed body. Within range and with blocks the dot is an item instead of the
+// variables, so only $.name counts there.
+func collectVariables(node parse.Node, seen map[string]bool, dotIsRoot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectVariables(child, seen, dotIsRoot)
+		}
+	case *parse.ActionNode:
+		collectVariables(n.Pipe, seen, dotIsRoot)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectVariables(cmd, seen, dotIsRoot)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectVariables(arg, seen, dotIsRoot)
+		}
+	case *parse.FieldNode:
+		if dotIsRoot {
+			seen[n.Ident[0]] = true
+		}
+	case *parse.ChainNode:
+		collectVariables(n.Node, seen, dotIsRoot)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			seen[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		collectVariables(n.Pipe, seen, dotIsRoot)
+		collectVariables(n.List, seen, dotIsRoot)
+		collectVariables(n.ElseList, seen, dotIsRoot)
+	case *parse.RangeNode:
+		collectVariables(n.Pipe, seen, dotIsRoot)
+		collectVariables(n.List, seen, false)
+		collectVariables(n.ElseList, seen, dotIsRoot)
+	case *parse.WithNode:
+		collectVariables(n.Pipe, seen, dotIsRoot)
+		collectVariables(n.List, seen, false)
+		collectVariables(n.ElseList, seen, dotIsRoot)
+	}
+}
+
+// normalize turns the whole numbers decoded from JSON into integers, so they print as 1500 instead of 1.5e+03
+// and compare with the integer constants of a template
+func normalize(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for name, item := range v {
+			normalized[name] = normalize(item)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+func toString(value any) string {
+	if value == nil {
+		return ""
+	}
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func title(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// formatNumber formats a number with thousands separators and the given number of decimals, 0 by default and
+// at most maxDecimals, e.g. {{number .total 2}} renders 1234.5 as 1,234.50
+func formatNumber(value any, decimals ...int) (string, error) {
+	var f float64
+	switch v := value.(type) {
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+		f = parsed
+	default:
+		return "", fmt.Errorf("%v is not a number", value)
+	}
+
+	precision := 0
+	if len(decimals) > 0 {
+		precision = decimals[0]
+	}
+	if precision < 0 || precision > maxDecimals {
+		return "", fmt.Errorf("decimals must be between 0 and %d, got %d", maxDecimals, precision)
+	}
+
+	formatted := strconv.FormatFloat(math.Abs(f), 'f', precision, 64)
+	integer, fraction, _ := strings.Cut(formatted, ".")
+
+	var grouped strings.Builder
+	if f < 0 && strings.Trim(formatted, "0.") != "" {
+		grouped.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString("." + fraction)
+	}
+	return grouped.String(), nil
+}
+
+// formatDate formats an RFC3339 time, a date like 2006-01-02 or a unix timestamp with a Go layout,
+// e.g. {{date "02 Jan 2006" .due}}
+func formatDate(layout string, value any) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout), nil
+	case int64:
+		return time.Unix(v, 0).UTC().Format(layout), nil
+	case float64:
+		return time.Unix(int64(v), 0).UTC().Format(layout), nil
+	case string:
+		for _, dateLayout := range dateLayouts {
+			if t, err := time.Parse(dateLayout, strings.TrimSpace(v)); err == nil {
+				return t.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("%q is not a date", v)
+	default:
+		return "", fmt.Errorf("%v is not a date", value)
+	}
+}
+
+// join joins the items of a list, e.g. {{join ", " .items}}
+func join(separator string, value any) string {
+	items, ok := value.([]any)
+	if !ok {
+		return toString(value)
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = toString(item)
+	}
+	return strings.Join(parts, separator)
+}
@@ -0,0 +1,136 @@
+package template_test
+
+import (
+	"testing"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		variables map[string]any
+		defaults  map[string]any
+		expected  string
+		err       string
+	}{
+		{
+			name:      "variables",
+			body:      "Hi {{.name}}, order {{.order}} is ready",
+			variables: map[string]any{"name": "Ana", "order": float64(1500200)},
+			expected:  "Hi Ana, order 1500200 is ready",
+		},
+		{
+			name:      "defaults are overridden by variables",
+			body:      "Hi {{.name}} from {{.shop}}",
+			variables: map[string]any{"name": "Ana"},
+			defaults:  map[string]any{"name": "customer", "shop": "Toko"},
+			expected:  "Hi Ana from Toko",
+		},
+		{
+			name:      "conditionals",
+			body:      "{{if .vip}}Dear {{.name}}{{else}}Hi{{end}}{{if gt .points 100}}, you have {{.points}} points{{end}}",
+			variables: map[string]any{"name": "Ana", "vip": true, "points": float64(120)},
+			expected:  "Dear Ana, you have 120 points",
+		},
+		{
+			name:      "formatting helpers",
+			body:      `{{bold (upper .name)}} {{italic (title .city)}} {{number .total 2}} {{date "02 Jan 2006" .due}} {{join ", " .items}}`,
+			variables: map[string]any{"name": "ana", "city": "new york", "total": 1234567.5, "due": "2025-03-09", "items": []any{"tea", float64(2)}},
+			expected:  "*ANA* _New York_ 1,234,567.50 09 Mar 2025 tea, 2",
+		},
+		{
+			name:      "negative number",
+			body:      "{{number .balance}}",
+			variables: map[string]any{"balance": float64(-1234)},
+			expected:  "-1,234",
+		},
+		{
+			name:      "missing variable",
+			body:      "Hi {{.name}}, use {{.code}}",
+			variables: map[string]any{"name": "Ana"},
+			err:       `missing variable "code"`,
+		},
+		{
+			name:      "invalid number",
+			body:      "Total {{number .total}}",
+			variables: map[string]any{"total": "a lot"},
+			err:       `body:1:8: executing "body" at <number .total>: error calling number: "a lot" is not a number`,
+		},
+		{
+			name:      "too many decimals",
+			body:      "Total {{number .total 1000000000}}",
+			variables: map[string]any{"total": 1.5},
+			err:       `body:1:8: executing "body" at <number .total 1000000000>: error calling number: decimals must be between 0 and 10, got 1000000000`,
+		},
+		{
+			name:      "range within the limits",
+			body:      "{{range $i, $item := .items}}{{if $i}}, {{end}}{{$item}}{{end}}{{range 3}}.{{end}}",
+			variables: map[string]any{"items": []any{"tea", "milk"}},
+			expected:  "tea, milk...",
+		},
+		{
+			name: "huge integer range",
+			body: "{{range 1000000000}}x{{end}}",
+			err:  "range runs more than 10000 iterations",
+		},
+		{
+			name: "nested ranges without output",
+			body: "{{range 1000}}{{range 1000}}{{range 1000}}{{end}}{{end}}{{end}}",
+			err:  "range runs more than 10000 iterations",
+		},
+		{
+			name:      "output too large",
+			body:      "{{range 5000}}{{$.text}}{{end}}",
+			variables: map[string]any{"text": "0123456789abcdef"},
+			err:       "rendered text is longer than 65536 bytes",
+		},
+		{
+			name: "huge printf width",
+			body: `{{printf "%0999999999d" 1}}`,
+			err:  `body:1:2: executing "body" at <printf "%0999999999d" 1>: error calling printf: printf: widths and precisions are limited to 100`,
+		},
+		{
+			name: "recursive template",
+			body: `{{define "loop"}}{{template "loop" .}}{{template "loop" .}}{{end}}{{template "loop" .}}`,
+			err:  "template, define and block actions are not supported",
+		},
+		{
+			name: "self call",
+			body: `{{template "body" .}}`,
+			err:  "template, define and block actions are not supported",
+		},
+		{
+			name: "unknown helper",
+			body: "{{shout .name}}",
+			err:  `body:1: function "shout" not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := domainTemplate.Render(tt.body, tt.variables, tt.defaults)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	body := "{{if .vip}}Dear {{upper .name}}{{end}} {{range .items}}{{.title}} for {{$.currency}}{{end}} {{with .address}}{{.city}}{{end}}"
+	assert.Equal(t, []string{"address", "currency", "items", "name", "vip"}, domainTemplate.Variables(body))
+	assert.Empty(t, domainTemplate.Variables("Hi there"))
+	assert.Nil(t, domainTemplate.Variables("{{.name"))
+}
+
+func TestParse(t *testing.T) {
+	assert.NoError(t, domainTemplate.Parse("Hi {{.name}}"))
+	assert.EqualError(t, domainTemplate.Parse("Hi {{if .name}}"), "body:1: unexpected EOF")
+}
@@ -0,0 +1,92 @@
+package template
+
+// Request and Response structures for message templates
+
+type CreateTemplateRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Body        string         `json:"body"`
+	MediaType   string         `json:"media_type"`
+	MediaURL    string         `json:"media_url"`
+	Defaults    map[string]any `json:"defaults"`
+}
+
+// UpdateTemplateRequest replaces the content of a template and stores it as a new version. When Version is
+// set the update is rejected unless the template is still at that version.
+type UpdateTemplateRequest struct {
+	ID          string         `json:"id" uri:"id"`
+	Version     int            `json:"version"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Body        string         `json:"body"`
+	MediaType   string         `json:"media_type"`
+	MediaURL    string         `json:"media_url"`
+	Defaults    map[string]any `json:"defaults"`
+}
+
+type TemplateRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type VersionRequest struct {
+	ID      string `json:"id" uri:"id"`
+	Version int    `json:"version" uri:"version"`
+}
+
+type RenderTemplateRequest struct {
+	ID        string         `json:"id" uri:"id"`
+	Version   int            `json:"version"` // 0 renders the current version
+	Variables map[string]any `json:"variables"`
+}
+
+type ListTemplatesRequest struct {
+	Search string `json:"search" query:"search"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type ListTemplatesResponse struct {
+	Data       []TemplateInfo     `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type TemplateInfo struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Body        string         `json:"body"`
+	MediaType   string         `json:"media_type,omitempty"`
+	MediaURL    string         `json:"media_url,omitempty"`
+	Defaults    map[string]any `json:"defaults,omitempty"`
+	Variables   []string       `json:"variables"` // variables the body uses
+	Version     int            `json:"version"`
+	Actor       string         `json:"actor,omitempty"`
+	CreatedAt   string         `json:"created_at"`
+	UpdatedAt   string         `json:"updated_at"`
+}
+
+type VersionInfo struct {
+	TemplateID string         `json:"template_id"`
+	Version    int            `json:"version"`
+	Body       string         `json:"body"`
+	MediaType  string         `json:"media_type,omitempty"`
+	MediaURL   string         `json:"media_url,omitempty"`
+	Defaults   map[string]any `json:"defaults,omitempty"`
+	Variables  []string       `json:"variables"`
+	Actor      string         `json:"actor,omitempty"`
+	CreatedAt  string         `json:"created_at"`
+}
+
+type RenderResponse struct {
+	TemplateID string `json:"template_id"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+	MediaType  string `json:"media_type,omitempty"`
+	MediaURL   string `json:"media_url,omitempty"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
@@ -0,0 +1,79 @@
+package template
+
+import (
+	"context"
+	"time"
+)
+
+// Media types a template can attach, the rendered text is sent as caption
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// Template is a stored message body rendered with the variables of a send. Every change stores a new version
+// and the old versions are kept, so a sent message can be traced back to the text it was rendered from.
+type Template struct {
+	ID          string         `db:"id"`
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Body        string         `db:"body"`
+	MediaType   string         `db:"media_type"`
+	MediaURL    string         `db:"media_url"`
+	Defaults    map[string]any `db:"defaults"` // values of the variables a send does not set
+	Version     int            `db:"version"`  // current version, starts at 1
+	Actor       string         `db:"actor"`    // username of the last editor
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// Version is the content of a template as it was saved in one of its versions
+type Version struct {
+	TemplateID string         `db:"template_id"`
+	Version    int            `db:"version"`
+	Body       string         `db:"body"`
+	MediaType  string         `db:"media_type"`
+	MediaURL   string         `db:"media_url"`
+	Defaults   map[string]any `db:"defaults"`
+	Actor      string         `db:"actor"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+// Current returns the current version of the template
+func (t *Template) Current() *Version {
+	return &Version{
+		TemplateID: t.ID,
+		Version:    t.Version,
+		Body:       t.Body,
+		MediaType:  t.MediaType,
+		MediaURL:   t.MediaURL,
+		Defaults:   t.Defaults,
+		Actor:      t.Actor,
+		CreatedAt:  t.UpdatedAt,
+	}
+}
+
+// TemplateFilter represents query filters for templates
+type TemplateFilter struct {
+	Search string // matched against the name and description
+	Limit  int
+	Offset int
+}
+
+type ITemplateRepository interface {
+	// CreateTemplate stores a template as its first version
+	CreateTemplate(ctx context.Context, template *Template) error
+	// UpdateTemplate stores the content of the template as a new version, expectedVersion guards against
+	// concurrent edits and false is returned when the template is no longer at that version
+	UpdateTemplate(ctx context.Context, template *Template, expectedVersion int) (bool, error)
+	// DeleteTemplate marks a template as deleted, it is no longer found by ID or name but its versions are kept
+	DeleteTemplate(ctx context.Context, id string) (bool, error)
+	GetTemplate(ctx context.Context, id string) (*Template, error)
+	GetTemplateByName(ctx context.Context, name string) (*Template, error)
+	GetTemplates(ctx context.Context, filter *TemplateFilter) ([]*Template, error)
+	CountTemplates(ctx context.Context, filter *TemplateFilter) (int64, error)
+
+	// GetVersions returns the versions of a template, newest first
+	GetVersions(ctx context.Context, templateID string) ([]*Version, error)
+	GetVersion(ctx context.Context, templateID string, version int) (*Version, error)
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// Delivery states of the webhook outbox
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed" // dead-letter: retries exhausted, only delivered again on replay
+)
+
+// ReplayableStatuses are the states a delivery can be replayed from, a pending delivery is still being attempted
+var ReplayableStatuses = []string{DeliveryStatusFailed, DeliveryStatusDelivered}
+
+// Delivery is a webhook payload persisted in the outbox until it has been delivered
+type Delivery struct {
+	ID            int64      `db:"id"`
+	EndpointID    string     `db:"endpoint_id"`
+	URL           string     `db:"url"`
+	Event         string     `db:"event"`
+	Payload       string     `db:"payload"`
+	Origin        Origin     // incoming message of the event, the actions of the response are run against its chat
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	LastError     string     `db:"last_error"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	RetryUntil    time.Time  `db:"retry_until"`
+	DeliveredAt   *time.Time `db:"delivered_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
+
+// DeliveryFilter represents query filters for deliveries
+type DeliveryFilter struct {
+	EndpointID string
+	Status     string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type IWebhookDeliveryRepository interface {
+	// Outbox operations
+	EnqueueDelivery(ctx context.Context, delivery *Delivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+	MarkDelivered(ctx context.Context, id int64, attempts int, deliveredAt time.Time) error
+	MarkRetry(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id int64, attempts int, lastError string) error
+	ExpediteDeliveries(ctx context.Context, endpointID string, now time.Time) (int64, error)
+	// ClaimResponseActions records that the first count actions of the response to a delivery ran, and returns
+	// how many of them already ran for an earlier attempt or replay of the delivery
+	ClaimResponseActions(ctx context.Context, id int64, count int) (int, error)
+
+	// Query operations
+	GetDelivery(ctx context.Context, id int64) (*Delivery, error)
+	GetDeliveries(ctx context.Context, filter *DeliveryFilter) ([]*Delivery, error)
+	CountDeliveries(ctx context.Context, filter *DeliveryFilter) (int64, error)
+
+	// Replay and cleanup operations
+	// ReplayDelivery schedules a failed or delivered delivery again, false when it is in another state
+	ReplayDelivery(ctx context.Context, id int64, now time.Time, retryUntil time.Time) (bool, error)
+	ReplayDeliveries(ctx context.Context, filter *DeliveryFilter, now time.Time, retryUntil time.Time) (int64, error)
+	PurgeDeliveredBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IWebhookRepository groups the webhook storage backed by the chat storage database
+type IWebhookRepository interface {
+	IWebhookDeliveryRepository
+	IWebhookEndpointRepository
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event types a webhook endpoint can subscribe to
+const (
+	EventMessage  = "message"
+	EventReceipt  = "receipt"
+	EventGroup    = "group"
+	EventDelete   = "delete"
+	EventPresence = "presence"
+	EventSendJob  = "send_job"
+	EventPoll     = "poll"
+)
+
+// EventTypes lists every event type that can be forwarded to a webhook endpoint
+var EventTypes = []string{EventMessage, EventReceipt, EventGroup, EventDelete, EventPresence, EventSendJob, EventPoll}
+
+// LegacyEventTypes are the events delivered to the URLs configured with --webhook
+var LegacyEventTypes = []string{EventMessage, EventReceipt, EventGroup, EventDelete}
+
+// Circuit breaker states of a webhook endpoint
+const (
+	CircuitClosed   = "closed"    // deliveries are sent normally
+	CircuitOpen     = "open"      // the endpoint looks down, deliveries are postponed
+	CircuitHalfOpen = "half_open" // a trial delivery decides whether the circuit closes again
+)
+
+// Endpoint is a webhook receiver with its own secret and event filters
+type Endpoint struct {
+	ID     string `db:"id"`
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+
+	// While a secret is rotated, requests are signed with both secrets until the previous one expires
+	PreviousSecret          string     `db:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time `db:"previous_secret_expires_at"`
+
+	Events         []string  `db:"events"`
+	AllowChats     []string  `db:"allow_chats"`     // when not empty, only events of these chats are delivered
+	DenyChats      []string  `db:"deny_chats"`      // events of these chats are never delivered
+	PayloadVersion int       `db:"payload_version"` // one of PayloadVersions
+	Enabled        bool      `db:"enabled"`
+	ReadOnly       bool      `db:"-"` // endpoints from the configuration cannot be changed through the API
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// Secrets returns the secrets requests must currently be signed with, the current one first
+func (e *Endpoint) Secrets(now time.Time) []string {
+	secrets := []string{e.Secret}
+	if e.PreviousSecret != "" && e.PreviousSecretExpiresAt != nil && now.Before(*e.PreviousSecretExpiresAt) {
+		secrets = append(secrets, e.PreviousSecret)
+	}
+	return secrets
+}
+
+// Accepts reports whether the endpoint wants the given event of the given chat.
+// An empty chat JID (events not bound to a chat) only passes when no allow list is set.
+func (e *Endpoint) Accepts(eventType string, chatJID string) bool {
+	if !e.Enabled || !slices.Contains(e.Events, eventType) {
+		return false
+	}
+	if chatJID != "" && MatchesChat(e.DenyChats, chatJID) {
+		return false
+	}
+	if len(e.AllowChats) > 0 {
+		return chatJID != "" && MatchesChat(e.AllowChats, chatJID)
+	}
+	return true
+}
+
+// MatchesChat compares chat JIDs loosely so "628123" also matches "628123@s.whatsapp.net"
+func MatchesChat(chats []string, chatJID string) bool {
+	user, _, _ := strings.Cut(chatJID, "@")
+	for _, chat := range chats {
+		if chat == chatJID || (!strings.Contains(chat, "@") && chat == user) {
+			return true
+		}
+	}
+	return false
+}
+
+type IWebhookEndpointRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	UpdateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	DeleteEndpoint(ctx context.Context, id string) error
+	GetEndpoint(ctx context.Context, id string) (*Endpoint, error)
+	GetEndpoints(ctx context.Context) ([]*Endpoint, error)
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"context"
+)
+
+// IWebhookUsecase defines the interface for webhook endpoint and outbox operations
+type IWebhookUsecase interface {
+	ListEndpoints(ctx context.Context) (response []EndpointInfo, err error)
+	GetEndpoint(ctx context.Context, request EndpointRequest) (response EndpointInfo, err error)
+	CreateEndpoint(ctx context.Context, request CreateEndpointRequest) (response EndpointInfo, err error)
+	UpdateEndpoint(ctx context.Context, request UpdateEndpointRequest) (response EndpointInfo, err error)
+	DeleteEndpoint(ctx context.Context, request EndpointRequest) (err error)
+	RotateSecret(ctx context.Context, request RotateSecretRequest) (response EndpointInfo, err error)
+	ListCircuits(ctx context.Context) (response []CircuitInfo, err error)
+	ResetCircuit(ctx context.Context, request EndpointRequest) (response CircuitInfo, err error)
+
+	ListDeliveries(ctx context.Context, request ListDeliveriesRequest) (response ListDeliveriesResponse, err error)
+	ReplayDelivery(ctx context.Context, request ReplayDeliveryRequest) (response DeliveryInfo, err error)
+	ReplayDeliveries(ctx context.Context, request ReplayDeliveriesRequest) (response ReplayDeliveriesResponse, err error)
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"time"
+)
+
+// Payload versions a webhook endpoint can receive
+const (
+	PayloadVersion1 = 1 // the original bodies, every event has its own shape
+	PayloadVersion2 = 2 // every event wrapped in an envelope with event, version and typed data
+)
+
+// PayloadVersions lists every supported payload version
+var PayloadVersions = []int{PayloadVersion1, PayloadVersion2}
+
+// Event names used as discriminator of the payloads
+const (
+	EventNameMessage             = "message"
+	EventNameMessageEdited       = "message.edited"
+	EventNameMessageRevoked      = "message.revoked"
+	EventNameMessageAck          = "message.ack"
+	EventNameMessageDeletedForMe = "message.deleted_for_me"
+	EventNameGroupParticipants   = "group.participants"
+	EventNamePresence            = "presence"
+	EventNameSendJobSent         = "send_job.sent"
+	EventNameSendJobFailed       = "send_job.failed"
+	EventNamePollVote            = "poll.vote"
+)
+
+// Event is a webhook event that can be rendered in every payload version
+type Event struct {
+	Type      string // subscription type, one of EventTypes
+	ChatJID   string // chat of the event, matched against the chat filters of the endpoints
+	DeviceID  string
+	Timestamp time.Time
+	Legacy    LegacyPayload // body of payload version 1
+	Data      EventData     // data of payload version 2
+	Origin    *Origin       // incoming message the event is about, nil when a response cannot act on the event
+}
+
+// LegacyPayload is the body of an event in payload version 1
+type LegacyPayload interface {
+	setDeviceID(deviceID string)
+}
+
+// EventData is the typed data of an event in payload version 2
+type EventData interface {
+	EventName() string
+}
+
+// Envelope is the body of every event in payload version 2
+type Envelope struct {
+	Event     string    `json:"event"`
+	Version   int       `json:"version"`
+	DeviceID  string    `json:"device_id"`
+	Timestamp string    `json:"timestamp" jsonschema:"format=date-time"`
+	Data      EventData `json:"data"`
+}
+
+// Payload returns the body of the event for an endpoint receiving the given payload version
+func (e Event) Payload(version int) any {
+	switch version {
+	case PayloadVersion2:
+		return Envelope{
+			Event:     e.Data.EventName(),
+			Version:   PayloadVersion2,
+			DeviceID:  e.DeviceID,
+			Timestamp: e.Timestamp.UTC().Format(time.RFC3339),
+			Data:      e.Data,
+		}
+	default:
+		e.Legacy.setDeviceID(e.DeviceID)
+		return e.Legacy
+	}
+}
@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// Payload version 1 keeps the bodies sent before payloads were versioned, field for field.
+// Do not change these structs, add new fields to the version 2 data instead.
+
+// MessagePayloadV1 is the body of a message event
+type MessagePayloadV1 struct {
+	SenderID  string         `json:"sender_id"`
+	ChatID    string         `json:"chat_id"`
+	From      string         `json:"from,omitempty"`
+	FromLID   string         `json:"from_lid,omitempty"`
+	Timestamp string         `json:"timestamp"`
+	PushName  string         `json:"pushname,omitempty"`
+	DeviceID  string         `json:"device_id"`
+	Message   *MessageTextV1 `json:"message,omitempty"`
+	Reaction  *ReactionV1    `json:"reaction,omitempty"`
+	ViewOnce  bool           `json:"view_once,omitempty"`
+	Forwarded bool           `json:"forwarded,omitempty"`
+
+	// Protocol messages
+	Action           string `json:"action,omitempty" jsonschema:"enum=message_revoked,enum=message_edited"`
+	RevokedMessageID string `json:"revoked_message_id,omitempty"`
+	RevokedFromMe    *bool  `json:"revoked_from_me,omitempty"`
+	RevokedChat      string `json:"revoked_chat,omitempty"`
+	EditedText       string `json:"edited_text,omitempty"`
+
+	Audio        *MediaFileV1               `json:"audio,omitempty"`
+	Contact      *waE2E.ContactMessage      `json:"contact,omitempty"`
+	Document     *MediaFileV1               `json:"document,omitempty"`
+	Image        *MediaFileV1               `json:"image,omitempty"`
+	List         *waE2E.ListMessage         `json:"list,omitempty"`
+	LiveLocation *waE2E.LiveLocationMessage `json:"live_location,omitempty"`
+	Location     *waE2E.LocationMessage     `json:"location,omitempty"`
+	Order        *waE2E.OrderMessage        `json:"order,omitempty"`
+	Sticker      *MediaFileV1               `json:"sticker,omitempty"`
+	Video        *MediaFileV1               `json:"video,omitempty"`
+}
+
+type MessageTextV1 struct {
+	Text          string `json:"text"`
+	ID            string `json:"id"`
+	RepliedID     string `json:"replied_id"`
+	QuotedMessage string `json:"quoted_message"`
+}
+
+type ReactionV1 struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+type MediaFileV1 struct {
+	MediaPath string `json:"media_path"`
+	MimeType  string `json:"mime_type"`
+	Caption   string `json:"caption"`
+}
+
+// ReceiptPayloadV1 is the body of a receipt event
+type ReceiptPayloadV1 struct {
+	Event     string    `json:"event" jsonschema:"enum=message.ack"`
+	Timestamp string    `json:"timestamp"`
+	DeviceID  string    `json:"device_id"`
+	Payload   ReceiptV1 `json:"payload"`
+}
+
+type ReceiptV1 struct {
+	IDs                    []string `json:"ids,omitempty"`
+	ChatID                 string   `json:"chat_id"`
+	SenderID               string   `json:"sender_id"`
+	From                   string   `json:"from"`
+	ReceiptType            string   `json:"receipt_type"`
+	ReceiptTypeDescription string   `json:"receipt_type_description"`
+}
+
+// GroupPayloadV1 is the body of a group participants event
+type GroupPayloadV1 struct {
+	Event     string              `json:"event" jsonschema:"enum=group.participants"`
+	Timestamp string              `json:"timestamp"`
+	DeviceID  string              `json:"device_id"`
+	Payload   GroupParticipantsV1 `json:"payload"`
+}
+
+type GroupParticipantsV1 struct {
+	ChatID string   `json:"chat_id"`
+	Type   string   `json:"type" jsonschema:"enum=join,enum=leave,enum=promote,enum=demote"`
+	JIDs   []string `json:"jids"`
+}
+
+// DeletePayloadV1 is the body of a delete for me event
+type DeletePayloadV1 struct {
+	Action           string `json:"action" jsonschema:"enum=event.delete_for_me"`
+	DeletedMessageID string `json:"deleted_message_id"`
+	SenderID         string `json:"sender_id"`
+	From             string `json:"from,omitempty"`
+	Timestamp        string `json:"timestamp"`
+	DeviceID         string `json:"device_id"`
+
+	// Only present when the deleted message is in the chat storage
+	*OriginalMessageV1
+}
+
+type OriginalMessageV1 struct {
+	ChatID            string `json:"chat_id"`
+	OriginalContent   string `json:"original_content"`
+	OriginalSender    string `json:"original_sender"`
+	OriginalTimestamp string `json:"original_timestamp"`
+	WasFromMe         bool   `json:"was_from_me"`
+	OriginalMediaType string `json:"original_media_type,omitempty"`
+	OriginalFilename  string `json:"original_filename,omitempty"`
+}
+
+// PresencePayloadV1 is the body of a presence event
+type PresencePayloadV1 struct {
+	Event     string     `json:"event" jsonschema:"enum=presence"`
+	Timestamp string     `json:"timestamp"`
+	DeviceID  string     `json:"device_id"`
+	Payload   PresenceV1 `json:"payload"`
+}
+
+type PresenceV1 struct {
+	From        string `json:"from"`
+	Unavailable bool   `json:"unavailable"`
+	LastSeen    string `json:"last_seen,omitempty"`
+}
+
+// SendJobPayloadV1 is the body of a send_job.sent or send_job.failed event
+type SendJobPayloadV1 struct {
+	Event     string    `json:"event" jsonschema:"enum=send_job.sent,enum=send_job.failed"`
+	Timestamp string    `json:"timestamp"`
+	DeviceID  string    `json:"device_id"`
+	Payload   SendJobV1 `json:"payload"`
+}
+
+type SendJobV1 struct {
+	JobID       string `json:"job_id"`
+	Recipient   string `json:"recipient"`
+	MessageID   string `json:"message_id"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error,omitempty"`
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+	SentAt      string `json:"sent_at,omitempty"`
+}
+
+// PollVotePayloadV1 is the body of a poll.vote event
+type PollVotePayloadV1 struct {
+	Event     string     `json:"event" jsonschema:"enum=poll.vote"`
+	Timestamp string     `json:"timestamp"`
+	DeviceID  string     `json:"device_id"`
+	Payload   PollVoteV1 `json:"payload"`
+}
+
+type PollVoteV1 struct {
+	PollID       string   `json:"poll_id"`
+	ChatID       string   `json:"chat_id"`
+	Voter        string   `json:"voter"`
+	Question     string   `json:"question,omitempty"`
+	Options      []string `json:"options"`
+	OptionHashes []string `json:"option_hashes"`
+}
+
+func (p *MessagePayloadV1) setDeviceID(deviceID string)  { p.DeviceID = deviceID }
+func (p *ReceiptPayloadV1) setDeviceID(deviceID string)  { p.DeviceID = deviceID }
+func (p *GroupPayloadV1) setDeviceID(deviceID string)    { p.DeviceID = deviceID }
+func (p *DeletePayloadV1) setDeviceID(deviceID string)   { p.DeviceID = deviceID }
+func (p *PresencePayloadV1) setDeviceID(deviceID string) { p.DeviceID = deviceID }
+func (p *SendJobPayloadV1) setDeviceID(deviceID string)  { p.DeviceID = deviceID }
+func (p *PollVotePayloadV1) setDeviceID(deviceID string) { p.DeviceID = deviceID }
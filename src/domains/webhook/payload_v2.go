@@ -0,0 +1,200 @@
+package webhook
+
+// Payload version 2 sends every event as an Envelope. The data structs below may gain new optional fields,
+// existing fields are never renamed or removed within the version.
+
+// MessageData is the data of message, message.edited and message.revoked events
+type MessageData struct {
+	ID            string `json:"id"`
+	ChatJID       string `json:"chat_jid"`
+	SenderJID     string `json:"sender_jid"`
+	From          string `json:"from,omitempty"`
+	FromLID       string `json:"from_lid,omitempty"`
+	PushName      string `json:"pushname,omitempty"`
+	Text          string `json:"text,omitempty"`
+	RepliedID     string `json:"replied_id,omitempty"`
+	QuotedMessage string `json:"quoted_message,omitempty"`
+	ViewOnce      bool   `json:"view_once"`
+	Forwarded     bool   `json:"forwarded"`
+
+	Reaction     *Reaction       `json:"reaction,omitempty"`
+	Edited       *EditedMessage  `json:"edited,omitempty"`
+	Revoked      *RevokedMessage `json:"revoked,omitempty"`
+	Media        *Media          `json:"media,omitempty"`
+	Contact      *Contact        `json:"contact,omitempty"`
+	Location     *Location       `json:"location,omitempty"`
+	LiveLocation *LiveLocation   `json:"live_location,omitempty"`
+	List         *List           `json:"list,omitempty"`
+	Order        *Order          `json:"order,omitempty"`
+}
+
+func (d *MessageData) EventName() string {
+	switch {
+	case d.Revoked != nil:
+		return EventNameMessageRevoked
+	case d.Edited != nil:
+		return EventNameMessageEdited
+	default:
+		return EventNameMessage
+	}
+}
+
+type Reaction struct {
+	Text      string `json:"text"` // empty when the reaction was removed
+	MessageID string `json:"message_id"`
+}
+
+type EditedMessage struct {
+	Text string `json:"text"`
+}
+
+type RevokedMessage struct {
+	MessageID string `json:"message_id"`
+	FromMe    bool   `json:"from_me"`
+	ChatJID   string `json:"chat_jid,omitempty"`
+}
+
+// Media types of a message
+const (
+	MediaTypeImage    = "image"
+	MediaTypeVideo    = "video"
+	MediaTypeAudio    = "audio"
+	MediaTypeDocument = "document"
+	MediaTypeSticker  = "sticker"
+)
+
+type Media struct {
+	Type     string `json:"type" jsonschema:"enum=image,enum=video,enum=audio,enum=document,enum=sticker"`
+	Path     string `json:"path"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+type Contact struct {
+	DisplayName string `json:"display_name"`
+	VCard       string `json:"vcard"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+	URL       string  `json:"url,omitempty"`
+}
+
+type LiveLocation struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	AccuracyInMeters uint32  `json:"accuracy_in_meters,omitempty"`
+	Caption          string  `json:"caption,omitempty"`
+	SequenceNumber   int64   `json:"sequence_number"`
+}
+
+type List struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	ButtonText  string        `json:"button_text"`
+	Sections    []ListSection `json:"sections"`
+}
+
+type ListSection struct {
+	Title string    `json:"title"`
+	Rows  []ListRow `json:"rows"`
+}
+
+type ListRow struct {
+	RowID       string `json:"row_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type Order struct {
+	OrderID         string `json:"order_id"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message,omitempty"`
+	Status          string `json:"status"`
+	ItemCount       int32  `json:"item_count"`
+	SellerJID       string `json:"seller_jid"`
+	TotalAmount1000 int64  `json:"total_amount_1000"` // total amount multiplied by 1000
+	Currency        string `json:"currency"`
+}
+
+// ReceiptData is the data of message.ack events
+type ReceiptData struct {
+	MessageIDs  []string `json:"message_ids"`
+	ChatJID     string   `json:"chat_jid"`
+	SenderJID   string   `json:"sender_jid"`
+	ReceiptType string   `json:"receipt_type"`
+}
+
+func (d *ReceiptData) EventName() string { return EventNameMessageAck }
+
+// GroupParticipantsData is the data of group.participants events
+type GroupParticipantsData struct {
+	ChatJID string   `json:"chat_jid"`
+	Action  string   `json:"action" jsonschema:"enum=join,enum=leave,enum=promote,enum=demote"`
+	JIDs    []string `json:"jids"`
+}
+
+func (d *GroupParticipantsData) EventName() string { return EventNameGroupParticipants }
+
+// DeletedForMeData is the data of message.deleted_for_me events
+type DeletedForMeData struct {
+	MessageID string           `json:"message_id"`
+	ChatJID   string           `json:"chat_jid,omitempty"`
+	SenderJID string           `json:"sender_jid,omitempty"`
+	Original  *OriginalMessage `json:"original,omitempty"` // only present when the message is in the chat storage
+}
+
+func (d *DeletedForMeData) EventName() string { return EventNameMessageDeletedForMe }
+
+type OriginalMessage struct {
+	Content   string `json:"content"`
+	SenderJID string `json:"sender_jid"`
+	Timestamp string `json:"timestamp" jsonschema:"format=date-time"`
+	FromMe    bool   `json:"from_me"`
+	MediaType string `json:"media_type,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+}
+
+// PresenceData is the data of presence events
+type PresenceData struct {
+	JID         string `json:"jid"`
+	Unavailable bool   `json:"unavailable"`
+	LastSeen    string `json:"last_seen,omitempty" jsonschema:"format=date-time"`
+}
+
+func (d *PresenceData) EventName() string { return EventNamePresence }
+
+// SendJobData is the data of send_job.sent and send_job.failed events, the outcome of a queued or scheduled message
+type SendJobData struct {
+	JobID       string `json:"job_id"`
+	Recipient   string `json:"recipient"`
+	MessageID   string `json:"message_id"`
+	Status      string `json:"status" jsonschema:"enum=sent,enum=failed"`
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error,omitempty"`
+	ScheduledAt string `json:"scheduled_at,omitempty" jsonschema:"format=date-time"`
+	SentAt      string `json:"sent_at,omitempty" jsonschema:"format=date-time"`
+}
+
+func (d *SendJobData) EventName() string {
+	if d.Status == "sent" {
+		return EventNameSendJobSent
+	}
+	return EventNameSendJobFailed
+}
+
+// PollVoteData is the data of poll.vote events, the latest vote of a voter on a poll. A vote without options takes
+// the earlier vote back.
+type PollVoteData struct {
+	PollID       string   `json:"poll_id"`
+	ChatJID      string   `json:"chat_jid"`
+	VoterJID     string   `json:"voter_jid"`
+	Question     string   `json:"question,omitempty"` // only present when the poll is in the chat storage
+	Options      []string `json:"options"`            // names of the selected options, known when the poll is in the chat storage
+	OptionHashes []string `json:"option_hashes"`      // hex SHA-256 hashes of the names of the selected options
+}
+
+func (d *PollVoteData) EventName() string { return EventNamePollVote }
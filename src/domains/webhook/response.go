@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Actions a receiver can return in its response to a message event, they are run against the chat of the message
+const (
+	ResponseActionReply      = "reply"       // send a text
+	ResponseActionReplyMedia = "reply_media" // send an image, video or audio downloaded from a URL
+	ResponseActionReact      = "react"       // react to the message
+	ResponseActionMarkRead   = "mark_read"   // mark the message as read
+	ResponseActionTyping     = "typing"      // show or stop the typing or recording indicator
+)
+
+// ResponseActionTypes lists every action a webhook response can contain
+var ResponseActionTypes = []string{ResponseActionReply, ResponseActionReplyMedia, ResponseActionReact, ResponseActionMarkRead, ResponseActionTyping}
+
+// ResponseMediaTypes lists the media a reply_media action can send
+var ResponseMediaTypes = []string{MediaTypeImage, MediaTypeVideo, MediaTypeAudio}
+
+// Typing states of a typing action
+const (
+	TypingComposing = "composing"
+	TypingRecording = "recording"
+	TypingPaused    = "paused"
+)
+
+// TypingStates lists the states of a typing action
+var TypingStates = []string{TypingComposing, TypingRecording, TypingPaused}
+
+const (
+	MaxResponseActions = 10
+	MaxTypingSeconds   = 10
+)
+
+// Origin is the incoming message an event is about, the actions of a webhook response are run against it
+type Origin struct {
+	Device    string `db:"origin_device"` // device the message was received on, empty for the default device
+	ChatJID   string `db:"origin_chat_jid"`
+	SenderJID string `db:"origin_sender_jid"`
+	MessageID string `db:"origin_message_id"`
+}
+
+// Response is the body a receiver may answer a message event with
+type Response struct {
+	Actions []ResponseAction `json:"actions"`
+}
+
+// ResponseAction is an action of a webhook response
+type ResponseAction struct {
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`       // reply
+	MediaURL  string `json:"media_url,omitempty"`  // reply_media
+	MediaType string `json:"media_type,omitempty"` // reply_media, one of ResponseMediaTypes
+	Caption   string `json:"caption,omitempty"`    // reply_media of an image or video
+	Quote     bool   `json:"quote,omitempty"`      // reply and reply_media quoting the message
+	Emoji     string `json:"emoji,omitempty"`      // react, empty removes the reaction
+	State     string `json:"state,omitempty"`      // typing, one of TypingStates, defaults to composing
+	Seconds   int    `json:"seconds,omitempty"`    // typing, how long to wait before the next action
+}
+
+// ParseResponse returns the actions of a webhook response. The body is either an object with an actions list or the
+// list itself; bodies that are not JSON, like "OK", have no actions. An invalid action rejects the whole response
+// so a receiver never gets half of what it asked for.
+func ParseResponse(body []byte) ([]ResponseAction, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var actions []ResponseAction
+	switch body[0] {
+	case '{':
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("invalid response body: %w", err)
+		}
+		actions = response.Actions
+	case '[':
+		if err := json.Unmarshal(body, &actions); err != nil {
+			return nil, fmt.Errorf("invalid response body: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	if len(actions) > MaxResponseActions {
+		return nil, fmt.Errorf("a response can have at most %d actions, got %d", MaxResponseActions, len(actions))
+	}
+	for i, action := range actions {
+		if err := action.validate(); err != nil {
+			return nil, fmt.Errorf("action %d: %w", i+1, err)
+		}
+	}
+	return actions, nil
+}
+
+func (a ResponseAction) validate() error {
+	switch a.Type {
+	case ResponseActionReply:
+		if a.Text == "" {
+			return fmt.Errorf("text is required for a reply action")
+		}
+	case ResponseActionReplyMedia:
+		if a.MediaURL == "" {
+			return fmt.Errorf("media_url is required for a reply_media action")
+		}
+		if !slices.Contains(ResponseMediaTypes, a.MediaType) {
+			return fmt.Errorf("media_type must be one of %v", ResponseMediaTypes)
+		}
+	case ResponseActionTyping:
+		if a.State != "" && !slices.Contains(TypingStates, a.State) {
+			return fmt.Errorf("state must be one of %v", TypingStates)
+		}
+		if a.Seconds < 0 || a.Seconds > MaxTypingSeconds {
+			return fmt.Errorf("seconds must be between 0 and %d", MaxTypingSeconds)
+		}
+	case ResponseActionReact, ResponseActionMarkRead:
+	default:
+		return fmt.Errorf("type must be one of %v", ResponseActionTypes)
+	}
+	return nil
+}
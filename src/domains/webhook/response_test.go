@@ -0,0 +1,89 @@
+package webhook_test
+
+import (
+	"testing"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []domainWebhook.ResponseAction
+		err      string
+	}{
+		{
+			name: "empty body",
+			body: "  ",
+		},
+		{
+			name: "body that is not JSON",
+			body: "OK",
+		},
+		{
+			name: "object without actions",
+			body: `{"status": "ok"}`,
+		},
+		{
+			name: "object with actions",
+			body: `{"actions": [{"type": "typing", "seconds": 2}, {"type": "reply", "text": "Hi", "quote": true}]}`,
+			expected: []domainWebhook.ResponseAction{
+				{Type: domainWebhook.ResponseActionTyping, Seconds: 2},
+				{Type: domainWebhook.ResponseActionReply, Text: "Hi", Quote: true},
+			},
+		},
+		{
+			name: "list of actions",
+			body: `[{"type": "react", "emoji": "👍"}, {"type": "mark_read"}]`,
+			expected: []domainWebhook.ResponseAction{
+				{Type: domainWebhook.ResponseActionReact, Emoji: "👍"},
+				{Type: domainWebhook.ResponseActionMarkRead},
+			},
+		},
+		{
+			name: "malformed JSON",
+			body: `{"actions": [`,
+			err:  "invalid response body: unexpected end of JSON input",
+		},
+		{
+			name: "unknown action",
+			body: `[{"type": "mark_read"}, {"type": "forward"}]`,
+			err:  "action 2: type must be one of [reply reply_media react mark_read typing]",
+		},
+		{
+			name: "reply without text",
+			body: `[{"type": "reply"}]`,
+			err:  "action 1: text is required for a reply action",
+		},
+		{
+			name: "media of an unsupported type",
+			body: `[{"type": "reply_media", "media_url": "https://example.com/a.pdf", "media_type": "document"}]`,
+			err:  "action 1: media_type must be one of [image video audio]",
+		},
+		{
+			name: "typing too long",
+			body: `[{"type": "typing", "seconds": 60}]`,
+			err:  "action 1: seconds must be between 0 and 10",
+		},
+		{
+			name: "too many actions",
+			body: `[{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},
+				{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"},{"type":"mark_read"}]`,
+			err: "a response can have at most 10 actions, got 11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions, err := domainWebhook.ParseResponse([]byte(tt.body))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actions)
+		})
+	}
+}
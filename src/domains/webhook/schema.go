@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/invopop/jsonschema"
+)
+
+// PayloadSchemaID is the base of the $id of the generated payload schemas
+const PayloadSchemaID = "https://github.com/aldinokemal/go-whatsapp-web-multidevice/docs/webhook-schema"
+
+// legacyPayloads are the bodies of payload version 1
+var legacyPayloads = []LegacyPayload{
+	&MessagePayloadV1{},
+	&ReceiptPayloadV1{},
+	&GroupPayloadV1{},
+	&DeletePayloadV1{},
+	&PresencePayloadV1{},
+	&SendJobPayloadV1{},
+	&PollVotePayloadV1{},
+}
+
+// eventPayloads are the data of payload version 2 with the event names they are sent with
+var eventPayloads = []struct {
+	events []string
+	data   EventData
+}{
+	{[]string{EventNameMessage, EventNameMessageEdited, EventNameMessageRevoked}, &MessageData{}},
+	{[]string{EventNameMessageAck}, &ReceiptData{}},
+	{[]string{EventNameGroupParticipants}, &GroupParticipantsData{}},
+	{[]string{EventNameMessageDeletedForMe}, &DeletedForMeData{}},
+	{[]string{EventNamePresence}, &PresenceData{}},
+	{[]string{EventNameSendJobSent, EventNameSendJobFailed}, &SendJobData{}},
+	{[]string{EventNamePollVote}, &PollVoteData{}},
+}
+
+// PayloadSchema generates the JSON Schema of every payload sent in the given payload version from the payload structs
+func PayloadSchema(version int) (*jsonschema.Schema, error) {
+	reflector := &jsonschema.Reflector{
+		AllowAdditionalProperties: true,
+		Mapper:                    protoMessageSchema,
+	}
+
+	root := &jsonschema.Schema{
+		Version:     jsonschema.Version,
+		ID:          jsonschema.ID(fmt.Sprintf("%s/v%d.json", PayloadSchemaID, version)),
+		Title:       fmt.Sprintf("Webhook payload version %d", version),
+		Definitions: jsonschema.Definitions{},
+	}
+
+	// addDefinitions reflects a struct into the definitions of the root schema and returns a reference to it
+	addDefinitions := func(v any) *jsonschema.Schema {
+		schema := reflector.Reflect(v)
+		for name, definition := range schema.Definitions {
+			root.Definitions[name] = definition
+		}
+		return &jsonschema.Schema{Ref: schema.Ref}
+	}
+
+	switch version {
+	case PayloadVersion1:
+		for _, payload := range legacyPayloads {
+			root.OneOf = append(root.OneOf, addDefinitions(payload))
+		}
+	case PayloadVersion2:
+		envelope := addDefinitions(&Envelope{})
+		for _, payload := range eventPayloads {
+			events := make([]any, len(payload.events))
+			for i, event := range payload.events {
+				events[i] = event
+			}
+
+			properties := jsonschema.NewProperties()
+			properties.Set("event", &jsonschema.Schema{Type: "string", Enum: events})
+			properties.Set("version", &jsonschema.Schema{Type: "integer", Const: PayloadVersion2})
+			properties.Set("data", addDefinitions(payload.data))
+
+			root.OneOf = append(root.OneOf, &jsonschema.Schema{
+				AllOf:      []*jsonschema.Schema{envelope},
+				Properties: properties,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook payload version %d", version)
+	}
+
+	return root, nil
+}
+
+// protoMessageSchema describes the WhatsApp protobuf messages of version 1 payloads as plain objects,
+// they are serialized as they come from WhatsApp and have no stable shape
+func protoMessageSchema(t reflect.Type) *jsonschema.Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() != "go.mau.fi/whatsmeow/proto/waE2E" {
+		return nil
+	}
+	return &jsonschema.Schema{Type: "object"}
+}
+
+// JSONSchemaExtend makes the fields of the original message optional, they are only sent when the message is stored
+func (DeletePayloadV1) JSONSchemaExtend(schema *jsonschema.Schema) {
+	original := reflect.TypeOf(OriginalMessageV1{})
+	schema.Required = slices.DeleteFunc(schema.Required, func(name string) bool {
+		for i := range original.NumField() {
+			if tag := original.Field(i).Tag.Get("json"); tag == name {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,42 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run `go test ./domains/webhook -run TestPayloadSchema -update` to regenerate the published schemas
+var update = flag.Bool("update", false, "update the JSON schemas in docs/webhook-schema")
+
+func TestPayloadSchema(t *testing.T) {
+	for _, version := range domainWebhook.PayloadVersions {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			schema, err := domainWebhook.PayloadSchema(version)
+			require.NoError(t, err)
+
+			got, err := json.MarshalIndent(schema, "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			published := filepath.Join("..", "..", "..", "docs", "webhook-schema", fmt.Sprintf("v%d.json", version))
+			if *update {
+				require.NoError(t, os.WriteFile(published, got, 0644))
+			}
+
+			want, err := os.ReadFile(published)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got), "the payload structs changed, run the test with -update")
+		})
+	}
+
+	_, err := domainWebhook.PayloadSchema(99)
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package webhook
+
+// Request and Response structures for webhook endpoint and outbox operations
+
+type EndpointRequest struct {
+	ID string `json:"id" uri:"id"`
+}
+
+type CreateEndpointRequest struct {
+	URL            string   `json:"url" form:"url"`
+	Secret         string   `json:"secret" form:"secret"`
+	Events         []string `json:"events" form:"events"`
+	AllowChats     []string `json:"allow_chats" form:"allow_chats"`
+	DenyChats      []string `json:"deny_chats" form:"deny_chats"`
+	PayloadVersion int      `json:"payload_version" form:"payload_version"` // defaults to version 1
+	Enabled        *bool    `json:"enabled" form:"enabled"`
+}
+
+type UpdateEndpointRequest struct {
+	ID             string   `json:"id" uri:"id"`
+	URL            string   `json:"url" form:"url"`
+	Secret         string   `json:"secret" form:"secret"` // empty keeps the current secret
+	Events         []string `json:"events" form:"events"`
+	AllowChats     []string `json:"allow_chats" form:"allow_chats"`
+	DenyChats      []string `json:"deny_chats" form:"deny_chats"`
+	PayloadVersion int      `json:"payload_version" form:"payload_version"` // zero keeps the current version
+	Enabled        *bool    `json:"enabled" form:"enabled"`
+}
+
+type RotateSecretRequest struct {
+	ID      string `json:"id" uri:"id"`
+	Secret  string `json:"secret" form:"secret"`   // empty generates a new secret
+	Overlap string `json:"overlap" form:"overlap"` // how long the previous secret keeps signing, e.g. "24h"
+}
+
+type EndpointInfo struct {
+	ID                      string       `json:"id"`
+	URL                     string       `json:"url"`
+	Secret                  string       `json:"secret,omitempty"` // only returned when the endpoint is created or its secret rotated
+	PreviousSecretExpiresAt string       `json:"previous_secret_expires_at,omitempty"`
+	Events                  []string     `json:"events"`
+	AllowChats              []string     `json:"allow_chats"`
+	DenyChats               []string     `json:"deny_chats"`
+	PayloadVersion          int          `json:"payload_version"`
+	Enabled                 bool         `json:"enabled"`
+	ReadOnly                bool         `json:"read_only"`
+	Circuit                 *CircuitInfo `json:"circuit,omitempty"`
+	CreatedAt               string       `json:"created_at,omitempty"`
+	UpdatedAt               string       `json:"updated_at,omitempty"`
+}
+
+type CircuitInfo struct {
+	EndpointID          string `json:"endpoint_id,omitempty"`
+	URL                 string `json:"url,omitempty"`
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	OpenedAt            string `json:"opened_at,omitempty"`
+	RetryAt             string `json:"retry_at,omitempty"`
+	QueueLength         int    `json:"queue_length"`
+}
+
+type ListDeliveriesRequest struct {
+	EndpointID string  `json:"endpoint_id" query:"endpoint_id"`
+	Status     string  `json:"status" query:"status"`
+	From       *string `json:"from" query:"from"`
+	To         *string `json:"to" query:"to"`
+	Limit      int     `json:"limit" query:"limit"`
+	Offset     int     `json:"offset" query:"offset"`
+}
+
+type ListDeliveriesResponse struct {
+	Data       []DeliveryInfo     `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type ReplayDeliveryRequest struct {
+	ID int64 `json:"id" uri:"id"`
+}
+
+type ReplayDeliveriesRequest struct {
+	EndpointID string  `json:"endpoint_id"`
+	Status     string  `json:"status"`
+	From       *string `json:"from"`
+	To         *string `json:"to"`
+}
+
+type ReplayDeliveriesResponse struct {
+	Replayed int64 `json:"replayed"`
+}
+
+type DeliveryInfo struct {
+	ID            int64  `json:"id"`
+	EndpointID    string `json:"endpoint_id"`
+	URL           string `json:"url"`
+	Event         string `json:"event"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	DeliveredAt   string `json:"delivered_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	Payload       any    `json:"payload,omitempty"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	purgeInterval = time.Hour
+	writeTimeout  = 5 * time.Second
+
+	// MaxSummaryLength limits the stored request summary
+	MaxSummaryLength = 2000
+)
+
+var repo domainAudit.IAuditRepository
+
+// Init sets the repository the audit log is written to
+func Init(repository domainAudit.IAuditRepository) {
+	repo = repository
+}
+
+// Record appends an entry to the audit log. Failures are logged and never fail the audited call.
+func Record(ctx context.Context, entry domainAudit.Entry) {
+	if repo == nil {
+		return
+	}
+	if entry.Result == "" {
+		entry.Result = domainAudit.ResultSuccess
+	}
+	if len(entry.Summary) > MaxSummaryLength {
+		entry.Summary = entry.Summary[:MaxSummaryLength] + "..."
+	}
+
+	// The entry is written even when the request context has been cancelled in the meantime
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+	if err := repo.InsertEntry(writeCtx, &entry); err != nil {
+		logrus.Errorf("Failed to write audit entry for %s by %q: %v", entry.Action, entry.Actor, err)
+	}
+}
+
+// RunPurger removes entries older than the audit retention until the context is cancelled
+func RunPurger(ctx context.Context) {
+	if repo == nil || config.AppAuditRetention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context) {
+	purged, err := repo.PurgeEntriesBefore(ctx, time.Now().UTC().Add(-config.AppAuditRetention))
+	if err != nil {
+		logrus.Errorf("Failed to purge audit log: %v", err)
+		return
+	}
+	if purged > 0 {
+		logrus.Infof("Purged %d audit entr(ies) older than %s", purged, config.AppAuditRetention)
+	}
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxValueLength limits single values of the summary, e.g. base64 media or long messages
+const maxValueLength = 200
+
+// targetFields are the request fields naming the target of a call, the first one present wins
+var targetFields = []string{"chat_jid", "phone", "group_id", "newsletter_id", "jid", "username", "id"}
+
+// Target returns the JID, or other identifier, a call with the given request fields was aimed at
+func Target(fields map[string]any) string {
+	for _, name := range targetFields {
+		if value, ok := fields[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Summarize renders request fields as JSON for the audit log. Secrets are redacted and long values truncated.
+func Summarize(fields map[string]any) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	body, err := json.Marshal(sanitize(fields))
+	if err != nil {
+		return ""
+	}
+	return string(body)
+}
+
+func sanitize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clean := make(map[string]any, len(v))
+		for name, field := range v {
+			if isSecretField(name) {
+				clean[name] = "[redacted]"
+				continue
+			}
+			clean[name] = sanitize(field)
+		}
+		return clean
+	case []any:
+		clean := make([]any, len(v))
+		for i, item := range v {
+			clean[i] = sanitize(item)
+		}
+		return clean
+	case string:
+		if len(v) > maxValueLength {
+			// Cut at the start of a rune, so a multi-byte character is not split into invalid UTF-8
+			cut := maxValueLength
+			for cut > 0 && !utf8.RuneStart(v[cut]) {
+				cut--
+			}
+			return fmt.Sprintf("%s... (%d bytes)", v[:cut], len(v))
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "password") || strings.Contains(name, "secret") || strings.Contains(name, "token")
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarget(t *testing.T) {
+	assert.Equal(t, "628123456789", Target(map[string]any{"phone": "628123456789", "message": "hi"}))
+	assert.Equal(t, "120363@g.us", Target(map[string]any{"group_id": "120363@g.us", "participants": []any{"6281"}}))
+	assert.Equal(t, "6281@s.whatsapp.net", Target(map[string]any{"chat_jid": "6281@s.whatsapp.net", "phone": "6282"}))
+	assert.Equal(t, "", Target(map[string]any{"message": "hi"}))
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, "", Summarize(nil))
+
+	summary := Summarize(map[string]any{
+		"phone":    "628123456789",
+		"password": "hunter22",
+		"image":    strings.Repeat("A", 1000),
+		"nested":   map[string]any{"webhook_secret": "s"},
+	})
+	assert.Contains(t, summary, `"phone":"628123456789"`)
+	assert.Contains(t, summary, `"password":"[redacted]"`)
+	assert.Contains(t, summary, `"webhook_secret":"[redacted]"`)
+	assert.Contains(t, summary, "... (1000 bytes)")
+	assert.NotContains(t, summary, "hunter22")
+}
+
+func TestSummarizeMultiByte(t *testing.T) {
+	// "é" is two bytes, the 200th byte falls in the middle of the 100th character
+	message := "x" + strings.Repeat("é", 150)
+	summary := Summarize(map[string]any{"message": message})
+
+	assert.True(t, utf8.ValidString(summary))
+	assert.NotContains(t, summary, "\\ufffd")
+	assert.Contains(t, summary, `"message":"x`+strings.Repeat("é", 99)+`... (301 bytes)"`)
+}
@@ -0,0 +1,210 @@
+package autoreply
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/appstate"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// maxCooldowns is the number of cooldowns kept before the expired ones are dropped
+const maxCooldowns = 10000
+
+var (
+	repo   domainAutoReply.IAutoReplyRepository
+	sender domainSend.ISendUsecase
+
+	cooldownsMu sync.Mutex
+	cooldowns   = make(map[string]time.Time) // by rule, device and chat, the rule does not run again before
+)
+
+// Init sets the repository of the rules and the send service the replies are sent through, loads the rules and
+// evaluates them on the messages of every device
+func Init(ctx context.Context, repository domainAutoReply.IAutoReplyRepository, sendService domainSend.ISendUsecase) error {
+	repo, sender = repository, sendService
+	whatsapp.AddMessageListener(handleMessage)
+	return ReloadRules(ctx)
+}
+
+// handleMessage runs the actions of the rules matching an incoming message, in priority order until a rule
+// matches that does not continue
+func handleMessage(ctx context.Context, evt *events.Message) {
+	message, ok := describeMessage(evt)
+	if !ok {
+		return
+	}
+
+	for _, matcher := range loadedMatchers() {
+		rule := matcher.Rule
+		if !onDevice(ctx, rule.DeviceID) || !matcher.Matches(message) {
+			continue
+		}
+
+		if coolingDown(ctx, rule, evt.Info.Chat, message.Time) {
+			logrus.Debugf("Auto-reply rule %s matched message %s but is cooling down in %s", rule.ID, evt.Info.ID, evt.Info.Chat)
+		} else {
+			logrus.Infof("Auto-reply rule %s matched message %s from %s", rule.ID, evt.Info.ID, evt.Info.SourceString())
+			for _, action := range rule.Actions {
+				if err := run(ctx, rule, action, evt, message); err != nil {
+					logrus.Errorf("Auto-reply rule %s failed to %s message %s: %v", rule.ID, action.Type, evt.Info.ID, err)
+				}
+			}
+		}
+
+		if !rule.Continue {
+			return
+		}
+	}
+}
+
+// describeMessage returns what the conditions are matched against, false for messages no rule answers: our own,
+// broadcasts and status updates, and protocol messages like edits, revokes and reactions
+func describeMessage(evt *events.Message) (domainAutoReply.Message, bool) {
+	if evt.Info.IsFromMe || evt.Info.IsIncomingBroadcast() || evt.Info.Chat.Server == types.BroadcastServer {
+		return domainAutoReply.Message{}, false
+	}
+
+	message := domainAutoReply.Message{SenderJID: evt.Info.Sender.String(), Time: evt.Info.Timestamp}
+	switch evt.Info.Chat.Server {
+	case types.DefaultUserServer, types.HiddenUserServer:
+		message.ChatType = domainAutoReply.ChatPrivate
+	case types.GroupServer:
+		message.ChatType = domainAutoReply.ChatGroup
+	default:
+		return message, false
+	}
+
+	msg := evt.Message
+	switch {
+	case msg.GetConversation() != "":
+		message.MediaType, message.Text = domainAutoReply.MediaText, msg.GetConversation()
+	case msg.GetExtendedTextMessage().GetText() != "":
+		message.MediaType, message.Text = domainAutoReply.MediaText, msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		message.MediaType, message.Text = domainAutoReply.MediaImage, msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		message.MediaType, message.Text = domainAutoReply.MediaVideo, msg.GetVideoMessage().GetCaption()
+	case msg.GetAudioMessage() != nil:
+		message.MediaType = domainAutoReply.MediaAudio
+	case msg.GetDocumentMessage() != nil:
+		message.MediaType, message.Text = domainAutoReply.MediaDocument, msg.GetDocumentMessage().GetCaption()
+	case msg.GetStickerMessage() != nil:
+		message.MediaType = domainAutoReply.MediaSticker
+	case msg.GetLocationMessage() != nil || msg.GetLiveLocationMessage() != nil:
+		message.MediaType = domainAutoReply.MediaLocation
+	case msg.GetContactMessage() != nil || msg.GetContactsArrayMessage() != nil:
+		message.MediaType = domainAutoReply.MediaContact
+	case msg.GetPollCreationMessage() != nil || msg.GetPollCreationMessageV2() != nil || msg.GetPollCreationMessageV3() != nil:
+		message.MediaType = domainAutoReply.MediaPoll
+	default:
+		return message, false
+	}
+	return message, true
+}
+
+// onDevice reports whether the device of a rule is the device the message was received on
+func onDevice(ctx context.Context, deviceID string) bool {
+	if deviceID == "" {
+		return true
+	}
+	return whatsapp.DeviceExists(deviceID) &&
+		whatsapp.ClientFromContext(whatsapp.ContextWithDevice(ctx, deviceID)) == whatsapp.ClientFromContext(ctx)
+}
+
+// coolingDown reports whether the rule ran in the chat within its cooldown, and starts the cooldown when not
+func coolingDown(ctx context.Context, rule *domainAutoReply.Rule, chat types.JID, now time.Time) bool {
+	if rule.Cooldown <= 0 {
+		return false
+	}
+
+	key := strings.Join([]string{rule.ID, whatsapp.DeviceIDFromContext(ctx), chat.String()}, "|")
+
+	cooldownsMu.Lock()
+	defer cooldownsMu.Unlock()
+
+	if until, ok := cooldowns[key]; ok && now.Before(until) {
+		return true
+	}
+	if len(cooldowns) >= maxCooldowns {
+		for k, until := range cooldowns {
+			if !now.Before(until) {
+				delete(cooldowns, k)
+			}
+		}
+	}
+	cooldowns[key] = now.Add(rule.Cooldown)
+	return false
+}
+
+// run runs an action of a rule on the message
+func run(ctx context.Context, rule *domainAutoReply.Rule, action domainAutoReply.Action, evt *events.Message, message domainAutoReply.Message) (err error) {
+	defer whatsapp.RecoverSend(&err)
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("device is not connected")
+	}
+
+	switch action.Type {
+	case domainAutoReply.ActionReply:
+		return reply(ctx, rule, action, evt, message)
+	case domainAutoReply.ActionReact:
+		_, err = client.SendMessage(ctx, evt.Info.Chat, client.BuildReaction(evt.Info.Chat, evt.Info.Sender, evt.Info.ID, action.Emoji))
+		return err
+	case domainAutoReply.ActionMarkRead:
+		return client.MarkRead([]types.MessageID{evt.Info.ID}, time.Now(), evt.Info.Chat, evt.Info.Sender)
+	case domainAutoReply.ActionLabel:
+		return client.SendAppState(ctx, appstate.BuildLabelChat(evt.Info.Chat, action.LabelID, true))
+	case domainAutoReply.ActionWebhook:
+		endpoint := webhook.GetEndpoint(action.WebhookID)
+		if endpoint == nil || !endpoint.Enabled {
+			return fmt.Errorf("webhook endpoint %s does not exist or is disabled", action.WebhookID)
+		}
+		event, err := whatsapp.CreateMessageEvent(ctx, evt)
+		if err != nil {
+			return err
+		}
+		return webhook.Enqueue(ctx, endpoint, event)
+	default:
+		return fmt.Errorf("unknown action %q", action.Type)
+	}
+}
+
+// reply sends the text or template of an action to the chat through the send service, as the user who created
+// the rule. Templates get the name and phone of the sender and the text of the message as variables.
+func reply(ctx context.Context, rule *domainAutoReply.Rule, action domainAutoReply.Action, evt *events.Message, message domainAutoReply.Message) error {
+	ctx = domainAuth.ContextWithPrincipal(ctx, domainAuth.Principal{Username: rule.Actor})
+
+	request := domainSend.MessageRequest{
+		BaseRequest: domainSend.BaseRequest{Phone: evt.Info.Chat.String()},
+		Message:     action.Text,
+	}
+	if action.Quote {
+		request.ReplyMessageID = &evt.Info.ID
+	}
+	if action.TemplateID != "" {
+		request.TemplateID = action.TemplateID
+		request.Variables = map[string]any{
+			"name":    evt.Info.PushName,
+			"phone":   evt.Info.Sender.User,
+			"message": message.Text,
+		}
+		for name, value := range action.Variables {
+			request.Variables[name] = value
+		}
+	}
+
+	_, err := sender.SendText(ctx, request)
+	return err
+}
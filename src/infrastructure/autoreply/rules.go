@@ -0,0 +1,113 @@
+package autoreply
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	"github.com/sirupsen/logrus"
+)
+
+// LegacyRuleID is the ID of the rule created from --autoreply
+const LegacyRuleID = "config-autoreply"
+
+var (
+	rulesMu  sync.RWMutex
+	matchers []*domainAutoReply.Matcher
+)
+
+// legacyRule turns the --autoreply message into a read-only rule replying to every text of a private chat,
+// like the single auto-reply did before the rules
+func legacyRule() *domainAutoReply.Rule {
+	if config.WhatsappAutoReplyMessage == "" {
+		return nil
+	}
+	return &domainAutoReply.Rule{
+		ID:      LegacyRuleID,
+		Name:    "--autoreply",
+		Enabled: true,
+		Conditions: domainAutoReply.Conditions{
+			ChatTypes:  []string{domainAutoReply.ChatPrivate},
+			MediaTypes: []string{domainAutoReply.MediaText},
+		},
+		Actions:  []domainAutoReply.Action{{Type: domainAutoReply.ActionReply, Text: config.WhatsappAutoReplyMessage}},
+		ReadOnly: true,
+	}
+}
+
+// ReloadRules refreshes the in-memory rules from the configuration and the chat storage.
+// It is called on boot and after every change made through the API, so changes apply to the next message.
+func ReloadRules(ctx context.Context) error {
+	var rules []*domainAutoReply.Rule
+	if repo != nil {
+		stored, err := repo.GetRules(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load auto-reply rules: %w", err)
+		}
+		rules = stored
+	}
+	// The configured rule answers whatever no stored rule of the same priority answered
+	if legacy := legacyRule(); legacy != nil {
+		rules = append(rules, legacy)
+		slices.SortStableFunc(rules, func(a, b *domainAutoReply.Rule) int { return a.Priority - b.Priority })
+	}
+
+	loaded := make([]*domainAutoReply.Matcher, 0, len(rules))
+	for _, rule := range rules {
+		matcher, err := domainAutoReply.NewMatcher(rule)
+		if err != nil {
+			// Validated when the rule was saved, the time zone database may differ on this host
+			logrus.Errorf("Auto-reply rule %s is skipped: %v", rule.ID, err)
+			continue
+		}
+		loaded = append(loaded, matcher)
+	}
+
+	rulesMu.Lock()
+	matchers = loaded
+	rulesMu.Unlock()
+
+	logrus.Debugf("Loaded %d auto-reply rule(s)", len(loaded))
+	return nil
+}
+
+// Rules returns every loaded rule in the order they are evaluated in
+func Rules() []*domainAutoReply.Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rules := make([]*domainAutoReply.Rule, 0, len(matchers))
+	for _, matcher := range matchers {
+		rules = append(rules, matcher.Rule)
+	}
+	return rules
+}
+
+// GetRule returns the loaded rule with the given ID, or nil when it does not exist
+func GetRule(id string) *domainAutoReply.Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, matcher := range matchers {
+		if matcher.Rule.ID == id {
+			return matcher.Rule
+		}
+	}
+	return nil
+}
+
+// loadedMatchers returns the matchers of the enabled rules
+func loadedMatchers() []*domainAutoReply.Matcher {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	enabled := make([]*domainAutoReply.Matcher, 0, len(matchers))
+	for _, matcher := range matchers {
+		if matcher.Rule.Enabled {
+			enabled = append(enabled, matcher)
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,351 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	pollInterval     = 5 * time.Second
+	queuedBatch      = 100
+	notLoggedInDelay = 30 * time.Second // a campaign of a device that is not logged in waits without failing recipients
+)
+
+// Websocket code of a finished campaign
+const wsCodeCampaignCompleted = "CAMPAIGN_COMPLETED"
+
+var (
+	repo    domainCampaign.ICampaignRepository
+	jobRepo domainSend.ISendJobRepository
+	sender  domainSend.ISendUsecase
+	wakeup  = make(chan struct{}, 1)
+
+	mu          sync.Mutex
+	defaultRate domainRateLimit.Limit
+	nextSend    = make(map[string]time.Time) // by campaign, no message is sent before
+)
+
+// Init sets the repositories of the campaigns and of the send queue and the send service the campaigns send
+// through, and rolls the receipts of every device up into the recipients
+func Init(repository domainCampaign.ICampaignRepository, jobRepository domainSend.ISendJobRepository, sendService domainSend.ISendUsecase) error {
+	rate, err := domainRateLimit.ParseLimit(config.WhatsappCampaignRate)
+	if err != nil {
+		return fmt.Errorf("campaign rate: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	repo, jobRepo, sender, defaultRate = repository, jobRepository, sendService, rate
+	whatsapp.AddReceiptListener(recordReceipt)
+	return nil
+}
+
+// Wakeup makes the runner look for running campaigns without waiting for the next poll
+func Wakeup() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends the messages of the running campaigns, each at its own rate, until the context is cancelled
+func Run(ctx context.Context) {
+	if repo == nil {
+		logrus.Warn("Campaign runner not started: the campaigns are not initialized")
+		return
+	}
+
+	// Whether a message left sending by a previous run reached WhatsApp is unknown, sending it again could duplicate it
+	if failed, err := repo.FailSendingRecipients(ctx, "interrupted while sending, it may not have been sent"); err != nil {
+		logrus.Errorf("Failed to fail interrupted campaign recipients: %v", err)
+	} else if failed > 0 {
+		logrus.Warnf("%d campaign recipients were interrupted while sending and marked as failed", failed)
+	}
+
+	for {
+		syncQueued(ctx)
+		wait := sendNext(ctx)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-wakeup:
+			timer.Stop()
+		}
+	}
+}
+
+// sendNext sends one message of every running campaign whose rate allows it and returns how long to wait
+// before the next campaign may send
+func sendNext(ctx context.Context) time.Duration {
+	mu.Lock()
+	defer mu.Unlock()
+
+	campaigns, err := repo.GetCampaigns(ctx, &domainCampaign.CampaignFilter{Status: domainCampaign.StatusRunning})
+	if err != nil {
+		logrus.Errorf("Failed to load running campaigns: %v", err)
+		return pollInterval
+	}
+
+	wait := pollInterval
+	running := make(map[string]bool, len(campaigns))
+	for _, campaign := range campaigns {
+		running[campaign.ID] = true
+
+		now := time.Now().UTC()
+		if next := nextSend[campaign.ID]; next.After(now) {
+			wait = min(wait, next.Sub(now))
+			continue
+		}
+
+		recipient, err := repo.NextPendingRecipient(ctx, campaign.ID)
+		if err != nil {
+			logrus.Errorf("Failed to load next recipient of campaign %s: %v", campaign.ID, err)
+			continue
+		}
+		if recipient == nil {
+			complete(ctx, campaign)
+			continue
+		}
+
+		delay := send(ctx, campaign, recipient)
+		nextSend[campaign.ID] = time.Now().UTC().Add(delay)
+		wait = min(wait, delay)
+	}
+
+	// Forget the campaigns that were paused, cancelled or completed
+	for id := range nextSend {
+		if !running[id] {
+			delete(nextSend, id)
+		}
+	}
+	return wait
+}
+
+// send sends the message of a campaign to a recipient and records the outcome. It returns how long the campaign
+// waits before its next message.
+func send(ctx context.Context, campaign *domainCampaign.Campaign, recipient *domainCampaign.Recipient) time.Duration {
+	recipient.Status = domainCampaign.RecipientSending
+	claimed, err := repo.UpdateRecipient(ctx, recipient, domainCampaign.RecipientPending)
+	if err != nil {
+		logrus.Errorf("Failed to claim recipient %s of campaign %s: %v", recipient.Phone, campaign.ID, err)
+		return pollInterval
+	}
+	if !claimed {
+		// Skipped by a cancel since it was loaded
+		return 0
+	}
+
+	// The sends count against the limits of the creator, and the idempotency key keeps a queued message from being
+	// queued twice
+	principal := domainAuth.Principal{Username: campaign.Actor}
+	sendCtx := whatsapp.ContextWithDevice(ctx, campaign.DeviceID)
+	sendCtx = domainAuth.ContextWithPrincipal(sendCtx, principal)
+	sendCtx = domainSend.ContextWithIdempotencyKey(sendCtx, fmt.Sprintf("campaign:%s:%d", campaign.ID, recipient.ID))
+
+	if !whatsapp.DeviceExists(campaign.DeviceID) {
+		// The session of the campaign was removed, another device must not send it
+		release(ctx, campaign, recipient)
+		hold(ctx, campaign)
+		return pollInterval
+	}
+	client := whatsapp.ClientFromContext(sendCtx)
+	if client == nil || !client.IsLoggedIn() {
+		release(ctx, campaign, recipient)
+		return notLoggedInDelay
+	}
+
+	var rateLimited pkgError.RateLimitError
+	if err := ratelimit.Allow(sendCtx, principal, permission(campaign), recipient.Phone); errors.As(err, &rateLimited) {
+		logrus.Debugf("Campaign %s waits %s for the rate limit of %s", campaign.ID, rateLimited.RetryAfter, campaign.Actor)
+		release(ctx, campaign, recipient)
+		return rateLimited.RetryAfter
+	}
+
+	response, err := sendMessage(sendCtx, campaign, recipient)
+	if err != nil {
+		logrus.Warnf("Campaign %s failed to send to %s: %v", campaign.ID, recipient.Phone, err)
+		recipient.Status, recipient.Error = domainCampaign.RecipientFailed, err.Error()
+	} else if response.JobID != "" {
+		recipient.Status, recipient.MessageID, recipient.JobID = domainCampaign.RecipientQueued, response.MessageID, response.JobID
+	} else {
+		sentAt := time.Now().UTC()
+		recipient.Status, recipient.MessageID, recipient.SentAt = domainCampaign.RecipientSent, response.MessageID, &sentAt
+	}
+
+	if _, err := repo.UpdateRecipient(ctx, recipient, domainCampaign.RecipientSending); err != nil {
+		logrus.Errorf("Failed to record send to %s of campaign %s: %v", recipient.Phone, campaign.ID, err)
+	}
+	return interval(campaign)
+}
+
+// sendMessage renders the message of a campaign for a recipient and sends it with the media of the campaign
+func sendMessage(ctx context.Context, campaign *domainCampaign.Campaign, recipient *domainCampaign.Recipient) (response domainSend.GenericResponse, err error) {
+	// The device may disconnect after it was checked, the send then panics instead of failing the recipient
+	defer whatsapp.RecoverSend(&err)
+
+	message, err := domainTemplate.Render(campaign.Message, domainCampaign.Variables(recipient.Phone, recipient.Variables), nil)
+	if err != nil {
+		return response, err
+	}
+
+	phone := recipient.Phone
+	utils.SanitizePhone(&phone)
+	base := domainSend.BaseRequest{Phone: phone}
+
+	switch campaign.MediaType {
+	case domainCampaign.MediaImage:
+		return sender.SendImage(ctx, domainSend.ImageRequest{BaseRequest: base, Caption: message, ImageURL: &campaign.MediaURL, Compress: true})
+	case domainCampaign.MediaVideo:
+		return sender.SendVideo(ctx, domainSend.VideoRequest{BaseRequest: base, Caption: message, VideoURL: &campaign.MediaURL})
+	default:
+		return sender.SendText(ctx, domainSend.MessageRequest{BaseRequest: base, Message: message})
+	}
+}
+
+// release puts a claimed recipient back so it is sent to once the campaign may send again
+func release(ctx context.Context, campaign *domainCampaign.Campaign, recipient *domainCampaign.Recipient) {
+	recipient.Status = domainCampaign.RecipientPending
+	if _, err := repo.UpdateRecipient(ctx, recipient, domainCampaign.RecipientSending); err != nil {
+		logrus.Errorf("Failed to release recipient %s of campaign %s: %v", recipient.Phone, campaign.ID, err)
+	}
+}
+
+// hold pauses a running campaign whose device is no longer served, it can be resumed or cancelled once checked
+func hold(ctx context.Context, campaign *domainCampaign.Campaign) {
+	paused, err := repo.SetCampaignStatus(ctx, campaign.ID, domainCampaign.StatusPaused, domainCampaign.StatusRunning)
+	if err != nil {
+		logrus.Errorf("Failed to pause campaign %s: %v", campaign.ID, err)
+		return
+	}
+	if paused {
+		logrus.Warnf("Campaign %s paused: device %s: %v", campaign.ID, campaign.DeviceID, pkgError.ErrDeviceNotFound)
+	}
+}
+
+// complete finishes a campaign without pending recipients once the send queue is done with its messages too
+func complete(ctx context.Context, campaign *domainCampaign.Campaign) {
+	counts, err := repo.CountRecipientsByStatus(ctx, campaign.ID)
+	if err != nil {
+		logrus.Errorf("Failed to count recipients of campaign %s: %v", campaign.ID, err)
+		return
+	}
+	if counts[domainCampaign.RecipientPending]+counts[domainCampaign.RecipientSending]+counts[domainCampaign.RecipientQueued] > 0 {
+		return
+	}
+
+	completed, err := repo.SetCampaignStatus(ctx, campaign.ID, domainCampaign.StatusCompleted, domainCampaign.StatusRunning)
+	if err != nil {
+		logrus.Errorf("Failed to complete campaign %s: %v", campaign.ID, err)
+		return
+	}
+	if !completed {
+		return
+	}
+
+	failed := counts[domainCampaign.RecipientFailed]
+	logrus.Infof("Campaign %s completed, %d of %d recipients failed", campaign.ID, failed, campaign.Recipients)
+	whatsapp.BroadcastDeviceResult(whatsapp.ContextWithDevice(ctx, campaign.DeviceID), wsCodeCampaignCompleted,
+		fmt.Sprintf("Campaign %s completed, %d of %d recipients failed", campaign.Name, failed, campaign.Recipients),
+		map[string]any{"campaign_id": campaign.ID, "statuses": counts})
+}
+
+// syncQueued moves the recipients whose message waited in the send queue to the outcome of their job
+func syncQueued(ctx context.Context) {
+	recipients, err := repo.GetQueuedRecipients(ctx, queuedBatch)
+	if err != nil {
+		logrus.Errorf("Failed to load queued campaign recipients: %v", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		job, err := jobRepo.GetJob(ctx, recipient.JobID)
+		if err != nil {
+			logrus.Errorf("Failed to load send job %s of campaign %s: %v", recipient.JobID, recipient.CampaignID, err)
+			continue
+		}
+
+		switch {
+		case job == nil:
+			recipient.Status, recipient.Error = domainCampaign.RecipientFailed, fmt.Sprintf("send job %s no longer exists", recipient.JobID)
+		case job.Status == domainSend.JobStatusSent:
+			recipient.Status, recipient.SentAt = domainCampaign.RecipientSent, job.SentAt
+		case job.Status == domainSend.JobStatusFailed:
+			recipient.Status, recipient.Error = domainCampaign.RecipientFailed, job.LastError
+		case job.Status == domainSend.JobStatusCancelled:
+			recipient.Status, recipient.Error = domainCampaign.RecipientSkipped, fmt.Sprintf("send job %s was cancelled", job.ID)
+		default:
+			continue
+		}
+
+		if _, err := repo.UpdateRecipient(ctx, recipient, domainCampaign.RecipientQueued); err != nil {
+			logrus.Errorf("Failed to record send job outcome of campaign %s: %v", recipient.CampaignID, err)
+		}
+	}
+}
+
+// recordReceipt moves the recipients of the messages a contact received or read forward
+func recordReceipt(ctx context.Context, evt *events.Receipt) {
+	// Receipts of our other devices say nothing about the recipients
+	if evt.IsFromMe {
+		return
+	}
+
+	var status string
+	switch evt.Type {
+	case types.ReceiptTypeDelivered:
+		status = domainCampaign.RecipientDelivered
+	case types.ReceiptTypeRead:
+		status = domainCampaign.RecipientRead
+	default:
+		return
+	}
+
+	if _, err := repo.RecordReceipt(ctx, evt.MessageIDs, status, evt.Timestamp); err != nil {
+		logrus.Errorf("Failed to record %s receipt of campaign messages %v: %v", status, evt.MessageIDs, err)
+	}
+}
+
+// interval returns the pause between two messages of a campaign
+func interval(campaign *domainCampaign.Campaign) time.Duration {
+	rate := defaultRate
+	if campaign.Rate != "" {
+		// Validated when the campaign was created
+		if limit, err := domainRateLimit.ParseLimit(campaign.Rate); err == nil {
+			rate = limit
+		}
+	}
+	return rate.Per / time.Duration(rate.Count)
+}
+
+// permission returns the send permission whose rate limit the messages of a campaign count against
+func permission(campaign *domainCampaign.Campaign) string {
+	switch campaign.MediaType {
+	case domainCampaign.MediaImage:
+		return domainAuth.PermissionSendImage
+	case domainCampaign.MediaVideo:
+		return domainAuth.PermissionSendVideo
+	default:
+		return domainAuth.PermissionSendText
+	}
+}
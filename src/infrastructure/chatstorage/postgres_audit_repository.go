@@ -0,0 +1,116 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+)
+
+// PostgresAuditRepository stores the audit log in the Postgres chat storage
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditRepository creates an audit repository. The table is created by the chat storage migrations.
+func NewPostgresAuditRepository(db *sql.DB) domainAudit.IAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// InsertEntry appends an entry to the audit log
+func (r *PostgresAuditRepository) InsertEntry(ctx context.Context, entry *domainAudit.Entry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log (actor, api_key_id, source, action, device_id, target, summary, result, error, message_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, entry.Actor, entry.APIKeyID, entry.Source, entry.Action, entry.DeviceID, entry.Target, entry.Summary,
+		entry.Result, entry.Error, entry.MessageID, entry.CreatedAt.UTC()).Scan(&entry.ID)
+}
+
+// GetEntries retrieves entries with filtering, newest first
+func (r *PostgresAuditRepository) GetEntries(ctx context.Context, filter *domainAudit.EntryFilter) ([]*domainAudit.Entry, error) {
+	where, args := postgresAuditConditions(filter, 1)
+	query := "SELECT " + auditEntryColumns + " FROM audit_log" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+// CountEntries returns the number of entries matching the filter
+func (r *PostgresAuditRepository) CountEntries(ctx context.Context, filter *domainAudit.EntryFilter) (int64, error) {
+	where, args := postgresAuditConditions(filter, 1)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&count)
+	return count, err
+}
+
+// PurgeEntriesBefore removes entries created before the given time
+func (r *PostgresAuditRepository) PurgeEntriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM audit_log WHERE created_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// postgresAuditConditions builds the WHERE clause for an audit filter, numbering the parameters from start
+func postgresAuditConditions(filter *domainAudit.EntryFilter, start int) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", start+len(args)-1))
+	}
+	if filter.Action != "" {
+		args = append(args, escapeLike(filter.Action)+"%")
+		conditions = append(conditions, fmt.Sprintf("action LIKE $%d ESCAPE '\\'", start+len(args)-1))
+	}
+	if filter.Source != "" {
+		args = append(args, filter.Source)
+		conditions = append(conditions, fmt.Sprintf("source = $%d", start+len(args)-1))
+	}
+	if filter.Target != "" {
+		args = append(args, filter.Target)
+		conditions = append(conditions, fmt.Sprintf("target = $%d", start+len(args)-1))
+	}
+	if filter.Result != "" {
+		args = append(args, filter.Result)
+		conditions = append(conditions, fmt.Sprintf("result = $%d", start+len(args)-1))
+	}
+	if filter.From != nil {
+		args = append(args, filter.From.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", start+len(args)-1))
+	}
+	if filter.To != nil {
+		args = append(args, filter.To.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", start+len(args)-1))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,87 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+)
+
+// PostgresAutoReplyRepository stores the auto-reply rules in the PostgreSQL chat storage
+type PostgresAutoReplyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAutoReplyRepository creates an auto-reply rule repository. The table is created by the chat storage migrations.
+func NewPostgresAutoReplyRepository(db *sql.DB) domainAutoReply.IAutoReplyRepository {
+	return &PostgresAutoReplyRepository{db: db}
+}
+
+// CreateRule stores a new rule
+func (r *PostgresAutoReplyRepository) CreateRule(ctx context.Context, rule *domainAutoReply.Rule) error {
+	conditions, actions, err := encodeAutoReplyRule(rule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO auto_reply_rules (id, name, enabled, priority, device_id, conditions, actions, cooldown_seconds, continue_matching, actor, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, rule.ID, rule.Name, rule.Enabled, rule.Priority, rule.DeviceID, conditions, actions, int(rule.Cooldown/time.Second),
+		rule.Continue, rule.Actor, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// UpdateRule replaces the settings of a rule
+func (r *PostgresAutoReplyRepository) UpdateRule(ctx context.Context, rule *domainAutoReply.Rule) error {
+	conditions, actions, err := encodeAutoReplyRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now().UTC()
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE auto_reply_rules
+		SET name = $1, enabled = $2, priority = $3, device_id = $4, conditions = $5, actions = $6, cooldown_seconds = $7, continue_matching = $8, actor = $9, updated_at = $10
+		WHERE id = $11
+	`, rule.Name, rule.Enabled, rule.Priority, rule.DeviceID, conditions, actions, int(rule.Cooldown/time.Second),
+		rule.Continue, rule.Actor, rule.UpdatedAt, rule.ID)
+	return err
+}
+
+// DeleteRule removes a rule, it returns false when the rule does not exist
+func (r *PostgresAutoReplyRepository) DeleteRule(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM auto_reply_rules WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetRule retrieves a rule by ID, nil when it does not exist
+func (r *PostgresAutoReplyRepository) GetRule(ctx context.Context, id string) (*domainAutoReply.Rule, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules WHERE id = $1", id)
+	rule, err := scanAutoReplyRule(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// GetRules retrieves every rule by priority, then by age
+func (r *PostgresAutoReplyRepository) GetRules(ctx context.Context) ([]*domainAutoReply.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules ORDER BY priority ASC, created_at ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAutoReplyRules(rows)
+}
@@ -0,0 +1,323 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+)
+
+// PostgresCampaignRepository stores the campaigns and their recipients in the PostgreSQL chat storage
+type PostgresCampaignRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCampaignRepository creates a campaign repository. The tables are created by the chat storage migrations.
+func NewPostgresCampaignRepository(db *sql.DB) domainCampaign.ICampaignRepository {
+	return &PostgresCampaignRepository{db: db}
+}
+
+// CreateCampaign persists a campaign and its recipients, either all of them or none
+func (r *PostgresCampaignRepository) CreateCampaign(ctx context.Context, campaign *domainCampaign.Campaign, recipients []*domainCampaign.Recipient) error {
+	now := time.Now().UTC()
+	if campaign.Status == "" {
+		campaign.Status = domainCampaign.StatusDraft
+	}
+	campaign.Recipients = len(recipients)
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO campaigns (id, device_id, actor, name, message, media_type, media_url, rate, status, recipients, created_at, updated_at, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, campaign.ID, campaign.DeviceID, campaign.Actor, campaign.Name, campaign.Message, campaign.MediaType, campaign.MediaURL,
+		campaign.Rate, campaign.Status, campaign.Recipients, campaign.CreatedAt, campaign.UpdatedAt, utcOrNil(campaign.StartedAt))
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO campaign_recipients (campaign_id, phone, variables, status, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, recipient := range recipients {
+		variables, err := encodeRecipientVariables(recipient.Variables)
+		if err != nil {
+			return err
+		}
+		recipient.CampaignID = campaign.ID
+		recipient.Status = domainCampaign.RecipientPending
+		recipient.UpdatedAt = now
+
+		err = stmt.QueryRowContext(ctx, recipient.CampaignID, recipient.Phone, variables, recipient.Status, recipient.UpdatedAt).Scan(&recipient.ID)
+		if err != nil {
+			return fmt.Errorf("failed to store recipient %s: %w", recipient.Phone, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCampaign retrieves a campaign by ID, nil when it does not exist
+func (r *PostgresCampaignRepository) GetCampaign(ctx context.Context, id string) (*domainCampaign.Campaign, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = $1", id)
+	campaign, err := scanCampaign(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return campaign, err
+}
+
+// GetCampaigns retrieves campaigns with filtering, newest first
+func (r *PostgresCampaignRepository) GetCampaigns(ctx context.Context, filter *domainCampaign.CampaignFilter) ([]*domainCampaign.Campaign, error) {
+	where, args := postgresCampaignConditions(filter, 1)
+	query := "SELECT " + campaignColumns + " FROM campaigns" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaigns(rows)
+}
+
+// CountCampaigns returns the number of campaigns matching the filter
+func (r *PostgresCampaignRepository) CountCampaigns(ctx context.Context, filter *domainCampaign.CampaignFilter) (int64, error) {
+	where, args := postgresCampaignConditions(filter, 1)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM campaigns"+where, args...).Scan(&count)
+	return count, err
+}
+
+// SetCampaignStatus changes the status of a campaign that still has one of the from statuses. Starting a campaign
+// records when it first started, finishing it when it finished.
+func (r *PostgresCampaignRepository) SetCampaignStatus(ctx context.Context, id, status string, from ...string) (bool, error) {
+	now := time.Now().UTC()
+	startedAt, completedAt := campaignStatusTimes(status, now)
+
+	args := []any{status, now, startedAt, completedAt, id}
+	for _, status := range from {
+		args = append(args, status)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaigns
+		SET status = $1, updated_at = $2, started_at = COALESCE(started_at, $3::TIMESTAMP), completed_at = $4::TIMESTAMP
+		WHERE id = $5 AND status IN (`+postgresPlaceholders(6, len(from))+`)
+	`, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetRecipients retrieves the recipients of a campaign in the order they are sent to
+func (r *PostgresCampaignRepository) GetRecipients(ctx context.Context, campaignID string, filter *domainCampaign.RecipientFilter) ([]*domainCampaign.Recipient, error) {
+	where, args := postgresRecipientConditions(campaignID, filter)
+	query := "SELECT " + campaignRecipientColumns + " FROM campaign_recipients" + where + " ORDER BY id ASC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaignRecipients(rows)
+}
+
+// CountRecipients returns the number of recipients of a campaign matching the filter
+func (r *PostgresCampaignRepository) CountRecipients(ctx context.Context, campaignID string, filter *domainCampaign.RecipientFilter) (int64, error) {
+	where, args := postgresRecipientConditions(campaignID, filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM campaign_recipients"+where, args...).Scan(&count)
+	return count, err
+}
+
+// CountRecipientsByStatus returns the number of recipients of a campaign per status
+func (r *PostgresCampaignRepository) CountRecipientsByStatus(ctx context.Context, campaignID string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM campaign_recipients WHERE campaign_id = $1 GROUP BY status", campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRecipientStatusCounts(rows)
+}
+
+// NextPendingRecipient returns the first recipient of a campaign that was not sent to yet, nil when there is none
+func (r *PostgresCampaignRepository) NextPendingRecipient(ctx context.Context, campaignID string) (*domainCampaign.Recipient, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+campaignRecipientColumns+`
+		FROM campaign_recipients
+		WHERE campaign_id = $1 AND status = $2
+		ORDER BY id ASC
+		LIMIT 1
+	`, campaignID, domainCampaign.RecipientPending)
+	recipient, err := scanCampaignRecipient(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return recipient, err
+}
+
+// UpdateRecipient stores the send state of a recipient that still has the from status
+func (r *PostgresCampaignRepository) UpdateRecipient(ctx context.Context, recipient *domainCampaign.Recipient, from string) (bool, error) {
+	recipient.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients
+		SET status = $1, message_id = $2, job_id = $3, error = $4, sent_at = $5, updated_at = $6
+		WHERE id = $7 AND status = $8
+	`, recipient.Status, recipient.MessageID, recipient.JobID, recipient.Error, utcOrNil(recipient.SentAt), recipient.UpdatedAt,
+		recipient.ID, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetQueuedRecipients returns the recipients waiting for the send queue, the longest waiting first
+func (r *PostgresCampaignRepository) GetQueuedRecipients(ctx context.Context, limit int) ([]*domainCampaign.Recipient, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+campaignRecipientColumns+`
+		FROM campaign_recipients
+		WHERE status = $1
+		ORDER BY updated_at ASC, id ASC
+		LIMIT $2
+	`, domainCampaign.RecipientQueued, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaignRecipients(rows)
+}
+
+// FailSendingRecipients fails the recipients a previous run left in the sending state
+func (r *PostgresCampaignRepository) FailSendingRecipients(ctx context.Context, reason string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE campaign_recipients SET status = $1, error = $2, updated_at = $3 WHERE status = $4",
+		domainCampaign.RecipientFailed, reason, time.Now().UTC(), domainCampaign.RecipientSending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SkipPendingRecipients skips the recipients of a campaign that were not sent to yet
+func (r *PostgresCampaignRepository) SkipPendingRecipients(ctx context.Context, campaignID string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE campaign_recipients SET status = $1, updated_at = $2 WHERE campaign_id = $3 AND status = $4",
+		domainCampaign.RecipientSkipped, time.Now().UTC(), campaignID, domainCampaign.RecipientPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RecordReceipt moves the recipients of the messages forward to delivered or read, a receipt never moves one back
+func (r *PostgresCampaignRepository) RecordReceipt(ctx context.Context, messageIDs []string, status string, at time.Time) (int64, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	from := []string{domainCampaign.RecipientQueued, domainCampaign.RecipientSent}
+	readAt := any(nil)
+	if status == domainCampaign.RecipientRead {
+		from = append(from, domainCampaign.RecipientDelivered)
+		readAt = at.UTC()
+	}
+
+	args := []any{status, at.UTC(), readAt, time.Now().UTC()}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+	for _, status := range from {
+		args = append(args, status)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients
+		SET status = $1, sent_at = COALESCE(sent_at, $2), delivered_at = COALESCE(delivered_at, $2), read_at = $3::TIMESTAMP, updated_at = $4
+		WHERE message_id IN (`+postgresPlaceholders(5, len(messageIDs))+`)
+			AND status IN (`+postgresPlaceholders(5+len(messageIDs), len(from))+`)
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// postgresCampaignConditions builds the WHERE clause for a campaign filter, numbering the placeholders from start
+func postgresCampaignConditions(filter *domainCampaign.CampaignFilter, start int) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", start+len(args)-1))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", start+len(args)-1))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// postgresRecipientConditions builds the WHERE clause for the recipients of a campaign
+func postgresRecipientConditions(campaignID string, filter *domainCampaign.RecipientFilter) (string, []any) {
+	where, args := " WHERE campaign_id = $1", []any{campaignID}
+	if filter != nil && filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	return where, args
+}
+
+// postgresPlaceholders returns n comma separated placeholders for an IN list, numbered from start
+func postgresPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
@@ -0,0 +1,52 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+)
+
+// PostgresEventStreamRepository keeps the streamed events in the Postgres chat storage
+type PostgresEventStreamRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEventStreamRepository creates an event log repository. The table is created by the chat storage migrations.
+func NewPostgresEventStreamRepository(db *sql.DB) domainEventStream.IEventStreamRepository {
+	return &PostgresEventStreamRepository{db: db}
+}
+
+// StoreEvent appends an event to the event log and sets its ID
+func (r *PostgresEventStreamRepository) StoreEvent(ctx context.Context, event *domainEventStream.Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO event_log (device_id, type, event, chat_jid, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, event.DeviceID, event.Type, event.Name, event.ChatJID, string(event.Payload), event.CreatedAt.UTC()).Scan(&event.ID)
+}
+
+// GetEventsAfter returns the oldest events with an ID above the given one, in order
+func (r *PostgresEventStreamRepository) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*domainEventStream.Event, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+eventLogColumns+" FROM event_log WHERE id > $1 ORDER BY id LIMIT $2", afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// PurgeEventsBefore removes events created before the given time
+func (r *PostgresEventStreamRepository) PurgeEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM event_log WHERE created_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
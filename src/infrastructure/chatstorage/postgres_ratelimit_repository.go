@@ -0,0 +1,113 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+)
+
+// PostgresRateLimitRepository persists the rate limit buckets and send quota usage in the Postgres chat storage
+type PostgresRateLimitRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRateLimitRepository creates a rate limit repository. The tables are created by the chat storage migrations.
+func NewPostgresRateLimitRepository(db *sql.DB) domainRateLimit.IRateLimitRepository {
+	return &PostgresRateLimitRepository{db: db}
+}
+
+// GetBucket returns the bucket or nil when it has not been used yet
+func (r *PostgresRateLimitRepository) GetBucket(ctx context.Context, subject, class string) (*domainRateLimit.Bucket, error) {
+	bucket := &domainRateLimit.Bucket{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT subject, class, tokens, updated_at FROM rate_limit_buckets WHERE subject = $1 AND class = $2
+	`, subject, class).Scan(&bucket.Subject, &bucket.Class, &bucket.Tokens, &bucket.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// GetBuckets returns the buckets of a subject, or of every user and API key when subject is empty
+func (r *PostgresRateLimitRepository) GetBuckets(ctx context.Context, subject string) ([]*domainRateLimit.Bucket, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subject, class, tokens, updated_at FROM rate_limit_buckets
+		WHERE ($1::text = '' AND subject NOT LIKE 'recipient:%') OR subject = $2
+		ORDER BY subject, class
+	`, subject, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRateLimitBuckets(rows)
+}
+
+// SaveBucket inserts or updates a bucket
+func (r *PostgresRateLimitRepository) SaveBucket(ctx context.Context, bucket *domainRateLimit.Bucket) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (subject, class, tokens, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT(subject, class) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at
+	`, bucket.Subject, bucket.Class, bucket.Tokens, bucket.UpdatedAt.UTC())
+	return err
+}
+
+// PurgeBucketsBefore removes buckets that were not used since the given time
+func (r *PostgresRateLimitRepository) PurgeBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE updated_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetUsage returns the sends of a subject in a period
+func (r *PostgresRateLimitRepository) GetUsage(ctx context.Context, subject, period string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT count FROM send_quota_usage WHERE subject = $1 AND period = $2", subject, period).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// GetUsages returns the usage in the period of a subject, or of every subject when subject is empty
+func (r *PostgresRateLimitRepository) GetUsages(ctx context.Context, subject, period string) ([]*domainRateLimit.Usage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subject, period, count, updated_at FROM send_quota_usage
+		WHERE period = $1 AND ($2::text = '' OR subject = $3)
+		ORDER BY subject
+	`, period, subject, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendQuotaUsages(rows)
+}
+
+// IncrementUsage counts a send of a subject in a period
+func (r *PostgresRateLimitRepository) IncrementUsage(ctx context.Context, subject, period string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO send_quota_usage (subject, period, count, updated_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT(subject, period) DO UPDATE SET count = send_quota_usage.count + 1, updated_at = excluded.updated_at
+	`, subject, period, now.UTC())
+	return err
+}
+
+// PurgeUsageBefore removes usage that was not counted since the given time
+func (r *PostgresRateLimitRepository) PurgeUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM send_quota_usage WHERE updated_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
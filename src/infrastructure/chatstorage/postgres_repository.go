@@ -3,6 +3,7 @@ package chatstorage
 import (
     "context"
     "database/sql"
+    "encoding/json"
     "fmt"
     "strings"
     "time"
@@ -44,8 +45,23 @@ func (r *PostgresRepository) GetChat(jid string) (*domainChatStorage.Chat, error
     return r.scanChat(row)
 }
 
-// DeleteChat removes a chat (messages will be removed via FK constraints if set)
+// DeleteChat removes a chat (messages will be removed via FK constraints if set) with its receipts, reactions, edit history and polls
 func (r *PostgresRepository) DeleteChat(jid string) error {
+    if _, err := r.db.Exec(`DELETE FROM message_receipts WHERE chat_jid = $1`, jid); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM message_reactions WHERE chat_jid = $1`, jid); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM message_edits WHERE chat_jid = $1`, jid); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM poll_votes WHERE chat_jid = $1`, jid); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM polls WHERE chat_jid = $1`, jid); err != nil {
+        return err
+    }
     _, err := r.db.Exec(`DELETE FROM chats WHERE jid = $1`, jid)
     return err
 }
@@ -181,7 +197,7 @@ func (r *PostgresRepository) GetMessageByID(id string) (*domainChatStorage.Messa
     row := r.db.QueryRow(`
         SELECT id, chat_jid, sender, content, timestamp, is_from_me,
                media_type, filename, url, media_key, file_sha256,
-               file_enc_sha256, file_length, created_at, updated_at
+               file_enc_sha256, file_length, created_at, updated_at, edited_at, revoked_at
         FROM messages WHERE id = $1
         ORDER BY timestamp DESC LIMIT 1
     `, id)
@@ -189,7 +205,7 @@ func (r *PostgresRepository) GetMessageByID(id string) (*domainChatStorage.Messa
 }
 
 func (r *PostgresRepository) GetMessages(filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
-    base := `SELECT id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at FROM messages`
+    base := `SELECT id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at, edited_at, revoked_at FROM messages`
     var where []string
     var args []any
     if filter != nil {
@@ -241,7 +257,7 @@ func (r *PostgresRepository) GetMessages(filter *domainChatStorage.MessageFilter
 
 func (r *PostgresRepository) SearchMessages(chatJID, searchText string, limit int) ([]*domainChatStorage.Message, error) {
     rows, err := r.db.Query(`
-        SELECT id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at
+        SELECT id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at, edited_at, revoked_at
         FROM messages
         WHERE chat_jid = $1 AND content ILIKE $2
         ORDER BY timestamp DESC
@@ -264,10 +280,348 @@ func (r *PostgresRepository) SearchMessages(chatJID, searchText string, limit in
 }
 
 func (r *PostgresRepository) DeleteMessage(id, chatJID string) error {
+    if _, err := r.db.Exec(`DELETE FROM message_receipts WHERE message_id = $1 AND chat_jid = $2`, id, chatJID); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM message_reactions WHERE message_id = $1 AND chat_jid = $2`, id, chatJID); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM message_edits WHERE message_id = $1 AND chat_jid = $2`, id, chatJID); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM poll_votes WHERE poll_id = $1 AND chat_jid = $2`, id, chatJID); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM polls WHERE message_id = $1 AND chat_jid = $2`, id, chatJID); err != nil {
+        return err
+    }
     _, err := r.db.Exec(`DELETE FROM messages WHERE id = $1 AND chat_jid = $2`, id, chatJID)
     return err
 }
 
+// EditMessage replaces the content of a message and keeps the previous content in the edit history. Edits older
+// than the last one applied, and edits of messages that are not stored, are ignored.
+func (r *PostgresRepository) EditMessage(id, chatJID, content string, editedAt time.Time) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var previous string
+    var lastEditedAt sql.NullTime
+    err = tx.QueryRow(
+        `SELECT COALESCE(content, ''), edited_at FROM messages WHERE id = $1 AND chat_jid = $2 FOR UPDATE`, id, chatJID,
+    ).Scan(&previous, &lastEditedAt)
+    if err == sql.ErrNoRows {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if lastEditedAt.Valid && !editedAt.After(lastEditedAt.Time) {
+        return nil
+    }
+
+    if _, err = tx.Exec(`
+        INSERT INTO message_edits (message_id, chat_jid, content, edited_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (message_id, chat_jid, edited_at) DO NOTHING
+    `, id, chatJID, previous, editedAt.UTC()); err != nil {
+        return err
+    }
+    if _, err = tx.Exec(
+        `UPDATE messages SET content = $1, edited_at = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 AND chat_jid = $4`,
+        content, editedAt.UTC(), id, chatJID,
+    ); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// RevokeMessage marks a message as deleted for everyone, keeping the time it was first revoked
+func (r *PostgresRepository) RevokeMessage(id, chatJID string, revokedAt time.Time) error {
+    _, err := r.db.Exec(
+        `UPDATE messages SET revoked_at = COALESCE(revoked_at, $1), updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND chat_jid = $3`,
+        revokedAt.UTC(), id, chatJID,
+    )
+    return err
+}
+
+// GetMessageEdits returns the edit history of the messages of a chat, oldest first
+func (r *PostgresRepository) GetMessageEdits(chatJID string, messageIDs ...string) ([]*domainChatStorage.MessageEdit, error) {
+    if len(messageIDs) == 0 {
+        return nil, nil
+    }
+
+    args := []any{chatJID}
+    for _, id := range messageIDs {
+        args = append(args, id)
+    }
+
+    rows, err := r.db.Query(`
+        SELECT message_id, chat_jid, content, edited_at
+        FROM message_edits
+        WHERE chat_jid = $1 AND message_id IN (`+postgresPlaceholders(2, len(messageIDs))+`)
+        ORDER BY edited_at
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var out []*domainChatStorage.MessageEdit
+    for rows.Next() {
+        var e domainChatStorage.MessageEdit
+        if err := rows.Scan(&e.MessageID, &e.ChatJID, &e.Content, &e.EditedAt); err != nil {
+            return nil, err
+        }
+        out = append(out, &e)
+    }
+    return out, rows.Err()
+}
+
+// StoreReceipts records the receipts, keeping the first time a recipient reached every state
+func (r *PostgresRepository) StoreReceipts(receipts []*domainChatStorage.Receipt) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.Prepare(`
+        INSERT INTO message_receipts (message_id, chat_jid, participant, delivered_at, read_at, played_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (message_id, chat_jid, participant) DO UPDATE SET
+            delivered_at = COALESCE(message_receipts.delivered_at, EXCLUDED.delivered_at),
+            read_at = COALESCE(message_receipts.read_at, EXCLUDED.read_at),
+            played_at = COALESCE(message_receipts.played_at, EXCLUDED.played_at)
+    `)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+
+    for _, rc := range receipts {
+        if _, err := stmt.Exec(
+            rc.MessageID, rc.ChatJID, rc.Participant, utcOrNil(rc.DeliveredAt), utcOrNil(rc.ReadAt), utcOrNil(rc.PlayedAt),
+        ); err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// GetReceipts returns the receipts of the messages of a chat
+func (r *PostgresRepository) GetReceipts(chatJID string, messageIDs ...string) ([]*domainChatStorage.Receipt, error) {
+    if len(messageIDs) == 0 {
+        return nil, nil
+    }
+
+    args := []any{chatJID}
+    for _, id := range messageIDs {
+        args = append(args, id)
+    }
+
+    rows, err := r.db.Query(`
+        SELECT message_id, chat_jid, participant, delivered_at, read_at, played_at
+        FROM message_receipts
+        WHERE chat_jid = $1 AND message_id IN (`+postgresPlaceholders(2, len(messageIDs))+`)
+        ORDER BY message_id, participant
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var out []*domainChatStorage.Receipt
+    for rows.Next() {
+        rc, err := scanReceipt(rows)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, rc)
+    }
+    return out, rows.Err()
+}
+
+// StoreReactions adds, changes or removes reactions, an older reaction of a sender never replaces a newer one
+func (r *PostgresRepository) StoreReactions(reactions []*domainChatStorage.Reaction) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.Prepare(`
+        INSERT INTO message_reactions (message_id, chat_jid, sender, emoji, is_from_me, timestamp)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (message_id, chat_jid, sender) DO UPDATE SET
+            emoji = EXCLUDED.emoji,
+            timestamp = EXCLUDED.timestamp
+        WHERE EXCLUDED.timestamp >= message_reactions.timestamp
+    `)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+
+    for _, rc := range reactions {
+        if _, err := stmt.Exec(rc.MessageID, rc.ChatJID, rc.Sender, rc.Emoji, rc.IsFromMe, rc.Timestamp.UTC()); err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// GetReactions returns the current reactions to the messages of a chat, oldest first
+func (r *PostgresRepository) GetReactions(chatJID string, messageIDs ...string) ([]*domainChatStorage.Reaction, error) {
+    if len(messageIDs) == 0 {
+        return nil, nil
+    }
+
+    args := []any{chatJID}
+    for _, id := range messageIDs {
+        args = append(args, id)
+    }
+
+    rows, err := r.db.Query(`
+        SELECT message_id, chat_jid, sender, emoji, is_from_me, timestamp
+        FROM message_reactions
+        WHERE chat_jid = $1 AND message_id IN (`+postgresPlaceholders(2, len(messageIDs))+`) AND emoji <> ''
+        ORDER BY timestamp, sender
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var out []*domainChatStorage.Reaction
+    for rows.Next() {
+        var rc domainChatStorage.Reaction
+        if err := rows.Scan(&rc.MessageID, &rc.ChatJID, &rc.Sender, &rc.Emoji, &rc.IsFromMe, &rc.Timestamp); err != nil {
+            return nil, err
+        }
+        out = append(out, &rc)
+    }
+    return out, rows.Err()
+}
+
+// StorePoll creates or updates a poll
+func (r *PostgresRepository) StorePoll(poll *domainChatStorage.Poll) error {
+    options, err := json.Marshal(poll.Options)
+    if err != nil {
+        return err
+    }
+
+    _, err = r.db.Exec(`
+        INSERT INTO polls (message_id, chat_jid, sender, question, options, selectable_count, secret, timestamp)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (message_id, chat_jid) DO UPDATE SET
+            sender = EXCLUDED.sender,
+            question = EXCLUDED.question,
+            options = EXCLUDED.options,
+            selectable_count = EXCLUDED.selectable_count,
+            secret = COALESCE(EXCLUDED.secret, polls.secret)
+    `, poll.MessageID, poll.ChatJID, poll.Sender, poll.Question, string(options), poll.SelectableCount, poll.Secret, poll.Timestamp.UTC())
+    return err
+}
+
+// GetPoll returns a poll, nil when it is not stored
+func (r *PostgresRepository) GetPoll(chatJID, messageID string) (*domainChatStorage.Poll, error) {
+    row := r.db.QueryRow(`
+        SELECT message_id, chat_jid, sender, question, options, selectable_count, secret, timestamp
+        FROM polls
+        WHERE chat_jid = $1 AND message_id = $2
+    `, chatJID, messageID)
+
+    poll, err := scanPoll(row)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    return poll, err
+}
+
+// StoreSessionToken keeps the token of the session of a paired device, so it survives restarts
+func (r *PostgresRepository) StoreSessionToken(deviceJID, token string) error {
+    _, err := r.db.Exec(`
+        INSERT INTO device_sessions (device_jid, token, created_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (device_jid) DO UPDATE SET token = EXCLUDED.token
+    `, deviceJID, token, time.Now().UTC())
+    return err
+}
+
+// GetSessionTokens returns the tokens of the sessions by device JID
+func (r *PostgresRepository) GetSessionTokens() (map[string]string, error) {
+    rows, err := r.db.Query(`SELECT device_jid, token FROM device_sessions`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    tokens := make(map[string]string)
+    for rows.Next() {
+        var deviceJID, token string
+        if err := rows.Scan(&deviceJID, &token); err != nil {
+            return nil, err
+        }
+        tokens[deviceJID] = token
+    }
+    return tokens, rows.Err()
+}
+
+// DeleteSessionToken forgets a removed session
+func (r *PostgresRepository) DeleteSessionToken(token string) error {
+    _, err := r.db.Exec(`DELETE FROM device_sessions WHERE token = $1`, token)
+    return err
+}
+
+// StorePollVote replaces the vote of the voter, an older vote never replaces a newer one
+func (r *PostgresRepository) StorePollVote(vote *domainChatStorage.PollVote) error {
+    options, err := json.Marshal(vote.Options)
+    if err != nil {
+        return err
+    }
+
+    _, err = r.db.Exec(`
+        INSERT INTO poll_votes (poll_id, chat_jid, voter, options, timestamp)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (poll_id, chat_jid, voter) DO UPDATE SET
+            options = EXCLUDED.options,
+            timestamp = EXCLUDED.timestamp
+        WHERE EXCLUDED.timestamp >= poll_votes.timestamp
+    `, vote.PollID, vote.ChatJID, vote.Voter, string(options), vote.Timestamp.UTC())
+    return err
+}
+
+// GetPollVotes returns the latest vote of every voter on a poll, oldest first
+func (r *PostgresRepository) GetPollVotes(chatJID, pollID string) ([]*domainChatStorage.PollVote, error) {
+    rows, err := r.db.Query(`
+        SELECT poll_id, chat_jid, voter, options, timestamp
+        FROM poll_votes
+        WHERE chat_jid = $1 AND poll_id = $2
+        ORDER BY timestamp, voter
+    `, chatJID, pollID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var out []*domainChatStorage.PollVote
+    for rows.Next() {
+        vote, err := scanPollVote(rows)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, vote)
+    }
+    return out, rows.Err()
+}
+
 func (r *PostgresRepository) GetChatMessageCount(chatJID string) (int64, error) {
     return r.getCount(`SELECT COUNT(*) FROM messages WHERE chat_jid = $1`, chatJID)
 }
@@ -281,7 +635,7 @@ func (r *PostgresRepository) GetTotalChatCount() (int64, error) {
 }
 
 func (r *PostgresRepository) TruncateAllChats() error {
-    _, err := r.db.Exec(`TRUNCATE TABLE messages, chats RESTART IDENTITY CASCADE`)
+    _, err := r.db.Exec(`TRUNCATE TABLE poll_votes, polls, message_edits, message_reactions, message_receipts, messages, chats RESTART IDENTITY CASCADE`)
     return err
 }
 
@@ -314,6 +668,16 @@ func (r *PostgresRepository) CreateMessage(ctx context.Context, evt *events.Mess
         return nil
     }
 
+    // Reactions are kept apart from the messages they react to
+    if reaction := reactionFromEvent(evt); reaction != nil {
+        return r.StoreReactions([]*domainChatStorage.Reaction{reaction})
+    }
+
+    // Edits and revokes change the message they are about
+    if handled, err := applyProtocolMessage(r, evt); handled {
+        return err
+    }
+
     chatJID := evt.Info.Chat.String()
     sender := evt.Info.Sender.String()
     chatName := r.GetChatNameWithPushName(evt.Info.Chat, chatJID, evt.Info.Sender.User, evt.Info.PushName)
@@ -476,6 +840,295 @@ func (r *PostgresRepository) getMigrations() []string {
         );
         CREATE INDEX IF NOT EXISTS idx_app_users_enabled ON app_users(enabled);
         `,
+        `
+        CREATE TABLE IF NOT EXISTS webhook_deliveries (
+            id BIGSERIAL PRIMARY KEY,
+            url TEXT NOT NULL,
+            event TEXT NOT NULL,
+            payload TEXT NOT NULL,
+            status TEXT NOT NULL DEFAULT 'pending',
+            attempts INTEGER DEFAULT 0,
+            last_error TEXT DEFAULT '',
+            next_attempt_at TIMESTAMP NOT NULL,
+            retry_until TIMESTAMP NOT NULL,
+            delivered_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries(created_at);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS webhook_endpoints (
+            id TEXT PRIMARY KEY,
+            url TEXT NOT NULL,
+            secret TEXT NOT NULL DEFAULT '',
+            events TEXT NOT NULL DEFAULT '[]',
+            allow_chats TEXT NOT NULL DEFAULT '[]',
+            deny_chats TEXT NOT NULL DEFAULT '[]',
+            enabled BOOLEAN DEFAULT TRUE,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        );
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS endpoint_id TEXT NOT NULL DEFAULT '';
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id);
+        `,
+        `
+        ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS previous_secret TEXT NOT NULL DEFAULT '';
+        ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS previous_secret_expires_at TIMESTAMP;
+        `,
+        `
+        ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS payload_version INTEGER NOT NULL DEFAULT 1;
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS app_api_keys (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            username TEXT NOT NULL,
+            key_hash TEXT NOT NULL UNIQUE,
+            prefix TEXT NOT NULL,
+            scopes TEXT NOT NULL DEFAULT '[]',
+            expires_at TIMESTAMP,
+            last_used_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_app_api_keys_username ON app_api_keys(username);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS audit_log (
+            id BIGSERIAL PRIMARY KEY,
+            actor TEXT NOT NULL DEFAULT '',
+            api_key_id TEXT NOT NULL DEFAULT '',
+            source TEXT NOT NULL,
+            action TEXT NOT NULL,
+            device_id TEXT NOT NULL DEFAULT '',
+            target TEXT NOT NULL DEFAULT '',
+            summary TEXT NOT NULL DEFAULT '',
+            result TEXT NOT NULL,
+            error TEXT NOT NULL DEFAULT '',
+            message_id TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP NOT NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+        CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, created_at);
+        CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target, created_at);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+            subject TEXT NOT NULL,
+            class TEXT NOT NULL,
+            tokens DOUBLE PRECISION NOT NULL,
+            updated_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (subject, class)
+        );
+        CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
+        CREATE TABLE IF NOT EXISTS send_quota_usage (
+            subject TEXT NOT NULL,
+            period TEXT NOT NULL,
+            count BIGINT NOT NULL DEFAULT 0,
+            updated_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (subject, period)
+        );
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS send_jobs (
+            id TEXT PRIMARY KEY,
+            device_id TEXT NOT NULL DEFAULT '',
+            actor TEXT NOT NULL DEFAULT '',
+            idempotency_key TEXT,
+            recipient TEXT NOT NULL,
+            message BYTEA NOT NULL,
+            content TEXT NOT NULL DEFAULT '',
+            message_id TEXT NOT NULL,
+            status TEXT NOT NULL DEFAULT 'queued',
+            attempts INTEGER NOT NULL DEFAULT 0,
+            last_error TEXT,
+            next_attempt_at TIMESTAMP NOT NULL,
+            sent_at TIMESTAMP,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        );
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_send_jobs_idempotency ON send_jobs(device_id, actor, idempotency_key);
+        CREATE INDEX IF NOT EXISTS idx_send_jobs_due ON send_jobs(status, next_attempt_at);
+        CREATE INDEX IF NOT EXISTS idx_send_jobs_created ON send_jobs(created_at);
+        `,
+        `
+        ALTER TABLE send_jobs ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+        CREATE INDEX IF NOT EXISTS idx_send_jobs_scheduled ON send_jobs(scheduled_at);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS campaigns (
+            id TEXT PRIMARY KEY,
+            device_id TEXT NOT NULL DEFAULT '',
+            actor TEXT NOT NULL DEFAULT '',
+            name TEXT NOT NULL,
+            message TEXT NOT NULL DEFAULT '',
+            media_type TEXT NOT NULL DEFAULT '',
+            media_url TEXT NOT NULL DEFAULT '',
+            rate TEXT NOT NULL DEFAULT '',
+            status TEXT NOT NULL DEFAULT 'draft',
+            recipients INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL,
+            started_at TIMESTAMP,
+            completed_at TIMESTAMP
+        );
+        CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status);
+        CREATE INDEX IF NOT EXISTS idx_campaigns_created ON campaigns(created_at);
+        CREATE TABLE IF NOT EXISTS campaign_recipients (
+            id BIGSERIAL PRIMARY KEY,
+            campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+            phone TEXT NOT NULL,
+            variables TEXT NOT NULL DEFAULT '{}',
+            status TEXT NOT NULL DEFAULT 'pending',
+            message_id TEXT NOT NULL DEFAULT '',
+            job_id TEXT NOT NULL DEFAULT '',
+            error TEXT NOT NULL DEFAULT '',
+            sent_at TIMESTAMP,
+            delivered_at TIMESTAMP,
+            read_at TIMESTAMP,
+            updated_at TIMESTAMP NOT NULL,
+            UNIQUE (campaign_id, phone)
+        );
+        CREATE INDEX IF NOT EXISTS idx_campaign_recipients_status ON campaign_recipients(campaign_id, status);
+        CREATE INDEX IF NOT EXISTS idx_campaign_recipients_message ON campaign_recipients(message_id);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS message_templates (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL UNIQUE,
+            description TEXT NOT NULL DEFAULT '',
+            body TEXT NOT NULL DEFAULT '',
+            media_type TEXT NOT NULL DEFAULT '',
+            media_url TEXT NOT NULL DEFAULT '',
+            defaults TEXT NOT NULL DEFAULT '{}',
+            version INTEGER NOT NULL DEFAULT 1,
+            actor TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS message_template_versions (
+            template_id TEXT NOT NULL REFERENCES message_templates(id) ON DELETE CASCADE,
+            version INTEGER NOT NULL,
+            body TEXT NOT NULL DEFAULT '',
+            media_type TEXT NOT NULL DEFAULT '',
+            media_url TEXT NOT NULL DEFAULT '',
+            defaults TEXT NOT NULL DEFAULT '{}',
+            actor TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (template_id, version)
+        );
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS auto_reply_rules (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            enabled BOOLEAN NOT NULL DEFAULT TRUE,
+            priority INTEGER NOT NULL DEFAULT 0,
+            device_id TEXT NOT NULL DEFAULT '',
+            conditions TEXT NOT NULL DEFAULT '{}',
+            actions TEXT NOT NULL DEFAULT '[]',
+            cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+            continue_matching BOOLEAN NOT NULL DEFAULT FALSE,
+            actor TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL
+        );
+        `,
+        `
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS origin_device TEXT NOT NULL DEFAULT '';
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS origin_chat_jid TEXT NOT NULL DEFAULT '';
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS origin_sender_jid TEXT NOT NULL DEFAULT '';
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS origin_message_id TEXT NOT NULL DEFAULT '';
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS event_log (
+            id BIGSERIAL PRIMARY KEY,
+            device_id TEXT NOT NULL DEFAULT '',
+            type TEXT NOT NULL,
+            event TEXT NOT NULL,
+            chat_jid TEXT NOT NULL DEFAULT '',
+            payload TEXT NOT NULL,
+            created_at TIMESTAMP NOT NULL
+        );
+        CREATE INDEX IF NOT EXISTS idx_event_log_created ON event_log(created_at);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS message_receipts (
+            message_id TEXT NOT NULL,
+            chat_jid TEXT NOT NULL,
+            participant TEXT NOT NULL,
+            delivered_at TIMESTAMP,
+            read_at TIMESTAMP,
+            played_at TIMESTAMP,
+            PRIMARY KEY (message_id, chat_jid, participant)
+        );
+        CREATE INDEX IF NOT EXISTS idx_message_receipts_chat ON message_receipts(chat_jid);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS message_reactions (
+            message_id TEXT NOT NULL,
+            chat_jid TEXT NOT NULL,
+            sender TEXT NOT NULL,
+            emoji TEXT NOT NULL DEFAULT '',
+            is_from_me BOOLEAN NOT NULL DEFAULT FALSE,
+            timestamp TIMESTAMP NOT NULL,
+            PRIMARY KEY (message_id, chat_jid, sender)
+        );
+        CREATE INDEX IF NOT EXISTS idx_message_reactions_chat ON message_reactions(chat_jid);
+        `,
+        `
+        ALTER TABLE messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+        ALTER TABLE messages ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+        CREATE TABLE IF NOT EXISTS message_edits (
+            message_id TEXT NOT NULL,
+            chat_jid TEXT NOT NULL,
+            content TEXT NOT NULL DEFAULT '',
+            edited_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (message_id, chat_jid, edited_at)
+        );
+        CREATE INDEX IF NOT EXISTS idx_message_edits_chat ON message_edits(chat_jid);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS polls (
+            message_id TEXT NOT NULL,
+            chat_jid TEXT NOT NULL,
+            sender TEXT NOT NULL,
+            question TEXT NOT NULL DEFAULT '',
+            options TEXT NOT NULL DEFAULT '[]',
+            selectable_count INTEGER NOT NULL DEFAULT 0,
+            secret BYTEA,
+            timestamp TIMESTAMP NOT NULL,
+            PRIMARY KEY (message_id, chat_jid)
+        );
+        CREATE TABLE IF NOT EXISTS poll_votes (
+            poll_id TEXT NOT NULL,
+            chat_jid TEXT NOT NULL,
+            voter TEXT NOT NULL,
+            options TEXT NOT NULL DEFAULT '[]',
+            timestamp TIMESTAMP NOT NULL,
+            PRIMARY KEY (poll_id, chat_jid, voter)
+        );
+        CREATE INDEX IF NOT EXISTS idx_poll_votes_chat ON poll_votes(chat_jid);
+        `,
+        `
+        CREATE TABLE IF NOT EXISTS device_sessions (
+            device_jid TEXT PRIMARY KEY,
+            token TEXT NOT NULL,
+            created_at TIMESTAMP NOT NULL
+        );
+        `,
+        `
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
+        `,
+        `
+        ALTER TABLE message_templates ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+        ALTER TABLE message_templates DROP CONSTRAINT IF EXISTS message_templates_name_key;
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_name ON message_templates(name) WHERE deleted_at IS NULL;
+        `,
+        `
+        ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS response_actions INTEGER NOT NULL DEFAULT 0;
+        `,
     }
 }
 
@@ -488,14 +1141,18 @@ func (r *PostgresRepository) getCount(query string, args ...any) (int64, error)
 func (r *PostgresRepository) scanMessage(scanner interface{ Scan(...any) error }) (*domainChatStorage.Message, error) {
     var m domainChatStorage.Message
     var mediaKey, fileSha, fileEncSha []byte
+    var editedAt, revokedAt sql.NullTime
     err := scanner.Scan(
         &m.ID, &m.ChatJID, &m.Sender, &m.Content, &m.Timestamp, &m.IsFromMe,
         &m.MediaType, &m.Filename, &m.URL, &mediaKey, &fileSha, &fileEncSha, &m.FileLength, &m.CreatedAt, &m.UpdatedAt,
+        &editedAt, &revokedAt,
     )
     if err != nil { return nil, err }
     m.MediaKey = mediaKey
     m.FileSHA256 = fileSha
     m.FileEncSHA256 = fileEncSha
+    if editedAt.Valid { m.EditedAt = &editedAt.Time }
+    if revokedAt.Valid { m.RevokedAt = &revokedAt.Time }
     return &m, nil
 }
 
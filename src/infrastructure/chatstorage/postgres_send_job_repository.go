@@ -0,0 +1,246 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+)
+
+// PostgresSendJobRepository stores the send queue in the PostgreSQL chat storage
+type PostgresSendJobRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSendJobRepository creates a send queue repository. The table is created by the chat storage migrations.
+func NewPostgresSendJobRepository(db *sql.DB) domainSend.ISendJobRepository {
+	return &PostgresSendJobRepository{db: db}
+}
+
+// EnqueueJob persists a new job, a duplicate idempotency key leaves the table unchanged
+func (r *PostgresSendJobRepository) EnqueueJob(ctx context.Context, job *domainSend.Job) (bool, error) {
+	now := time.Now().UTC()
+	if job.Status == "" {
+		job.Status = domainSend.JobStatusQueued
+	}
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = now
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO send_jobs (id, device_id, actor, idempotency_key, recipient, message, content, message_id, status, attempts, last_error, next_attempt_at, scheduled_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT DO NOTHING
+	`, job.ID, job.DeviceID, job.Actor, nullIfEmpty(job.IdempotencyKey), job.Recipient, job.Message, job.Content, job.MessageID,
+		job.Status, job.Attempts, job.LastError, job.NextAttemptAt.UTC(), utcOrNil(job.ScheduledAt), job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetJob retrieves a job by ID, nil when it does not exist
+func (r *PostgresSendJobRepository) GetJob(ctx context.Context, id string) (*domainSend.Job, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sendJobColumns+" FROM send_jobs WHERE id = $1", id)
+	return scanSendJobRow(row)
+}
+
+// GetJobByIdempotencyKey retrieves the job a device and actor enqueued with the key, nil when there is none
+func (r *PostgresSendJobRepository) GetJobByIdempotencyKey(ctx context.Context, deviceID, actor, key string) (*domainSend.Job, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sendJobColumns+" FROM send_jobs WHERE device_id = $1 AND actor = $2 AND idempotency_key = $3",
+		deviceID, actor, key)
+	return scanSendJobRow(row)
+}
+
+// GetJobs retrieves jobs with filtering, newest first
+func (r *PostgresSendJobRepository) GetJobs(ctx context.Context, filter *domainSend.JobFilter) ([]*domainSend.Job, error) {
+	where, args := postgresSendJobConditions(filter, 1)
+	query := "SELECT " + sendJobColumns + " FROM send_jobs" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// CountJobs returns the number of jobs matching the filter
+func (r *PostgresSendJobRepository) CountJobs(ctx context.Context, filter *domainSend.JobFilter) (int64, error) {
+	where, args := postgresSendJobConditions(filter, 1)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM send_jobs"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetDueJobs returns queued jobs that are due, oldest first
+func (r *PostgresSendJobRepository) GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*domainSend.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sendJobColumns+`
+		FROM send_jobs
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY created_at ASC, id ASC
+		LIMIT $3
+	`, domainSend.JobStatusQueued, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// MarkSending claims a queued job for the worker
+func (r *PostgresSendJobRepository) MarkSending(ctx context.Context, id string) (bool, error) {
+	return r.transition(ctx, id, domainSend.JobStatusQueued, domainSend.JobStatusSending)
+}
+
+// MarkSent records a sent job
+func (r *PostgresSendJobRepository) MarkSent(ctx context.Context, id string, attempts int, sentAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = $1, attempts = $2, last_error = '', sent_at = $3, updated_at = $4
+		WHERE id = $5
+	`, domainSend.JobStatusSent, attempts, sentAt.UTC(), time.Now().UTC(), id)
+	return err
+}
+
+// MarkRetry records a failed attempt and queues the job again
+func (r *PostgresSendJobRepository) MarkRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, updated_at = $5
+		WHERE id = $6
+	`, domainSend.JobStatusQueued, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), id)
+	return err
+}
+
+// MarkFailed records a job that is not sent again
+func (r *PostgresSendJobRepository) MarkFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = $1, attempts = $2, last_error = $3, updated_at = $4
+		WHERE id = $5
+	`, domainSend.JobStatusFailed, attempts, lastError, time.Now().UTC(), id)
+	return err
+}
+
+// FailSendingJobs fails the jobs a previous run left in the sending state
+func (r *PostgresSendJobRepository) FailSendingJobs(ctx context.Context, lastError string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = $1, last_error = $2, updated_at = $3
+		WHERE status = $4
+	`, domainSend.JobStatusFailed, lastError, time.Now().UTC(), domainSend.JobStatusSending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetMissedJobs returns the queued scheduled jobs due before the given time that were never attempted
+func (r *PostgresSendJobRepository) GetMissedJobs(ctx context.Context, before time.Time) ([]*domainSend.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sendJobColumns+`
+		FROM send_jobs
+		WHERE status = $1 AND scheduled_at IS NOT NULL AND scheduled_at < $2 AND attempts = 0
+		ORDER BY scheduled_at ASC, id ASC
+	`, domainSend.JobStatusQueued, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// CancelJob cancels a queued job
+func (r *PostgresSendJobRepository) CancelJob(ctx context.Context, id string) (bool, error) {
+	return r.transition(ctx, id, domainSend.JobStatusQueued, domainSend.JobStatusCancelled)
+}
+
+// RescheduleJob changes the send time of a queued job
+func (r *PostgresSendJobRepository) RescheduleJob(ctx context.Context, id string, sendAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET scheduled_at = $1, next_attempt_at = $1, updated_at = $2
+		WHERE id = $3 AND status = $4
+	`, sendAt.UTC(), time.Now().UTC(), id, domainSend.JobStatusQueued)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// PurgeFinishedJobsBefore removes sent, failed and cancelled jobs last updated before the given time
+func (r *PostgresSendJobRepository) PurgeFinishedJobsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM send_jobs WHERE status IN ($1, $2, $3) AND updated_at < $4",
+		domainSend.JobStatusSent, domainSend.JobStatusFailed, domainSend.JobStatusCancelled, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// transition changes the status of a job only when it still has the expected status
+func (r *PostgresSendJobRepository) transition(ctx context.Context, id, from, to string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE send_jobs SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4",
+		to, time.Now().UTC(), id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// postgresSendJobConditions builds the WHERE clause for a job filter, numbering the placeholders from start
+func postgresSendJobConditions(filter *domainSend.JobFilter, start int) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if len(filter.DeviceIDs) > 0 {
+		conditions = append(conditions, "device_id IN ("+postgresPlaceholders(start+len(args), len(filter.DeviceIDs))+")")
+		for _, deviceID := range filter.DeviceIDs {
+			args = append(args, deviceID)
+		}
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", start+len(args)-1))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", start+len(args)-1))
+	}
+	if filter.Recipient != "" {
+		args = append(args, filter.Recipient)
+		conditions = append(conditions, fmt.Sprintf("recipient = $%d", start+len(args)-1))
+	}
+	if filter.Scheduled {
+		conditions = append(conditions, "scheduled_at IS NOT NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
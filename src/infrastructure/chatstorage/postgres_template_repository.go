@@ -0,0 +1,195 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+)
+
+// PostgresTemplateRepository stores the message templates and their versions in the PostgreSQL chat storage
+type PostgresTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTemplateRepository creates a template repository. The tables are created by the chat storage migrations.
+func NewPostgresTemplateRepository(db *sql.DB) domainTemplate.ITemplateRepository {
+	return &PostgresTemplateRepository{db: db}
+}
+
+// CreateTemplate persists a template together with its first version
+func (r *PostgresTemplateRepository) CreateTemplate(ctx context.Context, template *domainTemplate.Template) error {
+	defaults, err := encodeTemplateDefaults(template.Defaults)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	template.Version = 1
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO message_templates (id, name, description, body, media_type, media_url, defaults, version, actor, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, template.ID, template.Name, template.Description, template.Body, template.MediaType, template.MediaURL, defaults,
+		template.Version, template.Actor, template.CreatedAt, template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertVersion(ctx, tx, template, defaults); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateTemplate stores the content of a template that is still at expectedVersion as its next version
+func (r *PostgresTemplateRepository) UpdateTemplate(ctx context.Context, template *domainTemplate.Template, expectedVersion int) (bool, error) {
+	defaults, err := encodeTemplateDefaults(template.Defaults)
+	if err != nil {
+		return false, err
+	}
+
+	template.Version = expectedVersion + 1
+	template.UpdatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE message_templates
+		SET name = $1, description = $2, body = $3, media_type = $4, media_url = $5, defaults = $6, version = $7, actor = $8, updated_at = $9
+		WHERE id = $10 AND version = $11 AND deleted_at IS NULL
+	`, template.Name, template.Description, template.Body, template.MediaType, template.MediaURL, defaults,
+		template.Version, template.Actor, template.UpdatedAt, template.ID, expectedVersion)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := r.insertVersion(ctx, tx, template, defaults); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// DeleteTemplate marks a template as deleted and keeps its versions, so the messages sent with it can still be
+// traced back to their text. It returns false when the template does not exist.
+func (r *PostgresTemplateRepository) DeleteTemplate(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE message_templates SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetTemplate retrieves a template by ID, nil when it does not exist or was deleted
+func (r *PostgresTemplateRepository) GetTemplate(ctx context.Context, id string) (*domainTemplate.Template, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateColumns+" FROM message_templates WHERE id = $1 AND deleted_at IS NULL", id)
+	template, err := scanTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// GetTemplateByName retrieves a template by its unique name, nil when it does not exist or was deleted
+func (r *PostgresTemplateRepository) GetTemplateByName(ctx context.Context, name string) (*domainTemplate.Template, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateColumns+" FROM message_templates WHERE name = $1 AND deleted_at IS NULL", name)
+	template, err := scanTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// GetTemplates retrieves templates with filtering, by name
+func (r *PostgresTemplateRepository) GetTemplates(ctx context.Context, filter *domainTemplate.TemplateFilter) ([]*domainTemplate.Template, error) {
+	where, args := postgresTemplateConditions(filter)
+	query := "SELECT " + templateColumns + " FROM message_templates" + where + " ORDER BY name ASC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTemplates(rows)
+}
+
+// CountTemplates returns the number of templates matching the filter
+func (r *PostgresTemplateRepository) CountTemplates(ctx context.Context, filter *domainTemplate.TemplateFilter) (int64, error) {
+	where, args := postgresTemplateConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM message_templates"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetVersions retrieves the versions of a template, newest first, also of a deleted template
+func (r *PostgresTemplateRepository) GetVersions(ctx context.Context, templateID string) ([]*domainTemplate.Version, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+templateVersionColumns+" FROM message_template_versions WHERE template_id = $1 ORDER BY version DESC", templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTemplateVersions(rows)
+}
+
+// GetVersion retrieves one version of a template, nil when it does not exist
+func (r *PostgresTemplateRepository) GetVersion(ctx context.Context, templateID string, version int) (*domainTemplate.Version, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateVersionColumns+" FROM message_template_versions WHERE template_id = $1 AND version = $2", templateID, version)
+	templateVersion, err := scanTemplateVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return templateVersion, err
+}
+
+func (r *PostgresTemplateRepository) insertVersion(ctx context.Context, tx *sql.Tx, template *domainTemplate.Template, defaults string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO message_template_versions (template_id, version, body, media_type, media_url, defaults, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, template.ID, template.Version, template.Body, template.MediaType, template.MediaURL, defaults, template.Actor, template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store version %d of template %s: %w", template.Version, template.ID, err)
+	}
+	return nil
+}
+
+// postgresTemplateConditions builds the WHERE clause for a template filter
+func postgresTemplateConditions(filter *domainTemplate.TemplateFilter) (string, []any) {
+	if filter.Search == "" {
+		return " WHERE deleted_at IS NULL", nil
+	}
+	search := "%" + filter.Search + "%"
+	return " WHERE deleted_at IS NULL AND (name ILIKE $1 OR description ILIKE $1)", []any{search}
+}
@@ -0,0 +1,142 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+)
+
+// PostgresUserRepository stores the app_users accounts in Postgres
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserRepository creates a user repository. The tables are created by the chat storage migrations or, for the auth database, at startup.
+func NewPostgresUserRepository(db *sql.DB) domainAuth.IUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+// GetUser retrieves a user by username
+func (r *PostgresUserRepository) GetUser(ctx context.Context, username string) (*domainAuth.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+appUserColumns+" FROM app_users WHERE username = $1", username)
+	user, err := scanAppUser(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetUsers retrieves all users ordered by username
+func (r *PostgresUserRepository) GetUsers(ctx context.Context) ([]*domainAuth.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+appUserColumns+" FROM app_users ORDER BY username ASC")
+	if err != nil {
+		return nil, err
+	}
+	return scanAppUsers(rows)
+}
+
+// CreateUser persists a new user
+func (r *PostgresUserRepository) CreateUser(ctx context.Context, user *domainAuth.User) error {
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO app_users (username, password_hash, role, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, user.Username, user.PasswordHash, user.Role, user.Enabled, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+// UpdateUser updates the password, role and enabled flag of a user
+func (r *PostgresUserRepository) UpdateUser(ctx context.Context, user *domainAuth.User) error {
+	user.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE app_users
+		SET password_hash = $1, role = $2, enabled = $3, updated_at = $4
+		WHERE username = $5
+	`, user.PasswordHash, user.Role, user.Enabled, user.UpdatedAt, user.Username)
+	return err
+}
+
+// DeleteUser removes a user together with its API keys
+func (r *PostgresUserRepository) DeleteUser(ctx context.Context, username string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM app_api_keys WHERE username = $1", username); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM app_users WHERE username = $1", username); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAPIKey retrieves an API key by ID
+func (r *PostgresUserRepository) GetAPIKey(ctx context.Context, id string) (*domainAuth.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM app_api_keys WHERE id = $1", id)
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of the key
+func (r *PostgresUserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domainAuth.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM app_api_keys WHERE key_hash = $1", keyHash)
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// GetAPIKeys retrieves the API keys of a user, or of every user when username is empty, oldest first
+func (r *PostgresUserRepository) GetAPIKeys(ctx context.Context, username string) ([]*domainAuth.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM app_api_keys
+		WHERE $1::text = '' OR username = $2
+		ORDER BY created_at ASC
+	`, username, username)
+	if err != nil {
+		return nil, err
+	}
+	return scanAPIKeys(rows)
+}
+
+// CreateAPIKey persists a new API key
+func (r *PostgresUserRepository) CreateAPIKey(ctx context.Context, key *domainAuth.APIKey) error {
+	key.CreatedAt = time.Now().UTC()
+	scopes, err := encodeScopes(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO app_api_keys (id, name, username, key_hash, prefix, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, key.ID, key.Name, key.Username, key.KeyHash, key.Prefix, scopes, utcOrNil(key.ExpiresAt), key.CreatedAt)
+	return err
+}
+
+// TouchAPIKey records when an API key was last used
+func (r *PostgresUserRepository) TouchAPIKey(ctx context.Context, id string, lastUsedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE app_api_keys SET last_used_at = $1 WHERE id = $2", lastUsedAt.UTC(), id)
+	return err
+}
+
+// DeleteAPIKey removes an API key
+func (r *PostgresUserRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM app_api_keys WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,336 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+// PostgresWebhookRepository implements the webhook outbox and endpoints on top of the Postgres chat storage
+type PostgresWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresWebhookRepository creates a webhook repository. The tables are created by the chat storage migrations.
+func NewPostgresWebhookRepository(db *sql.DB) domainWebhook.IWebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+// EnqueueDelivery persists a new delivery in the outbox
+func (r *PostgresWebhookRepository) EnqueueDelivery(ctx context.Context, delivery *domainWebhook.Delivery) error {
+	now := time.Now().UTC()
+	if delivery.Status == "" {
+		delivery.Status = domainWebhook.DeliveryStatusPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (endpoint_id, url, event, payload, status, attempts, last_error, next_attempt_at, retry_until, created_at, updated_at,
+			origin_device, origin_chat_jid, origin_sender_jid, origin_message_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`, delivery.EndpointID, delivery.URL, delivery.Event, delivery.Payload, delivery.Status, delivery.Attempts, delivery.LastError,
+		delivery.NextAttemptAt.UTC(), delivery.RetryUntil.UTC(), delivery.CreatedAt, delivery.UpdatedAt,
+		delivery.Origin.Device, delivery.Origin.ChatJID, delivery.Origin.SenderJID, delivery.Origin.MessageID).Scan(&delivery.ID)
+}
+
+// ClaimDueDeliveries returns pending deliveries that are due and leases them so they are not picked up twice
+func (r *PostgresWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domainWebhook.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries
+		SET claimed_until = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= $3 AND (claimed_until IS NULL OR claimed_until <= $3)
+			ORDER BY next_attempt_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns,
+		now.Add(lease).UTC(), domainWebhook.DeliveryStatusPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domainWebhook.Delivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// MarkDelivered records a successful delivery
+func (r *PostgresWebhookRepository) MarkDelivered(ctx context.Context, id int64, attempts int, deliveredAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, last_error = '', delivered_at = $3, claimed_until = NULL, updated_at = $4
+		WHERE id = $5
+	`, domainWebhook.DeliveryStatusDelivered, attempts, deliveredAt.UTC(), time.Now().UTC(), id)
+	return err
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (r *PostgresWebhookRepository) MarkRetry(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = $1, next_attempt_at = $2, last_error = $3, claimed_until = NULL, updated_at = $4
+		WHERE id = $5
+	`, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), id)
+	return err
+}
+
+// MarkFailed moves a delivery to the dead-letter state
+func (r *PostgresWebhookRepository) MarkFailed(ctx context.Context, id int64, attempts int, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, last_error = $3, claimed_until = NULL, updated_at = $4
+		WHERE id = $5
+	`, domainWebhook.DeliveryStatusFailed, attempts, lastError, time.Now().UTC(), id)
+	return err
+}
+
+// ExpediteDeliveries makes the postponed deliveries of an endpoint due now, the claimed ones are being attempted
+func (r *PostgresWebhookRepository) ExpediteDeliveries(ctx context.Context, endpointID string, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1, updated_at = $1
+		WHERE endpoint_id = $2 AND status = $3 AND next_attempt_at > $1 AND (claimed_until IS NULL OR claimed_until <= $1)
+	`, now.UTC(), endpointID, domainWebhook.DeliveryStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimResponseActions records that the first count actions of the response to a delivery ran, it returns how
+// many of them already ran before
+func (r *PostgresWebhookRepository) ClaimResponseActions(ctx context.Context, id int64, count int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ran int
+	if err := tx.QueryRowContext(ctx, "SELECT response_actions FROM webhook_deliveries WHERE id = $1 FOR UPDATE", id).Scan(&ran); err != nil {
+		return 0, err
+	}
+	if ran >= count {
+		return ran, nil
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE webhook_deliveries SET response_actions = $1 WHERE id = $2", count, id); err != nil {
+		return 0, err
+	}
+	return ran, tx.Commit()
+}
+
+// GetDelivery retrieves a delivery by ID
+func (r *PostgresWebhookRepository) GetDelivery(ctx context.Context, id int64) (*domainWebhook.Delivery, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = $1", id)
+	delivery, err := scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// GetDeliveries retrieves deliveries with filtering, newest first
+func (r *PostgresWebhookRepository) GetDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, error) {
+	where, args := postgresDeliveryConditions(filter, 1)
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domainWebhook.Delivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CountDeliveries returns the number of deliveries matching the filter
+func (r *PostgresWebhookRepository) CountDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter) (int64, error) {
+	where, args := postgresDeliveryConditions(filter, 1)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries"+where, args...).Scan(&count)
+	return count, err
+}
+
+// ReplayDelivery schedules a failed or delivered delivery again, it returns false when the delivery is pending
+func (r *PostgresWebhookRepository) ReplayDelivery(ctx context.Context, id int64, now time.Time, retryUntil time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, last_error = '', next_attempt_at = $2, retry_until = $3, delivered_at = NULL, updated_at = $2
+		WHERE id = $4 AND status IN ($5, $6)
+	`, domainWebhook.DeliveryStatusPending, now.UTC(), retryUntil.UTC(), id,
+		domainWebhook.DeliveryStatusFailed, domainWebhook.DeliveryStatusDelivered)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReplayDeliveries schedules every failed or delivered delivery matching the filter again
+func (r *PostgresWebhookRepository) ReplayDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter, now time.Time, retryUntil time.Time) (int64, error) {
+	where, args := postgresDeliveryConditions(filter, 4)
+	replayable := fmt.Sprintf("status IN ($%d, $%d)", 4+len(args), 5+len(args))
+	if where == "" {
+		where = " WHERE " + replayable
+	} else {
+		where += " AND " + replayable
+	}
+	args = append([]any{domainWebhook.DeliveryStatusPending, now.UTC(), retryUntil.UTC()}, args...)
+	args = append(args, domainWebhook.DeliveryStatusFailed, domainWebhook.DeliveryStatusDelivered)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, last_error = '', next_attempt_at = $2, retry_until = $3, delivered_at = NULL, updated_at = $2
+	`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeDeliveredBefore removes delivered entries created before the given time
+func (r *PostgresWebhookRepository) PurgeDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE status = $1 AND created_at < $2",
+		domainWebhook.DeliveryStatusDelivered, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// postgresDeliveryConditions builds the WHERE clause for a delivery filter, numbering placeholders from start
+func postgresDeliveryConditions(filter *domainWebhook.DeliveryFilter, start int) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.EndpointID != "" {
+		args = append(args, filter.EndpointID)
+		conditions = append(conditions, fmt.Sprintf("endpoint_id = $%d", start+len(args)-1))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", start+len(args)-1))
+	}
+	if filter.From != nil {
+		args = append(args, filter.From.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", start+len(args)-1))
+	}
+	if filter.To != nil {
+		args = append(args, filter.To.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", start+len(args)-1))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// CreateEndpoint stores a new webhook endpoint
+func (r *PostgresWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domainWebhook.Endpoint) error {
+	events, allowChats, denyChats, err := encodeEndpointLists(endpoint)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	endpoint.CreatedAt = now
+	endpoint.UpdatedAt = now
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO webhook_endpoints (id, url, secret, previous_secret, previous_secret_expires_at, events, allow_chats, deny_chats, payload_version, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, endpoint.ID, endpoint.URL, endpoint.Secret, endpoint.PreviousSecret, utcOrNil(endpoint.PreviousSecretExpiresAt), events, allowChats, denyChats, endpoint.PayloadVersion, endpoint.Enabled, endpoint.CreatedAt, endpoint.UpdatedAt)
+	return err
+}
+
+// UpdateEndpoint replaces the settings of a webhook endpoint
+func (r *PostgresWebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *domainWebhook.Endpoint) error {
+	events, allowChats, denyChats, err := encodeEndpointLists(endpoint)
+	if err != nil {
+		return err
+	}
+
+	endpoint.UpdatedAt = time.Now().UTC()
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE webhook_endpoints
+		SET url = $1, secret = $2, previous_secret = $3, previous_secret_expires_at = $4, events = $5, allow_chats = $6, deny_chats = $7, payload_version = $8, enabled = $9, updated_at = $10
+		WHERE id = $11
+	`, endpoint.URL, endpoint.Secret, endpoint.PreviousSecret, utcOrNil(endpoint.PreviousSecretExpiresAt), events, allowChats, denyChats, endpoint.PayloadVersion, endpoint.Enabled, endpoint.UpdatedAt, endpoint.ID)
+	return err
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (r *PostgresWebhookRepository) DeleteEndpoint(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM webhook_endpoints WHERE id = $1", id)
+	return err
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID
+func (r *PostgresWebhookRepository) GetEndpoint(ctx context.Context, id string) (*domainWebhook.Endpoint, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = $1", id)
+	endpoint, err := scanWebhookEndpoint(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return endpoint, err
+}
+
+// GetEndpoints retrieves all webhook endpoints, oldest first
+func (r *PostgresWebhookRepository) GetEndpoints(ctx context.Context) ([]*domainWebhook.Endpoint, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints ORDER BY created_at ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*domainWebhook.Endpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
@@ -0,0 +1,143 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+)
+
+const auditEntryColumns = `id, actor, api_key_id, source, action, device_id, target, summary, result, error, message_id, created_at`
+
+// SQLiteAuditRepository stores the audit log in the SQLite chat storage
+type SQLiteAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuditRepository creates an audit repository. The table is created by the chat storage migrations.
+func NewSQLiteAuditRepository(db *sql.DB) domainAudit.IAuditRepository {
+	return &SQLiteAuditRepository{db: db}
+}
+
+// InsertEntry appends an entry to the audit log
+func (r *SQLiteAuditRepository) InsertEntry(ctx context.Context, entry *domainAudit.Entry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO audit_log (actor, api_key_id, source, action, device_id, target, summary, result, error, message_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Actor, entry.APIKeyID, entry.Source, entry.Action, entry.DeviceID, entry.Target, entry.Summary,
+		entry.Result, entry.Error, entry.MessageID, entry.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetEntries retrieves entries with filtering, newest first
+func (r *SQLiteAuditRepository) GetEntries(ctx context.Context, filter *domainAudit.EntryFilter) ([]*domainAudit.Entry, error) {
+	where, args := sqliteAuditConditions(filter)
+	query := "SELECT " + auditEntryColumns + " FROM audit_log" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+// CountEntries returns the number of entries matching the filter
+func (r *SQLiteAuditRepository) CountEntries(ctx context.Context, filter *domainAudit.EntryFilter) (int64, error) {
+	where, args := sqliteAuditConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&count)
+	return count, err
+}
+
+// PurgeEntriesBefore removes entries created before the given time
+func (r *SQLiteAuditRepository) PurgeEntriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM audit_log WHERE created_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sqliteAuditConditions builds the WHERE clause for an audit filter
+func sqliteAuditConditions(filter *domainAudit.EntryFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action LIKE ? ESCAPE '\\'")
+		args = append(args, escapeLike(filter.Action)+"%")
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if filter.Target != "" {
+		conditions = append(conditions, "target = ?")
+		args = append(args, filter.Target)
+	}
+	if filter.Result != "" {
+		conditions = append(conditions, "result = ?")
+		args = append(args, filter.Result)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so the value is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func scanAuditEntries(rows *sql.Rows) ([]*domainAudit.Entry, error) {
+	var entries []*domainAudit.Entry
+	for rows.Next() {
+		entry := &domainAudit.Entry{}
+		err := rows.Scan(
+			&entry.ID, &entry.Actor, &entry.APIKeyID, &entry.Source, &entry.Action, &entry.DeviceID, &entry.Target,
+			&entry.Summary, &entry.Result, &entry.Error, &entry.MessageID, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
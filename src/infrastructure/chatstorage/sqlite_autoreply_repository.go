@@ -0,0 +1,145 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+)
+
+const autoReplyRuleColumns = `id, name, enabled, priority, device_id, conditions, actions, cooldown_seconds, continue_matching, actor, created_at, updated_at`
+
+// SQLiteAutoReplyRepository stores the auto-reply rules in the SQLite chat storage
+type SQLiteAutoReplyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAutoReplyRepository creates an auto-reply rule repository. The table is created by the chat storage migrations.
+func NewSQLiteAutoReplyRepository(db *sql.DB) domainAutoReply.IAutoReplyRepository {
+	return &SQLiteAutoReplyRepository{db: db}
+}
+
+// CreateRule stores a new rule
+func (r *SQLiteAutoReplyRepository) CreateRule(ctx context.Context, rule *domainAutoReply.Rule) error {
+	conditions, actions, err := encodeAutoReplyRule(rule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO auto_reply_rules (id, name, enabled, priority, device_id, conditions, actions, cooldown_seconds, continue_matching, actor, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.Name, rule.Enabled, rule.Priority, rule.DeviceID, conditions, actions, int(rule.Cooldown/time.Second),
+		rule.Continue, rule.Actor, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// UpdateRule replaces the settings of a rule
+func (r *SQLiteAutoReplyRepository) UpdateRule(ctx context.Context, rule *domainAutoReply.Rule) error {
+	conditions, actions, err := encodeAutoReplyRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now().UTC()
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE auto_reply_rules
+		SET name = ?, enabled = ?, priority = ?, device_id = ?, conditions = ?, actions = ?, cooldown_seconds = ?, continue_matching = ?, actor = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.Enabled, rule.Priority, rule.DeviceID, conditions, actions, int(rule.Cooldown/time.Second),
+		rule.Continue, rule.Actor, rule.UpdatedAt, rule.ID)
+	return err
+}
+
+// DeleteRule removes a rule, it returns false when the rule does not exist
+func (r *SQLiteAutoReplyRepository) DeleteRule(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM auto_reply_rules WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetRule retrieves a rule by ID, nil when it does not exist
+func (r *SQLiteAutoReplyRepository) GetRule(ctx context.Context, id string) (*domainAutoReply.Rule, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules WHERE id = ?", id)
+	rule, err := scanAutoReplyRule(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// GetRules retrieves every rule by priority, then by age
+func (r *SQLiteAutoReplyRepository) GetRules(ctx context.Context) ([]*domainAutoReply.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules ORDER BY priority ASC, created_at ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAutoReplyRules(rows)
+}
+
+// encodeAutoReplyRule serializes the conditions and actions of a rule as JSON
+func encodeAutoReplyRule(rule *domainAutoReply.Rule) (conditions, actions string, err error) {
+	data, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return "", "", err
+	}
+	conditions = string(data)
+
+	ruleActions := rule.Actions
+	if ruleActions == nil {
+		ruleActions = []domainAutoReply.Action{}
+	}
+	if data, err = json.Marshal(ruleActions); err != nil {
+		return "", "", err
+	}
+	return conditions, string(data), nil
+}
+
+func scanAutoReplyRules(rows *sql.Rows) ([]*domainAutoReply.Rule, error) {
+	var rules []*domainAutoReply.Rule
+	for rows.Next() {
+		rule, err := scanAutoReplyRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// scanAutoReplyRule is a private helper for scanning auto-reply rule rows
+func scanAutoReplyRule(scanner interface{ Scan(...any) error }) (*domainAutoReply.Rule, error) {
+	rule := &domainAutoReply.Rule{}
+	var conditions, actions string
+	var cooldown int
+
+	err := scanner.Scan(
+		&rule.ID, &rule.Name, &rule.Enabled, &rule.Priority, &rule.DeviceID, &conditions, &actions, &cooldown,
+		&rule.Continue, &rule.Actor, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Cooldown = time.Duration(cooldown) * time.Second
+	if err := json.Unmarshal([]byte(conditions), &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("invalid conditions of auto-reply rule %s: %w", rule.ID, err)
+	}
+	if err := json.Unmarshal([]byte(actions), &rule.Actions); err != nil {
+		return nil, fmt.Errorf("invalid actions of auto-reply rule %s: %w", rule.ID, err)
+	}
+	return rule, nil
+}
@@ -0,0 +1,436 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+)
+
+const campaignColumns = `id, device_id, actor, name, message, media_type, media_url, rate, status, recipients,
+	created_at, updated_at, started_at, completed_at`
+
+const campaignRecipientColumns = `id, campaign_id, phone, variables, status, message_id, job_id, error, sent_at,
+	delivered_at, read_at, updated_at`
+
+// SQLiteCampaignRepository stores the campaigns and their recipients in the SQLite chat storage
+type SQLiteCampaignRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteCampaignRepository creates a campaign repository. The tables are created by the chat storage migrations.
+func NewSQLiteCampaignRepository(db *sql.DB) domainCampaign.ICampaignRepository {
+	return &SQLiteCampaignRepository{db: db}
+}
+
+// CreateCampaign persists a campaign and its recipients, either all of them or none
+func (r *SQLiteCampaignRepository) CreateCampaign(ctx context.Context, campaign *domainCampaign.Campaign, recipients []*domainCampaign.Recipient) error {
+	now := time.Now().UTC()
+	if campaign.Status == "" {
+		campaign.Status = domainCampaign.StatusDraft
+	}
+	campaign.Recipients = len(recipients)
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO campaigns (id, device_id, actor, name, message, media_type, media_url, rate, status, recipients, created_at, updated_at, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, campaign.ID, campaign.DeviceID, campaign.Actor, campaign.Name, campaign.Message, campaign.MediaType, campaign.MediaURL,
+		campaign.Rate, campaign.Status, campaign.Recipients, campaign.CreatedAt, campaign.UpdatedAt, utcOrNil(campaign.StartedAt))
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO campaign_recipients (campaign_id, phone, variables, status, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, recipient := range recipients {
+		variables, err := encodeRecipientVariables(recipient.Variables)
+		if err != nil {
+			return err
+		}
+		recipient.CampaignID = campaign.ID
+		recipient.Status = domainCampaign.RecipientPending
+		recipient.UpdatedAt = now
+
+		result, err := stmt.ExecContext(ctx, recipient.CampaignID, recipient.Phone, variables, recipient.Status, recipient.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store recipient %s: %w", recipient.Phone, err)
+		}
+		if recipient.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCampaign retrieves a campaign by ID, nil when it does not exist
+func (r *SQLiteCampaignRepository) GetCampaign(ctx context.Context, id string) (*domainCampaign.Campaign, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = ?", id)
+	campaign, err := scanCampaign(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return campaign, err
+}
+
+// GetCampaigns retrieves campaigns with filtering, newest first
+func (r *SQLiteCampaignRepository) GetCampaigns(ctx context.Context, filter *domainCampaign.CampaignFilter) ([]*domainCampaign.Campaign, error) {
+	where, args := sqliteCampaignConditions(filter)
+	query := "SELECT " + campaignColumns + " FROM campaigns" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaigns(rows)
+}
+
+// CountCampaigns returns the number of campaigns matching the filter
+func (r *SQLiteCampaignRepository) CountCampaigns(ctx context.Context, filter *domainCampaign.CampaignFilter) (int64, error) {
+	where, args := sqliteCampaignConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM campaigns"+where, args...).Scan(&count)
+	return count, err
+}
+
+// SetCampaignStatus changes the status of a campaign that still has one of the from statuses. Starting a campaign
+// records when it first started, finishing it when it finished.
+func (r *SQLiteCampaignRepository) SetCampaignStatus(ctx context.Context, id, status string, from ...string) (bool, error) {
+	now := time.Now().UTC()
+	startedAt, completedAt := campaignStatusTimes(status, now)
+
+	args := []any{status, now, startedAt, completedAt, id}
+	for _, status := range from {
+		args = append(args, status)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaigns
+		SET status = ?, updated_at = ?, started_at = COALESCE(started_at, ?), completed_at = ?
+		WHERE id = ? AND status IN (`+sqlitePlaceholders(len(from))+`)
+	`, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetRecipients retrieves the recipients of a campaign in the order they are sent to
+func (r *SQLiteCampaignRepository) GetRecipients(ctx context.Context, campaignID string, filter *domainCampaign.RecipientFilter) ([]*domainCampaign.Recipient, error) {
+	where, args := sqliteRecipientConditions(campaignID, filter)
+	query := "SELECT " + campaignRecipientColumns + " FROM campaign_recipients" + where + " ORDER BY id ASC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaignRecipients(rows)
+}
+
+// CountRecipients returns the number of recipients of a campaign matching the filter
+func (r *SQLiteCampaignRepository) CountRecipients(ctx context.Context, campaignID string, filter *domainCampaign.RecipientFilter) (int64, error) {
+	where, args := sqliteRecipientConditions(campaignID, filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM campaign_recipients"+where, args...).Scan(&count)
+	return count, err
+}
+
+// CountRecipientsByStatus returns the number of recipients of a campaign per status
+func (r *SQLiteCampaignRepository) CountRecipientsByStatus(ctx context.Context, campaignID string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM campaign_recipients WHERE campaign_id = ? GROUP BY status", campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRecipientStatusCounts(rows)
+}
+
+// NextPendingRecipient returns the first recipient of a campaign that was not sent to yet, nil when there is none
+func (r *SQLiteCampaignRepository) NextPendingRecipient(ctx context.Context, campaignID string) (*domainCampaign.Recipient, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+campaignRecipientColumns+`
+		FROM campaign_recipients
+		WHERE campaign_id = ? AND status = ?
+		ORDER BY id ASC
+		LIMIT 1
+	`, campaignID, domainCampaign.RecipientPending)
+	recipient, err := scanCampaignRecipient(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return recipient, err
+}
+
+// UpdateRecipient stores the send state of a recipient that still has the from status
+func (r *SQLiteCampaignRepository) UpdateRecipient(ctx context.Context, recipient *domainCampaign.Recipient, from string) (bool, error) {
+	recipient.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients
+		SET status = ?, message_id = ?, job_id = ?, error = ?, sent_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, recipient.Status, recipient.MessageID, recipient.JobID, recipient.Error, utcOrNil(recipient.SentAt), recipient.UpdatedAt,
+		recipient.ID, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetQueuedRecipients returns the recipients waiting for the send queue, the longest waiting first
+func (r *SQLiteCampaignRepository) GetQueuedRecipients(ctx context.Context, limit int) ([]*domainCampaign.Recipient, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+campaignRecipientColumns+`
+		FROM campaign_recipients
+		WHERE status = ?
+		ORDER BY updated_at ASC, id ASC
+		LIMIT ?
+	`, domainCampaign.RecipientQueued, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCampaignRecipients(rows)
+}
+
+// FailSendingRecipients fails the recipients a previous run left in the sending state
+func (r *SQLiteCampaignRepository) FailSendingRecipients(ctx context.Context, reason string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE campaign_recipients SET status = ?, error = ?, updated_at = ? WHERE status = ?",
+		domainCampaign.RecipientFailed, reason, time.Now().UTC(), domainCampaign.RecipientSending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SkipPendingRecipients skips the recipients of a campaign that were not sent to yet
+func (r *SQLiteCampaignRepository) SkipPendingRecipients(ctx context.Context, campaignID string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE campaign_recipients SET status = ?, updated_at = ? WHERE campaign_id = ? AND status = ?",
+		domainCampaign.RecipientSkipped, time.Now().UTC(), campaignID, domainCampaign.RecipientPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RecordReceipt moves the recipients of the messages forward to delivered or read, a receipt never moves one back
+func (r *SQLiteCampaignRepository) RecordReceipt(ctx context.Context, messageIDs []string, status string, at time.Time) (int64, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	from := []string{domainCampaign.RecipientQueued, domainCampaign.RecipientSent}
+	readAt := any(nil)
+	if status == domainCampaign.RecipientRead {
+		from = append(from, domainCampaign.RecipientDelivered)
+		readAt = at.UTC()
+	}
+
+	args := []any{status, at.UTC(), at.UTC(), readAt, time.Now().UTC()}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+	for _, status := range from {
+		args = append(args, status)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE campaign_recipients
+		SET status = ?, sent_at = COALESCE(sent_at, ?), delivered_at = COALESCE(delivered_at, ?), read_at = ?, updated_at = ?
+		WHERE message_id IN (`+sqlitePlaceholders(len(messageIDs))+`) AND status IN (`+sqlitePlaceholders(len(from))+`)
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sqliteCampaignConditions builds the WHERE clause for a campaign filter
+func sqliteCampaignConditions(filter *domainCampaign.CampaignFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// sqliteRecipientConditions builds the WHERE clause for the recipients of a campaign
+func sqliteRecipientConditions(campaignID string, filter *domainCampaign.RecipientFilter) (string, []any) {
+	where, args := " WHERE campaign_id = ?", []any{campaignID}
+	if filter != nil && filter.Status != "" {
+		where += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	return where, args
+}
+
+// sqlitePlaceholders returns n comma separated placeholders for an IN list
+func sqlitePlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// campaignStatusTimes returns the started_at and completed_at values stored with a new campaign status
+func campaignStatusTimes(status string, now time.Time) (startedAt, completedAt any) {
+	if status == domainCampaign.StatusRunning {
+		startedAt = now
+	}
+	if status == domainCampaign.StatusCompleted || status == domainCampaign.StatusCancelled {
+		completedAt = now
+	}
+	return startedAt, completedAt
+}
+
+// encodeRecipientVariables serializes the variables of a recipient as a JSON object
+func encodeRecipientVariables(variables map[string]string) (string, error) {
+	if variables == nil {
+		variables = map[string]string{}
+	}
+	data, err := json.Marshal(variables)
+	return string(data), err
+}
+
+func scanCampaigns(rows *sql.Rows) ([]*domainCampaign.Campaign, error) {
+	var campaigns []*domainCampaign.Campaign
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	return campaigns, rows.Err()
+}
+
+// scanCampaign is a private helper for scanning campaign rows
+func scanCampaign(scanner interface{ Scan(...any) error }) (*domainCampaign.Campaign, error) {
+	campaign := &domainCampaign.Campaign{}
+	var startedAt, completedAt sql.NullTime
+
+	err := scanner.Scan(
+		&campaign.ID, &campaign.DeviceID, &campaign.Actor, &campaign.Name, &campaign.Message, &campaign.MediaType,
+		&campaign.MediaURL, &campaign.Rate, &campaign.Status, &campaign.Recipients, &campaign.CreatedAt, &campaign.UpdatedAt,
+		&startedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if startedAt.Valid {
+		campaign.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		campaign.CompletedAt = &completedAt.Time
+	}
+	return campaign, nil
+}
+
+func scanCampaignRecipients(rows *sql.Rows) ([]*domainCampaign.Recipient, error) {
+	var recipients []*domainCampaign.Recipient
+	for rows.Next() {
+		recipient, err := scanCampaignRecipient(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+// scanCampaignRecipient is a private helper for scanning recipient rows
+func scanCampaignRecipient(scanner interface{ Scan(...any) error }) (*domainCampaign.Recipient, error) {
+	recipient := &domainCampaign.Recipient{}
+	var variables string
+	var sentAt, deliveredAt, readAt sql.NullTime
+
+	err := scanner.Scan(
+		&recipient.ID, &recipient.CampaignID, &recipient.Phone, &variables, &recipient.Status, &recipient.MessageID,
+		&recipient.JobID, &recipient.Error, &sentAt, &deliveredAt, &readAt, &recipient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(variables), &recipient.Variables); err != nil {
+		return nil, fmt.Errorf("invalid variables of recipient %s: %w", recipient.Phone, err)
+	}
+	if sentAt.Valid {
+		recipient.SentAt = &sentAt.Time
+	}
+	if deliveredAt.Valid {
+		recipient.DeliveredAt = &deliveredAt.Time
+	}
+	if readAt.Valid {
+		recipient.ReadAt = &readAt.Time
+	}
+	return recipient, nil
+}
+
+func scanRecipientStatusCounts(rows *sql.Rows) (map[string]int, error) {
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
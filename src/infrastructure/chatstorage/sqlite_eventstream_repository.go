@@ -0,0 +1,73 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+)
+
+const eventLogColumns = `id, device_id, type, event, chat_jid, payload, created_at`
+
+// SQLiteEventStreamRepository keeps the streamed events in the SQLite chat storage
+type SQLiteEventStreamRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteEventStreamRepository creates an event log repository. The table is created by the chat storage migrations.
+func NewSQLiteEventStreamRepository(db *sql.DB) domainEventStream.IEventStreamRepository {
+	return &SQLiteEventStreamRepository{db: db}
+}
+
+// StoreEvent appends an event to the event log and sets its ID
+func (r *SQLiteEventStreamRepository) StoreEvent(ctx context.Context, event *domainEventStream.Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO event_log (device_id, type, event, chat_jid, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, event.DeviceID, event.Type, event.Name, event.ChatJID, string(event.Payload), event.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	event.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetEventsAfter returns the oldest events with an ID above the given one, in order
+func (r *SQLiteEventStreamRepository) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]*domainEventStream.Event, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+eventLogColumns+" FROM event_log WHERE id > ? ORDER BY id LIMIT ?", afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// PurgeEventsBefore removes events created before the given time
+func (r *SQLiteEventStreamRepository) PurgeEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM event_log WHERE created_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanEvents(rows *sql.Rows) ([]*domainEventStream.Event, error) {
+	var events []*domainEventStream.Event
+	for rows.Next() {
+		event := &domainEventStream.Event{}
+		var payload string
+		if err := rows.Scan(&event.ID, &event.DeviceID, &event.Type, &event.Name, &event.ChatJID, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = []byte(payload)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,137 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+)
+
+// SQLiteRateLimitRepository persists the rate limit buckets and send quota usage in the SQLite chat storage
+type SQLiteRateLimitRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRateLimitRepository creates a rate limit repository. The tables are created by the chat storage migrations.
+func NewSQLiteRateLimitRepository(db *sql.DB) domainRateLimit.IRateLimitRepository {
+	return &SQLiteRateLimitRepository{db: db}
+}
+
+// GetBucket returns the bucket or nil when it has not been used yet
+func (r *SQLiteRateLimitRepository) GetBucket(ctx context.Context, subject, class string) (*domainRateLimit.Bucket, error) {
+	bucket := &domainRateLimit.Bucket{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT subject, class, tokens, updated_at FROM rate_limit_buckets WHERE subject = ? AND class = ?
+	`, subject, class).Scan(&bucket.Subject, &bucket.Class, &bucket.Tokens, &bucket.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// GetBuckets returns the buckets of a subject, or of every user and API key when subject is empty
+func (r *SQLiteRateLimitRepository) GetBuckets(ctx context.Context, subject string) ([]*domainRateLimit.Bucket, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subject, class, tokens, updated_at FROM rate_limit_buckets
+		WHERE (? = '' AND subject NOT LIKE 'recipient:%') OR subject = ?
+		ORDER BY subject, class
+	`, subject, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRateLimitBuckets(rows)
+}
+
+// SaveBucket inserts or updates a bucket
+func (r *SQLiteRateLimitRepository) SaveBucket(ctx context.Context, bucket *domainRateLimit.Bucket) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (subject, class, tokens, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(subject, class) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at
+	`, bucket.Subject, bucket.Class, bucket.Tokens, bucket.UpdatedAt.UTC())
+	return err
+}
+
+// PurgeBucketsBefore removes buckets that were not used since the given time
+func (r *SQLiteRateLimitRepository) PurgeBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE updated_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetUsage returns the sends of a subject in a period
+func (r *SQLiteRateLimitRepository) GetUsage(ctx context.Context, subject, period string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT count FROM send_quota_usage WHERE subject = ? AND period = ?", subject, period).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// GetUsages returns the usage in the period of a subject, or of every subject when subject is empty
+func (r *SQLiteRateLimitRepository) GetUsages(ctx context.Context, subject, period string) ([]*domainRateLimit.Usage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subject, period, count, updated_at FROM send_quota_usage
+		WHERE period = ? AND (? = '' OR subject = ?)
+		ORDER BY subject
+	`, period, subject, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendQuotaUsages(rows)
+}
+
+// IncrementUsage counts a send of a subject in a period
+func (r *SQLiteRateLimitRepository) IncrementUsage(ctx context.Context, subject, period string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO send_quota_usage (subject, period, count, updated_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT(subject, period) DO UPDATE SET count = count + 1, updated_at = excluded.updated_at
+	`, subject, period, now.UTC())
+	return err
+}
+
+// PurgeUsageBefore removes usage that was not counted since the given time
+func (r *SQLiteRateLimitRepository) PurgeUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM send_quota_usage WHERE updated_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanRateLimitBuckets(rows *sql.Rows) ([]*domainRateLimit.Bucket, error) {
+	var buckets []*domainRateLimit.Bucket
+	for rows.Next() {
+		bucket := &domainRateLimit.Bucket{}
+		if err := rows.Scan(&bucket.Subject, &bucket.Class, &bucket.Tokens, &bucket.UpdatedAt); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, rows.Err()
+}
+
+func scanSendQuotaUsages(rows *sql.Rows) ([]*domainRateLimit.Usage, error) {
+	var usages []*domainRateLimit.Usage
+	for rows.Next() {
+		usage := &domainRateLimit.Usage{}
+		if err := rows.Scan(&usage.Subject, &usage.Period, &usage.Count, &usage.UpdatedAt); err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, rows.Err()
+}
@@ -3,6 +3,8 @@ package chatstorage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +12,7 @@ import (
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -65,7 +68,8 @@ func (r *SQLiteRepository) GetMessageByID(id string) (*domainChatStorage.Message
 	query := `
 		SELECT id, chat_jid, sender, content, timestamp, is_from_me,
 			media_type, filename, url, media_key, file_sha256,
-			file_enc_sha256, file_length, created_at, updated_at
+			file_enc_sha256, file_length, created_at, updated_at,
+			edited_at, revoked_at
 		FROM messages
 		WHERE id = ?
 		LIMIT 1
@@ -146,6 +150,31 @@ func (r *SQLiteRepository) DeleteChat(jid string) error {
 	}
 	defer tx.Rollback()
 
+	_, err = tx.Exec("DELETE FROM message_receipts WHERE chat_jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM message_reactions WHERE chat_jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM message_edits WHERE chat_jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM poll_votes WHERE chat_jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM polls WHERE chat_jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
 	// Delete messages first (foreign key constraint)
 	_, err = tx.Exec("DELETE FROM messages WHERE chat_jid = ?", jid)
 	if err != nil {
@@ -296,7 +325,8 @@ func (r *SQLiteRepository) GetMessages(filter *domainChatStorage.MessageFilter)
 	query := `
 		SELECT id, chat_jid, sender, content, timestamp, is_from_me,
 			media_type, filename, url, media_key, file_sha256,
-			file_enc_sha256, file_length, created_at, updated_at
+			file_enc_sha256, file_length, created_at, updated_at,
+			edited_at, revoked_at
 		FROM messages
 		WHERE ` + strings.Join(conditions, " AND ") + `
 		ORDER BY timestamp DESC
@@ -356,7 +386,8 @@ func (r *SQLiteRepository) SearchMessages(chatJID, searchText string, limit int)
 	query := `
 		SELECT id, chat_jid, sender, content, timestamp, is_from_me,
 			media_type, filename, url, media_key, file_sha256,
-			file_enc_sha256, file_length, created_at, updated_at
+			file_enc_sha256, file_length, created_at, updated_at,
+			edited_at, revoked_at
 		FROM messages
 		WHERE ` + strings.Join(conditions, " AND ") + `
 		ORDER BY timestamp DESC
@@ -394,12 +425,185 @@ func (r *SQLiteRepository) SearchMessages(chatJID, searchText string, limit int)
 	return messages, nil
 }
 
-// DeleteMessage deletes a specific message
+// DeleteMessage deletes a specific message with its receipts, reactions, edit history and poll votes
 func (r *SQLiteRepository) DeleteMessage(id, chatJID string) error {
-	_, err := r.db.Exec("DELETE FROM messages WHERE id = ? AND chat_jid = ?", id, chatJID)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM message_receipts WHERE message_id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM message_reactions WHERE message_id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM message_edits WHERE message_id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM poll_votes WHERE poll_id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM polls WHERE message_id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM messages WHERE id = ? AND chat_jid = ?", id, chatJID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EditMessage replaces the content of a message and keeps the previous content in the edit history. Edits older
+// than the last one applied, and edits of messages that are not stored, are ignored.
+func (r *SQLiteRepository) EditMessage(id, chatJID, content string, editedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous string
+	var lastEditedAt sql.NullTime
+	err = tx.QueryRow("SELECT content, edited_at FROM messages WHERE id = ? AND chat_jid = ?", id, chatJID).Scan(&previous, &lastEditedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lastEditedAt.Valid && !editedAt.After(lastEditedAt.Time) {
+		return nil
+	}
+
+	if _, err = tx.Exec(`
+		INSERT INTO message_edits (message_id, chat_jid, content, edited_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(message_id, chat_jid, edited_at) DO NOTHING
+	`, id, chatJID, previous, editedAt.UTC()); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(
+		"UPDATE messages SET content = ?, edited_at = ?, updated_at = ? WHERE id = ? AND chat_jid = ?",
+		content, editedAt.UTC(), time.Now(), id, chatJID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeMessage marks a message as deleted for everyone, keeping the time it was first revoked
+func (r *SQLiteRepository) RevokeMessage(id, chatJID string, revokedAt time.Time) error {
+	_, err := r.db.Exec(
+		"UPDATE messages SET revoked_at = COALESCE(revoked_at, ?), updated_at = ? WHERE id = ? AND chat_jid = ?",
+		revokedAt.UTC(), time.Now(), id, chatJID,
+	)
 	return err
 }
 
+// GetMessageEdits retrieves the edit history of the messages of a chat, oldest first
+func (r *SQLiteRepository) GetMessageEdits(chatJID string, messageIDs ...string) ([]*domainChatStorage.MessageEdit, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	args := []any{chatJID}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT message_id, chat_jid, content, edited_at
+		FROM message_edits
+		WHERE chat_jid = ? AND message_id IN (`+sqlitePlaceholders(len(messageIDs))+`)
+		ORDER BY edited_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edits []*domainChatStorage.MessageEdit
+	for rows.Next() {
+		edit := &domainChatStorage.MessageEdit{}
+		if err := rows.Scan(&edit.MessageID, &edit.ChatJID, &edit.Content, &edit.EditedAt); err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+
+	return edits, rows.Err()
+}
+
+// StoreReceipts records the receipts, keeping the first time a recipient reached every state
+func (r *SQLiteRepository) StoreReceipts(receipts []*domainChatStorage.Receipt) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO message_receipts (message_id, chat_jid, participant, delivered_at, read_at, played_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(message_id, chat_jid, participant) DO UPDATE SET
+			delivered_at = COALESCE(message_receipts.delivered_at, excluded.delivered_at),
+			read_at = COALESCE(message_receipts.read_at, excluded.read_at),
+			played_at = COALESCE(message_receipts.played_at, excluded.played_at)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, receipt := range receipts {
+		if _, err := stmt.Exec(
+			receipt.MessageID, receipt.ChatJID, receipt.Participant,
+			utcOrNil(receipt.DeliveredAt), utcOrNil(receipt.ReadAt), utcOrNil(receipt.PlayedAt),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReceipts retrieves the receipts of the messages of a chat
+func (r *SQLiteRepository) GetReceipts(chatJID string, messageIDs ...string) ([]*domainChatStorage.Receipt, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	args := []any{chatJID}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT message_id, chat_jid, participant, delivered_at, read_at, played_at
+		FROM message_receipts
+		WHERE chat_jid = ? AND message_id IN (`+sqlitePlaceholders(len(messageIDs))+`)
+		ORDER BY message_id, participant
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []*domainChatStorage.Receipt
+	for rows.Next() {
+		receipt, err := scanReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
 // getCount is a private helper for count queries
 func (r *SQLiteRepository) getCount(query string, args ...any) (int64, error) {
 	var count int64
@@ -410,15 +614,260 @@ func (r *SQLiteRepository) getCount(query string, args ...any) (int64, error) {
 // scanMessage is a private helper for scanning message rows
 func (r *SQLiteRepository) scanMessage(scanner interface{ Scan(...any) error }) (*domainChatStorage.Message, error) {
 	message := &domainChatStorage.Message{}
+	var editedAt, revokedAt sql.NullTime
 	err := scanner.Scan(
 		&message.ID, &message.ChatJID, &message.Sender, &message.Content,
 		&message.Timestamp, &message.IsFromMe, &message.MediaType, &message.Filename,
 		&message.URL, &message.MediaKey, &message.FileSHA256, &message.FileEncSHA256,
 		&message.FileLength, &message.CreatedAt, &message.UpdatedAt,
+		&editedAt, &revokedAt,
 	)
+	if editedAt.Valid {
+		message.EditedAt = &editedAt.Time
+	}
+	if revokedAt.Valid {
+		message.RevokedAt = &revokedAt.Time
+	}
 	return message, err
 }
 
+// StoreReactions adds, changes or removes reactions, an older reaction of a sender never replaces a newer one
+func (r *SQLiteRepository) StoreReactions(reactions []*domainChatStorage.Reaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO message_reactions (message_id, chat_jid, sender, emoji, is_from_me, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(message_id, chat_jid, sender) DO UPDATE SET
+			emoji = excluded.emoji,
+			timestamp = excluded.timestamp
+		WHERE excluded.timestamp >= message_reactions.timestamp
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, reaction := range reactions {
+		if _, err := stmt.Exec(
+			reaction.MessageID, reaction.ChatJID, reaction.Sender, reaction.Emoji, reaction.IsFromMe, reaction.Timestamp.UTC(),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReactions retrieves the current reactions to the messages of a chat, oldest first
+func (r *SQLiteRepository) GetReactions(chatJID string, messageIDs ...string) ([]*domainChatStorage.Reaction, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	args := []any{chatJID}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT message_id, chat_jid, sender, emoji, is_from_me, timestamp
+		FROM message_reactions
+		WHERE chat_jid = ? AND message_id IN (`+sqlitePlaceholders(len(messageIDs))+`) AND emoji != ''
+		ORDER BY timestamp, sender
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []*domainChatStorage.Reaction
+	for rows.Next() {
+		reaction := &domainChatStorage.Reaction{}
+		if err := rows.Scan(
+			&reaction.MessageID, &reaction.ChatJID, &reaction.Sender, &reaction.Emoji, &reaction.IsFromMe, &reaction.Timestamp,
+		); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, reaction)
+	}
+
+	return reactions, rows.Err()
+}
+
+// StorePoll creates or updates a poll
+func (r *SQLiteRepository) StorePoll(poll *domainChatStorage.Poll) error {
+	options, err := json.Marshal(poll.Options)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO polls (message_id, chat_jid, sender, question, options, selectable_count, secret, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(message_id, chat_jid) DO UPDATE SET
+			sender = excluded.sender,
+			question = excluded.question,
+			options = excluded.options,
+			selectable_count = excluded.selectable_count,
+			secret = COALESCE(excluded.secret, polls.secret)
+	`, poll.MessageID, poll.ChatJID, poll.Sender, poll.Question, string(options), poll.SelectableCount, poll.Secret, poll.Timestamp.UTC())
+	return err
+}
+
+// GetPoll retrieves a poll, nil when it is not stored
+func (r *SQLiteRepository) GetPoll(chatJID, messageID string) (*domainChatStorage.Poll, error) {
+	row := r.db.QueryRow(`
+		SELECT message_id, chat_jid, sender, question, options, selectable_count, secret, timestamp
+		FROM polls
+		WHERE chat_jid = ? AND message_id = ?
+	`, chatJID, messageID)
+
+	poll, err := scanPoll(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return poll, err
+}
+
+// StoreSessionToken keeps the token of the session of a paired device, so it survives restarts
+func (r *SQLiteRepository) StoreSessionToken(deviceJID, token string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO device_sessions (device_jid, token, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(device_jid) DO UPDATE SET token = excluded.token
+	`, deviceJID, token, time.Now().UTC())
+	return err
+}
+
+// GetSessionTokens returns the tokens of the sessions by device JID
+func (r *SQLiteRepository) GetSessionTokens() (map[string]string, error) {
+	rows, err := r.db.Query("SELECT device_jid, token FROM device_sessions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]string)
+	for rows.Next() {
+		var deviceJID, token string
+		if err := rows.Scan(&deviceJID, &token); err != nil {
+			return nil, err
+		}
+		tokens[deviceJID] = token
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteSessionToken forgets a removed session
+func (r *SQLiteRepository) DeleteSessionToken(token string) error {
+	_, err := r.db.Exec("DELETE FROM device_sessions WHERE token = ?", token)
+	return err
+}
+
+// StorePollVote replaces the vote of the voter, an older vote never replaces a newer one
+func (r *SQLiteRepository) StorePollVote(vote *domainChatStorage.PollVote) error {
+	options, err := json.Marshal(vote.Options)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO poll_votes (poll_id, chat_jid, voter, options, timestamp)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(poll_id, chat_jid, voter) DO UPDATE SET
+			options = excluded.options,
+			timestamp = excluded.timestamp
+		WHERE excluded.timestamp >= poll_votes.timestamp
+	`, vote.PollID, vote.ChatJID, vote.Voter, string(options), vote.Timestamp.UTC())
+	return err
+}
+
+// GetPollVotes retrieves the latest vote of every voter on a poll, oldest first
+func (r *SQLiteRepository) GetPollVotes(chatJID, pollID string) ([]*domainChatStorage.PollVote, error) {
+	rows, err := r.db.Query(`
+		SELECT poll_id, chat_jid, voter, options, timestamp
+		FROM poll_votes
+		WHERE chat_jid = ? AND poll_id = ?
+		ORDER BY timestamp, voter
+	`, chatJID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []*domainChatStorage.PollVote
+	for rows.Next() {
+		vote, err := scanPollVote(rows)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+
+	return votes, rows.Err()
+}
+
+// scanPoll is a private helper for scanning poll rows, shared with the PostgreSQL repository
+func scanPoll(scanner interface{ Scan(...any) error }) (*domainChatStorage.Poll, error) {
+	poll := &domainChatStorage.Poll{}
+	var options string
+
+	err := scanner.Scan(
+		&poll.MessageID, &poll.ChatJID, &poll.Sender, &poll.Question, &options, &poll.SelectableCount, &poll.Secret,
+		&poll.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(options), &poll.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of poll %s: %w", poll.MessageID, err)
+	}
+	return poll, nil
+}
+
+// scanPollVote is a private helper for scanning poll vote rows, shared with the PostgreSQL repository
+func scanPollVote(scanner interface{ Scan(...any) error }) (*domainChatStorage.PollVote, error) {
+	vote := &domainChatStorage.PollVote{}
+	var options string
+
+	if err := scanner.Scan(&vote.PollID, &vote.ChatJID, &vote.Voter, &options, &vote.Timestamp); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(options), &vote.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of the vote of %s: %w", vote.Voter, err)
+	}
+	return vote, nil
+}
+
+// scanReceipt is a private helper for scanning receipt rows, shared with the PostgreSQL repository
+func scanReceipt(scanner interface{ Scan(...any) error }) (*domainChatStorage.Receipt, error) {
+	receipt := &domainChatStorage.Receipt{}
+	var deliveredAt, readAt, playedAt sql.NullTime
+
+	err := scanner.Scan(&receipt.MessageID, &receipt.ChatJID, &receipt.Participant, &deliveredAt, &readAt, &playedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveredAt.Valid {
+		receipt.DeliveredAt = &deliveredAt.Time
+	}
+	if readAt.Valid {
+		receipt.ReadAt = &readAt.Time
+	}
+	if playedAt.Valid {
+		receipt.PlayedAt = &playedAt.Time
+	}
+	return receipt, nil
+}
+
 // scanChat is a private helper for scanning chat rows
 func (r *SQLiteRepository) scanChat(scanner interface{ Scan(...any) error }) (*domainChatStorage.Chat, error) {
 	chat := &domainChatStorage.Chat{}
@@ -453,6 +902,31 @@ func (r *SQLiteRepository) TruncateAllChats() error {
 	}
 	defer tx.Rollback()
 
+	_, err = tx.Exec("DELETE FROM message_receipts")
+	if err != nil {
+		return fmt.Errorf("failed to delete message receipts: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM message_reactions")
+	if err != nil {
+		return fmt.Errorf("failed to delete message reactions: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM message_edits")
+	if err != nil {
+		return fmt.Errorf("failed to delete message edits: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM poll_votes")
+	if err != nil {
+		return fmt.Errorf("failed to delete poll votes: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM polls")
+	if err != nil {
+		return fmt.Errorf("failed to delete polls: %w", err)
+	}
+
 	// Delete messages first (foreign key constraint)
 	_, err = tx.Exec("DELETE FROM messages")
 	if err != nil {
@@ -511,6 +985,16 @@ func (r *SQLiteRepository) CreateMessage(ctx context.Context, evt *events.Messag
 		return nil
 	}
 
+	// Reactions are kept apart from the messages they react to
+	if reaction := reactionFromEvent(evt); reaction != nil {
+		return r.StoreReactions([]*domainChatStorage.Reaction{reaction})
+	}
+
+	// Edits and revokes change the message they are about
+	if handled, err := applyProtocolMessage(r, evt); handled {
+		return err
+	}
+
 	// Extract chat and sender information
 	chatJID := evt.Info.Chat.String()
 	// Store the full sender JID (user@server) to ensure consistency between received and sent messages
@@ -579,6 +1063,100 @@ func (r *SQLiteRepository) CreateMessage(ctx context.Context, evt *events.Messag
 	return r.StoreMessage(message)
 }
 
+// reactionFromEvent returns the reaction of a reaction message, nil for other messages. Shared with the PostgreSQL
+// repository.
+func reactionFromEvent(evt *events.Message) *domainChatStorage.Reaction {
+	reactionMessage := evt.Message.GetReactionMessage()
+	if reactionMessage == nil || reactionMessage.GetKey().GetID() == "" {
+		return nil
+	}
+
+	timestamp := evt.Info.Timestamp
+	if ms := reactionMessage.GetSenderTimestampMS(); ms > 0 {
+		timestamp = time.UnixMilli(ms)
+	}
+
+	return &domainChatStorage.Reaction{
+		MessageID: reactionMessage.GetKey().GetID(),
+		ChatJID:   evt.Info.Chat.String(),
+		Sender:    evt.Info.Sender.ToNonAD().String(),
+		Emoji:     reactionMessage.GetText(),
+		IsFromMe:  evt.Info.IsFromMe,
+		Timestamp: timestamp,
+	}
+}
+
+// applyProtocolMessage applies an edit or revoke to the message it is about and reports whether the event was one.
+// Only the sender edits or revokes a message. Group admins can revoke the messages of others too, as the storage
+// cannot tell who the admins are only the revokes of our own device are trusted for them. Shared with the PostgreSQL
+// repository.
+func applyProtocolMessage(repo domainChatStorage.IChatStorageRepository, evt *events.Message) (bool, error) {
+	protocolMessage := evt.Message.GetProtocolMessage()
+	messageID := protocolMessage.GetKey().GetID()
+	if protocolMessage == nil || messageID == "" {
+		return false, nil
+	}
+	chatJID := evt.Info.Chat.String()
+
+	switch protocolMessage.GetType() {
+	case waE2E.ProtocolMessage_MESSAGE_EDIT:
+		content := utils.ExtractMessageTextFromProto(protocolMessage.GetEditedMessage())
+		if content == "" {
+			return true, nil
+		}
+		message, err := repo.GetMessageByID(messageID)
+		if errors.Is(err, sql.ErrNoRows) || message == nil || message.ChatJID != chatJID {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		if !sentBy(message, evt.Info.MessageSource) {
+			return true, fmt.Errorf("edit of message %s by %s who did not send it", messageID, evt.Info.Sender)
+		}
+
+		editedAt := evt.Info.Timestamp
+		if ms := protocolMessage.GetTimestampMS(); ms > 0 {
+			editedAt = time.UnixMilli(ms)
+		}
+		return true, repo.EditMessage(messageID, chatJID, content, editedAt)
+	case waE2E.ProtocolMessage_REVOKE:
+		message, err := repo.GetMessageByID(messageID)
+		if errors.Is(err, sql.ErrNoRows) || message == nil || message.ChatJID != chatJID {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		if !evt.Info.IsFromMe && !sentBy(message, evt.Info.MessageSource) {
+			return true, fmt.Errorf("revoke of message %s by %s who did not send it", messageID, evt.Info.Sender)
+		}
+		return true, repo.RevokeMessage(messageID, chatJID, evt.Info.Timestamp)
+	default:
+		return false, nil
+	}
+}
+
+// sentBy reports whether the message was sent by the sender of an event. The sender may be addressed by phone number
+// or by LID, either one matches the stored sender.
+func sentBy(message *domainChatStorage.Message, source types.MessageSource) bool {
+	if message.IsFromMe || source.IsFromMe {
+		return message.IsFromMe == source.IsFromMe
+	}
+
+	sender, err := types.ParseJID(message.Sender)
+	if err != nil {
+		return false
+	}
+	sender = sender.ToNonAD()
+	for _, jid := range []types.JID{source.Sender, source.SenderAlt} {
+		if !jid.IsEmpty() && jid.ToNonAD() == sender {
+			return true
+		}
+	}
+	return false
+}
+
 // GetStorageStatistics returns current storage statistics for logging purposes
 func (r *SQLiteRepository) GetStorageStatistics() (chatCount int64, messageCount int64, err error) {
 	// Count all chats using efficient query
@@ -824,5 +1402,395 @@ func (r *SQLiteRepository) getMigrations() []string {
 
         CREATE INDEX IF NOT EXISTS idx_app_users_enabled ON app_users(enabled);
         `,
+
+		// Migration 4: Add webhook_deliveries table as the durable webhook outbox
+		`
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT NOT NULL,
+			event TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER DEFAULT 0,
+			last_error TEXT DEFAULT '',
+			next_attempt_at TIMESTAMP NOT NULL,
+			retry_until TIMESTAMP NOT NULL,
+			delivered_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries(created_at);
+		`,
+
+		// Migration 5: Add webhook_endpoints table and link deliveries to their endpoint
+		`
+		CREATE TABLE IF NOT EXISTS webhook_endpoints (
+			id TEXT PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL DEFAULT '',
+			events TEXT NOT NULL DEFAULT '[]',
+			allow_chats TEXT NOT NULL DEFAULT '[]',
+			deny_chats TEXT NOT NULL DEFAULT '[]',
+			enabled BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		ALTER TABLE webhook_deliveries ADD COLUMN endpoint_id TEXT NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id);
+		`,
+
+		// Migration 6: Keep the previous secret of a webhook endpoint during a rotation
+		`
+		ALTER TABLE webhook_endpoints ADD COLUMN previous_secret TEXT NOT NULL DEFAULT '';
+		ALTER TABLE webhook_endpoints ADD COLUMN previous_secret_expires_at TIMESTAMP;
+		`,
+
+		// Migration 7: Payload version sent to a webhook endpoint
+		`
+		ALTER TABLE webhook_endpoints ADD COLUMN payload_version INTEGER NOT NULL DEFAULT 1;
+		`,
+
+		// Migration 8: Add app_api_keys table for bearer authentication of the app_users
+		`
+		CREATE TABLE IF NOT EXISTS app_api_keys (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			username TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			prefix TEXT NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			expires_at TIMESTAMP,
+			last_used_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_app_api_keys_username ON app_api_keys(username);
+		`,
+
+		// Migration 9: Add audit_log table recording state-changing REST and MCP calls
+		`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			actor TEXT NOT NULL DEFAULT '',
+			api_key_id TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL,
+			action TEXT NOT NULL,
+			device_id TEXT NOT NULL DEFAULT '',
+			target TEXT NOT NULL DEFAULT '',
+			summary TEXT NOT NULL DEFAULT '',
+			result TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			message_id TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, created_at);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target, created_at);
+		`,
+
+		// Migration 10: Add rate limit buckets and send quota usage
+		`
+		CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			subject TEXT NOT NULL,
+			class TEXT NOT NULL,
+			tokens REAL NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (subject, class)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
+
+		CREATE TABLE IF NOT EXISTS send_quota_usage (
+			subject TEXT NOT NULL,
+			period TEXT NOT NULL,
+			count INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (subject, period)
+		);
+		`,
+
+		// Migration 11: Add the send queue
+		`
+		CREATE TABLE IF NOT EXISTS send_jobs (
+			id TEXT PRIMARY KEY,
+			device_id TEXT NOT NULL DEFAULT '',
+			actor TEXT NOT NULL DEFAULT '',
+			idempotency_key TEXT,
+			recipient TEXT NOT NULL,
+			message BLOB NOT NULL,
+			content TEXT NOT NULL DEFAULT '',
+			message_id TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'queued',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			next_attempt_at TIMESTAMP NOT NULL,
+			sent_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_send_jobs_idempotency ON send_jobs(device_id, actor, idempotency_key);
+		CREATE INDEX IF NOT EXISTS idx_send_jobs_due ON send_jobs(status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS idx_send_jobs_created ON send_jobs(created_at);
+		`,
+
+		// Migration 12: Add scheduled messages to the send queue
+		`
+		ALTER TABLE send_jobs ADD COLUMN scheduled_at TIMESTAMP;
+		CREATE INDEX IF NOT EXISTS idx_send_jobs_scheduled ON send_jobs(scheduled_at);
+		`,
+
+		// Migration 13: Add broadcast campaigns
+		`
+		CREATE TABLE IF NOT EXISTS campaigns (
+			id TEXT PRIMARY KEY,
+			device_id TEXT NOT NULL DEFAULT '',
+			actor TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL,
+			message TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_url TEXT NOT NULL DEFAULT '',
+			rate TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'draft',
+			recipients INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			started_at TIMESTAMP,
+			completed_at TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status);
+		CREATE INDEX IF NOT EXISTS idx_campaigns_created ON campaigns(created_at);
+
+		CREATE TABLE IF NOT EXISTS campaign_recipients (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			campaign_id TEXT NOT NULL,
+			phone TEXT NOT NULL,
+			variables TEXT NOT NULL DEFAULT '{}',
+			status TEXT NOT NULL DEFAULT 'pending',
+			message_id TEXT NOT NULL DEFAULT '',
+			job_id TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			sent_at TIMESTAMP,
+			delivered_at TIMESTAMP,
+			read_at TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL,
+			UNIQUE (campaign_id, phone),
+			FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_campaign_recipients_status ON campaign_recipients(campaign_id, status);
+		CREATE INDEX IF NOT EXISTS idx_campaign_recipients_message ON campaign_recipients(message_id);
+		`,
+
+		// Migration 14: Add message templates and their versions
+		`
+		CREATE TABLE IF NOT EXISTS message_templates (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_url TEXT NOT NULL DEFAULT '',
+			defaults TEXT NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL DEFAULT 1,
+			actor TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS message_template_versions (
+			template_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			body TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_url TEXT NOT NULL DEFAULT '',
+			defaults TEXT NOT NULL DEFAULT '{}',
+			actor TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (template_id, version),
+			FOREIGN KEY (template_id) REFERENCES message_templates(id) ON DELETE CASCADE
+		);
+		`,
+
+		// Migration 15: Add auto-reply rules
+		`
+		CREATE TABLE IF NOT EXISTS auto_reply_rules (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			priority INTEGER NOT NULL DEFAULT 0,
+			device_id TEXT NOT NULL DEFAULT '',
+			conditions TEXT NOT NULL DEFAULT '{}',
+			actions TEXT NOT NULL DEFAULT '[]',
+			cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+			continue_matching BOOLEAN NOT NULL DEFAULT FALSE,
+			actor TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+		`,
+
+		// Migration 16: Keep the message a webhook delivery is about, so the response can act on its chat
+		`
+		ALTER TABLE webhook_deliveries ADD COLUMN origin_device TEXT NOT NULL DEFAULT '';
+		ALTER TABLE webhook_deliveries ADD COLUMN origin_chat_jid TEXT NOT NULL DEFAULT '';
+		ALTER TABLE webhook_deliveries ADD COLUMN origin_sender_jid TEXT NOT NULL DEFAULT '';
+		ALTER TABLE webhook_deliveries ADD COLUMN origin_message_id TEXT NOT NULL DEFAULT '';
+		`,
+
+		// Migration 17: Keep the streamed events, so websocket clients can catch up on the ones they missed
+		`
+		CREATE TABLE IF NOT EXISTS event_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			device_id TEXT NOT NULL DEFAULT '',
+			type TEXT NOT NULL,
+			event TEXT NOT NULL,
+			chat_jid TEXT NOT NULL DEFAULT '',
+			payload TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_event_log_created ON event_log(created_at);
+		`,
+
+		// Migration 18: Keep the receipts of the messages, one per participant of a group message
+		`
+		CREATE TABLE IF NOT EXISTS message_receipts (
+			message_id TEXT NOT NULL,
+			chat_jid TEXT NOT NULL,
+			participant TEXT NOT NULL,
+			delivered_at TIMESTAMP,
+			read_at TIMESTAMP,
+			played_at TIMESTAMP,
+			PRIMARY KEY (message_id, chat_jid, participant)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_message_receipts_chat ON message_receipts(chat_jid);
+		`,
+
+		// Migration 19: Keep the reactions to the messages, one per sender
+		`
+		CREATE TABLE IF NOT EXISTS message_reactions (
+			message_id TEXT NOT NULL,
+			chat_jid TEXT NOT NULL,
+			sender TEXT NOT NULL,
+			emoji TEXT NOT NULL DEFAULT '',
+			is_from_me BOOLEAN NOT NULL DEFAULT FALSE,
+			timestamp TIMESTAMP NOT NULL,
+			PRIMARY KEY (message_id, chat_jid, sender)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_message_reactions_chat ON message_reactions(chat_jid);
+		`,
+
+		// Migration 20: Keep the edits and revokes of the messages
+		`
+		ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP;
+		ALTER TABLE messages ADD COLUMN revoked_at TIMESTAMP;
+
+		CREATE TABLE IF NOT EXISTS message_edits (
+			message_id TEXT NOT NULL,
+			chat_jid TEXT NOT NULL,
+			content TEXT NOT NULL DEFAULT '',
+			edited_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (message_id, chat_jid, edited_at)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_message_edits_chat ON message_edits(chat_jid);
+		`,
+
+		// Migration 21: Keep the polls with their secrets and the latest vote of every voter
+		`
+		CREATE TABLE IF NOT EXISTS polls (
+			message_id TEXT NOT NULL,
+			chat_jid TEXT NOT NULL,
+			sender TEXT NOT NULL,
+			question TEXT NOT NULL DEFAULT '',
+			options TEXT NOT NULL DEFAULT '[]',
+			selectable_count INTEGER NOT NULL DEFAULT 0,
+			secret BLOB,
+			timestamp TIMESTAMP NOT NULL,
+			PRIMARY KEY (message_id, chat_jid)
+		);
+
+		CREATE TABLE IF NOT EXISTS poll_votes (
+			poll_id TEXT NOT NULL,
+			chat_jid TEXT NOT NULL,
+			voter TEXT NOT NULL,
+			options TEXT NOT NULL DEFAULT '[]',
+			timestamp TIMESTAMP NOT NULL,
+			PRIMARY KEY (poll_id, chat_jid, voter)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_poll_votes_chat ON poll_votes(chat_jid);
+		`,
+
+		// Migration 22: Keep the tokens the additional sessions were created with
+		`
+		CREATE TABLE IF NOT EXISTS device_sessions (
+			device_jid TEXT PRIMARY KEY,
+			token TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		);
+		`,
+
+		// Migration 23: Lease claimed webhook deliveries in their own column, so expediting them does not hand them out twice
+		`
+		ALTER TABLE webhook_deliveries ADD COLUMN claimed_until TIMESTAMP;
+		`,
+
+		// Migration 24: Soft-delete templates and keep their versions, the name only has to be unique among the
+		// templates that are not deleted. SQLite cannot drop the UNIQUE constraint, so both tables are rebuilt; the
+		// versions are moved aside first, so dropping the old templates cannot cascade to them.
+		`
+		CREATE TABLE message_templates_new (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_url TEXT NOT NULL DEFAULT '',
+			defaults TEXT NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL DEFAULT 1,
+			actor TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			deleted_at TIMESTAMP
+		);
+		INSERT INTO message_templates_new (id, name, description, body, media_type, media_url, defaults, version, actor, created_at, updated_at)
+		SELECT id, name, description, body, media_type, media_url, defaults, version, actor, created_at, updated_at FROM message_templates;
+		CREATE TABLE message_template_versions_old AS SELECT * FROM message_template_versions;
+
+		DROP TABLE message_template_versions;
+		DROP TABLE message_templates;
+		ALTER TABLE message_templates_new RENAME TO message_templates;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_name ON message_templates(name) WHERE deleted_at IS NULL;
+
+		CREATE TABLE message_template_versions (
+			template_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			body TEXT NOT NULL DEFAULT '',
+			media_type TEXT NOT NULL DEFAULT '',
+			media_url TEXT NOT NULL DEFAULT '',
+			defaults TEXT NOT NULL DEFAULT '{}',
+			actor TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (template_id, version),
+			FOREIGN KEY (template_id) REFERENCES message_templates(id) ON DELETE CASCADE
+		);
+		INSERT INTO message_template_versions (template_id, version, body, media_type, media_url, defaults, actor, created_at)
+		SELECT template_id, version, body, media_type, media_url, defaults, actor, created_at FROM message_template_versions_old;
+		DROP TABLE message_template_versions_old;
+		`,
+
+		// Migration 25: Count the response actions that ran for a webhook delivery, so a replay does not run them again
+		`
+		ALTER TABLE webhook_deliveries ADD COLUMN response_actions INTEGER NOT NULL DEFAULT 0;
+		`,
     }
 }
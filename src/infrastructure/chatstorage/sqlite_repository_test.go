@@ -0,0 +1,367 @@
+package chatstorage_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// openSQLite returns a migrated chat storage database in a temporary file
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "chat.db")+"?_journal_mode=WAL")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, chatstorage.NewStorageRepository(db).InitializeSchema())
+	return db
+}
+
+var (
+	groupJID   = types.NewJID("120363000000000001", types.GroupServer)
+	alicePN    = types.NewJID("628111111111", types.DefaultUserServer)
+	aliceLID   = types.NewJID("100000000000001", types.HiddenUserServer)
+	bobPN      = types.NewJID("628222222222", types.DefaultUserServer)
+	sentAt     = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	originalID = "3EB0000000000000000001"
+)
+
+func storeOriginal(t *testing.T, repo domainChatStorage.IChatStorageRepository) {
+	t.Helper()
+	require.NoError(t, repo.StoreMessage(&domainChatStorage.Message{
+		ID:        originalID,
+		ChatJID:   groupJID.String(),
+		Sender:    alicePN.String(),
+		Content:   "original",
+		Timestamp: sentAt,
+	}))
+}
+
+func getMessage(t *testing.T, repo domainChatStorage.IChatStorageRepository) *domainChatStorage.Message {
+	t.Helper()
+	message, err := repo.GetMessageByID(originalID)
+	require.NoError(t, err)
+	require.NotNil(t, message)
+	return message
+}
+
+func editContents(t *testing.T, repo domainChatStorage.IChatStorageRepository) []string {
+	t.Helper()
+	edits, err := repo.GetMessageEdits(groupJID.String(), originalID)
+	require.NoError(t, err)
+	contents := make([]string, 0, len(edits))
+	for _, edit := range edits {
+		contents = append(contents, edit.Content)
+	}
+	return contents
+}
+
+func TestSQLiteEditMessage(t *testing.T) {
+	repo := chatstorage.NewStorageRepository(openSQLite(t))
+	storeOriginal(t, repo)
+
+	require.NoError(t, repo.EditMessage(originalID, groupJID.String(), "first edit", sentAt.Add(time.Minute)))
+	require.NoError(t, repo.EditMessage(originalID, groupJID.String(), "second edit", sentAt.Add(2*time.Minute)))
+	// An edit older than the last one arrives late and is ignored
+	require.NoError(t, repo.EditMessage(originalID, groupJID.String(), "stale edit", sentAt.Add(90*time.Second)))
+
+	message := getMessage(t, repo)
+	assert.Equal(t, "second edit", message.Content)
+	require.NotNil(t, message.EditedAt)
+	assert.True(t, message.EditedAt.Equal(sentAt.Add(2*time.Minute)))
+	assert.Equal(t, []string{"original", "first edit"}, editContents(t, repo))
+}
+
+func TestSQLiteRevokeMessage(t *testing.T) {
+	repo := chatstorage.NewStorageRepository(openSQLite(t))
+	storeOriginal(t, repo)
+
+	require.NoError(t, repo.RevokeMessage(originalID, groupJID.String(), sentAt.Add(time.Minute)))
+	require.NoError(t, repo.RevokeMessage(originalID, groupJID.String(), sentAt.Add(time.Hour)))
+
+	message := getMessage(t, repo)
+	require.NotNil(t, message.RevokedAt)
+	assert.True(t, message.RevokedAt.Equal(sentAt.Add(time.Minute)))
+	assert.Equal(t, "original", message.Content)
+}
+
+// protocolEvent returns a protocol message about the original message, sent in the group
+func protocolEvent(source types.MessageSource, protocolMessage *waE2E.ProtocolMessage, at time.Time) *events.Message {
+	protocolMessage.Key = &waCommon.MessageKey{ID: proto.String(originalID)}
+	source.Chat, source.IsGroup = groupJID, true
+	return &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: source,
+			ID:            "3EB0000000000000000099",
+			Timestamp:     at,
+		},
+		Message: &waE2E.Message{ProtocolMessage: protocolMessage},
+	}
+}
+
+func editEvent(source types.MessageSource, content string, at time.Time) *events.Message {
+	return protocolEvent(source, &waE2E.ProtocolMessage{
+		Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+		EditedMessage: &waE2E.Message{Conversation: proto.String(content)},
+		TimestampMS:   proto.Int64(at.UnixMilli()),
+	}, at)
+}
+
+func revokeEvent(source types.MessageSource, at time.Time) *events.Message {
+	return protocolEvent(source, &waE2E.ProtocolMessage{Type: waE2E.ProtocolMessage_REVOKE.Enum()}, at)
+}
+
+func TestSQLiteCreateMessageRevokeBySender(t *testing.T) {
+	repo := chatstorage.NewStorageRepository(openSQLite(t))
+	storeOriginal(t, repo)
+
+	alice := types.MessageSource{Sender: aliceLID, SenderAlt: alicePN, AddressingMode: types.AddressingModeLID}
+	require.NoError(t, repo.CreateMessage(context.Background(), revokeEvent(alice, sentAt.Add(time.Minute))))
+	assert.NotNil(t, getMessage(t, repo).RevokedAt)
+}
+
+func TestSQLiteCreateMessageProtocol(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewStorageRepository(openSQLite(t))
+	storeOriginal(t, repo)
+
+	// Another participant cannot edit the message
+	bob := types.MessageSource{Sender: bobPN}
+	assert.Error(t, repo.CreateMessage(ctx, editEvent(bob, "edited by bob", sentAt.Add(time.Minute))))
+	assert.Equal(t, "original", getMessage(t, repo).Content)
+
+	// The sender can, also when the edit addresses them by LID from another device
+	alice := types.MessageSource{
+		Sender:         types.JID{User: aliceLID.User, Device: 2, Server: types.HiddenUserServer},
+		SenderAlt:      alicePN,
+		AddressingMode: types.AddressingModeLID,
+	}
+	require.NoError(t, repo.CreateMessage(ctx, editEvent(alice, "edited by alice", sentAt.Add(2*time.Minute))))
+	assert.Equal(t, "edited by alice", getMessage(t, repo).Content)
+	assert.Equal(t, []string{"original"}, editContents(t, repo))
+
+	// Another participant cannot revoke the message, our own device revokes as a group admin
+	assert.Error(t, repo.CreateMessage(ctx, revokeEvent(bob, sentAt.Add(3*time.Minute))))
+	assert.Nil(t, getMessage(t, repo).RevokedAt)
+
+	own := types.MessageSource{Sender: types.NewJID("628333333333", types.DefaultUserServer), IsFromMe: true}
+	require.NoError(t, repo.CreateMessage(ctx, revokeEvent(own, sentAt.Add(4*time.Minute))))
+	message := getMessage(t, repo)
+	require.NotNil(t, message.RevokedAt)
+	assert.True(t, message.RevokedAt.Equal(sentAt.Add(4*time.Minute)))
+
+	// The protocol messages are not stored as messages of their own
+	count, err := repo.GetChatMessageCount(groupJID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestSQLiteSessionTokens(t *testing.T) {
+	repo := chatstorage.NewStorageRepository(openSQLite(t))
+	device := "628111111111:3@s.whatsapp.net"
+
+	require.NoError(t, repo.StoreSessionToken(device, "4b1c2d3e-first"))
+	require.NoError(t, repo.StoreSessionToken(device, "4b1c2d3e-second"))
+	require.NoError(t, repo.StoreSessionToken("628222222222:5@s.whatsapp.net", "removed"))
+	require.NoError(t, repo.DeleteSessionToken("removed"))
+
+	tokens, err := repo.GetSessionTokens()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{device: "4b1c2d3e-second"}, tokens)
+}
+
+func TestSQLiteStoreReceipts(t *testing.T) {
+	secondID := "3EB0000000000000000002"
+	at := func(minutes int) *time.Time {
+		value := sentAt.Add(time.Duration(minutes) * time.Minute)
+		return &value
+	}
+	receipt := func(messageID string, participant types.JID, delivered, read, played *time.Time) *domainChatStorage.Receipt {
+		return &domainChatStorage.Receipt{
+			MessageID: messageID, ChatJID: groupJID.String(), Participant: participant.String(),
+			DeliveredAt: delivered, ReadAt: read, PlayedAt: played,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		batches       [][]*domainChatStorage.Receipt
+		wantStates    map[string]string // delivery state per message
+		wantReceipts  int
+		wantDelivered *time.Time // delivered time of the receipt of bob for the original message
+	}{
+		{
+			name:          "read without a delivered receipt",
+			batches:       [][]*domainChatStorage.Receipt{{receipt(originalID, bobPN, nil, at(2), nil)}},
+			wantStates:    map[string]string{originalID: domainChatStorage.DeliveryRead, secondID: domainChatStorage.DeliverySent},
+			wantReceipts:  1,
+			wantDelivered: nil,
+		},
+		{
+			name: "upsert keeps the first time of every state",
+			batches: [][]*domainChatStorage.Receipt{
+				{receipt(originalID, bobPN, at(1), nil, nil)},
+				{receipt(originalID, bobPN, at(3), at(4), nil)},
+			},
+			wantStates:    map[string]string{originalID: domainChatStorage.DeliveryRead, secondID: domainChatStorage.DeliverySent},
+			wantReceipts:  1,
+			wantDelivered: at(1),
+		},
+		{
+			name: "a late delivered receipt fills in without undoing the read",
+			batches: [][]*domainChatStorage.Receipt{
+				{receipt(originalID, bobPN, nil, at(2), nil)},
+				{receipt(originalID, bobPN, at(3), nil, nil)},
+			},
+			wantStates:    map[string]string{originalID: domainChatStorage.DeliveryRead, secondID: domainChatStorage.DeliverySent},
+			wantReceipts:  1,
+			wantDelivered: at(3),
+		},
+		{
+			name: "every message gets the furthest state of its participants",
+			batches: [][]*domainChatStorage.Receipt{{
+				receipt(originalID, bobPN, at(1), nil, nil),
+				receipt(originalID, alicePN, at(1), at(2), at(3)),
+				receipt(secondID, bobPN, at(1), nil, nil),
+			}},
+			wantStates:    map[string]string{originalID: domainChatStorage.DeliveryPlayed, secondID: domainChatStorage.DeliveryDelivered},
+			wantReceipts:  3,
+			wantDelivered: at(1),
+		},
+		{
+			name: "receipts of other chats and messages are not returned",
+			batches: [][]*domainChatStorage.Receipt{{
+				{MessageID: originalID, ChatJID: bobPN.String(), Participant: bobPN.String(), ReadAt: at(2)},
+				receipt("3EB0000000000000000003", bobPN, at(1), at(2), nil),
+			}},
+			wantStates: map[string]string{originalID: domainChatStorage.DeliverySent, secondID: domainChatStorage.DeliverySent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := chatstorage.NewStorageRepository(openSQLite(t))
+			for _, batch := range tt.batches {
+				require.NoError(t, repo.StoreReceipts(batch))
+			}
+
+			receipts, err := repo.GetReceipts(groupJID.String(), originalID, secondID)
+			require.NoError(t, err)
+			assert.Len(t, receipts, tt.wantReceipts)
+
+			byMessage := make(map[string][]*domainChatStorage.Receipt)
+			var bobDelivered *time.Time
+			for _, receipt := range receipts {
+				byMessage[receipt.MessageID] = append(byMessage[receipt.MessageID], receipt)
+				if receipt.MessageID == originalID && receipt.Participant == bobPN.String() {
+					bobDelivered = receipt.DeliveredAt
+				}
+			}
+			states := make(map[string]string)
+			for _, id := range []string{originalID, secondID} {
+				states[id] = domainChatStorage.DeliveryState(byMessage[id])
+			}
+			assert.Equal(t, tt.wantStates, states)
+
+			if tt.wantDelivered == nil {
+				assert.Nil(t, bobDelivered)
+			} else {
+				require.NotNil(t, bobDelivered)
+				assert.True(t, bobDelivered.Equal(*tt.wantDelivered))
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreReactions(t *testing.T) {
+	secondID := "3EB0000000000000000002"
+	reaction := func(messageID string, sender types.JID, emoji string, minutes int) *domainChatStorage.Reaction {
+		return &domainChatStorage.Reaction{
+			MessageID: messageID, ChatJID: groupJID.String(), Sender: sender.String(), Emoji: emoji,
+			Timestamp: sentAt.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		batches [][]*domainChatStorage.Reaction
+		want    map[string][]string // "sender emoji" per message, oldest first
+	}{
+		{
+			name: "a newer reaction of a sender replaces the old one",
+			batches: [][]*domainChatStorage.Reaction{
+				{reaction(originalID, bobPN, "👍", 1)},
+				{reaction(originalID, bobPN, "❤️", 2)},
+			},
+			want: map[string][]string{originalID: {bobPN.String() + " ❤️"}},
+		},
+		{
+			name: "an older reaction arriving late is ignored",
+			batches: [][]*domainChatStorage.Reaction{
+				{reaction(originalID, bobPN, "❤️", 2)},
+				{reaction(originalID, bobPN, "👍", 1)},
+			},
+			want: map[string][]string{originalID: {bobPN.String() + " ❤️"}},
+		},
+		{
+			name: "an empty emoji removes the reaction",
+			batches: [][]*domainChatStorage.Reaction{
+				{reaction(originalID, bobPN, "👍", 1), reaction(originalID, alicePN, "👍", 1)},
+				{reaction(originalID, bobPN, "", 2)},
+			},
+			want: map[string][]string{originalID: {alicePN.String() + " 👍"}},
+		},
+		{
+			name: "a removed reaction does not come back with an older one",
+			batches: [][]*domainChatStorage.Reaction{
+				{reaction(originalID, bobPN, "", 2)},
+				{reaction(originalID, bobPN, "👍", 1)},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "reactions are kept per message and sender",
+			batches: [][]*domainChatStorage.Reaction{{
+				reaction(originalID, alicePN, "👍", 2),
+				reaction(originalID, bobPN, "👍", 1),
+				reaction(secondID, bobPN, "😂", 3),
+				reaction("3EB0000000000000000003", bobPN, "🙏", 3),
+				{MessageID: originalID, ChatJID: bobPN.String(), Sender: bobPN.String(), Emoji: "🔥", Timestamp: sentAt},
+			}},
+			want: map[string][]string{
+				originalID: {bobPN.String() + " 👍", alicePN.String() + " 👍"},
+				secondID:   {bobPN.String() + " 😂"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := chatstorage.NewStorageRepository(openSQLite(t))
+			for _, batch := range tt.batches {
+				require.NoError(t, repo.StoreReactions(batch))
+			}
+
+			reactions, err := repo.GetReactions(groupJID.String(), originalID, secondID)
+			require.NoError(t, err)
+			got := make(map[string][]string)
+			for _, reaction := range reactions {
+				got[reaction.MessageID] = append(got[reaction.MessageID], reaction.Sender+" "+reaction.Emoji)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
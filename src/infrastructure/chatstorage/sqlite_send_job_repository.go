@@ -0,0 +1,301 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+)
+
+const sendJobColumns = `id, device_id, actor, idempotency_key, recipient, message, content, message_id, status, attempts,
+	last_error, next_attempt_at, scheduled_at, sent_at, created_at, updated_at`
+
+// SQLiteSendJobRepository stores the send queue in the SQLite chat storage
+type SQLiteSendJobRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteSendJobRepository creates a send queue repository. The table is created by the chat storage migrations.
+func NewSQLiteSendJobRepository(db *sql.DB) domainSend.ISendJobRepository {
+	return &SQLiteSendJobRepository{db: db}
+}
+
+// EnqueueJob persists a new job, a duplicate idempotency key leaves the table unchanged
+func (r *SQLiteSendJobRepository) EnqueueJob(ctx context.Context, job *domainSend.Job) (bool, error) {
+	now := time.Now().UTC()
+	if job.Status == "" {
+		job.Status = domainSend.JobStatusQueued
+	}
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = now
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO send_jobs (id, device_id, actor, idempotency_key, recipient, message, content, message_id, status, attempts, last_error, next_attempt_at, scheduled_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, job.ID, job.DeviceID, job.Actor, nullIfEmpty(job.IdempotencyKey), job.Recipient, job.Message, job.Content, job.MessageID,
+		job.Status, job.Attempts, job.LastError, job.NextAttemptAt.UTC(), utcOrNil(job.ScheduledAt), job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetJob retrieves a job by ID, nil when it does not exist
+func (r *SQLiteSendJobRepository) GetJob(ctx context.Context, id string) (*domainSend.Job, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sendJobColumns+" FROM send_jobs WHERE id = ?", id)
+	return scanSendJobRow(row)
+}
+
+// GetJobByIdempotencyKey retrieves the job a device and actor enqueued with the key, nil when there is none
+func (r *SQLiteSendJobRepository) GetJobByIdempotencyKey(ctx context.Context, deviceID, actor, key string) (*domainSend.Job, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sendJobColumns+" FROM send_jobs WHERE device_id = ? AND actor = ? AND idempotency_key = ?",
+		deviceID, actor, key)
+	return scanSendJobRow(row)
+}
+
+// GetJobs retrieves jobs with filtering, newest first
+func (r *SQLiteSendJobRepository) GetJobs(ctx context.Context, filter *domainSend.JobFilter) ([]*domainSend.Job, error) {
+	where, args := sqliteSendJobConditions(filter)
+	query := "SELECT " + sendJobColumns + " FROM send_jobs" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// CountJobs returns the number of jobs matching the filter
+func (r *SQLiteSendJobRepository) CountJobs(ctx context.Context, filter *domainSend.JobFilter) (int64, error) {
+	where, args := sqliteSendJobConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM send_jobs"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetDueJobs returns queued jobs that are due, oldest first
+func (r *SQLiteSendJobRepository) GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*domainSend.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sendJobColumns+`
+		FROM send_jobs
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY created_at ASC, id ASC
+		LIMIT ?
+	`, domainSend.JobStatusQueued, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// MarkSending claims a queued job for the worker
+func (r *SQLiteSendJobRepository) MarkSending(ctx context.Context, id string) (bool, error) {
+	return r.transition(ctx, id, domainSend.JobStatusQueued, domainSend.JobStatusSending)
+}
+
+// MarkSent records a sent job
+func (r *SQLiteSendJobRepository) MarkSent(ctx context.Context, id string, attempts int, sentAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = ?, attempts = ?, last_error = '', sent_at = ?, updated_at = ?
+		WHERE id = ?
+	`, domainSend.JobStatusSent, attempts, sentAt.UTC(), time.Now().UTC(), id)
+	return err
+}
+
+// MarkRetry records a failed attempt and queues the job again
+func (r *SQLiteSendJobRepository) MarkRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, domainSend.JobStatusQueued, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), id)
+	return err
+}
+
+// MarkFailed records a job that is not sent again
+func (r *SQLiteSendJobRepository) MarkFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = ?, attempts = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, domainSend.JobStatusFailed, attempts, lastError, time.Now().UTC(), id)
+	return err
+}
+
+// FailSendingJobs fails the jobs a previous run left in the sending state
+func (r *SQLiteSendJobRepository) FailSendingJobs(ctx context.Context, lastError string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET status = ?, last_error = ?, updated_at = ?
+		WHERE status = ?
+	`, domainSend.JobStatusFailed, lastError, time.Now().UTC(), domainSend.JobStatusSending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetMissedJobs returns the queued scheduled jobs due before the given time that were never attempted
+func (r *SQLiteSendJobRepository) GetMissedJobs(ctx context.Context, before time.Time) ([]*domainSend.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sendJobColumns+`
+		FROM send_jobs
+		WHERE status = ? AND scheduled_at IS NOT NULL AND scheduled_at < ? AND attempts = 0
+		ORDER BY scheduled_at ASC, id ASC
+	`, domainSend.JobStatusQueued, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSendJobs(rows)
+}
+
+// CancelJob cancels a queued job
+func (r *SQLiteSendJobRepository) CancelJob(ctx context.Context, id string) (bool, error) {
+	return r.transition(ctx, id, domainSend.JobStatusQueued, domainSend.JobStatusCancelled)
+}
+
+// RescheduleJob changes the send time of a queued job
+func (r *SQLiteSendJobRepository) RescheduleJob(ctx context.Context, id string, sendAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE send_jobs
+		SET scheduled_at = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, sendAt.UTC(), sendAt.UTC(), time.Now().UTC(), id, domainSend.JobStatusQueued)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// PurgeFinishedJobsBefore removes sent, failed and cancelled jobs last updated before the given time
+func (r *SQLiteSendJobRepository) PurgeFinishedJobsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM send_jobs WHERE status IN (?, ?, ?) AND updated_at < ?",
+		domainSend.JobStatusSent, domainSend.JobStatusFailed, domainSend.JobStatusCancelled, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// transition changes the status of a job only when it still has the expected status
+func (r *SQLiteSendJobRepository) transition(ctx context.Context, id, from, to string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE send_jobs SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+		to, time.Now().UTC(), id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// sqliteSendJobConditions builds the WHERE clause for a job filter
+func sqliteSendJobConditions(filter *domainSend.JobFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if len(filter.DeviceIDs) > 0 {
+		conditions = append(conditions, "device_id IN ("+sqlitePlaceholders(len(filter.DeviceIDs))+")")
+		for _, deviceID := range filter.DeviceIDs {
+			args = append(args, deviceID)
+		}
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Recipient != "" {
+		conditions = append(conditions, "recipient = ?")
+		args = append(args, filter.Recipient)
+	}
+	if filter.Scheduled {
+		conditions = append(conditions, "scheduled_at IS NOT NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// nullIfEmpty stores an empty string as NULL, so it never collides in a unique index
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func scanSendJobRow(row *sql.Row) (*domainSend.Job, error) {
+	job, err := scanSendJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func scanSendJobs(rows *sql.Rows) ([]*domainSend.Job, error) {
+	var jobs []*domainSend.Job
+	for rows.Next() {
+		job, err := scanSendJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// scanSendJob is a private helper for scanning job rows
+func scanSendJob(scanner interface{ Scan(...any) error }) (*domainSend.Job, error) {
+	job := &domainSend.Job{}
+	var idempotencyKey, lastError sql.NullString
+	var scheduledAt, sentAt sql.NullTime
+
+	err := scanner.Scan(
+		&job.ID, &job.DeviceID, &job.Actor, &idempotencyKey, &job.Recipient, &job.Message, &job.Content, &job.MessageID,
+		&job.Status, &job.Attempts, &lastError, &job.NextAttemptAt, &scheduledAt, &sentAt, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.IdempotencyKey = idempotencyKey.String
+	job.LastError = lastError.String
+	if scheduledAt.Valid {
+		job.ScheduledAt = &scheduledAt.Time
+	}
+	if sentAt.Valid {
+		job.SentAt = &sentAt.Time
+	}
+	return job, nil
+}
@@ -0,0 +1,53 @@
+package chatstorage_test
+
+import (
+	"context"
+	"testing"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteGetJobsByDeviceAndActor(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteSendJobRepository(openSQLite(t))
+
+	jobs := []*domainSend.Job{
+		{ID: "default-alice", DeviceID: "", Actor: "alice"},
+		{ID: "default-by-jid-alice", DeviceID: "628111111111", Actor: "alice"},
+		{ID: "session-alice", DeviceID: "session-token", Actor: "alice"},
+		{ID: "default-bob", DeviceID: "default", Actor: "bob"},
+	}
+	for _, job := range jobs {
+		job.Recipient, job.MessageID, job.Message = bobPN.String(), job.ID, []byte{}
+		created, err := repo.EnqueueJob(ctx, job)
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+
+	ids := func(filter *domainSend.JobFilter) []string {
+		t.Helper()
+		found, err := repo.GetJobs(ctx, filter)
+		require.NoError(t, err)
+		count, err := repo.CountJobs(ctx, filter)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(found), count)
+
+		result := make([]string, 0, len(found))
+		for _, job := range found {
+			result = append(result, job.ID)
+		}
+		return result
+	}
+
+	defaultAliases := []string{"", "default", "628111111111"}
+	assert.ElementsMatch(t, []string{"default-alice", "default-by-jid-alice"},
+		ids(&domainSend.JobFilter{DeviceIDs: defaultAliases, Actor: "alice"}))
+	assert.ElementsMatch(t, []string{"default-alice", "default-by-jid-alice", "default-bob"},
+		ids(&domainSend.JobFilter{DeviceIDs: defaultAliases}))
+	assert.ElementsMatch(t, []string{"session-alice"},
+		ids(&domainSend.JobFilter{DeviceIDs: []string{"session-token"}, Actor: "alice"}))
+	assert.Len(t, ids(&domainSend.JobFilter{}), 4)
+}
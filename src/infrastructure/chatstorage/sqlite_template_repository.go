@@ -0,0 +1,283 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+)
+
+const templateColumns = `id, name, description, body, media_type, media_url, defaults, version, actor, created_at, updated_at`
+
+const templateVersionColumns = `template_id, version, body, media_type, media_url, defaults, actor, created_at`
+
+// SQLiteTemplateRepository stores the message templates and their versions in the SQLite chat storage
+type SQLiteTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTemplateRepository creates a template repository. The tables are created by the chat storage migrations.
+func NewSQLiteTemplateRepository(db *sql.DB) domainTemplate.ITemplateRepository {
+	return &SQLiteTemplateRepository{db: db}
+}
+
+// CreateTemplate persists a template together with its first version
+func (r *SQLiteTemplateRepository) CreateTemplate(ctx context.Context, template *domainTemplate.Template) error {
+	defaults, err := encodeTemplateDefaults(template.Defaults)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	template.Version = 1
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO message_templates (id, name, description, body, media_type, media_url, defaults, version, actor, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, template.ID, template.Name, template.Description, template.Body, template.MediaType, template.MediaURL, defaults,
+		template.Version, template.Actor, template.CreatedAt, template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertVersion(ctx, tx, template, defaults); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateTemplate stores the content of a template that is still at expectedVersion as its next version
+func (r *SQLiteTemplateRepository) UpdateTemplate(ctx context.Context, template *domainTemplate.Template, expectedVersion int) (bool, error) {
+	defaults, err := encodeTemplateDefaults(template.Defaults)
+	if err != nil {
+		return false, err
+	}
+
+	template.Version = expectedVersion + 1
+	template.UpdatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE message_templates
+		SET name = ?, description = ?, body = ?, media_type = ?, media_url = ?, defaults = ?, version = ?, actor = ?, updated_at = ?
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`, template.Name, template.Description, template.Body, template.MediaType, template.MediaURL, defaults,
+		template.Version, template.Actor, template.UpdatedAt, template.ID, expectedVersion)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := r.insertVersion(ctx, tx, template, defaults); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// DeleteTemplate marks a template as deleted and keeps its versions, so the messages sent with it can still be
+// traced back to their text. It returns false when the template does not exist.
+func (r *SQLiteTemplateRepository) DeleteTemplate(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE message_templates SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetTemplate retrieves a template by ID, nil when it does not exist or was deleted
+func (r *SQLiteTemplateRepository) GetTemplate(ctx context.Context, id string) (*domainTemplate.Template, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateColumns+" FROM message_templates WHERE id = ? AND deleted_at IS NULL", id)
+	template, err := scanTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// GetTemplateByName retrieves a template by its unique name, nil when it does not exist or was deleted
+func (r *SQLiteTemplateRepository) GetTemplateByName(ctx context.Context, name string) (*domainTemplate.Template, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateColumns+" FROM message_templates WHERE name = ? AND deleted_at IS NULL", name)
+	template, err := scanTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return template, err
+}
+
+// GetTemplates retrieves templates with filtering, by name
+func (r *SQLiteTemplateRepository) GetTemplates(ctx context.Context, filter *domainTemplate.TemplateFilter) ([]*domainTemplate.Template, error) {
+	where, args := sqliteTemplateConditions(filter)
+	query := "SELECT " + templateColumns + " FROM message_templates" + where + " ORDER BY name ASC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTemplates(rows)
+}
+
+// CountTemplates returns the number of templates matching the filter
+func (r *SQLiteTemplateRepository) CountTemplates(ctx context.Context, filter *domainTemplate.TemplateFilter) (int64, error) {
+	where, args := sqliteTemplateConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM message_templates"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetVersions retrieves the versions of a template, newest first, also of a deleted template
+func (r *SQLiteTemplateRepository) GetVersions(ctx context.Context, templateID string) ([]*domainTemplate.Version, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+templateVersionColumns+" FROM message_template_versions WHERE template_id = ? ORDER BY version DESC", templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTemplateVersions(rows)
+}
+
+// GetVersion retrieves one version of a template, nil when it does not exist
+func (r *SQLiteTemplateRepository) GetVersion(ctx context.Context, templateID string, version int) (*domainTemplate.Version, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+templateVersionColumns+" FROM message_template_versions WHERE template_id = ? AND version = ?", templateID, version)
+	templateVersion, err := scanTemplateVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return templateVersion, err
+}
+
+func (r *SQLiteTemplateRepository) insertVersion(ctx context.Context, tx *sql.Tx, template *domainTemplate.Template, defaults string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO message_template_versions (template_id, version, body, media_type, media_url, defaults, actor, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, template.ID, template.Version, template.Body, template.MediaType, template.MediaURL, defaults, template.Actor, template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store version %d of template %s: %w", template.Version, template.ID, err)
+	}
+	return nil
+}
+
+// sqliteTemplateConditions builds the WHERE clause for a template filter
+func sqliteTemplateConditions(filter *domainTemplate.TemplateFilter) (string, []any) {
+	if filter.Search == "" {
+		return " WHERE deleted_at IS NULL", nil
+	}
+	search := "%" + filter.Search + "%"
+	return " WHERE deleted_at IS NULL AND (name LIKE ? OR description LIKE ?)", []any{search, search}
+}
+
+// encodeTemplateDefaults serializes the default variables of a template as a JSON object
+func encodeTemplateDefaults(defaults map[string]any) (string, error) {
+	if defaults == nil {
+		defaults = map[string]any{}
+	}
+	data, err := json.Marshal(defaults)
+	return string(data), err
+}
+
+// decodeTemplateDefaults reads the default variables of a template, nil when it has none
+func decodeTemplateDefaults(data string) (map[string]any, error) {
+	var defaults map[string]any
+	if err := json.Unmarshal([]byte(data), &defaults); err != nil {
+		return nil, err
+	}
+	if len(defaults) == 0 {
+		return nil, nil
+	}
+	return defaults, nil
+}
+
+func scanTemplates(rows *sql.Rows) ([]*domainTemplate.Template, error) {
+	var templates []*domainTemplate.Template
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// scanTemplate is a private helper for scanning template rows
+func scanTemplate(scanner interface{ Scan(...any) error }) (*domainTemplate.Template, error) {
+	template := &domainTemplate.Template{}
+	var defaults string
+
+	err := scanner.Scan(
+		&template.ID, &template.Name, &template.Description, &template.Body, &template.MediaType, &template.MediaURL,
+		&defaults, &template.Version, &template.Actor, &template.CreatedAt, &template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.Defaults, err = decodeTemplateDefaults(defaults); err != nil {
+		return nil, fmt.Errorf("invalid defaults of template %s: %w", template.ID, err)
+	}
+	return template, nil
+}
+
+func scanTemplateVersions(rows *sql.Rows) ([]*domainTemplate.Version, error) {
+	var versions []*domainTemplate.Version
+	for rows.Next() {
+		version, err := scanTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// scanTemplateVersion is a private helper for scanning template version rows
+func scanTemplateVersion(scanner interface{ Scan(...any) error }) (*domainTemplate.Version, error) {
+	version := &domainTemplate.Version{}
+	var defaults string
+
+	err := scanner.Scan(
+		&version.TemplateID, &version.Version, &version.Body, &version.MediaType, &version.MediaURL, &defaults,
+		&version.Actor, &version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if version.Defaults, err = decodeTemplateDefaults(defaults); err != nil {
+		return nil, fmt.Errorf("invalid defaults of version %d of template %s: %w", version.Version, version.TemplateID, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,63 @@
+package chatstorage_test
+
+import (
+	"context"
+	"testing"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteDeleteTemplateKeepsVersions(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteTemplateRepository(openSQLite(t))
+
+	template := &domainTemplate.Template{ID: "welcome-1", Name: "welcome", Body: "Hi {{.name}}"}
+	require.NoError(t, repo.CreateTemplate(ctx, template))
+	template.Body = "Hello {{.name}}"
+	updated, err := repo.UpdateTemplate(ctx, template, 1)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	deleted, err := repo.DeleteTemplate(ctx, "welcome-1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	found, err := repo.GetTemplate(ctx, "welcome-1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	found, err = repo.GetTemplateByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	count, err := repo.CountTemplates(ctx, &domainTemplate.TemplateFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// The versions stay, so messages sent with the template can be traced back to their text
+	versions, err := repo.GetVersions(ctx, "welcome-1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Hello {{.name}}", versions[0].Body)
+	version, err := repo.GetVersion(ctx, "welcome-1", 1)
+	require.NoError(t, err)
+	require.NotNil(t, version)
+	assert.Equal(t, "Hi {{.name}}", version.Body)
+
+	// A deleted template is neither deleted nor changed again
+	deleted, err = repo.DeleteTemplate(ctx, "welcome-1")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	updated, err = repo.UpdateTemplate(ctx, template, 2)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	// Its name is free again, but stays unique among the templates that are not deleted
+	require.NoError(t, repo.CreateTemplate(ctx, &domainTemplate.Template{ID: "welcome-2", Name: "welcome", Body: "Hey"}))
+	assert.Error(t, repo.CreateTemplate(ctx, &domainTemplate.Template{ID: "welcome-3", Name: "welcome", Body: "Yo"}))
+	found, err = repo.GetTemplateByName(ctx, "welcome")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "welcome-2", found.ID)
+}
@@ -0,0 +1,227 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+)
+
+const appUserColumns = `username, password_hash, role, enabled, created_at, updated_at`
+
+const apiKeyColumns = `id, name, username, key_hash, prefix, scopes, expires_at, last_used_at, created_at`
+
+// SQLiteUserRepository stores the app_users accounts in the SQLite chat storage
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository creates a user repository. The tables are created by the chat storage migrations.
+func NewSQLiteUserRepository(db *sql.DB) domainAuth.IUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+// GetUser retrieves a user by username
+func (r *SQLiteUserRepository) GetUser(ctx context.Context, username string) (*domainAuth.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+appUserColumns+" FROM app_users WHERE username = ?", username)
+	user, err := scanAppUser(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetUsers retrieves all users ordered by username
+func (r *SQLiteUserRepository) GetUsers(ctx context.Context) ([]*domainAuth.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+appUserColumns+" FROM app_users ORDER BY username ASC")
+	if err != nil {
+		return nil, err
+	}
+	return scanAppUsers(rows)
+}
+
+// CreateUser persists a new user
+func (r *SQLiteUserRepository) CreateUser(ctx context.Context, user *domainAuth.User) error {
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO app_users (username, password_hash, role, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, user.Username, user.PasswordHash, user.Role, user.Enabled, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+// UpdateUser updates the password, role and enabled flag of a user
+func (r *SQLiteUserRepository) UpdateUser(ctx context.Context, user *domainAuth.User) error {
+	user.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE app_users
+		SET password_hash = ?, role = ?, enabled = ?, updated_at = ?
+		WHERE username = ?
+	`, user.PasswordHash, user.Role, user.Enabled, user.UpdatedAt, user.Username)
+	return err
+}
+
+// DeleteUser removes a user together with its API keys
+func (r *SQLiteUserRepository) DeleteUser(ctx context.Context, username string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM app_api_keys WHERE username = ?", username); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM app_users WHERE username = ?", username); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAPIKey retrieves an API key by ID
+func (r *SQLiteUserRepository) GetAPIKey(ctx context.Context, id string) (*domainAuth.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM app_api_keys WHERE id = ?", id)
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of the key
+func (r *SQLiteUserRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domainAuth.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM app_api_keys WHERE key_hash = ?", keyHash)
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// GetAPIKeys retrieves the API keys of a user, or of every user when username is empty, oldest first
+func (r *SQLiteUserRepository) GetAPIKeys(ctx context.Context, username string) ([]*domainAuth.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM app_api_keys
+		WHERE ? = '' OR username = ?
+		ORDER BY created_at ASC
+	`, username, username)
+	if err != nil {
+		return nil, err
+	}
+	return scanAPIKeys(rows)
+}
+
+// CreateAPIKey persists a new API key
+func (r *SQLiteUserRepository) CreateAPIKey(ctx context.Context, key *domainAuth.APIKey) error {
+	key.CreatedAt = time.Now().UTC()
+	scopes, err := encodeScopes(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO app_api_keys (id, name, username, key_hash, prefix, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.ID, key.Name, key.Username, key.KeyHash, key.Prefix, scopes, utcOrNil(key.ExpiresAt), key.CreatedAt)
+	return err
+}
+
+// TouchAPIKey records when an API key was last used
+func (r *SQLiteUserRepository) TouchAPIKey(ctx context.Context, id string, lastUsedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE app_api_keys SET last_used_at = ? WHERE id = ?", lastUsedAt.UTC(), id)
+	return err
+}
+
+// DeleteAPIKey removes an API key
+func (r *SQLiteUserRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM app_api_keys WHERE id = ?", id)
+	return err
+}
+
+func scanAppUsers(rows *sql.Rows) ([]*domainAuth.User, error) {
+	defer rows.Close()
+
+	var users []*domainAuth.User
+	for rows.Next() {
+		user, err := scanAppUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func scanAppUser(scanner interface{ Scan(...any) error }) (*domainAuth.User, error) {
+	var (
+		user      domainAuth.User
+		role      sql.NullString
+		enabled   sql.NullBool
+		createdAt sql.NullTime
+		updatedAt sql.NullTime
+	)
+	if err := scanner.Scan(&user.Username, &user.PasswordHash, &role, &enabled, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	// Rows inserted by hand may leave the columns with defaults empty
+	user.Role = role.String
+	user.Enabled = enabled.Bool
+	user.CreatedAt = createdAt.Time
+	user.UpdatedAt = updatedAt.Time
+	return &user, nil
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]*domainAuth.APIKey, error) {
+	defer rows.Close()
+
+	var keys []*domainAuth.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func scanAPIKey(scanner interface{ Scan(...any) error }) (*domainAuth.APIKey, error) {
+	var (
+		key        domainAuth.APIKey
+		scopes     string
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+	)
+	err := scanner.Scan(&key.ID, &key.Name, &key.Username, &key.KeyHash, &key.Prefix, &scopes, &expiresAt, &lastUsedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes of API key %s: %w", key.ID, err)
+	}
+	return &key, nil
+}
+
+func encodeScopes(scopes []string) (string, error) {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	data, err := json.Marshal(scopes)
+	return string(data), err
+}
@@ -0,0 +1,439 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+const webhookDeliveryColumns = `id, endpoint_id, url, event, payload, status, attempts, last_error,
+	next_attempt_at, retry_until, delivered_at, created_at, updated_at,
+	origin_device, origin_chat_jid, origin_sender_jid, origin_message_id`
+
+// SQLiteWebhookRepository implements the webhook outbox and endpoints on top of the SQLite chat storage
+type SQLiteWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteWebhookRepository creates a webhook repository. The tables are created by the chat storage migrations.
+func NewSQLiteWebhookRepository(db *sql.DB) domainWebhook.IWebhookRepository {
+	return &SQLiteWebhookRepository{db: db}
+}
+
+// EnqueueDelivery persists a new delivery in the outbox
+func (r *SQLiteWebhookRepository) EnqueueDelivery(ctx context.Context, delivery *domainWebhook.Delivery) error {
+	now := time.Now().UTC()
+	if delivery.Status == "" {
+		delivery.Status = domainWebhook.DeliveryStatusPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (endpoint_id, url, event, payload, status, attempts, last_error, next_attempt_at, retry_until, created_at, updated_at,
+			origin_device, origin_chat_jid, origin_sender_jid, origin_message_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, delivery.EndpointID, delivery.URL, delivery.Event, delivery.Payload, delivery.Status, delivery.Attempts, delivery.LastError,
+		delivery.NextAttemptAt.UTC(), delivery.RetryUntil.UTC(), delivery.CreatedAt, delivery.UpdatedAt,
+		delivery.Origin.Device, delivery.Origin.ChatJID, delivery.Origin.SenderJID, delivery.Origin.MessageID)
+	if err != nil {
+		return err
+	}
+
+	delivery.ID, err = result.LastInsertId()
+	return err
+}
+
+// ClaimDueDeliveries returns pending deliveries that are due and leases them so they are not picked up twice
+func (r *SQLiteWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domainWebhook.Delivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ? AND (claimed_until IS NULL OR claimed_until <= ?)
+		ORDER BY next_attempt_at ASC
+		LIMIT ?
+	`, domainWebhook.DeliveryStatusPending, now.UTC(), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*domainWebhook.Delivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leaseUntil := now.Add(lease).UTC()
+	for _, delivery := range deliveries {
+		if _, err := tx.ExecContext(ctx, "UPDATE webhook_deliveries SET claimed_until = ? WHERE id = ?", leaseUntil, delivery.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return deliveries, tx.Commit()
+}
+
+// MarkDelivered records a successful delivery
+func (r *SQLiteWebhookRepository) MarkDelivered(ctx context.Context, id int64, attempts int, deliveredAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_error = '', delivered_at = ?, claimed_until = NULL, updated_at = ?
+		WHERE id = ?
+	`, domainWebhook.DeliveryStatusDelivered, attempts, deliveredAt.UTC(), time.Now().UTC(), id)
+	return err
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (r *SQLiteWebhookRepository) MarkRetry(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = ?, next_attempt_at = ?, last_error = ?, claimed_until = NULL, updated_at = ?
+		WHERE id = ?
+	`, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), id)
+	return err
+}
+
+// MarkFailed moves a delivery to the dead-letter state
+func (r *SQLiteWebhookRepository) MarkFailed(ctx context.Context, id int64, attempts int, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_error = ?, claimed_until = NULL, updated_at = ?
+		WHERE id = ?
+	`, domainWebhook.DeliveryStatusFailed, attempts, lastError, time.Now().UTC(), id)
+	return err
+}
+
+// ExpediteDeliveries makes the postponed deliveries of an endpoint due now, the claimed ones are being attempted
+func (r *SQLiteWebhookRepository) ExpediteDeliveries(ctx context.Context, endpointID string, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?, updated_at = ?
+		WHERE endpoint_id = ? AND status = ? AND next_attempt_at > ? AND (claimed_until IS NULL OR claimed_until <= ?)
+	`, now.UTC(), now.UTC(), endpointID, domainWebhook.DeliveryStatusPending, now.UTC(), now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimResponseActions records that the first count actions of the response to a delivery ran, it returns how
+// many of them already ran before
+func (r *SQLiteWebhookRepository) ClaimResponseActions(ctx context.Context, id int64, count int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ran int
+	if err := tx.QueryRowContext(ctx, "SELECT response_actions FROM webhook_deliveries WHERE id = ?", id).Scan(&ran); err != nil {
+		return 0, err
+	}
+	if ran >= count {
+		return ran, nil
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE webhook_deliveries SET response_actions = ? WHERE id = ?", count, id); err != nil {
+		return 0, err
+	}
+	return ran, tx.Commit()
+}
+
+// GetDelivery retrieves a delivery by ID
+func (r *SQLiteWebhookRepository) GetDelivery(ctx context.Context, id int64) (*domainWebhook.Delivery, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?", id)
+	delivery, err := scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// GetDeliveries retrieves deliveries with filtering, newest first
+func (r *SQLiteWebhookRepository) GetDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, error) {
+	where, args := sqliteDeliveryConditions(filter)
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries" + where + " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domainWebhook.Delivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CountDeliveries returns the number of deliveries matching the filter
+func (r *SQLiteWebhookRepository) CountDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter) (int64, error) {
+	where, args := sqliteDeliveryConditions(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries"+where, args...).Scan(&count)
+	return count, err
+}
+
+// ReplayDelivery schedules a failed or delivered delivery again, it returns false when the delivery is pending
+func (r *SQLiteWebhookRepository) ReplayDelivery(ctx context.Context, id int64, now time.Time, retryUntil time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, last_error = '', next_attempt_at = ?, retry_until = ?, delivered_at = NULL, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, domainWebhook.DeliveryStatusPending, now.UTC(), retryUntil.UTC(), now.UTC(), id,
+		domainWebhook.DeliveryStatusFailed, domainWebhook.DeliveryStatusDelivered)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReplayDeliveries schedules every failed or delivered delivery matching the filter again
+func (r *SQLiteWebhookRepository) ReplayDeliveries(ctx context.Context, filter *domainWebhook.DeliveryFilter, now time.Time, retryUntil time.Time) (int64, error) {
+	where, args := sqliteDeliveryConditions(filter)
+	if where == "" {
+		where = " WHERE status IN (?, ?)"
+	} else {
+		where += " AND status IN (?, ?)"
+	}
+	args = append([]any{domainWebhook.DeliveryStatusPending, now.UTC(), retryUntil.UTC(), now.UTC()}, args...)
+	args = append(args, domainWebhook.DeliveryStatusFailed, domainWebhook.DeliveryStatusDelivered)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, last_error = '', next_attempt_at = ?, retry_until = ?, delivered_at = NULL, updated_at = ?
+	`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeDeliveredBefore removes delivered entries created before the given time
+func (r *SQLiteWebhookRepository) PurgeDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE status = ? AND created_at < ?",
+		domainWebhook.DeliveryStatusDelivered, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sqliteDeliveryConditions builds the WHERE clause for a delivery filter
+func sqliteDeliveryConditions(filter *domainWebhook.DeliveryFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.EndpointID != "" {
+		conditions = append(conditions, "endpoint_id = ?")
+		args = append(args, filter.EndpointID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanWebhookDelivery is a private helper for scanning delivery rows
+func scanWebhookDelivery(scanner interface{ Scan(...any) error }) (*domainWebhook.Delivery, error) {
+	delivery := &domainWebhook.Delivery{}
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+
+	err := scanner.Scan(
+		&delivery.ID, &delivery.EndpointID, &delivery.URL, &delivery.Event, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &lastError, &delivery.NextAttemptAt, &delivery.RetryUntil,
+		&deliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+		&delivery.Origin.Device, &delivery.Origin.ChatJID, &delivery.Origin.SenderJID, &delivery.Origin.MessageID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.LastError = lastError.String
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
+
+// CreateEndpoint stores a new webhook endpoint
+func (r *SQLiteWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domainWebhook.Endpoint) error {
+	events, allowChats, denyChats, err := encodeEndpointLists(endpoint)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	endpoint.CreatedAt = now
+	endpoint.UpdatedAt = now
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO webhook_endpoints (id, url, secret, previous_secret, previous_secret_expires_at, events, allow_chats, deny_chats, payload_version, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, endpoint.ID, endpoint.URL, endpoint.Secret, endpoint.PreviousSecret, utcOrNil(endpoint.PreviousSecretExpiresAt), events, allowChats, denyChats, endpoint.PayloadVersion, endpoint.Enabled, endpoint.CreatedAt, endpoint.UpdatedAt)
+	return err
+}
+
+// UpdateEndpoint replaces the settings of a webhook endpoint
+func (r *SQLiteWebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *domainWebhook.Endpoint) error {
+	events, allowChats, denyChats, err := encodeEndpointLists(endpoint)
+	if err != nil {
+		return err
+	}
+
+	endpoint.UpdatedAt = time.Now().UTC()
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE webhook_endpoints
+		SET url = ?, secret = ?, previous_secret = ?, previous_secret_expires_at = ?, events = ?, allow_chats = ?, deny_chats = ?, payload_version = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, endpoint.URL, endpoint.Secret, endpoint.PreviousSecret, utcOrNil(endpoint.PreviousSecretExpiresAt), events, allowChats, denyChats, endpoint.PayloadVersion, endpoint.Enabled, endpoint.UpdatedAt, endpoint.ID)
+	return err
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (r *SQLiteWebhookRepository) DeleteEndpoint(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM webhook_endpoints WHERE id = ?", id)
+	return err
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID
+func (r *SQLiteWebhookRepository) GetEndpoint(ctx context.Context, id string) (*domainWebhook.Endpoint, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = ?", id)
+	endpoint, err := scanWebhookEndpoint(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return endpoint, err
+}
+
+// GetEndpoints retrieves all webhook endpoints, oldest first
+func (r *SQLiteWebhookRepository) GetEndpoints(ctx context.Context) ([]*domainWebhook.Endpoint, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints ORDER BY created_at ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*domainWebhook.Endpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+const webhookEndpointColumns = `id, url, secret, previous_secret, previous_secret_expires_at, events, allow_chats, deny_chats, payload_version, enabled, created_at, updated_at`
+
+// encodeEndpointLists serializes the list columns of an endpoint as JSON arrays
+func encodeEndpointLists(endpoint *domainWebhook.Endpoint) (events, allowChats, denyChats string, err error) {
+	encode := func(values []string) (string, error) {
+		if values == nil {
+			values = []string{}
+		}
+		data, err := json.Marshal(values)
+		return string(data), err
+	}
+
+	if events, err = encode(endpoint.Events); err != nil {
+		return
+	}
+	if allowChats, err = encode(endpoint.AllowChats); err != nil {
+		return
+	}
+	denyChats, err = encode(endpoint.DenyChats)
+	return
+}
+
+// utcOrNil converts an optional time to UTC, keeping NULL for nil
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// scanWebhookEndpoint is a private helper for scanning endpoint rows
+func scanWebhookEndpoint(scanner interface{ Scan(...any) error }) (*domainWebhook.Endpoint, error) {
+	endpoint := &domainWebhook.Endpoint{}
+	var events, allowChats, denyChats string
+	var previousSecretExpiresAt sql.NullTime
+
+	err := scanner.Scan(
+		&endpoint.ID, &endpoint.URL, &endpoint.Secret, &endpoint.PreviousSecret, &previousSecretExpiresAt, &events, &allowChats, &denyChats,
+		&endpoint.PayloadVersion, &endpoint.Enabled, &endpoint.CreatedAt, &endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if previousSecretExpiresAt.Valid {
+		endpoint.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
+	if err := json.Unmarshal([]byte(events), &endpoint.Events); err != nil {
+		return nil, fmt.Errorf("invalid events of webhook endpoint %s: %w", endpoint.ID, err)
+	}
+	if err := json.Unmarshal([]byte(allowChats), &endpoint.AllowChats); err != nil {
+		return nil, fmt.Errorf("invalid allow_chats of webhook endpoint %s: %w", endpoint.ID, err)
+	}
+	if err := json.Unmarshal([]byte(denyChats), &endpoint.DenyChats); err != nil {
+		return nil, fmt.Errorf("invalid deny_chats of webhook endpoint %s: %w", endpoint.ID, err)
+	}
+	return endpoint, nil
+}
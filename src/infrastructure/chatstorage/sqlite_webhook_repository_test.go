@@ -0,0 +1,131 @@
+package chatstorage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enqueue(t *testing.T, repo domainWebhook.IWebhookRepository, nextAttemptAt time.Time) *domainWebhook.Delivery {
+	t.Helper()
+	delivery := &domainWebhook.Delivery{
+		URL:           "https://receiver.example",
+		Event:         "message",
+		Payload:       `{}`,
+		NextAttemptAt: nextAttemptAt,
+		RetryUntil:    nextAttemptAt.Add(24 * time.Hour),
+	}
+	require.NoError(t, repo.EnqueueDelivery(context.Background(), delivery))
+	return delivery
+}
+
+func claimedIDs(t *testing.T, repo domainWebhook.IWebhookRepository, now time.Time) []int64 {
+	t.Helper()
+	deliveries, err := repo.ClaimDueDeliveries(context.Background(), now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	ids := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	return ids
+}
+
+func TestSQLiteClaimDueDeliveries(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteWebhookRepository(openSQLite(t))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	due := enqueue(t, repo, now.Add(-time.Minute))
+	later := enqueue(t, repo, now.Add(time.Hour))
+	delivered := enqueue(t, repo, now.Add(-time.Minute))
+	require.NoError(t, repo.MarkDelivered(ctx, delivered.ID, 1, now))
+	failed := enqueue(t, repo, now.Add(-time.Minute))
+	require.NoError(t, repo.MarkFailed(ctx, failed.ID, 3, "gone"))
+
+	assert.Equal(t, []int64{due.ID}, claimedIDs(t, repo, now))
+
+	// A leased delivery is not claimed again until the lease expires
+	assert.Empty(t, claimedIDs(t, repo, now.Add(time.Minute)))
+	assert.Equal(t, []int64{due.ID}, claimedIDs(t, repo, now.Add(6*time.Minute)))
+
+	// The delivery that was never attempted is due again after its lease, next to the one becoming due
+	assert.Equal(t, []int64{due.ID, later.ID}, claimedIDs(t, repo, now.Add(2*time.Hour)))
+}
+
+func TestSQLiteExpediteDeliveriesSkipsClaimed(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteWebhookRepository(openSQLite(t))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	claimed := enqueue(t, repo, now.Add(-time.Minute))
+	require.Equal(t, []int64{claimed.ID}, claimedIDs(t, repo, now))
+	postponed := enqueue(t, repo, now.Add(-time.Minute))
+	require.NoError(t, repo.MarkRetry(ctx, postponed.ID, 0, now.Add(time.Hour), "circuit open"))
+
+	// The endpoint of enqueue is the global webhook, whose endpoint ID is empty
+	expedited, err := repo.ExpediteDeliveries(ctx, "", now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expedited)
+
+	// The claimed delivery is still being attempted and is not handed out a second time
+	assert.Equal(t, []int64{postponed.ID}, claimedIDs(t, repo, now.Add(time.Second)))
+}
+
+func TestSQLiteReplayDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteWebhookRepository(openSQLite(t))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	delivery := enqueue(t, repo, now)
+	require.NoError(t, repo.MarkFailed(ctx, delivery.ID, 7, "status 500"))
+	assert.Empty(t, claimedIDs(t, repo, now))
+
+	ok, err := repo.ReplayDelivery(ctx, delivery.ID, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	replayed, err := repo.GetDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domainWebhook.DeliveryStatusPending, replayed.Status)
+	assert.Zero(t, replayed.Attempts)
+	assert.Empty(t, replayed.LastError)
+	assert.True(t, replayed.RetryUntil.Equal(now.Add(time.Hour)))
+	assert.Equal(t, []int64{delivery.ID}, claimedIDs(t, repo, now))
+
+	// The pending delivery is being attempted, replaying it would reset its attempts
+	require.NoError(t, repo.MarkRetry(ctx, delivery.ID, 3, now.Add(time.Minute), "status 502"))
+	ok, err = repo.ReplayDelivery(ctx, delivery.ID, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	pending, err := repo.GetDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pending.Attempts)
+	assert.Equal(t, "status 502", pending.LastError)
+}
+
+func TestSQLiteReplayDeliveries(t *testing.T) {
+	ctx := context.Background()
+	repo := chatstorage.NewSQLiteWebhookRepository(openSQLite(t))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	failed := enqueue(t, repo, now)
+	require.NoError(t, repo.MarkFailed(ctx, failed.ID, 7, "status 500"))
+	delivered := enqueue(t, repo, now)
+	require.NoError(t, repo.MarkDelivered(ctx, delivered.ID, 1, now))
+
+	replayed, err := repo.ReplayDeliveries(ctx, &domainWebhook.DeliveryFilter{Status: domainWebhook.DeliveryStatusFailed}, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+	assert.Equal(t, []int64{failed.ID}, claimedIDs(t, repo, now))
+
+	// Without a status only the delivered one is replayed, the failed one is pending again and being attempted
+	later := now.Add(time.Minute)
+	replayed, err = repo.ReplayDeliveries(ctx, &domainWebhook.DeliveryFilter{}, later, later.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+	assert.Equal(t, []int64{delivered.ID}, claimedIDs(t, repo, later))
+}
@@ -0,0 +1,165 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	purgeInterval = time.Hour
+	writeTimeout  = 5 * time.Second
+	catchUpBatch  = 500
+)
+
+var (
+	repo domainEventStream.IEventStreamRepository
+
+	subscriptionsMu sync.RWMutex
+	subscriptions   = make(map[*subscription]struct{})
+)
+
+// Init streams the events of every device to the subscriptions and keeps them in the event log while the event
+// retention lasts
+func Init(repository domainEventStream.IEventStreamRepository) {
+	repo = repository
+	whatsapp.AddEventListener(wantsEvent, publish)
+}
+
+// Retained reports whether events are kept, so clients can catch up on the ones they missed
+func Retained() bool {
+	return repo != nil && config.AppEventRetention > 0
+}
+
+// wantsEvent reports whether events of the type are kept or a subscription wants them. Without retention, events
+// nobody subscribed to are not built.
+func wantsEvent(eventType string) bool {
+	if Retained() {
+		return true
+	}
+
+	subscriptionsMu.RLock()
+	defer subscriptionsMu.RUnlock()
+	for s := range subscriptions {
+		if s.filter.Wants(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// publish keeps the event in the event log, which gives it the ID clients resume from, and passes it to the
+// subscriptions
+func publish(ctx context.Context, webhookEvent domainWebhook.Event) {
+	payload, err := json.Marshal(webhookEvent.Payload(domainWebhook.PayloadVersion2))
+	if err != nil {
+		logrus.Errorf("Failed to encode %s event for the event stream: %v", webhookEvent.Type, err)
+		return
+	}
+	event := domainEventStream.Event{
+		DeviceID:  webhookEvent.DeviceID,
+		Type:      webhookEvent.Type,
+		Name:      webhookEvent.Data.EventName(),
+		ChatJID:   webhookEvent.ChatJID,
+		Payload:   payload,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if Retained() {
+		// The event is kept even when the context of the WhatsApp event has been cancelled in the meantime
+		writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+		defer cancel()
+		if err := repo.StoreEvent(writeCtx, &event); err != nil {
+			logrus.Errorf("Failed to keep %s event in the event log: %v", event.Name, err)
+		}
+	}
+
+	subscriptionsMu.RLock()
+	defer subscriptionsMu.RUnlock()
+	for s := range subscriptions {
+		if s.filter.Accepts(event) {
+			s.offer(event)
+		}
+	}
+}
+
+// Subscribe starts a subscription to the events of the filter. With a resume token it first delivers the kept
+// events after the token, the live events arriving in the meantime follow without gaps or duplicates.
+func Subscribe(filter domainEventStream.Filter, afterID int64, resume bool) domainEventStream.Subscription {
+	s := newSubscription(filter)
+
+	subscriptionsMu.Lock()
+	subscriptions[s] = struct{}{}
+	subscriptionsMu.Unlock()
+
+	go s.run(afterID, resume)
+	return s
+}
+
+func unsubscribe(s *subscription) {
+	subscriptionsMu.Lock()
+	delete(subscriptions, s)
+	subscriptionsMu.Unlock()
+}
+
+// catchUp delivers the kept events of the filter after the given ID in order and returns the IDs it delivered
+func catchUp(ctx context.Context, filter domainEventStream.Filter, afterID int64, deliver func(domainEventStream.Event) bool) (map[int64]struct{}, error) {
+	delivered := make(map[int64]struct{})
+	for {
+		events, err := repo.GetEventsAfter(ctx, afterID, catchUpBatch)
+		if err != nil {
+			return delivered, err
+		}
+		for _, event := range events {
+			afterID = event.ID
+			if !filter.Accepts(*event) {
+				continue
+			}
+			if !deliver(*event) {
+				return delivered, nil
+			}
+			delivered[event.ID] = struct{}{}
+		}
+		if len(events) < catchUpBatch {
+			return delivered, nil
+		}
+	}
+}
+
+// RunPurger removes events older than the event retention until the context is cancelled
+func RunPurger(ctx context.Context) {
+	if !Retained() {
+		return
+	}
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context) {
+	purged, err := repo.PurgeEventsBefore(ctx, time.Now().UTC().Add(-config.AppEventRetention))
+	if err != nil {
+		logrus.Errorf("Failed to purge event log: %v", err)
+		return
+	}
+	if purged > 0 {
+		logrus.Infof("Purged %d event(s) older than %s", purged, config.AppEventRetention)
+	}
+}
@@ -0,0 +1,117 @@
+package eventstream
+
+import (
+	"context"
+	"sync"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/sirupsen/logrus"
+)
+
+// subscriptionBuffer is the number of live events waiting for a client, a client falling further behind lags
+const subscriptionBuffer = 256
+
+// subscription passes the events of its filter to a client. Publishers never wait for it: a client that does not
+// keep up is ended with ErrLagged and resumes from its last resume token.
+type subscription struct {
+	filter domainEventStream.Filter
+	live   chan domainEventStream.Event // filled by publish
+	events chan domainEventStream.Event // read by the client
+	err    error                        // why events was closed
+
+	done      chan struct{}
+	closeOnce sync.Once
+	lagged    chan struct{}
+	lagOnce   sync.Once
+}
+
+func newSubscription(filter domainEventStream.Filter) *subscription {
+	return &subscription{
+		filter: filter,
+		live:   make(chan domainEventStream.Event, subscriptionBuffer),
+		events: make(chan domainEventStream.Event),
+		done:   make(chan struct{}),
+		lagged: make(chan struct{}),
+	}
+}
+
+func (s *subscription) Events() <-chan domainEventStream.Event {
+	return s.events
+}
+
+func (s *subscription) Err() error {
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// offer queues a live event without blocking the publisher
+func (s *subscription) offer(event domainEventStream.Event) {
+	select {
+	case s.live <- event:
+	default:
+		s.lagOnce.Do(func() {
+			logrus.Warnf("Event stream client did not keep up, dropped its subscription at %s event", event.Name)
+			close(s.lagged)
+		})
+	}
+}
+
+// run delivers the kept events after the resume token, then the live events until the subscription ends
+func (s *subscription) run(afterID int64, resume bool) {
+	defer close(s.events)
+	defer unsubscribe(s)
+
+	var delivered map[int64]struct{}
+	if resume {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-s.done:
+			case <-s.lagged:
+			case <-ctx.Done():
+			}
+			cancel()
+		}()
+
+		var err error
+		delivered, err = catchUp(ctx, s.filter, afterID, s.deliver)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			s.err = err
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.lagged:
+			s.err = domainEventStream.ErrLagged
+			return
+		case event := <-s.live:
+			// Live events kept while catching up were delivered already
+			if _, ok := delivered[event.ID]; ok {
+				continue
+			}
+			if !s.deliver(event) {
+				continue
+			}
+		}
+	}
+}
+
+// deliver waits for the client to take the event, false when the subscription ended in the meantime
+func (s *subscription) deliver(event domainEventStream.Event) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return false
+	case <-s.lagged:
+		return false
+	}
+}
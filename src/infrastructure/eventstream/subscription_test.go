@@ -0,0 +1,83 @@
+package eventstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRepository keeps the events in order of their IDs
+type memoryRepository struct {
+	events []*domainEventStream.Event
+}
+
+func (r *memoryRepository) StoreEvent(_ context.Context, event *domainEventStream.Event) error {
+	event.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryRepository) GetEventsAfter(_ context.Context, afterID int64, limit int) ([]*domainEventStream.Event, error) {
+	var events []*domainEventStream.Event
+	for _, event := range r.events {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryRepository) PurgeEventsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func receive(t *testing.T, subscription domainEventStream.Subscription) (domainEventStream.Event, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-subscription.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return domainEventStream.Event{}, false
+	}
+}
+
+func TestSubscriptionCatchUp(t *testing.T) {
+	memory := &memoryRepository{}
+	repo = memory
+	defer func() { repo = nil }()
+	for _, eventType := range []string{"message", "receipt", "message"} {
+		_ = memory.StoreEvent(context.Background(), &domainEventStream.Event{Type: eventType})
+	}
+
+	s := newSubscription(domainEventStream.Filter{Events: []string{"message"}})
+	// Event 3 is published while the subscription catches up, it is delivered once
+	s.offer(domainEventStream.Event{ID: 3, Type: "message"})
+	s.offer(domainEventStream.Event{ID: 4, Type: "message"})
+	go s.run(0, true)
+	defer s.Close()
+
+	for _, id := range []int64{1, 3, 4} {
+		event, ok := receive(t, s)
+		assert.True(t, ok)
+		assert.Equal(t, id, event.ID)
+	}
+}
+
+func TestSubscriptionLagged(t *testing.T) {
+	s := newSubscription(domainEventStream.Filter{})
+	for i := 0; i <= subscriptionBuffer; i++ {
+		s.offer(domainEventStream.Event{Type: "presence"})
+	}
+	go s.run(0, false)
+
+	for {
+		if _, ok := receive(t, s); !ok {
+			break
+		}
+	}
+	assert.ErrorIs(t, s.Err(), domainEventStream.ErrLagged)
+}
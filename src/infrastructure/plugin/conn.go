@@ -0,0 +1,237 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainPlugin "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/plugin"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/sirupsen/logrus"
+)
+
+// conn is the JSON-RPC connection to a running plugin process
+type conn struct {
+	config domainPlugin.Config
+
+	writeMu sync.Mutex
+	encoder *json.Encoder
+
+	pendingMu sync.Mutex
+	nextID    int64
+	pending   map[string]chan *domainPlugin.Message // responses awaited by ID
+}
+
+func newConn(config domainPlugin.Config, stdin io.Writer) *conn {
+	return &conn{
+		config:  config,
+		encoder: json.NewEncoder(stdin),
+		pending: make(map[string]chan *domainPlugin.Message),
+	}
+}
+
+// write sends a message as a single line
+func (c *conn) write(message *domainPlugin.Message) error {
+	message.JSONRPC = domainPlugin.JSONRPCVersion
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.encoder.Encode(message)
+}
+
+// stream sends the queued events one at a time, each waits for its acknowledgement. A plugin leaving too many
+// events in a row unacknowledged is hung and gets killed, it is restarted by its supervisor.
+func (c *conn) stream(ctx context.Context, events <-chan domainWebhook.Event, kill context.CancelFunc) {
+	timeouts := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			err := c.request(ctx, domainPlugin.MethodEvent, event.Payload(domainWebhook.PayloadVersion2))
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				timeouts++
+				logrus.Warnf("Plugin %s did not acknowledge a %s event within %s", c.config.Name, event.Type, c.config.Timeout)
+				if timeouts >= maxTimeouts {
+					logrus.Errorf("Plugin %s left %d events in a row unacknowledged, killing it", c.config.Name, timeouts)
+					kill()
+					return
+				}
+			case err != nil:
+				timeouts = 0
+				if ctx.Err() == nil {
+					logrus.Warnf("Plugin %s failed to handle a %s event: %v", c.config.Name, event.Type, err)
+				}
+			default:
+				timeouts = 0
+			}
+		}
+	}
+}
+
+// request sends a request to the plugin and waits for its response within the timeout of the plugin
+func (c *conn) request(ctx context.Context, method string, params any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	c.pendingMu.Lock()
+	c.nextID++
+	id := strconv.FormatInt(c.nextID, 10)
+	response := make(chan *domainPlugin.Message, 1)
+	c.pending[id] = response
+	c.pendingMu.Unlock()
+
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	if err := c.write(&domainPlugin.Message{ID: json.RawMessage(id), Method: method, Params: body}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case message := <-response:
+		if message.Error != nil {
+			return message.Error
+		}
+		return nil
+	}
+}
+
+// read handles the lines the plugin writes until its output is closed: responses to our requests and calls of the
+// plugin, which run concurrently so a plugin may call the usecases while it handles an event
+func (c *conn) read(ctx context.Context, stdout io.Reader) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var message domainPlugin.Message
+		if err := json.Unmarshal(line, &message); err != nil {
+			c.respond(json.RawMessage("null"), nil, &domainPlugin.Error{Code: domainPlugin.CodeParseError, Message: fmt.Sprintf("invalid JSON: %v", err)})
+			continue
+		}
+
+		if message.IsRequest() {
+			go c.handle(ctx, message)
+			continue
+		}
+
+		c.pendingMu.Lock()
+		response, ok := c.pending[string(message.ID)]
+		c.pendingMu.Unlock()
+		if !ok {
+			logrus.Debugf("Plugin %s answered unknown or expired request %s", c.config.Name, message.ID)
+			continue
+		}
+		// A request is answered once, a repeated answer must not block the reader
+		select {
+		case response <- &message:
+		default:
+			logrus.Debugf("Plugin %s answered request %s more than once", c.config.Name, message.ID)
+		}
+	}
+	return scanner.Err()
+}
+
+// handle runs a call of the plugin and answers it, notifications are run without an answer
+func (c *conn) handle(ctx context.Context, message domainPlugin.Message) {
+	result, rpcErr := c.call(ctx, message)
+	if len(message.ID) == 0 {
+		if rpcErr != nil {
+			logrus.Warnf("Plugin %s notification %s failed: %s", c.config.Name, message.Method, rpcErr.Message)
+		}
+		return
+	}
+	c.respond(message.ID, result, rpcErr)
+}
+
+// respond sends the result or error of a call
+func (c *conn) respond(id json.RawMessage, result any, rpcErr *domainPlugin.Error) {
+	response := &domainPlugin.Message{ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			response.Error = &domainPlugin.Error{Code: domainPlugin.CodeInternalError, Message: fmt.Sprintf("failed to encode result: %v", err)}
+		} else {
+			response.Result = body
+		}
+	}
+	if err := c.write(response); err != nil {
+		logrus.Warnf("Failed to answer plugin %s: %v", c.config.Name, err)
+	}
+}
+
+// call runs a method of the usecases on the device selected by the params, as the plugin
+func (c *conn) call(ctx context.Context, message domainPlugin.Message) (result any, rpcErr *domainPlugin.Error) {
+	if message.JSONRPC != domainPlugin.JSONRPCVersion {
+		return nil, &domainPlugin.Error{Code: domainPlugin.CodeInvalidRequest, Message: fmt.Sprintf("jsonrpc must be %q", domainPlugin.JSONRPCVersion)}
+	}
+	handler, ok := methods[message.Method]
+	if !ok {
+		return nil, &domainPlugin.Error{Code: domainPlugin.CodeMethodNotFound, Message: fmt.Sprintf("method %s not found", message.Method)}
+	}
+
+	var params domainPlugin.CallParams
+	if len(message.Params) > 0 {
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &domainPlugin.Error{Code: domainPlugin.CodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+		}
+	}
+	if !whatsapp.DeviceExists(params.DeviceID) {
+		return nil, &domainPlugin.Error{Code: domainPlugin.CodeInvalidParams, Message: fmt.Sprintf("device %s not found", params.DeviceID)}
+	}
+
+	ctx = whatsapp.ContextWithDevice(ctx, params.DeviceID)
+	ctx = domainAuth.ContextWithPrincipal(ctx, domainAuth.Principal{Username: "plugin:" + c.config.Name})
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	result, err := func() (value any, err error) {
+		defer whatsapp.RecoverSend(&err)
+		return handler(ctx, message.Params)
+	}()
+	if err != nil {
+		return nil, toRPCError(err)
+	}
+	return result, nil
+}
+
+// toRPCError turns the error of a usecase into the error of a response, with the code the REST API would use
+func toRPCError(err error) *domainPlugin.Error {
+	var paramsErr invalidParamsError
+	if errors.As(err, &paramsErr) {
+		return &domainPlugin.Error{Code: domainPlugin.CodeInvalidParams, Message: err.Error()}
+	}
+
+	var genericErr pkgError.GenericError
+	if errors.As(err, &genericErr) {
+		return &domainPlugin.Error{
+			Code:    domainPlugin.CodeUsecaseError,
+			Message: genericErr.Error(),
+			Data:    &domainPlugin.ErrorData{Code: genericErr.ErrCode(), Status: genericErr.StatusCode()},
+		}
+	}
+	return &domainPlugin.Error{Code: domainPlugin.CodeUsecaseError, Message: err.Error()}
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainPlugin "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/plugin"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+)
+
+var (
+	plugins []*plugin
+	methods map[string]method
+)
+
+// Init parses the plugins configured with --plugin and streams the events of every device to them once Run started
+// them. Plugins call back into the send, message and group usecases.
+func Init(sendService domainSend.ISendUsecase, messageService domainMessage.IMessageUsecase, groupService domainGroup.IGroupUsecase) error {
+	configs, err := domainPlugin.ParseConfigs(config.WhatsappPlugins)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+
+	methods = newMethods(sendService, messageService, groupService)
+	for _, pluginConfig := range configs {
+		plugins = append(plugins, newPlugin(pluginConfig))
+	}
+	whatsapp.AddEventListener(wantsEvent, dispatch)
+	return nil
+}
+
+// Run starts every plugin and restarts the ones that exit until the context is cancelled
+func Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range plugins {
+		wg.Add(1)
+		go func(p *plugin) {
+			defer wg.Done()
+			p.supervise(ctx)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// wantsEvent reports whether a plugin wants events of the type
+func wantsEvent(eventType string) bool {
+	for _, p := range plugins {
+		if slices.Contains(p.config.Events, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch queues an event for every plugin that wants it, without waiting for the plugins
+func dispatch(_ context.Context, event domainWebhook.Event) {
+	for _, p := range plugins {
+		if p.config.Accepts(event.Type, event.ChatJID) {
+			p.offer(event)
+		}
+	}
+}
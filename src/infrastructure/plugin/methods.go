@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+)
+
+// method runs a usecase with the params of a call and returns the result of the response
+type method func(ctx context.Context, params json.RawMessage) (any, error)
+
+// invalidParamsError is returned when the params do not decode into the request of the usecase
+type invalidParamsError struct {
+	err error
+}
+
+func (e invalidParamsError) Error() string {
+	return fmt.Sprintf("invalid params: %v", e.err)
+}
+
+// newMethods maps the methods plugins can call to the usecases. The params are the JSON request of the usecase, like
+// the body of the matching REST endpoint. Media is passed by URL, the endpoints only taking uploads are left out.
+func newMethods(send domainSend.ISendUsecase, message domainMessage.IMessageUsecase, group domainGroup.IGroupUsecase) map[string]method {
+	return map[string]method{
+		"send.text":          call(send.SendText),
+		"send.image":         call(send.SendImage),
+		"send.video":         call(send.SendVideo),
+		"send.audio":         call(send.SendAudio),
+		"send.sticker":       call(send.SendSticker),
+		"send.contact":       call(send.SendContact),
+		"send.link":          call(send.SendLink),
+		"send.location":      call(send.SendLocation),
+		"send.poll":          call(send.SendPoll),
+		"send.presence":      call(send.SendPresence),
+		"send.chat_presence": call(send.SendChatPresence),
+
+		"message.read":         call(message.MarkAsRead),
+		"message.react":        call(message.ReactMessage),
+		"message.revoke":       call(message.RevokeMessage),
+		"message.update":       call(message.UpdateMessage),
+		"message.delete":       callWithoutResult(message.DeleteMessage),
+		"message.star":         callWithoutResult(message.StarMessage),
+		"message.download":     call(message.DownloadMedia),
+		"message.status":       call(message.GetMessageStatus),
+		"message.poll_results": call(message.GetPollResults),
+
+		"group.join_with_link":              call(group.JoinGroupWithLink),
+		"group.leave":                       callWithoutResult(group.LeaveGroup),
+		"group.create":                      call(group.CreateGroup),
+		"group.info_from_link":              call(group.GetGroupInfoFromLink),
+		"group.invite_link":                 call(group.GetGroupInviteLink),
+		"group.info":                        call(group.GroupInfo),
+		"group.participants":                call(group.GetGroupParticipants),
+		"group.manage_participants":         call(group.ManageParticipant),
+		"group.participant_requests":        call(group.GetGroupRequestParticipants),
+		"group.manage_participant_requests": call(group.ManageGroupRequestParticipants),
+		"group.set_name":                    callWithoutResult(group.SetGroupName),
+		"group.set_locked":                  callWithoutResult(group.SetGroupLocked),
+		"group.set_announce":                callWithoutResult(group.SetGroupAnnounce),
+		"group.set_topic":                   callWithoutResult(group.SetGroupTopic),
+	}
+}
+
+// call adapts a usecase taking a request and returning a response
+func call[Request any, Response any](fn func(context.Context, Request) (Response, error)) method {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var request Request
+		if err := decodeParams(params, &request); err != nil {
+			return nil, err
+		}
+		return fn(ctx, request)
+	}
+}
+
+// callWithoutResult adapts a usecase that only returns an error, its result is null
+func callWithoutResult[Request any](fn func(context.Context, Request) error) method {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		var request Request
+		if err := decodeParams(params, &request); err != nil {
+			return nil, err
+		}
+		return nil, fn(ctx, request)
+	}
+}
+
+func decodeParams(params json.RawMessage, request any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, request); err != nil {
+		return invalidParamsError{err: err}
+	}
+	return nil
+}
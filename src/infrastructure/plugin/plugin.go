@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	domainPlugin "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/plugin"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize       = 256     // events waiting per plugin, newer events are dropped while the queue is full
+	maxMessageSize  = 4 << 20 // longest line a plugin may write
+	restartDelay    = time.Second
+	maxRestartDelay = time.Minute
+	stableAfter     = time.Minute // a plugin running this long restarts with the shortest delay again
+	maxTimeouts     = 3           // unacknowledged events in a row after which a plugin counts as hung
+)
+
+// plugin is a configured executable, restarted whenever it exits
+type plugin struct {
+	config domainPlugin.Config
+	events chan domainWebhook.Event
+}
+
+func newPlugin(config domainPlugin.Config) *plugin {
+	return &plugin{
+		config: config,
+		events: make(chan domainWebhook.Event, queueSize),
+	}
+}
+
+// offer queues an event without blocking the WhatsApp event handlers
+func (p *plugin) offer(event domainWebhook.Event) {
+	select {
+	case p.events <- event:
+	default:
+		logrus.Warnf("Plugin %s is not keeping up, dropped a %s event", p.config.Name, event.Type)
+	}
+}
+
+// supervise runs the plugin and restarts it with an increasing delay whenever it exits, until the context is cancelled
+func (p *plugin) supervise(ctx context.Context) {
+	delay := restartDelay
+	for {
+		started := time.Now()
+		err := p.run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) >= stableAfter {
+			delay = restartDelay
+		}
+		logrus.Errorf("Plugin %s exited, restarting in %s: %v", p.config.Name, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
+
+// run starts the process of the plugin and talks to it until it exits. A hung plugin is killed.
+func (p *plugin) run(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.config.Command[0], p.config.Command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	logrus.Infof("Plugin %s started with pid %d", p.config.Name, cmd.Process.Pid)
+
+	c := newConn(p.config, stdin)
+	go c.stream(runCtx, p.events, cancel)
+
+	// Both pipes are read to the end before waiting for the process, as os/exec requires
+	var readers sync.WaitGroup
+	readers.Add(2)
+	var readErr error
+	go func() {
+		defer readers.Done()
+		readErr = c.read(runCtx, stdout)
+		// Nothing is read anymore, a plugin still running would block on its next write
+		cancel()
+	}()
+	go func() {
+		defer readers.Done()
+		p.logStderr(stderr)
+	}()
+	readers.Wait()
+
+	err = cmd.Wait()
+	if err == nil {
+		err = readErr
+	}
+	if err == nil {
+		err = errors.New("plugin closed its output")
+	}
+	return err
+}
+
+// logStderr logs what the plugin writes to stderr
+func (p *plugin) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxMessageSize)
+	for scanner.Scan() {
+		logrus.Infof("[plugin %s] %s", p.config.Name, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Warnf("Failed to read the stderr of plugin %s: %v", p.config.Name, err)
+		_, _ = io.Copy(io.Discard, stderr)
+	}
+}
@@ -0,0 +1,402 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/sirupsen/logrus"
+)
+
+const purgeInterval = time.Hour
+
+var (
+	repo           domainRateLimit.IRateLimitRepository
+	classLimits    map[string]domainRateLimit.Limit
+	recipientLimit *domainRateLimit.Limit
+
+	// The buckets and usage are cached, the repository is only read the first time a subject is seen and written
+	// after the check. mu only guards the maps, the checks of a subject are serialized by its subject lock so
+	// concurrent requests cannot overdraw a bucket.
+	mu           sync.Mutex
+	buckets      = make(map[string]*domainRateLimit.Bucket) // by subject and class
+	usage        = make(map[string]int64)                   // by subject and period
+	subjectLocks [64]sync.Mutex
+)
+
+// Init parses the configured limits and sets the repository the limits are persisted in
+func Init(repository domainRateLimit.IRateLimitRepository) error {
+	limits, err := domainRateLimit.ParseClassLimits(config.AppRateLimits)
+	if err != nil {
+		return err
+	}
+
+	var recipient *domainRateLimit.Limit
+	if config.AppRecipientRateLimit != "" {
+		limit, err := domainRateLimit.ParseLimit(config.AppRecipientRateLimit)
+		if err != nil {
+			return err
+		}
+		recipient = &limit
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	repo, classLimits, recipientLimit = repository, limits, recipient
+	buckets = make(map[string]*domainRateLimit.Bucket)
+	usage = make(map[string]int64)
+	return nil
+}
+
+// ClassLimit returns the limit of a route class
+func ClassLimit(class string) (domainRateLimit.Limit, bool) {
+	if limit, ok := classLimits[class]; ok {
+		return limit, true
+	}
+	limit, ok := classLimits[domainRateLimit.ClassDefault]
+	return limit, ok
+}
+
+// ClassLimits returns the configured limits per route class
+func ClassLimits() map[string]domainRateLimit.Limit {
+	return classLimits
+}
+
+// RecipientLimit returns the limit of the sends to a recipient, nil when it is unlimited
+func RecipientLimit() *domainRateLimit.Limit {
+	return recipientLimit
+}
+
+// Allow takes a token of the caller for the route class of the permission, and for sends also a token of the
+// recipient and one send of the quotas. Nothing is taken when one of them is exhausted, the returned
+// pkgError.RateLimitError tells how long the caller has to wait.
+func Allow(ctx context.Context, principal domainAuth.Principal, permission, recipient string) error {
+	reservation, err := Reserve(ctx, principal, permission, recipient)
+	if err != nil {
+		return err
+	}
+	reservation.Commit(ctx)
+	return nil
+}
+
+// Reservation holds the tokens and sends Reserve took until the call is done. Commit persists them, Cancel gives
+// them back. A nil reservation, of a call that is not limited, does nothing.
+type Reservation struct {
+	subject string
+	class   string
+	limits  []subjectLimit
+	quotas  []quota
+	now     time.Time
+	done    bool
+}
+
+// Reserve takes the same tokens and sends as Allow, but only in the cache: they are not persisted until the
+// reservation is committed, and cancelling it returns them, so calls that fail are not charged
+func Reserve(ctx context.Context, principal domainAuth.Principal, permission, recipient string) (*Reservation, error) {
+	class := domainRateLimit.RouteClass(permission)
+	if repo == nil || class == "" {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	subject := domainRateLimit.Subject(principal)
+	sends := domainRateLimit.SendsMessage(permission)
+
+	var limits []subjectLimit
+	if limit, ok := ClassLimit(class); ok {
+		limits = append(limits, subjectLimit{subject, limit})
+	}
+	if sends && recipient != "" && recipientLimit != nil {
+		limits = append(limits, subjectLimit{domainRateLimit.RecipientSubject(recipient), *recipientLimit})
+	}
+
+	var quotas []quota
+	if sends {
+		for _, q := range []quota{
+			{name: "daily", kind: domainRateLimit.PeriodDay, limit: config.AppSendQuotaDaily},
+			{name: "monthly", kind: domainRateLimit.PeriodMonth, limit: config.AppSendQuotaMonthly},
+		} {
+			// An unlimited quota is not counted
+			if q.limit > 0 {
+				q.period, q.end = domainRateLimit.Period(q.kind, now)
+				quotas = append(quotas, q)
+			}
+		}
+	}
+
+	// The repository is read and written outside the locks, so a slow database only delays its own caller
+	for _, l := range limits {
+		loadBucket(ctx, l.subject, class)
+	}
+	for _, q := range quotas {
+		loadUsage(ctx, subject, q.period)
+	}
+
+	if err := check(subject, class, limits, quotas, now); err != nil {
+		return nil, err
+	}
+	return &Reservation{subject: subject, class: class, limits: limits, quotas: quotas, now: now}, nil
+}
+
+// Commit persists the taken tokens and counts the sends in the repository
+func (r *Reservation) Commit(ctx context.Context) {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+
+	for _, l := range r.limits {
+		mu.Lock()
+		bucket, ok := buckets[l.subject+"|"+r.class]
+		mu.Unlock()
+		if !ok {
+			continue
+		}
+		if err := repo.SaveBucket(ctx, bucket); err != nil {
+			logrus.Errorf("Failed to save rate limit bucket of %s: %v", bucket.Subject, err)
+		}
+	}
+	for _, q := range r.quotas {
+		if err := repo.IncrementUsage(ctx, r.subject, q.period, r.now); err != nil {
+			logrus.Errorf("Failed to count send of %s: %v", r.subject, err)
+		}
+	}
+}
+
+// Cancel gives the taken tokens and sends back, nothing was persisted for them
+func (r *Reservation) Cancel() {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+
+	subjects := []string{r.subject}
+	for _, l := range r.limits {
+		subjects = append(subjects, l.subject)
+	}
+	defer lockSubjects(subjects...)()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, l := range r.limits {
+		key := l.subject + "|" + r.class
+		if cached, ok := buckets[key]; ok {
+			bucket := *cached
+			bucket.Return(l.limit)
+			buckets[key] = &bucket
+		}
+	}
+	for _, q := range r.quotas {
+		if key := r.subject + "|" + q.period; usage[key] > 0 {
+			usage[key]--
+		}
+	}
+}
+
+// subjectLimit is a limit of the bucket of a subject
+type subjectLimit struct {
+	subject string
+	limit   domainRateLimit.Limit
+}
+
+// quota is a send quota of a period, limit is never zero
+type quota struct {
+	name   string
+	kind   string
+	limit  int64
+	period string
+	end    time.Time
+}
+
+// check takes a token of every limit and counts a send of every quota in the cache, nothing when one of them is
+// exhausted. Cached buckets are replaced and never changed.
+func check(subject, class string, limits []subjectLimit, quotas []quota, now time.Time) error {
+	subjects := []string{subject}
+	for _, l := range limits {
+		subjects = append(subjects, l.subject)
+	}
+	defer lockSubjects(subjects...)()
+
+	taken := make([]*domainRateLimit.Bucket, 0, len(limits))
+	for _, l := range limits {
+		bucket, err := take(l.subject, class, l.limit, now)
+		if err != nil {
+			return err
+		}
+		taken = append(taken, bucket)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, q := range quotas {
+		if usage[subject+"|"+q.period] >= q.limit {
+			return pkgError.RateLimitError{
+				Message:    fmt.Sprintf("%s send quota of %d exceeded by %s", q.name, q.limit, subject),
+				RetryAfter: q.end.Sub(now),
+			}
+		}
+	}
+
+	// Every limit allows the call, commit the taken tokens and count the send
+	for _, bucket := range taken {
+		buckets[bucket.Subject+"|"+bucket.Class] = bucket
+	}
+	for _, q := range quotas {
+		usage[subject+"|"+q.period]++
+	}
+	return nil
+}
+
+// lockSubjects locks the checks of the subjects and returns the function unlocking them. Subjects share a fixed set
+// of locks, which are taken in order so two checks never wait on each other.
+func lockSubjects(subjects ...string) func() {
+	indexes := make([]int, 0, len(subjects))
+	for _, subject := range subjects {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(subject))
+		indexes = append(indexes, int(hash.Sum32()%uint32(len(subjectLocks))))
+	}
+	slices.Sort(indexes)
+	indexes = slices.Compact(indexes)
+
+	for _, i := range indexes {
+		subjectLocks[i].Lock()
+	}
+	return func() {
+		for _, i := range indexes {
+			subjectLocks[i].Unlock()
+		}
+	}
+}
+
+// take returns a copy of the cached bucket with one token taken, or a RateLimitError when it is empty
+func take(subject, class string, limit domainRateLimit.Limit, now time.Time) (*domainRateLimit.Bucket, error) {
+	bucket := domainRateLimit.Bucket{Subject: subject, Class: class, Tokens: float64(limit.Count), UpdatedAt: now}
+	mu.Lock()
+	if cached, ok := buckets[subject+"|"+class]; ok {
+		bucket = *cached
+	}
+	mu.Unlock()
+
+	if wait, ok := bucket.Take(limit, now); !ok {
+		return nil, pkgError.RateLimitError{
+			Message:    fmt.Sprintf("rate limit of %s for %s exceeded by %s", limit, class, subject),
+			RetryAfter: wait,
+		}
+	}
+	return &bucket, nil
+}
+
+// loadBucket caches the stored bucket of a subject the first time it is seen
+func loadBucket(ctx context.Context, subject, class string) {
+	key := subject + "|" + class
+	mu.Lock()
+	_, cached := buckets[key]
+	mu.Unlock()
+	if cached {
+		return
+	}
+
+	stored, err := repo.GetBucket(ctx, subject, class)
+	if err != nil {
+		// A failing storage must not block the API, the bucket starts full
+		logrus.Errorf("Failed to load rate limit bucket of %s: %v", subject, err)
+		return
+	}
+	if stored == nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := buckets[key]; !ok {
+		buckets[key] = stored
+	}
+}
+
+// loadUsage caches the stored sends of a subject in a period the first time it is seen
+func loadUsage(ctx context.Context, subject, period string) {
+	key := subject + "|" + period
+	mu.Lock()
+	_, cached := usage[key]
+	mu.Unlock()
+	if cached {
+		return
+	}
+
+	count, err := repo.GetUsage(ctx, subject, period)
+	if err != nil {
+		logrus.Errorf("Failed to load send quota of %s: %v", subject, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := usage[key]; !ok {
+		usage[key] = count
+	}
+}
+
+// RunPurger removes idle buckets and past usage until the context is cancelled
+func RunPurger(ctx context.Context) {
+	if repo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(ctx context.Context) {
+	now := time.Now().UTC()
+
+	// A bucket idle for longer than its refill period is full again, the same as a bucket never used
+	idle := time.Duration(0)
+	for _, limit := range classLimits {
+		idle = max(idle, limit.Per)
+	}
+	if recipientLimit != nil {
+		idle = max(idle, recipientLimit.Per)
+	}
+	day, _ := domainRateLimit.Period(domainRateLimit.PeriodDay, now)
+	month, _ := domainRateLimit.Period(domainRateLimit.PeriodMonth, now)
+
+	mu.Lock()
+	for key, bucket := range buckets {
+		if now.Sub(bucket.UpdatedAt) > idle {
+			delete(buckets, key)
+		}
+	}
+	for key := range usage {
+		if !strings.HasSuffix(key, "|"+day) && !strings.HasSuffix(key, "|"+month) {
+			delete(usage, key)
+		}
+	}
+	mu.Unlock()
+
+	if _, err := repo.PurgeBucketsBefore(ctx, now.Add(-idle)); err != nil {
+		logrus.Errorf("Failed to purge rate limit buckets: %v", err)
+	}
+	// Usage of the previous month is kept for reference until the end of the current one
+	if _, err := repo.PurgeUsageBefore(ctx, now.AddDate(0, -2, 0)); err != nil {
+		logrus.Errorf("Failed to purge send quota usage: %v", err)
+	}
+}
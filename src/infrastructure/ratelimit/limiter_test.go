@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepository counts the sends and blocks reading the buckets of the subjects in block until it is closed
+type memoryRepository struct {
+	block      map[string]chan struct{}
+	usageReads atomic.Int64
+	increments atomic.Int64
+}
+
+func (r *memoryRepository) GetBucket(_ context.Context, subject, _ string) (*domainRateLimit.Bucket, error) {
+	if wait, ok := r.block[subject]; ok {
+		<-wait
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) GetBuckets(context.Context, string) ([]*domainRateLimit.Bucket, error) {
+	return nil, nil
+}
+
+func (r *memoryRepository) SaveBucket(context.Context, *domainRateLimit.Bucket) error {
+	return nil
+}
+
+func (r *memoryRepository) PurgeBucketsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryRepository) GetUsage(context.Context, string, string) (int64, error) {
+	r.usageReads.Add(1)
+	return 0, nil
+}
+
+func (r *memoryRepository) GetUsages(context.Context, string, string) ([]*domainRateLimit.Usage, error) {
+	return nil, nil
+}
+
+func (r *memoryRepository) IncrementUsage(context.Context, string, string, time.Time) error {
+	r.increments.Add(1)
+	return nil
+}
+
+func (r *memoryRepository) PurgeUsageBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func setup(t *testing.T, limits []string, daily int64) *memoryRepository {
+	t.Helper()
+	previousLimits, previousDaily, previousMonthly := config.AppRateLimits, config.AppSendQuotaDaily, config.AppSendQuotaMonthly
+	t.Cleanup(func() {
+		config.AppRateLimits, config.AppSendQuotaDaily, config.AppSendQuotaMonthly = previousLimits, previousDaily, previousMonthly
+		repo = nil
+	})
+	config.AppRateLimits, config.AppSendQuotaDaily, config.AppSendQuotaMonthly = limits, daily, 0
+
+	memory := &memoryRepository{block: make(map[string]chan struct{})}
+	require.NoError(t, Init(memory))
+	return memory
+}
+
+func TestAllowUnlimitedQuota(t *testing.T) {
+	memory := setup(t, []string{"send=100/1m"}, 0)
+
+	for range 3 {
+		require.NoError(t, Allow(context.Background(), domainAuth.Principal{Username: "alice"}, domainAuth.PermissionSendText, "628123"))
+	}
+	assert.Zero(t, memory.usageReads.Load())
+	assert.Zero(t, memory.increments.Load())
+}
+
+func TestAllowPresenceOutsideQuota(t *testing.T) {
+	memory := setup(t, []string{"send=100/1m"}, 1)
+	principal := domainAuth.Principal{Username: "alice"}
+
+	require.NoError(t, Allow(context.Background(), principal, domainAuth.PermissionSendPresence, ""))
+	require.NoError(t, Allow(context.Background(), principal, domainAuth.PermissionSendChatPresence, "628123"))
+	assert.Zero(t, memory.increments.Load())
+
+	require.NoError(t, Allow(context.Background(), principal, domainAuth.PermissionSendText, "628123"))
+	var rateLimited pkgError.RateLimitError
+	require.True(t, errors.As(Allow(context.Background(), principal, domainAuth.PermissionSendText, "628123"), &rateLimited))
+	assert.True(t, strings.Contains(rateLimited.Message, "daily send quota"))
+	assert.Equal(t, int64(1), memory.increments.Load())
+}
+
+func TestAllowConcurrent(t *testing.T) {
+	setup(t, []string{"send=10/1h"}, 0)
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if Allow(context.Background(), domainAuth.Principal{Username: "alice"}, domainAuth.PermissionSendText, "") == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(10), allowed.Load())
+}
+
+func TestAllowSlowRepository(t *testing.T) {
+	memory := setup(t, []string{"send=10/1m"}, 0)
+	unblock := make(chan struct{})
+	memory.block["user:slow"] = unblock
+
+	slow := make(chan error, 1)
+	go func() { slow <- Allow(context.Background(), domainAuth.Principal{Username: "slow"}, domainAuth.PermissionSendText, "") }()
+	defer func() {
+		close(unblock)
+		assert.NoError(t, <-slow)
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- Allow(context.Background(), domainAuth.Principal{Username: "alice"}, domainAuth.PermissionSendText, "") }()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("a slow repository read of one caller blocked another")
+	}
+}
+
+func TestReserveCancel(t *testing.T) {
+	memory := setup(t, []string{"send=1/1h"}, 1)
+	principal := domainAuth.Principal{Username: "alice"}
+
+	reservation, err := Reserve(context.Background(), principal, domainAuth.PermissionSendText, "628123")
+	require.NoError(t, err)
+	var rateLimited pkgError.RateLimitError
+	require.True(t, errors.As(Allow(context.Background(), principal, domainAuth.PermissionSendText, "628123"), &rateLimited))
+
+	// The cancelled call is not charged, the next one can take the token and send
+	reservation.Cancel()
+	reservation.Commit(context.Background())
+	assert.Zero(t, memory.increments.Load())
+
+	reservation, err = Reserve(context.Background(), principal, domainAuth.PermissionSendText, "628123")
+	require.NoError(t, err)
+	reservation.Commit(context.Background())
+	reservation.Cancel()
+	assert.Equal(t, int64(1), memory.increments.Load())
+	require.True(t, errors.As(Allow(context.Background(), principal, domainAuth.PermissionSendText, "628123"), &rateLimited))
+}
@@ -0,0 +1,64 @@
+package sendqueue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/sirupsen/logrus"
+)
+
+// Websocket codes of the send job outcomes
+const (
+	wsCodeSendJobSent   = "SEND_JOB_SENT"
+	wsCodeSendJobFailed = "SEND_JOB_FAILED"
+)
+
+// createSendJobEvent creates a webhook event for the outcome of a job
+func createSendJobEvent(job *domainSend.Job, now time.Time) domainWebhook.Event {
+	data := &domainWebhook.SendJobData{
+		JobID:     job.ID,
+		Recipient: job.Recipient,
+		MessageID: job.MessageID,
+		Status:    job.Status,
+		Attempts:  job.Attempts,
+		Error:     job.LastError,
+	}
+	if job.ScheduledAt != nil {
+		data.ScheduledAt = job.ScheduledAt.UTC().Format(time.RFC3339)
+	}
+	if job.SentAt != nil {
+		data.SentAt = job.SentAt.UTC().Format(time.RFC3339)
+	}
+
+	return domainWebhook.Event{
+		Type:      domainWebhook.EventSendJob,
+		ChatJID:   job.Recipient,
+		Timestamp: now,
+		Legacy: &domainWebhook.SendJobPayloadV1{
+			Event:     data.EventName(),
+			Timestamp: now.Format(time.RFC3339),
+			Payload:   domainWebhook.SendJobV1(*data),
+		},
+		Data: data,
+	}
+}
+
+// reportOutcome publishes a sent or failed job to the webhook endpoints and the websocket clients
+func reportOutcome(ctx context.Context, job *domainSend.Job) {
+	deviceCtx := whatsapp.ContextWithDevice(ctx, job.DeviceID)
+	event := createSendJobEvent(job, time.Now())
+
+	if err := whatsapp.SubmitWebhook(deviceCtx, event); err != nil {
+		logrus.Errorf("Failed to forward outcome of send job %s to webhook: %v", job.ID, err)
+	}
+
+	code, message := wsCodeSendJobSent, fmt.Sprintf("Send job %s sent to %s", job.ID, job.Recipient)
+	if job.Status != domainSend.JobStatusSent {
+		code, message = wsCodeSendJobFailed, fmt.Sprintf("Send job %s to %s failed: %s", job.ID, job.Recipient, job.LastError)
+	}
+	whatsapp.BroadcastDeviceResult(deviceCtx, code, message, event.Data)
+}
@@ -0,0 +1,76 @@
+package sendqueue
+
+import (
+	"math/rand/v2"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+)
+
+// pacer spaces the queued messages: a global interval with random jitter between two messages, and a minimum
+// interval between two messages of a device to the same recipient
+type pacer struct {
+	interval          time.Duration
+	jitter            time.Duration
+	recipientInterval time.Duration
+
+	next     time.Time            // no message is sent before
+	lastSent map[string]time.Time // by device and recipient
+}
+
+func newPacer(interval, jitter, recipientInterval time.Duration) *pacer {
+	return &pacer{
+		interval:          interval,
+		jitter:            jitter,
+		recipientInterval: recipientInterval,
+		lastSent:          make(map[string]time.Time),
+	}
+}
+
+// wait returns how long the worker has to wait before the next message
+func (p *pacer) wait(now time.Time) time.Duration {
+	return max(0, p.next.Sub(now))
+}
+
+// pick returns the first job whose recipient may get a message now. Without one it returns how long until the
+// first recipient may, zero when there are no jobs.
+func (p *pacer) pick(jobs []*domainSend.Job, now time.Time) (*domainSend.Job, time.Duration) {
+	var wait time.Duration
+	for _, job := range jobs {
+		last, ok := p.lastSent[recipientKey(job)]
+		if !ok || now.Sub(last) >= p.recipientInterval {
+			return job, 0
+		}
+		if remaining := p.recipientInterval - now.Sub(last); wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return nil, wait
+}
+
+// record notes a message sent to the recipient of the job and schedules the next message
+func (p *pacer) record(job *domainSend.Job, now time.Time) {
+	p.lastSent[recipientKey(job)] = now
+
+	pause := p.interval
+	if p.jitter > 0 {
+		pause += rand.N(p.jitter)
+	}
+	p.next = now.Add(pause)
+
+	// Recipients outside the interval are free again, forget them so the map does not grow
+	for key, last := range p.lastSent {
+		if now.Sub(last) >= p.recipientInterval {
+			delete(p.lastSent, key)
+		}
+	}
+}
+
+func recipientKey(job *domainSend.Job) string {
+	return job.DeviceID + "|" + job.Recipient
+}
+
+// typingDuration returns how long the typing indicator is shown before a message, growing with its length
+func typingDuration(content string) time.Duration {
+	return min(max(time.Duration(len([]rune(content)))*50*time.Millisecond, time.Second), 5*time.Second)
+}
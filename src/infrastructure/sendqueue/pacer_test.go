@@ -0,0 +1,66 @@
+package sendqueue
+
+import (
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacer(t *testing.T) {
+	p := newPacer(3*time.Second, 0, 10*time.Second)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	alice := &domainSend.Job{ID: "1", Recipient: "alice@s.whatsapp.net"}
+	aliceAgain := &domainSend.Job{ID: "2", Recipient: "alice@s.whatsapp.net"}
+	bob := &domainSend.Job{ID: "3", Recipient: "bob@s.whatsapp.net"}
+
+	assert.Equal(t, time.Duration(0), p.wait(now))
+	job, _ := p.pick([]*domainSend.Job{alice, aliceAgain, bob}, now)
+	assert.Equal(t, alice, job)
+	p.record(job, now)
+
+	// The global interval applies to every recipient
+	assert.Equal(t, 3*time.Second, p.wait(now))
+	now = now.Add(3 * time.Second)
+	assert.Equal(t, time.Duration(0), p.wait(now))
+
+	// Alice got a message 3s ago, so bob is next even though the second job of alice is older
+	job, _ = p.pick([]*domainSend.Job{aliceAgain, bob}, now)
+	assert.Equal(t, bob, job)
+	p.record(job, now)
+
+	now = now.Add(3 * time.Second)
+	job, wait := p.pick([]*domainSend.Job{aliceAgain}, now)
+	assert.Nil(t, job)
+	assert.Equal(t, 4*time.Second, wait)
+
+	now = now.Add(4 * time.Second)
+	job, _ = p.pick([]*domainSend.Job{aliceAgain}, now)
+	assert.Equal(t, aliceAgain, job)
+
+	// The same recipient on another device has its own interval
+	job, _ = p.pick([]*domainSend.Job{{ID: "4", DeviceID: "628123456789", Recipient: "bob@s.whatsapp.net"}}, now)
+	assert.NotNil(t, job)
+
+	job, wait = p.pick(nil, now)
+	assert.Nil(t, job)
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestPacerJitter(t *testing.T) {
+	p := newPacer(time.Second, 2*time.Second, 0)
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		p.record(&domainSend.Job{Recipient: "alice@s.whatsapp.net"}, now)
+		assert.GreaterOrEqual(t, p.wait(now), time.Second)
+		assert.Less(t, p.wait(now), 3*time.Second)
+	}
+	assert.Empty(t, p.lastSent)
+}
+
+func TestTypingDuration(t *testing.T) {
+	assert.Equal(t, time.Second, typingDuration(""))
+	assert.Equal(t, 2*time.Second, typingDuration(string(make([]rune, 40))))
+	assert.Equal(t, 5*time.Second, typingDuration(string(make([]byte, 1000))))
+}
@@ -0,0 +1,360 @@
+package sendqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	pollInterval      = 5 * time.Second
+	purgeInterval     = time.Hour
+	dueBatch          = 50
+	maxAttempts       = 5
+	notLoggedInDelay  = 30 * time.Second   // a job of a device that is not logged in waits without using up an attempt
+	finishedRetention = 7 * 24 * time.Hour // finished jobs are kept so their status can still be polled
+	storeTimeout      = 2 * time.Second
+
+	baseBackoff = 10 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+var (
+	repo            domainSend.ISendJobRepository
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+	wakeup          = make(chan struct{}, 1)
+
+	mu      sync.Mutex
+	pace    *pacer
+	catchUp domainSend.CatchUp
+)
+
+// Init sets the repository of the send queue and the chat storage of the default device, sent messages are
+// stored in the chat storage of their device
+func Init(repository domainSend.ISendJobRepository, chatStorage domainChatStorage.IChatStorageRepository) error {
+	rate, err := domainRateLimit.ParseLimit(config.WhatsappSendQueueRate)
+	if err != nil {
+		return fmt.Errorf("send queue rate: %w", err)
+	}
+	policy, err := domainSend.ParseCatchUp(config.WhatsappScheduleCatchUp)
+	if err != nil {
+		return fmt.Errorf("schedule catch-up: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	repo, chatStorageRepo = repository, chatStorage
+	pace = newPacer(rate.Per/time.Duration(rate.Count), config.WhatsappSendQueueJitter, config.WhatsappSendQueueRecipientInterval)
+	catchUp = policy
+	return nil
+}
+
+// Enabled reports whether sends are queued instead of sent right away
+func Enabled() bool {
+	return config.WhatsappSendQueue && repo != nil
+}
+
+// Enqueue stores a job in the send queue. When the device and actor already enqueued a job with the same
+// idempotency key that job is returned instead and false tells it was not created.
+func Enqueue(ctx context.Context, job *domainSend.Job) (*domainSend.Job, bool, error) {
+	if repo == nil {
+		return nil, false, pkgError.InternalServerError("send queue is not initialized")
+	}
+
+	created, err := repo.EnqueueJob(ctx, job)
+	if err != nil {
+		return nil, false, pkgError.InternalServerError(fmt.Sprintf("failed to queue message: %v", err))
+	}
+	if !created {
+		existing, err := repo.GetJobByIdempotencyKey(ctx, job.DeviceID, job.Actor, job.IdempotencyKey)
+		if err != nil {
+			return nil, false, pkgError.InternalServerError(fmt.Sprintf("failed to load queued message: %v", err))
+		}
+		if existing == nil {
+			return nil, false, pkgError.InternalServerError("queued message of the idempotency key disappeared")
+		}
+		if existing.Recipient != job.Recipient {
+			return nil, false, pkgError.ConflictError(fmt.Sprintf("Idempotency-Key %s was already used for a message to %s", job.IdempotencyKey, existing.Recipient))
+		}
+		return existing, false, nil
+	}
+
+	logrus.Debugf("Message %s to %s queued as job %s", job.MessageID, job.Recipient, job.ID)
+	Wakeup()
+	return job, true, nil
+}
+
+// Wakeup makes the worker look for due jobs without waiting for the next poll
+func Wakeup() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends the queued and scheduled jobs one by one until the context is cancelled. It also sends the jobs
+// left over when the queue has been disabled since they were queued.
+func Run(ctx context.Context) {
+	if repo == nil {
+		logrus.Warn("Send queue worker not started: the queue is not initialized")
+		return
+	}
+
+	// Whether a job left sending by a previous run reached WhatsApp is unknown, sending it again could duplicate it
+	if failed, err := repo.FailSendingJobs(ctx, "interrupted while sending, it may not have been sent"); err != nil {
+		logrus.Errorf("Failed to fail interrupted send jobs: %v", err)
+	} else if failed > 0 {
+		logrus.Warnf("%d send jobs were interrupted while sending and marked as failed", failed)
+	}
+	catchUpMissed(ctx, time.Now().UTC())
+
+	lastPurge := time.Time{}
+	for {
+		wait := sendNext(ctx)
+
+		if time.Since(lastPurge) >= purgeInterval {
+			purgeFinished(ctx)
+			lastPurge = time.Now()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-wakeup:
+			timer.Stop()
+		}
+	}
+}
+
+// sendNext sends the next job the pacing allows and returns how long to wait before looking again. mu only guards
+// the pacer, it is not held while loading or sending a job, which includes the typing indicator.
+func sendNext(ctx context.Context) time.Duration {
+	mu.Lock()
+	paced := pace
+	wait := paced.wait(time.Now().UTC())
+	mu.Unlock()
+	if wait > 0 {
+		return wait
+	}
+
+	now := time.Now().UTC()
+	jobs, err := repo.GetDueJobs(ctx, now, dueBatch)
+	if err != nil {
+		logrus.Errorf("Failed to load due send jobs: %v", err)
+		return pollInterval
+	}
+
+	mu.Lock()
+	job, wait := paced.pick(jobs, now)
+	mu.Unlock()
+	if job == nil {
+		if wait == 0 || wait > pollInterval {
+			wait = pollInterval
+		}
+		return wait
+	}
+
+	if attempted := send(ctx, job); attempted {
+		mu.Lock()
+		paced.record(job, time.Now().UTC())
+		mu.Unlock()
+	}
+	// Look for the next job right away, it still waits for the pacing
+	return 0
+}
+
+// send sends a job once and records the outcome. It returns false when nothing was sent to WhatsApp.
+func send(ctx context.Context, job *domainSend.Job) bool {
+	claimed, err := repo.MarkSending(ctx, job.ID)
+	if err != nil {
+		logrus.Errorf("Failed to claim send job %s: %v", job.ID, err)
+		return false
+	}
+	if !claimed {
+		// Cancelled since it was loaded
+		return false
+	}
+
+	if !whatsapp.DeviceExists(job.DeviceID) {
+		// The session of the job was removed, another device must not send it
+		fail(ctx, job, job.Attempts, fmt.Sprintf("device %s: %s", job.DeviceID, pkgError.ErrDeviceNotFound.Error()))
+		return false
+	}
+	deviceCtx := whatsapp.ContextWithDevice(ctx, job.DeviceID)
+	client := whatsapp.ClientFromContext(deviceCtx)
+	if client == nil || !client.IsLoggedIn() {
+		retry(ctx, job, job.Attempts, time.Now().UTC().Add(notLoggedInDelay), pkgError.ErrNotLoggedIn.Error())
+		return false
+	}
+
+	recipient, err := types.ParseJID(job.Recipient)
+	if err != nil {
+		fail(ctx, job, job.Attempts, fmt.Sprintf("invalid recipient: %v", err))
+		return false
+	}
+	msg := &waE2E.Message{}
+	if err := proto.Unmarshal(job.Message, msg); err != nil {
+		fail(ctx, job, job.Attempts, fmt.Sprintf("invalid message: %v", err))
+		return false
+	}
+
+	if config.WhatsappSendQueueTyping {
+		showTyping(ctx, client, recipient, msg, job.Content)
+	}
+
+	attempts := job.Attempts + 1
+	// The message ID was generated when the job was queued, so a retry after a lost acknowledgement is
+	// recognized as the same message
+	resp, err := client.SendMessage(ctx, recipient, msg, whatsmeow.SendRequestExtra{ID: types.MessageID(job.MessageID)})
+	if err != nil {
+		if attempts >= maxAttempts {
+			fail(ctx, job, attempts, err.Error())
+		} else {
+			nextAttemptAt := time.Now().UTC().Add(backoff(attempts))
+			logrus.Warnf("Attempt %d to send job %s failed, retrying at %s: %v", attempts, job.ID, nextAttemptAt.Format(time.RFC3339), err)
+			retry(ctx, job, attempts, nextAttemptAt, err.Error())
+		}
+		return true
+	}
+
+	logrus.Infof("Send job %s sent as message %s to %s on attempt %d", job.ID, resp.ID, job.Recipient, attempts)
+	if err := repo.MarkSent(ctx, job.ID, attempts, resp.Timestamp); err != nil {
+		logrus.Errorf("Failed to mark send job %s as sent: %v", job.ID, err)
+	}
+	storeSentMessage(deviceCtx, client, job, msg, resp)
+
+	job.Status, job.Attempts, job.LastError, job.SentAt = domainSend.JobStatusSent, attempts, "", &resp.Timestamp
+	reportOutcome(ctx, job)
+	return true
+}
+
+// showTyping shows the typing, or recording for voice notes, indicator for a time that grows with the message
+func showTyping(ctx context.Context, client *whatsmeow.Client, recipient types.JID, msg *waE2E.Message, content string) {
+	media := types.ChatPresenceMediaText
+	if msg.GetAudioMessage() != nil {
+		media = types.ChatPresenceMediaAudio
+	}
+	if err := client.SendChatPresence(recipient, types.ChatPresenceComposing, media); err != nil {
+		logrus.Debugf("Failed to show typing indicator to %s: %v", recipient, err)
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(typingDuration(content)):
+	}
+
+	if err := client.SendChatPresence(recipient, types.ChatPresencePaused, media); err != nil {
+		logrus.Debugf("Failed to clear typing indicator to %s: %v", recipient, err)
+	}
+}
+
+// storeSentMessage stores the sent message in the chat storage of its device, like the messages sent right away
+func storeSentMessage(ctx context.Context, client *whatsmeow.Client, job *domainSend.Job, msg *waE2E.Message, resp whatsmeow.SendResponse) {
+	senderJID := ""
+	if client.Store.ID != nil {
+		senderJID = client.Store.ID.String()
+	}
+
+	repository, err := whatsapp.ChatStorageFromContext(ctx, chatStorageRepo)
+	if err != nil || repository == nil {
+		logrus.Warnf("Skipping storage of sent message %s: %v", resp.ID, err)
+		return
+	}
+
+	storeCtx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+	if err := repository.StoreSentMessageWithContext(storeCtx, resp.ID, senderJID, job.Recipient, job.Content, resp.Timestamp); err != nil {
+		logrus.Warnf("Failed to store sent message %s: %v", resp.ID, err)
+	}
+	if client.Store.ID != nil {
+		if recipient, err := types.ParseJID(job.Recipient); err == nil {
+			if err := whatsapp.StorePoll(repository, resp.ID, recipient, *client.Store.ID, msg, resp.Timestamp); err != nil {
+				logrus.Warnf("Failed to store sent poll %s: %v", resp.ID, err)
+			}
+		}
+	}
+}
+
+func retry(ctx context.Context, job *domainSend.Job, attempts int, nextAttemptAt time.Time, reason string) {
+	if err := repo.MarkRetry(ctx, job.ID, attempts, nextAttemptAt, reason); err != nil {
+		logrus.Errorf("Failed to reschedule send job %s: %v", job.ID, err)
+	}
+}
+
+func fail(ctx context.Context, job *domainSend.Job, attempts int, reason string) {
+	logrus.Errorf("Send job %s to %s failed after %d attempts: %s", job.ID, job.Recipient, attempts, reason)
+	if err := repo.MarkFailed(ctx, job.ID, attempts, reason); err != nil {
+		logrus.Errorf("Failed to mark send job %s as failed: %v", job.ID, err)
+	}
+
+	job.Status, job.Attempts, job.LastError = domainSend.JobStatusFailed, attempts, reason
+	reportOutcome(ctx, job)
+}
+
+// catchUpMissed applies the catch-up policy to the scheduled jobs that were due while the server was not running.
+// The jobs the policy still sends are left queued, the others fail.
+func catchUpMissed(ctx context.Context, startedAt time.Time) {
+	jobs, err := repo.GetMissedJobs(ctx, startedAt)
+	if err != nil {
+		logrus.Errorf("Failed to load missed scheduled jobs: %v", err)
+		return
+	}
+
+	skipped := 0
+	for _, job := range jobs {
+		delay := startedAt.Sub(*job.ScheduledAt)
+		if catchUp.Sends(delay) {
+			continue
+		}
+		// Claim the job first, it may have been cancelled in the meantime
+		if claimed, err := repo.MarkSending(ctx, job.ID); err != nil || !claimed {
+			continue
+		}
+		fail(ctx, job, job.Attempts, fmt.Sprintf("missed its schedule by %s while the server was not running, skipped by the catch-up policy", delay.Round(time.Second)))
+		skipped++
+	}
+
+	if len(jobs) > 0 {
+		logrus.Infof("%d scheduled jobs were missed while the server was not running, %d are sent late and %d skipped", len(jobs), len(jobs)-skipped, skipped)
+	}
+}
+
+// backoff returns the delay before the next attempt, doubling from baseBackoff up to maxBackoff
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func purgeFinished(ctx context.Context) {
+	purged, err := repo.PurgeFinishedJobsBefore(ctx, time.Now().UTC().Add(-finishedRetention))
+	if err != nil {
+		logrus.Errorf("Failed to purge finished send jobs: %v", err)
+		return
+	}
+	if purged > 0 {
+		logrus.Infof("Purged %d finished send jobs", purged)
+	}
+}
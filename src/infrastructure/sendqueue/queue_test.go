@@ -0,0 +1,48 @@
+package sendqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRepository blocks loading the due jobs until unblock is closed, it has no other methods
+type blockingRepository struct {
+	domainSend.ISendJobRepository
+	loading chan struct{}
+	unblock chan struct{}
+}
+
+func (r *blockingRepository) GetDueJobs(context.Context, time.Time, int) ([]*domainSend.Job, error) {
+	close(r.loading)
+	<-r.unblock
+	return nil, nil
+}
+
+func TestSendNextReleasesLock(t *testing.T) {
+	blocking := &blockingRepository{loading: make(chan struct{}), unblock: make(chan struct{})}
+	require.NoError(t, Init(blocking, nil))
+	t.Cleanup(func() { repo, chatStorageRepo = nil, nil })
+
+	next := make(chan time.Duration, 1)
+	go func() { next <- sendNext(context.Background()) }()
+	<-blocking.loading
+	defer func() {
+		close(blocking.unblock)
+		assert.Equal(t, pollInterval, <-next)
+	}()
+
+	// A slow database, like a slow send, must not block the callers of the lock
+	done := make(chan error, 1)
+	go func() { done <- Init(blocking, nil) }()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("sendNext held the lock while loading the due jobs")
+	}
+}
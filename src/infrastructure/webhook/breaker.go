@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+const (
+	breakerFailureThreshold = 5                // consecutive failures that open the circuit
+	breakerBaseCooldown     = 30 * time.Second // first pause before a trial request
+	breakerMaxCooldown      = 10 * time.Minute
+)
+
+// circuitBreaker stops sending to an endpoint after repeated failures. Once the cooldown has passed a single
+// trial request is let through (half-open): success closes the circuit, failure opens it again for twice as long.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	cooldown  time.Duration
+	openedAt  time.Time
+	retryAt   time.Time
+	trialBusy bool
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{state: domainWebhook.CircuitClosed, cooldown: breakerBaseCooldown}
+}
+
+// Allow reports whether a request may be sent now. When it may not, it returns when to try again.
+func (b *circuitBreaker) Allow(now time.Time) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case domainWebhook.CircuitOpen:
+		if now.Before(b.retryAt) {
+			return false, b.retryAt
+		}
+		b.state = domainWebhook.CircuitHalfOpen
+		b.trialBusy = true
+		return true, time.Time{}
+	case domainWebhook.CircuitHalfOpen:
+		if b.trialBusy {
+			return false, now.Add(breakerBaseCooldown)
+		}
+		b.trialBusy = true
+		return true, time.Time{}
+	default:
+		return true, time.Time{}
+	}
+}
+
+// Success records a delivered request and closes the circuit
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = domainWebhook.CircuitClosed
+	b.failures = 0
+	b.cooldown = breakerBaseCooldown
+	b.trialBusy = false
+}
+
+// Failure records a failed request and opens the circuit when the endpoint looks down
+func (b *circuitBreaker) Failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	switch b.state {
+	case domainWebhook.CircuitHalfOpen:
+		b.cooldown = min(b.cooldown*2, breakerMaxCooldown)
+		b.open(now)
+	case domainWebhook.CircuitClosed:
+		if b.failures >= breakerFailureThreshold {
+			b.open(now)
+		}
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = domainWebhook.CircuitOpen
+	b.openedAt = now
+	b.retryAt = now.Add(b.cooldown)
+	b.trialBusy = false
+}
+
+// Reset closes the circuit, e.g. after the receiver has been fixed
+func (b *circuitBreaker) Reset() {
+	b.Success()
+}
+
+// Info returns the current state of the circuit
+func (b *circuitBreaker) Info() domainWebhook.CircuitInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info := domainWebhook.CircuitInfo{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != domainWebhook.CircuitClosed {
+		info.OpenedAt = b.openedAt.Format(time.RFC3339)
+		info.RetryAt = b.retryAt.Format(time.RFC3339)
+	}
+	return info
+}
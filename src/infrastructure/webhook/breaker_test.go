@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+var breakerStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// openBreaker returns a breaker opened at breakerStart by consecutive failures
+func openBreaker() *circuitBreaker {
+	breaker := newCircuitBreaker()
+	for range breakerFailureThreshold {
+		breaker.Failure(breakerStart)
+	}
+	return breaker
+}
+
+func TestCircuitBreakerOpensAtThreshold(t *testing.T) {
+	breaker := newCircuitBreaker()
+	for range breakerFailureThreshold - 1 {
+		breaker.Failure(breakerStart)
+	}
+	assert.Equal(t, domainWebhook.CircuitClosed, breaker.Info().State)
+	allowed, _ := breaker.Allow(breakerStart)
+	assert.True(t, allowed)
+
+	breaker.Failure(breakerStart)
+	info := breaker.Info()
+	assert.Equal(t, domainWebhook.CircuitOpen, info.State)
+	assert.Equal(t, breakerFailureThreshold, info.ConsecutiveFailures)
+
+	allowed, retryAt := breaker.Allow(breakerStart.Add(time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, breakerStart.Add(breakerBaseCooldown), retryAt)
+}
+
+func TestCircuitBreakerHalfOpenAfterCooldown(t *testing.T) {
+	breaker := openBreaker()
+
+	allowed, _ := breaker.Allow(breakerStart.Add(breakerBaseCooldown - time.Second))
+	assert.False(t, allowed)
+
+	// A single trial request is let through once the cooldown has passed
+	allowed, _ = breaker.Allow(breakerStart.Add(breakerBaseCooldown))
+	assert.True(t, allowed)
+	assert.Equal(t, domainWebhook.CircuitHalfOpen, breaker.Info().State)
+
+	allowed, _ = breaker.Allow(breakerStart.Add(breakerBaseCooldown))
+	assert.False(t, allowed)
+}
+
+func TestCircuitBreakerCooldownDoubles(t *testing.T) {
+	breaker := openBreaker()
+	now := breakerStart
+
+	for _, cooldown := range []time.Duration{
+		time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, breakerMaxCooldown, breakerMaxCooldown,
+	} {
+		now = now.Add(breaker.cooldown)
+		allowed, _ := breaker.Allow(now)
+		assert.True(t, allowed)
+
+		// The failed trial opens the circuit again for twice as long
+		breaker.Failure(now)
+		allowed, retryAt := breaker.Allow(now)
+		assert.False(t, allowed)
+		assert.Equal(t, now.Add(cooldown), retryAt)
+	}
+}
+
+func TestCircuitBreakerSuccessResets(t *testing.T) {
+	breaker := openBreaker()
+	trial := breakerStart.Add(breakerBaseCooldown)
+	allowed, _ := breaker.Allow(trial)
+	assert.True(t, allowed)
+	breaker.Failure(trial)
+
+	now := trial.Add(breaker.cooldown)
+	allowed, _ = breaker.Allow(now)
+	assert.True(t, allowed)
+	breaker.Success()
+
+	info := breaker.Info()
+	assert.Equal(t, domainWebhook.CircuitClosed, info.State)
+	assert.Zero(t, info.ConsecutiveFailures)
+	assert.Empty(t, info.RetryAt)
+
+	// The next failures start over from the threshold and the base cooldown
+	for range breakerFailureThreshold - 1 {
+		breaker.Failure(now)
+	}
+	assert.Equal(t, domainWebhook.CircuitClosed, breaker.Info().State)
+	breaker.Failure(now)
+	_, retryAt := breaker.Allow(now)
+	assert.Equal(t, now.Add(breakerBaseCooldown), retryAt)
+}
@@ -0,0 +1,289 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pollInterval    = 5 * time.Second
+	purgeInterval   = time.Hour
+	claimLease      = 5 * time.Minute // a claimed delivery is picked up again if it was not attempted within the lease
+	claimBatch      = 50
+	requestTimeout  = 10 * time.Second
+	maxResponseSize = 64 << 10 // response bytes read for actions, the rest is discarded
+
+	baseBackoff = 5 * time.Second
+	maxBackoff  = 30 * time.Minute
+)
+
+var (
+	repo   domainWebhook.IWebhookRepository
+	wakeup = make(chan struct{}, 1)
+
+	// client is shared by all endpoint workers so connections to the same receiver are reused
+	client = &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: workersPerEndpoint,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: requestTimeout,
+		},
+	}
+)
+
+// InitDispatcher sets the repository used to persist and deliver webhooks and loads the endpoints
+func InitDispatcher(ctx context.Context, repository domainWebhook.IWebhookRepository) error {
+	repo = repository
+	if len(legacyEndpoints()) > 0 && config.WhatsappWebhookSecret == "secret" {
+		logrus.Warn("Webhooks are signed with the default secret, set --webhook-secret so receivers can trust the signature")
+	}
+	return ReloadEndpoints(ctx)
+}
+
+// Enqueue stores the event for the endpoint in the outbox, in the payload version of the endpoint.
+// The dispatcher delivers it in the background.
+func Enqueue(ctx context.Context, endpoint *domainWebhook.Endpoint, event domainWebhook.Event) error {
+	if repo == nil {
+		return pkgError.WebhookError("webhook outbox is not initialized")
+	}
+
+	body, err := json.Marshal(event.Payload(endpoint.PayloadVersion))
+	if err != nil {
+		return pkgError.WebhookError(fmt.Sprintf("Failed to marshal body: %v", err))
+	}
+
+	now := time.Now().UTC()
+	delivery := &domainWebhook.Delivery{
+		EndpointID:    endpoint.ID,
+		URL:           endpoint.URL,
+		Event:         event.Type,
+		Payload:       string(body),
+		Status:        domainWebhook.DeliveryStatusPending,
+		NextAttemptAt: now,
+		RetryUntil:    now.Add(config.WhatsappWebhookRetryHorizon),
+	}
+	if event.Origin != nil {
+		delivery.Origin = *event.Origin
+	}
+	if err := repo.EnqueueDelivery(ctx, delivery); err != nil {
+		return pkgError.WebhookError(fmt.Sprintf("error when store webhook in outbox: %v", err))
+	}
+
+	Wakeup()
+	return nil
+}
+
+// Wakeup makes the dispatcher look for due deliveries without waiting for the next poll
+func Wakeup() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// RunDispatcher delivers due webhooks from the outbox until the context is cancelled
+func RunDispatcher(ctx context.Context) {
+	if repo == nil {
+		logrus.Warn("Webhook dispatcher not started: outbox is not initialized")
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastPurge := time.Time{}
+	for {
+		dispatchDue(ctx)
+
+		if time.Since(lastPurge) >= purgeInterval {
+			purgeDelivered(ctx)
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wakeup:
+		}
+	}
+}
+
+// dispatchDue claims due deliveries batch by batch and fans them out to the worker of their endpoint
+func dispatchDue(ctx context.Context) {
+	for {
+		deliveries, err := repo.ClaimDueDeliveries(ctx, time.Now().UTC(), claimLease, claimBatch)
+		if err != nil {
+			logrus.Errorf("Failed to claim webhook deliveries: %v", err)
+			return
+		}
+
+		saturated := false
+		for _, delivery := range deliveries {
+			// A full queue leaves the delivery in the outbox, it is claimed again once its lease expires
+			if !workerFor(ctx, workerKey(delivery)).offer(delivery) {
+				logrus.Debugf("Webhook queue of %s is full, delivery %d stays in the outbox", workerKey(delivery), delivery.ID)
+				saturated = true
+			}
+		}
+
+		if saturated || len(deliveries) < claimBatch {
+			return
+		}
+	}
+}
+
+// attemptDelivery sends a delivery once and records the outcome
+func attemptDelivery(ctx context.Context, breaker *circuitBreaker, delivery *domainWebhook.Delivery) {
+	attempts := delivery.Attempts + 1
+
+	// Deliveries follow the current settings of their endpoint, so URL and secret changes apply to pending retries
+	url, secrets := delivery.URL, []string{config.WhatsappWebhookSecret}
+	if delivery.EndpointID != "" {
+		endpoint := GetEndpoint(delivery.EndpointID)
+		if endpoint == nil || !endpoint.Enabled {
+			reason := fmt.Sprintf("webhook endpoint %s was removed or disabled", delivery.EndpointID)
+			logrus.Warnf("Webhook %d moved to dead-letter: %s", delivery.ID, reason)
+			if err := repo.MarkFailed(ctx, delivery.ID, delivery.Attempts, reason); err != nil {
+				logrus.Errorf("Failed to mark webhook %d as failed: %v", delivery.ID, err)
+			}
+			return
+		}
+		url, secrets = endpoint.URL, endpoint.Secrets(time.Now())
+	}
+
+	// While the circuit is open the delivery waits without using up an attempt
+	if allowed, retryAt := breaker.Allow(time.Now().UTC()); !allowed {
+		postpone(ctx, delivery, retryAt)
+		return
+	}
+
+	response, err := send(ctx, url, strconv.FormatInt(delivery.ID, 10), secrets, []byte(delivery.Payload))
+	if err == nil {
+		breaker.Success()
+		logrus.Infof("Successfully submitted webhook %d (%s) on attempt %d", delivery.ID, delivery.Event, attempts)
+		if err := repo.MarkDelivered(ctx, delivery.ID, attempts, time.Now().UTC()); err != nil {
+			logrus.Errorf("Failed to mark webhook %d as delivered: %v", delivery.ID, err)
+		}
+		handleResponse(ctx, delivery, response)
+		return
+	}
+	breaker.Failure(time.Now().UTC())
+
+	nextAttemptAt := time.Now().UTC().Add(Backoff(attempts))
+	if nextAttemptAt.After(delivery.RetryUntil) {
+		logrus.Errorf("Webhook %d to %s moved to dead-letter after %d attempts: %v", delivery.ID, delivery.URL, attempts, err)
+		if err := repo.MarkFailed(ctx, delivery.ID, attempts, err.Error()); err != nil {
+			logrus.Errorf("Failed to mark webhook %d as failed: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	logrus.Warnf("Attempt %d to submit webhook %d failed, retrying at %s: %v", attempts, delivery.ID, nextAttemptAt.Format(time.RFC3339), err)
+	if err := repo.MarkRetry(ctx, delivery.ID, attempts, nextAttemptAt, err.Error()); err != nil {
+		logrus.Errorf("Failed to reschedule webhook %d: %v", delivery.ID, err)
+	}
+}
+
+// postpone reschedules a delivery held back by an open circuit
+func postpone(ctx context.Context, delivery *domainWebhook.Delivery, retryAt time.Time) {
+	if retryAt.After(delivery.RetryUntil) {
+		reason := fmt.Sprintf("circuit of webhook endpoint %s stayed open until the retry horizon", workerKey(delivery))
+		if delivery.LastError != "" {
+			reason += ": " + delivery.LastError
+		}
+		logrus.Errorf("Webhook %d moved to dead-letter: %s", delivery.ID, reason)
+		if err := repo.MarkFailed(ctx, delivery.ID, delivery.Attempts, reason); err != nil {
+			logrus.Errorf("Failed to mark webhook %d as failed: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	if err := repo.MarkRetry(ctx, delivery.ID, delivery.Attempts, retryAt, delivery.LastError); err != nil {
+		logrus.Errorf("Failed to postpone webhook %d: %v", delivery.ID, err)
+	}
+}
+
+// send posts a payload to the webhook URL and returns the body of the response. The body, delivery ID and
+// timestamp are signed with every active secret so receivers keep verifying while a secret is rotated.
+func send(ctx context.Context, url string, deliveryID string, secrets []string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, pkgError.WebhookError(fmt.Sprintf("error when create http object %v", err))
+	}
+
+	// X-Hub-Signature-256 only covers the body and is kept for existing receivers
+	signature, err := utils.GetMessageDigestOrSignature(body, []byte(secrets[0]))
+	if err != nil {
+		return nil, pkgError.WebhookError(fmt.Sprintf("error when create signature %v", err))
+	}
+
+	timestamp := time.Now().Unix()
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, utils.SignWebhook(secret, deliveryID, timestamp, body))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Signature-256", fmt.Sprintf("sha256=%s", signature))
+	req.Header.Set(utils.WebhookIDHeader, deliveryID)
+	req.Header.Set(utils.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(utils.WebhookSignatureHeader, strings.Join(signatures, " "))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return response, nil
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// purgeDelivered removes delivered entries older than the retry horizon
+func purgeDelivered(ctx context.Context) {
+	purged, err := repo.PurgeDeliveredBefore(ctx, time.Now().UTC().Add(-config.WhatsappWebhookRetryHorizon))
+	if err != nil {
+		logrus.Errorf("Failed to purge delivered webhooks: %v", err)
+		return
+	}
+	if purged > 0 {
+		logrus.Infof("Purged %d delivered webhook(s) from the outbox", purged)
+	}
+}
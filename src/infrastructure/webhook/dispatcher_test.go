@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		0:  baseBackoff,
+		1:  baseBackoff,
+		2:  10 * time.Second,
+		3:  20 * time.Second,
+		9:  1280 * time.Second,
+		10: maxBackoff,
+		50: maxBackoff,
+	} {
+		assert.Equal(t, want, Backoff(attempts), "attempts %d", attempts)
+	}
+}
+
+// useOutbox points the dispatcher at an outbox in a temporary SQLite database
+func useOutbox(t *testing.T) domainWebhook.IWebhookRepository {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "chat.db")+"?_journal_mode=WAL")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, chatstorage.NewStorageRepository(db).InitializeSchema())
+
+	repo = chatstorage.NewSQLiteWebhookRepository(db)
+	t.Cleanup(func() { repo = nil })
+	return repo
+}
+
+// receiver answers every webhook with the current status
+func receiver(t *testing.T, status *atomic.Int64) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func enqueueDelivery(t *testing.T, outbox domainWebhook.IWebhookRepository, url string, retryUntil time.Time) *domainWebhook.Delivery {
+	t.Helper()
+	delivery := &domainWebhook.Delivery{URL: url, Event: "message", Payload: `{}`, RetryUntil: retryUntil}
+	require.NoError(t, outbox.EnqueueDelivery(context.Background(), delivery))
+	return delivery
+}
+
+func getDelivery(t *testing.T, outbox domainWebhook.IWebhookRepository, id int64) *domainWebhook.Delivery {
+	t.Helper()
+	delivery, err := outbox.GetDelivery(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	return delivery
+}
+
+func TestAttemptDeliveryRetry(t *testing.T) {
+	outbox := useOutbox(t)
+	var status atomic.Int64
+	status.Store(http.StatusInternalServerError)
+	url := receiver(t, &status)
+
+	delivery := enqueueDelivery(t, outbox, url, time.Now().UTC().Add(time.Hour))
+	before := time.Now().UTC()
+	attemptDelivery(context.Background(), newCircuitBreaker(), delivery)
+
+	retried := getDelivery(t, outbox, delivery.ID)
+	assert.Equal(t, domainWebhook.DeliveryStatusPending, retried.Status)
+	assert.Equal(t, 1, retried.Attempts)
+	assert.Contains(t, retried.LastError, "status 500")
+	assert.WithinDuration(t, before.Add(Backoff(1)), retried.NextAttemptAt, 2*time.Second)
+}
+
+func TestAttemptDeliveryDeadLetter(t *testing.T) {
+	outbox := useOutbox(t)
+	var status atomic.Int64
+	status.Store(http.StatusInternalServerError)
+	url := receiver(t, &status)
+
+	// The next attempt would be after the retry horizon
+	delivery := enqueueDelivery(t, outbox, url, time.Now().UTC().Add(time.Second))
+	delivery.Attempts = 3
+	attemptDelivery(context.Background(), newCircuitBreaker(), delivery)
+
+	failed := getDelivery(t, outbox, delivery.ID)
+	assert.Equal(t, domainWebhook.DeliveryStatusFailed, failed.Status)
+	assert.Equal(t, 4, failed.Attempts)
+	assert.Contains(t, failed.LastError, "status 500")
+
+	// Replaying delivers it again from the first attempt
+	status.Store(http.StatusOK)
+	now := time.Now().UTC()
+	replayed, err := outbox.ReplayDelivery(context.Background(), delivery.ID, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, replayed)
+	claimed, err := outbox.ClaimDueDeliveries(context.Background(), now, claimLease, claimBatch)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	attemptDelivery(context.Background(), newCircuitBreaker(), claimed[0])
+
+	delivered := getDelivery(t, outbox, delivery.ID)
+	assert.Equal(t, domainWebhook.DeliveryStatusDelivered, delivered.Status)
+	assert.Equal(t, 1, delivered.Attempts)
+	assert.NotNil(t, delivered.DeliveredAt)
+}
+
+func TestReplayedDeliverySkipsRanResponseActions(t *testing.T) {
+	outbox := useOutbox(t)
+	var body atomic.Value
+	body.Store(`{"actions": [{"type": "mark_read"}, {"type": "reply", "text": "Hi"}]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	t.Cleanup(server.Close)
+
+	type run struct {
+		actions []domainWebhook.ResponseAction
+		first   int
+	}
+	runs := make(chan run, 4)
+	SetResponseHandler(func(_ context.Context, _ *domainWebhook.Delivery, actions []domainWebhook.ResponseAction, first int) {
+		runs <- run{actions: actions, first: first}
+	})
+	t.Cleanup(func() { SetResponseHandler(nil) })
+
+	delivery := &domainWebhook.Delivery{
+		URL: server.URL, Event: "message", Payload: `{}`, RetryUntil: time.Now().UTC().Add(time.Hour),
+		Origin: domainWebhook.Origin{ChatJID: "6289876543210@s.whatsapp.net", MessageID: "3EB0B430B6F8F1D0E053AC120E0A9E5C"},
+	}
+	require.NoError(t, outbox.EnqueueDelivery(context.Background(), delivery))
+
+	replay := func() {
+		t.Helper()
+		now := time.Now().UTC()
+		replayed, err := outbox.ReplayDelivery(context.Background(), delivery.ID, now, now.Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, replayed)
+		attemptDelivery(context.Background(), newCircuitBreaker(), getDelivery(t, outbox, delivery.ID))
+	}
+	next := func() (run, bool) {
+		select {
+		case r := <-runs:
+			return r, true
+		case <-time.After(200 * time.Millisecond):
+			return run{}, false
+		}
+	}
+
+	attemptDelivery(context.Background(), newCircuitBreaker(), delivery)
+	first, ok := next()
+	require.True(t, ok)
+	assert.Len(t, first.actions, 2)
+	assert.Zero(t, first.first)
+
+	// The same response to a replay runs nothing again
+	replay()
+	_, ok = next()
+	assert.False(t, ok)
+
+	// A longer response runs only the actions that did not run yet
+	body.Store(`{"actions": [{"type": "mark_read"}, {"type": "reply", "text": "Hi"}, {"type": "react", "emoji": "👍"}]}`)
+	replay()
+	second, ok := next()
+	require.True(t, ok)
+	assert.Len(t, second.actions, 3)
+	assert.Equal(t, 2, second.first)
+}
+
+func TestAttemptDeliveryOpenCircuit(t *testing.T) {
+	outbox := useOutbox(t)
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	previousWebhooks := config.WhatsappWebhook
+	t.Cleanup(func() {
+		config.WhatsappWebhook = previousWebhooks
+		require.NoError(t, ReloadEndpoints(context.Background()))
+	})
+	config.WhatsappWebhook = []string{server.URL}
+	require.NoError(t, ReloadEndpoints(context.Background()))
+	endpointID := LegacyEndpointID(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	breaker := workerFor(ctx, endpointID).breaker
+	now := time.Now().UTC()
+	for range breakerFailureThreshold {
+		breaker.Failure(now)
+	}
+
+	// The open circuit postpones the delivery until the trial request, without sending it or using an attempt
+	delivery := &domainWebhook.Delivery{EndpointID: endpointID, URL: server.URL, Event: "message", Payload: `{}`, RetryUntil: now.Add(time.Hour)}
+	require.NoError(t, outbox.EnqueueDelivery(ctx, delivery))
+	attemptDelivery(ctx, breaker, delivery)
+	postponed := getDelivery(t, outbox, delivery.ID)
+	assert.Zero(t, requests.Load())
+	assert.Equal(t, domainWebhook.DeliveryStatusPending, postponed.Status)
+	assert.Zero(t, postponed.Attempts)
+	assert.WithinDuration(t, now.Add(breakerBaseCooldown), postponed.NextAttemptAt, 2*time.Second)
+
+	// Resetting the circuit makes the delivery due right away and lets it through
+	require.NoError(t, ResetCircuit(ctx, endpointID))
+	assert.Equal(t, domainWebhook.CircuitClosed, Circuit(endpointID).State)
+	claimed, err := outbox.ClaimDueDeliveries(ctx, time.Now().UTC().Add(time.Second), claimLease, claimBatch)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	attemptDelivery(ctx, breaker, claimed[0])
+	assert.Equal(t, int64(1), requests.Load())
+	assert.Equal(t, domainWebhook.DeliveryStatusDelivered, getDelivery(t, outbox, delivery.ID).Status)
+}
+
+func TestDispatchDueIsolatesEndpoints(t *testing.T) {
+	outbox := useOutbox(t)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	var status atomic.Int64
+	status.Store(http.StatusOK)
+	fast := receiver(t, &status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer stopWorkers(nil, nil)
+
+	retryUntil := time.Now().UTC().Add(time.Hour)
+	var blocked []*domainWebhook.Delivery
+	for range workersPerEndpoint {
+		blocked = append(blocked, enqueueDelivery(t, outbox, slow.URL, retryUntil))
+	}
+	delivered := enqueueDelivery(t, outbox, fast, retryUntil)
+	dispatchDue(ctx)
+
+	// Every worker of the slow endpoint is busy, the other endpoint is delivered by its own
+	assert.Eventually(t, func() bool {
+		return getDelivery(t, outbox, delivered.ID).Status == domainWebhook.DeliveryStatusDelivered
+	}, time.Second, 10*time.Millisecond)
+	for _, delivery := range blocked {
+		assert.Zero(t, getDelivery(t, outbox, delivery.ID).Attempts)
+	}
+
+	close(release)
+	for _, delivery := range blocked {
+		assert.Eventually(t, func() bool {
+			return getDelivery(t, outbox, delivery.ID).Status == domainWebhook.DeliveryStatusDelivered
+		}, time.Second, 10*time.Millisecond)
+	}
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// LegacyEndpointPrefix prefixes the IDs of the endpoints created from the --webhook URLs
+const LegacyEndpointPrefix = "config-"
+
+var (
+	endpointsMu sync.RWMutex
+	endpoints   []*domainWebhook.Endpoint
+)
+
+// LegacyEndpointID returns the stable ID of an endpoint configured with --webhook
+func LegacyEndpointID(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return LegacyEndpointPrefix + hex.EncodeToString(sum[:])[:12]
+}
+
+// legacyEndpoints turns the configured webhook URLs into read-only endpoints subscribed to the legacy events
+func legacyEndpoints() []*domainWebhook.Endpoint {
+	var legacy []*domainWebhook.Endpoint
+	for _, url := range config.WhatsappWebhook {
+		if url == "" {
+			continue
+		}
+		legacy = append(legacy, &domainWebhook.Endpoint{
+			ID:             LegacyEndpointID(url),
+			URL:            url,
+			Secret:         config.WhatsappWebhookSecret,
+			Events:         slices.Clone(domainWebhook.LegacyEventTypes),
+			PayloadVersion: domainWebhook.PayloadVersion1,
+			Enabled:        true,
+			ReadOnly:       true,
+		})
+	}
+	return legacy
+}
+
+// ReloadEndpoints refreshes the in-memory endpoint list from the configuration and the chat storage.
+// It is called on boot and after every change made through the API, so changes apply without a restart.
+func ReloadEndpoints(ctx context.Context) error {
+	loaded := legacyEndpoints()
+
+	if repo != nil {
+		stored, err := repo.GetEndpoints(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load webhook endpoints: %w", err)
+		}
+		loaded = append(loaded, stored...)
+	}
+
+	endpointsMu.Lock()
+	previous := endpoints
+	endpoints = loaded
+	endpointsMu.Unlock()
+	stopWorkers(previous, loaded)
+
+	logrus.Debugf("Loaded %d webhook endpoint(s)", len(loaded))
+	return nil
+}
+
+// Endpoints returns every known endpoint, the configured ones first
+func Endpoints() []*domainWebhook.Endpoint {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	return slices.Clone(endpoints)
+}
+
+// GetEndpoint returns the endpoint with the given ID, or nil when it does not exist
+func GetEndpoint(id string) *domainWebhook.Endpoint {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	for _, endpoint := range endpoints {
+		if endpoint.ID == id {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the endpoints that want the given event of the given chat
+func Subscribers(eventType string, chatJID string) []*domainWebhook.Endpoint {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+
+	var subscribers []*domainWebhook.Endpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Accepts(eventType, chatJID) {
+			subscribers = append(subscribers, endpoint)
+		}
+	}
+	return subscribers
+}
+
+// HasSubscribers reports whether any enabled endpoint subscribes to the event type, regardless of chat filters
+func HasSubscribers(eventType string) bool {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	for _, endpoint := range endpoints {
+		if endpoint.Enabled && slices.Contains(endpoint.Events, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish stores the event in the outbox once for every endpoint subscribed to it, in the payload version of the endpoint
+func Publish(ctx context.Context, event domainWebhook.Event) error {
+	subscribers := Subscribers(event.Type, event.ChatJID)
+	if len(subscribers) == 0 {
+		return nil
+	}
+	logrus.Infof("Forwarding %s event to %d webhook endpoint(s)", event.Type, len(subscribers))
+
+	var errs []error
+	for _, endpoint := range subscribers {
+		if err := Enqueue(ctx, endpoint, event); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s failed: %w", endpoint.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
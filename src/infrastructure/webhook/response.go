@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// ResponseHandler runs the actions a receiver returned in its response to a delivery, from the action at index first.
+// The actions before it already ran for an earlier attempt or replay of the delivery.
+type ResponseHandler func(ctx context.Context, delivery *domainWebhook.Delivery, actions []domainWebhook.ResponseAction, first int)
+
+var responseHandler ResponseHandler
+
+// SetResponseHandler registers the handler running the actions of webhook responses. It is set at startup,
+// without a handler the responses are ignored.
+func SetResponseHandler(handler ResponseHandler) {
+	responseHandler = handler
+}
+
+// handleResponse passes the actions of a response to the handler. Only deliveries of an incoming message have
+// a chat to act on, the responses to other events are ignored.
+func handleResponse(ctx context.Context, delivery *domainWebhook.Delivery, body []byte) {
+	if responseHandler == nil || delivery.Origin.MessageID == "" {
+		return
+	}
+
+	actions, err := domainWebhook.ParseResponse(body)
+	if err != nil {
+		logrus.Warnf("Ignoring the response to webhook %d: %v", delivery.ID, err)
+		return
+	}
+	if len(actions) == 0 {
+		return
+	}
+
+	// A replayed delivery is answered again; the actions that already ran are skipped, so the replies are not sent
+	// twice also when there is no send queue to deduplicate them
+	ran, err := repo.ClaimResponseActions(ctx, delivery.ID, len(actions))
+	if err != nil {
+		logrus.Errorf("Ignoring the response to webhook %d: %v", delivery.ID, err)
+		return
+	}
+	if ran >= len(actions) {
+		logrus.Infof("Webhook %d responded with %d action(s) for message %s, they already ran", delivery.ID, len(actions), delivery.Origin.MessageID)
+		return
+	}
+
+	// Typing actions wait, the worker moves on to the next delivery meanwhile
+	logrus.Infof("Webhook %d responded with %d action(s) for message %s", delivery.ID, len(actions)-ran, delivery.Origin.MessageID)
+	go responseHandler(ctx, delivery, actions, ran)
+}
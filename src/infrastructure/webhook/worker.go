@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+)
+
+const (
+	workerQueueSize    = 64 // deliveries waiting per endpoint, the rest stay in the outbox
+	workersPerEndpoint = 4
+)
+
+// endpointWorker delivers the webhooks of a single endpoint, so a slow or dead endpoint never delays the others
+type endpointWorker struct {
+	queue   chan *domainWebhook.Delivery
+	breaker *circuitBreaker
+	stop    context.CancelFunc
+}
+
+var (
+	workersMu sync.Mutex
+	workers   = make(map[string]*endpointWorker)
+)
+
+// workerKey identifies the worker of a delivery. Deliveries enqueued before endpoints existed are keyed by URL.
+func workerKey(delivery *domainWebhook.Delivery) string {
+	if delivery.EndpointID != "" {
+		return delivery.EndpointID
+	}
+	return delivery.URL
+}
+
+// workerFor returns the worker of the key, starting it on first use
+func workerFor(ctx context.Context, key string) *endpointWorker {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	if worker, ok := workers[key]; ok {
+		return worker
+	}
+
+	workerCtx, stop := context.WithCancel(ctx)
+	worker := &endpointWorker{
+		queue:   make(chan *domainWebhook.Delivery, workerQueueSize),
+		breaker: newCircuitBreaker(),
+		stop:    stop,
+	}
+	for i := 0; i < workersPerEndpoint; i++ {
+		go worker.run(workerCtx)
+	}
+	workers[key] = worker
+	return worker
+}
+
+// lookupWorker returns the worker of the key without starting it
+func lookupWorker(key string) *endpointWorker {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	return workers[key]
+}
+
+// stopWorkers stops the workers of the endpoints that were removed, disabled or moved to another URL, a later
+// delivery starts a fresh one. Their queued deliveries stay claimed in the outbox until the lease expires.
+func stopWorkers(previous, current []*domainWebhook.Endpoint) {
+	urls := make(map[string]string, len(previous))
+	for _, endpoint := range previous {
+		urls[endpoint.ID] = endpoint.URL
+	}
+	keep := make(map[string]bool, len(current))
+	for _, endpoint := range current {
+		if !endpoint.Enabled {
+			continue
+		}
+		if url, ok := urls[endpoint.ID]; !ok || url == endpoint.URL {
+			keep[endpoint.ID] = true
+		}
+		keep[endpoint.URL] = true
+	}
+
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	for key, worker := range workers {
+		if !keep[key] {
+			worker.stop()
+			delete(workers, key)
+		}
+	}
+}
+
+// offer queues the delivery without blocking. It returns false when the queue is full.
+func (w *endpointWorker) offer(delivery *domainWebhook.Delivery) bool {
+	select {
+	case w.queue <- delivery:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *endpointWorker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-w.queue:
+			attemptDelivery(ctx, w.breaker, delivery)
+		}
+	}
+}
+
+// Circuit returns the circuit breaker state of an endpoint
+func Circuit(endpointID string) domainWebhook.CircuitInfo {
+	worker := lookupWorker(endpointID)
+	if worker == nil {
+		return domainWebhook.CircuitInfo{EndpointID: endpointID, State: domainWebhook.CircuitClosed}
+	}
+
+	info := worker.breaker.Info()
+	info.EndpointID = endpointID
+	info.QueueLength = len(worker.queue)
+	return info
+}
+
+// ResetCircuit closes the circuit of an endpoint and makes its postponed deliveries due right away
+func ResetCircuit(ctx context.Context, endpointID string) error {
+	if worker := lookupWorker(endpointID); worker != nil {
+		worker.breaker.Reset()
+	}
+
+	if repo != nil {
+		if _, err := repo.ExpediteDeliveries(ctx, endpointID, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+	Wakeup()
+	return nil
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer stopWorkers(nil, nil)
+
+	previous := []*domainWebhook.Endpoint{
+		{ID: "kept", URL: "https://kept.example", Enabled: true},
+		{ID: "removed", URL: "https://removed.example", Enabled: true},
+		{ID: "disabled", URL: "https://disabled.example", Enabled: true},
+		{ID: "moved", URL: "https://old.example", Enabled: true},
+	}
+	current := []*domainWebhook.Endpoint{
+		{ID: "kept", URL: "https://kept.example", Enabled: true},
+		{ID: "disabled", URL: "https://disabled.example", Enabled: false},
+		{ID: "moved", URL: "https://new.example", Enabled: true},
+	}
+
+	started := make(map[string]*endpointWorker)
+	for _, key := range []string{"kept", "removed", "disabled", "moved", "https://kept.example", "https://gone.example"} {
+		started[key] = workerFor(ctx, key)
+	}
+
+	stopWorkers(previous, current)
+
+	assert.Same(t, started["kept"], lookupWorker("kept"))
+	assert.Same(t, started["https://kept.example"], lookupWorker("https://kept.example"))
+	for _, key := range []string{"removed", "disabled", "moved", "https://gone.example"} {
+		assert.Nil(t, lookupWorker(key), key)
+	}
+
+	// A stopped endpoint gets a fresh worker and circuit on its next delivery
+	assert.NotSame(t, started["moved"], workerFor(ctx, "moved"))
+}
@@ -4,58 +4,72 @@ import (
 	"context"
 	"time"
 
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
 // forwardDeleteToWebhook sends a delete event to webhook
 func forwardDeleteToWebhook(ctx context.Context, evt *events.DeleteForMe, message *domainChatStorage.Message) error {
-	logrus.Infof("Forwarding delete event to %d configured webhook(s)", len(config.WhatsappWebhook))
-	payload, err := createDeletePayload(ctx, evt, message)
-	if err != nil {
+	if err := submitWebhook(ctx, createDeleteEvent(evt, message, time.Now())); err != nil {
 		return err
 	}
 
-	for _, url := range config.WhatsappWebhook {
-		if err = submitWebhook(ctx, payload, url); err != nil {
-			return err
-		}
-	}
-
 	logrus.Info("Delete event forwarded to webhook")
 	return nil
 }
 
-// createDeletePayload creates a webhook payload for delete events
-func createDeletePayload(_ context.Context, evt *events.DeleteForMe, message *domainChatStorage.Message) (map[string]any, error) {
-	body := make(map[string]any)
+// createDeleteEvent creates a webhook event for delete events
+func createDeleteEvent(evt *events.DeleteForMe, message *domainChatStorage.Message, now time.Time) domainWebhook.Event {
+	legacy := &domainWebhook.DeletePayloadV1{
+		Action:           "event.delete_for_me",
+		DeletedMessageID: evt.MessageID,
+		SenderID:         evt.SenderJID.User,
+		Timestamp:        now.Format(time.RFC3339),
+	}
+	data := &domainWebhook.DeletedForMeData{
+		MessageID: evt.MessageID,
+	}
+	if !evt.ChatJID.IsEmpty() {
+		data.ChatJID = evt.ChatJID.String()
+	}
 
-	// Basic delete event information
-	body["action"] = "event.delete_for_me"
-	body["deleted_message_id"] = evt.MessageID
-	body["sender_id"] = evt.SenderJID.User
-	body["timestamp"] = time.Now().Format(time.RFC3339)
+	// Parse sender JID for proper formatting
+	if evt.SenderJID.Server != "" {
+		legacy.From = evt.SenderJID.String()
+		data.SenderJID = evt.SenderJID.String()
+	}
 
 	// Include original message information if available
 	if message != nil {
-		body["chat_id"] = message.ChatJID
-		body["original_content"] = message.Content
-		body["original_sender"] = message.Sender
-		body["original_timestamp"] = message.Timestamp.Format(time.RFC3339)
-		body["was_from_me"] = message.IsFromMe
-
-		if message.MediaType != "" {
-			body["original_media_type"] = message.MediaType
-			body["original_filename"] = message.Filename
+		legacy.OriginalMessageV1 = &domainWebhook.OriginalMessageV1{
+			ChatID:            message.ChatJID,
+			OriginalContent:   message.Content,
+			OriginalSender:    message.Sender,
+			OriginalTimestamp: message.Timestamp.Format(time.RFC3339),
+			WasFromMe:         message.IsFromMe,
+			OriginalMediaType: message.MediaType,
+			OriginalFilename:  message.Filename,
+		}
+		data.Original = &domainWebhook.OriginalMessage{
+			Content:   message.Content,
+			SenderJID: message.Sender,
+			Timestamp: message.Timestamp.UTC().Format(time.RFC3339),
+			FromMe:    message.IsFromMe,
+			MediaType: message.MediaType,
+			Filename:  message.Filename,
+		}
+		if data.ChatJID == "" {
+			data.ChatJID = message.ChatJID
 		}
 	}
 
-	// Parse sender JID for proper formatting
-	if evt.SenderJID.Server != "" {
-		body["from"] = evt.SenderJID.String()
+	return domainWebhook.Event{
+		Type:      domainWebhook.EventDelete,
+		ChatJID:   evt.ChatJID.String(),
+		Timestamp: now,
+		Legacy:    legacy,
+		Data:      data,
 	}
-
-	return body, nil
 }
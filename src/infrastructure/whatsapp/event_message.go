@@ -7,9 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -18,34 +20,69 @@ import (
 
 // forwardMessageToWebhook is a helper function to forward message event to webhook url
 func forwardMessageToWebhook(ctx context.Context, evt *events.Message) error {
-	logrus.Infof("Forwarding message event to %d configured webhook(s)", len(config.WhatsappWebhook))
-	payload, err := createMessagePayload(ctx, evt)
+	event, err := createMessageEvent(ctx, evt)
 	if err != nil {
 		return err
 	}
 
-	for _, url := range config.WhatsappWebhook {
-		if err = submitWebhook(ctx, payload, url); err != nil {
-			return err
-		}
+	if err = submitWebhook(ctx, event); err != nil {
+		return err
 	}
 
 	logrus.Info("Message event forwarded to webhook")
 	return nil
 }
 
-func createMessagePayload(ctx context.Context, evt *events.Message) (map[string]any, error) {
+// MessageListener is notified of the messages every device receives
+type MessageListener func(ctx context.Context, evt *events.Message)
+
+var messageListeners []MessageListener
+
+// AddMessageListener registers a listener for the incoming messages, like the auto-reply rules.
+// Listeners are registered at startup and run outside of the event handler.
+func AddMessageListener(listener MessageListener) {
+	messageListeners = append(messageListeners, listener)
+}
+
+// notifyMessageListeners passes a message to every registered listener
+func notifyMessageListeners(ctx context.Context, evt *events.Message) {
+	for _, listener := range messageListeners {
+		listener(ctx, evt)
+	}
+}
+
+// CreateMessageEvent creates the webhook event of a message for delivery outside of the webhook subscriptions,
+// like an auto-reply rule calling a webhook endpoint
+func CreateMessageEvent(ctx context.Context, evt *events.Message) (domainWebhook.Event, error) {
+	event, err := createMessageEvent(ctx, evt)
+	event.DeviceID = deviceIDForEvent(ctx)
+	return event, err
+}
+
+func createMessageEvent(ctx context.Context, evt *events.Message) (domainWebhook.Event, error) {
 	message := utils.BuildEventMessage(evt)
 	waReaction := utils.BuildEventReaction(evt)
 	forwarded := utils.BuildForwarded(evt)
 
-	body := make(map[string]any)
-
-	body["sender_id"] = evt.Info.Sender.User
-	body["chat_id"] = evt.Info.Chat.User
+	body := &domainWebhook.MessagePayloadV1{
+		SenderID:  evt.Info.Sender.User,
+		ChatID:    evt.Info.Chat.User,
+		PushName:  evt.Info.PushName,
+		ViewOnce:  evt.IsViewOnce,
+		Forwarded: forwarded,
+		Timestamp: evt.Info.Timestamp.Format(time.RFC3339),
+	}
+	data := &domainWebhook.MessageData{
+		ID:        evt.Info.ID,
+		ChatJID:   evt.Info.Chat.String(),
+		SenderJID: evt.Info.Sender.String(),
+		PushName:  evt.Info.PushName,
+		ViewOnce:  evt.IsViewOnce,
+		Forwarded: forwarded,
+	}
 
 	if from := evt.Info.SourceString(); from != "" {
-		body["from"] = from
+		body.From = from
 
 		from_user, from_group := from, ""
 		if strings.Contains(from, " in ") {
@@ -54,24 +91,25 @@ func createMessagePayload(ctx context.Context, evt *events.Message) (map[string]
 		}
 
 		if strings.HasSuffix(from_user, "@lid") {
-			body["from_lid"] = from_user
+			body.FromLID = from_user
 			lid, err := types.ParseJID(from_user)
 			if err != nil {
 				logrus.Errorf("Error when parse jid: %v", err)
 			} else {
-				pn, err := cli.Store.LIDs.GetPNForLID(ctx, lid)
+				pn, err := ClientFromContext(ctx).Store.LIDs.GetPNForLID(ctx, lid)
 				if err != nil {
 					logrus.Errorf("Error when get pn for lid %s: %v", lid.String(), err)
 				}
 				if !pn.IsEmpty() {
 					if from_group != "" {
-						body["from"] = fmt.Sprintf("%s in %s", pn.String(), from_group)
+						body.From = fmt.Sprintf("%s in %s", pn.String(), from_group)
 					} else {
-						body["from"] = pn.String()
+						body.From = pn.String()
 					}
 				}
 			}
 		}
+		data.From, data.FromLID = body.From, body.FromLID
 	}
 	if message.ID != "" {
 		tags := regexp.MustCompile(`\B@\w+`).FindAllString(message.Text, -1)
@@ -84,7 +122,7 @@ func createMessagePayload(ctx context.Context, evt *events.Message) (map[string]
 			if err != nil {
 				logrus.Errorf("Error when parse jid: %v", err)
 			} else {
-				pn, err := cli.Store.LIDs.GetPNForLID(ctx, lid)
+				pn, err := ClientFromContext(ctx).Store.LIDs.GetPNForLID(ctx, lid)
 				if err != nil {
 					logrus.Errorf("Error when get pn for lid %s: %v", lid.String(), err)
 				}
@@ -93,22 +131,19 @@ func createMessagePayload(ctx context.Context, evt *events.Message) (map[string]
 				}
 			}
 		}
-		body["message"] = message
-	}
-	if pushname := evt.Info.PushName; pushname != "" {
-		body["pushname"] = pushname
+		body.Message = &domainWebhook.MessageTextV1{
+			Text:          message.Text,
+			ID:            message.ID,
+			RepliedID:     message.RepliedId,
+			QuotedMessage: message.QuotedMessage,
+		}
+		data.Text = message.Text
+		data.RepliedID = message.RepliedId
+		data.QuotedMessage = message.QuotedMessage
 	}
 	if waReaction.Message != "" {
-		body["reaction"] = waReaction
-	}
-	if evt.IsViewOnce {
-		body["view_once"] = evt.IsViewOnce
-	}
-	if forwarded {
-		body["forwarded"] = forwarded
-	}
-	if timestamp := evt.Info.Timestamp.Format(time.RFC3339); timestamp != "" {
-		body["timestamp"] = timestamp
+		body.Reaction = &domainWebhook.ReactionV1{Message: waReaction.Message, ID: waReaction.ID}
+		data.Reaction = &domainWebhook.Reaction{Text: waReaction.Message, MessageID: waReaction.ID}
 	}
 
 	// Handle protocol messages (revoke, etc.)
@@ -117,90 +152,162 @@ func createMessagePayload(ctx context.Context, evt *events.Message) (map[string]
 
 		switch protocolType {
 		case "REVOKE":
-			body["action"] = "message_revoked"
+			body.Action = "message_revoked"
+			data.Revoked = &domainWebhook.RevokedMessage{}
 			if key := protocolMessage.GetKey(); key != nil {
-				body["revoked_message_id"] = key.GetID()
-				body["revoked_from_me"] = key.GetFromMe()
-				if key.GetRemoteJID() != "" {
-					body["revoked_chat"] = key.GetRemoteJID()
-				}
+				fromMe := key.GetFromMe()
+				body.RevokedMessageID = key.GetID()
+				body.RevokedFromMe = &fromMe
+				body.RevokedChat = key.GetRemoteJID()
+				data.Revoked = &domainWebhook.RevokedMessage{MessageID: key.GetID(), FromMe: fromMe, ChatJID: key.GetRemoteJID()}
 			}
 		case "MESSAGE_EDIT":
-			body["action"] = "message_edited"
+			body.Action = "message_edited"
+			data.Edited = &domainWebhook.EditedMessage{}
 			if editedMessage := protocolMessage.GetEditedMessage(); editedMessage != nil {
 				if editedText := editedMessage.GetExtendedTextMessage(); editedText != nil {
-					body["edited_text"] = editedText.GetText()
+					body.EditedText = editedText.GetText()
 				} else if editedConv := editedMessage.GetConversation(); editedConv != "" {
-					body["edited_text"] = editedConv
+					body.EditedText = editedConv
 				}
+				data.Edited.Text = body.EditedText
 			}
 		}
 	}
 
+	var err error
 	if audioMedia := evt.Message.GetAudioMessage(); audioMedia != nil {
-		path, err := utils.ExtractMedia(ctx, cli, config.PathMedia, audioMedia)
-		if err != nil {
-			logrus.Errorf("Failed to download audio from %s: %v", evt.Info.SourceString(), err)
-			return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download audio: %v", err))
+		if body.Audio, err = extractWebhookMedia(ctx, evt, data, domainWebhook.MediaTypeAudio, audioMedia); err != nil {
+			return domainWebhook.Event{}, err
 		}
-		body["audio"] = path
-	}
-
-	if contactMessage := evt.Message.GetContactMessage(); contactMessage != nil {
-		body["contact"] = contactMessage
 	}
 
 	if documentMedia := evt.Message.GetDocumentMessage(); documentMedia != nil {
-		path, err := utils.ExtractMedia(ctx, cli, config.PathMedia, documentMedia)
-		if err != nil {
-			logrus.Errorf("Failed to download document from %s: %v", evt.Info.SourceString(), err)
-			return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download document: %v", err))
+		if body.Document, err = extractWebhookMedia(ctx, evt, data, domainWebhook.MediaTypeDocument, documentMedia); err != nil {
+			return domainWebhook.Event{}, err
 		}
-		body["document"] = path
 	}
 
 	if imageMedia := evt.Message.GetImageMessage(); imageMedia != nil {
-		path, err := utils.ExtractMedia(ctx, cli, config.PathMedia, imageMedia)
-		if err != nil {
-			logrus.Errorf("Failed to download image from %s: %v", evt.Info.SourceString(), err)
-			return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download image: %v", err))
+		if body.Image, err = extractWebhookMedia(ctx, evt, data, domainWebhook.MediaTypeImage, imageMedia); err != nil {
+			return domainWebhook.Event{}, err
+		}
+	}
+
+	if stickerMedia := evt.Message.GetStickerMessage(); stickerMedia != nil {
+		if body.Sticker, err = extractWebhookMedia(ctx, evt, data, domainWebhook.MediaTypeSticker, stickerMedia); err != nil {
+			return domainWebhook.Event{}, err
+		}
+	}
+
+	if videoMedia := evt.Message.GetVideoMessage(); videoMedia != nil {
+		if body.Video, err = extractWebhookMedia(ctx, evt, data, domainWebhook.MediaTypeVideo, videoMedia); err != nil {
+			return domainWebhook.Event{}, err
+		}
+	}
+
+	if contactMessage := evt.Message.GetContactMessage(); contactMessage != nil {
+		body.Contact = contactMessage
+		data.Contact = &domainWebhook.Contact{
+			DisplayName: contactMessage.GetDisplayName(),
+			VCard:       contactMessage.GetVcard(),
 		}
-		body["image"] = path
 	}
 
 	if listMessage := evt.Message.GetListMessage(); listMessage != nil {
-		body["list"] = listMessage
+		body.List = listMessage
+		data.List = &domainWebhook.List{
+			Title:       listMessage.GetTitle(),
+			Description: listMessage.GetDescription(),
+			ButtonText:  listMessage.GetButtonText(),
+			Sections:    []domainWebhook.ListSection{},
+		}
+		for _, section := range listMessage.GetSections() {
+			listSection := domainWebhook.ListSection{Title: section.GetTitle(), Rows: []domainWebhook.ListRow{}}
+			for _, row := range section.GetRows() {
+				listSection.Rows = append(listSection.Rows, domainWebhook.ListRow{
+					RowID:       row.GetRowID(),
+					Title:       row.GetTitle(),
+					Description: row.GetDescription(),
+				})
+			}
+			data.List.Sections = append(data.List.Sections, listSection)
+		}
 	}
 
 	if liveLocationMessage := evt.Message.GetLiveLocationMessage(); liveLocationMessage != nil {
-		body["live_location"] = liveLocationMessage
+		body.LiveLocation = liveLocationMessage
+		data.LiveLocation = &domainWebhook.LiveLocation{
+			Latitude:         liveLocationMessage.GetDegreesLatitude(),
+			Longitude:        liveLocationMessage.GetDegreesLongitude(),
+			AccuracyInMeters: liveLocationMessage.GetAccuracyInMeters(),
+			Caption:          liveLocationMessage.GetCaption(),
+			SequenceNumber:   liveLocationMessage.GetSequenceNumber(),
+		}
 	}
 
 	if locationMessage := evt.Message.GetLocationMessage(); locationMessage != nil {
-		body["location"] = locationMessage
+		body.Location = locationMessage
+		data.Location = &domainWebhook.Location{
+			Latitude:  locationMessage.GetDegreesLatitude(),
+			Longitude: locationMessage.GetDegreesLongitude(),
+			Name:      locationMessage.GetName(),
+			Address:   locationMessage.GetAddress(),
+			URL:       locationMessage.GetURL(),
+		}
 	}
 
 	if orderMessage := evt.Message.GetOrderMessage(); orderMessage != nil {
-		body["order"] = orderMessage
+		body.Order = orderMessage
+		data.Order = &domainWebhook.Order{
+			OrderID:         orderMessage.GetOrderID(),
+			Title:           orderMessage.GetOrderTitle(),
+			Message:         orderMessage.GetMessage(),
+			Status:          strings.ToLower(orderMessage.GetStatus().String()),
+			ItemCount:       orderMessage.GetItemCount(),
+			SellerJID:       orderMessage.GetSellerJID(),
+			TotalAmount1000: orderMessage.GetTotalAmount1000(),
+			Currency:        orderMessage.GetTotalCurrencyCode(),
+		}
 	}
 
-	if stickerMedia := evt.Message.GetStickerMessage(); stickerMedia != nil {
-		path, err := utils.ExtractMedia(ctx, cli, config.PathMedia, stickerMedia)
-		if err != nil {
-			logrus.Errorf("Failed to download sticker from %s: %v", evt.Info.SourceString(), err)
-			return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download sticker: %v", err))
+	event := domainWebhook.Event{
+		Type:      domainWebhook.EventMessage,
+		ChatJID:   evt.Info.Chat.String(),
+		Timestamp: evt.Info.Timestamp,
+		Legacy:    body,
+		Data:      data,
+	}
+	// Responses may act on the messages of others; acting on our own would let a bot answer itself
+	if !evt.Info.IsFromMe && data.Revoked == nil {
+		event.Origin = &domainWebhook.Origin{
+			Device:    DeviceIDFromContext(ctx),
+			ChatJID:   evt.Info.Chat.String(),
+			SenderJID: evt.Info.Sender.String(),
+			MessageID: evt.Info.ID,
 		}
-		body["sticker"] = path
 	}
+	return event, nil
+}
 
-	if videoMedia := evt.Message.GetVideoMessage(); videoMedia != nil {
-		path, err := utils.ExtractMedia(ctx, cli, config.PathMedia, videoMedia)
-		if err != nil {
-			logrus.Errorf("Failed to download video from %s: %v", evt.Info.SourceString(), err)
-			return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download video: %v", err))
-		}
-		body["video"] = path
+// extractWebhookMedia downloads the media of a message, it is referenced by its own key in payload version 1
+// and by the media field of the data in payload version 2
+func extractWebhookMedia(ctx context.Context, evt *events.Message, data *domainWebhook.MessageData, mediaType string, mediaFile whatsmeow.DownloadableMessage) (*domainWebhook.MediaFileV1, error) {
+	extracted, err := utils.ExtractMedia(ctx, ClientFromContext(ctx), config.PathMedia, mediaFile)
+	if err != nil {
+		logrus.Errorf("Failed to download %s from %s: %v", mediaType, evt.Info.SourceString(), err)
+		return nil, pkgError.WebhookError(fmt.Sprintf("Failed to download %s: %v", mediaType, err))
 	}
 
-	return body, nil
+	data.Media = &domainWebhook.Media{
+		Type:     mediaType,
+		Path:     extracted.MediaPath,
+		MimeType: extracted.MimeType,
+		Caption:  extracted.Caption,
+	}
+	return &domainWebhook.MediaFileV1{
+		MediaPath: extracted.MediaPath,
+		MimeType:  extracted.MimeType,
+		Caption:   extracted.Caption,
+	}, nil
 }
@@ -0,0 +1,163 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// pollCreation returns the poll of a poll creation message, nil for other messages
+func pollCreation(msg *waE2E.Message) *waE2E.PollCreationMessage {
+	switch {
+	case msg.GetPollCreationMessage() != nil:
+		return msg.GetPollCreationMessage()
+	case msg.GetPollCreationMessageV2() != nil:
+		return msg.GetPollCreationMessageV2()
+	default:
+		return msg.GetPollCreationMessageV3()
+	}
+}
+
+// StorePoll stores the poll of a poll creation message with the hashes of its options and its secret, so the votes
+// on it can be decrypted and counted. Other messages are ignored.
+func StorePoll(repo domainChatStorage.IChatStorageRepository, messageID string, chat, sender types.JID, msg *waE2E.Message, timestamp time.Time) error {
+	creation := pollCreation(msg)
+	if creation == nil {
+		return nil
+	}
+
+	names := make([]string, len(creation.GetOptions()))
+	for i, option := range creation.GetOptions() {
+		names[i] = option.GetOptionName()
+	}
+	options := make([]domainChatStorage.PollOption, len(names))
+	for i, hash := range whatsmeow.HashPollOptions(names) {
+		options[i] = domainChatStorage.PollOption{Name: names[i], Hash: hex.EncodeToString(hash)}
+	}
+
+	return repo.StorePoll(&domainChatStorage.Poll{
+		MessageID:       messageID,
+		ChatJID:         chat.String(),
+		Sender:          sender.ToNonAD().String(),
+		Question:        creation.GetName(),
+		Options:         options,
+		SelectableCount: creation.GetSelectableOptionsCount(),
+		Secret:          msg.GetMessageContextInfo().GetMessageSecret(),
+		Timestamp:       timestamp,
+	})
+}
+
+// storePollVote decrypts the vote of a poll update message and stores it as the latest vote of the voter. The poll
+// is nil when it is not stored, the vote is still kept for when it is.
+func storePollVote(ctx context.Context, evt *events.Message, repo domainChatStorage.IChatStorageRepository) (*domainChatStorage.Poll, *domainChatStorage.PollVote, error) {
+	update := evt.Message.GetPollUpdateMessage()
+	pollID := update.GetPollCreationMessageKey().GetID()
+	chatJID := evt.Info.Chat.String()
+
+	poll, err := repo.GetPoll(chatJID, pollID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get poll %s: %w", pollID, err)
+	}
+
+	client := ClientFromContext(ctx)
+	decrypted, err := client.DecryptPollVote(ctx, evt)
+	if errors.Is(err, whatsmeow.ErrOriginalMessageSecretNotFound) && poll != nil && len(poll.Secret) > 0 {
+		// The session lost the secret of the poll, restore the one kept with the poll and try again
+		sender, parseErr := types.ParseJID(poll.Sender)
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("invalid sender of poll %s: %w", pollID, parseErr)
+		}
+		if putErr := client.Store.MsgSecrets.PutMessageSecret(ctx, evt.Info.Chat, sender, pollID, poll.Secret); putErr != nil {
+			return nil, nil, fmt.Errorf("failed to restore the secret of poll %s: %w", pollID, putErr)
+		}
+		decrypted, err = client.DecryptPollVote(ctx, evt)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vote := &domainChatStorage.PollVote{
+		PollID:    pollID,
+		ChatJID:   chatJID,
+		Voter:     evt.Info.Sender.ToNonAD().String(),
+		Options:   make([]string, 0, len(decrypted.GetSelectedOptions())),
+		Timestamp: evt.Info.Timestamp,
+	}
+	if ms := update.GetSenderTimestampMS(); ms > 0 {
+		vote.Timestamp = time.UnixMilli(ms)
+	}
+	for _, hash := range decrypted.GetSelectedOptions() {
+		vote.Options = append(vote.Options, hex.EncodeToString(hash))
+	}
+
+	if err := repo.StorePollVote(vote); err != nil {
+		return nil, nil, fmt.Errorf("failed to store vote on poll %s: %w", pollID, err)
+	}
+	return poll, vote, nil
+}
+
+// createPollVoteEvent creates a webhook event for a vote on a poll. The names of the selected options are only known
+// when the poll is stored.
+func createPollVoteEvent(poll *domainChatStorage.Poll, vote *domainChatStorage.PollVote) domainWebhook.Event {
+	names := make([]string, 0, len(vote.Options))
+	if poll != nil {
+		for _, option := range poll.Options {
+			for _, hash := range vote.Options {
+				if option.Hash == hash {
+					names = append(names, option.Name)
+				}
+			}
+		}
+	}
+
+	legacy := &domainWebhook.PollVotePayloadV1{
+		Event:     domainWebhook.EventNamePollVote,
+		Timestamp: vote.Timestamp.Format(time.RFC3339),
+		Payload: domainWebhook.PollVoteV1{
+			PollID:       vote.PollID,
+			ChatID:       vote.ChatJID,
+			Voter:        vote.Voter,
+			Options:      names,
+			OptionHashes: vote.Options,
+		},
+	}
+	data := &domainWebhook.PollVoteData{
+		PollID:       vote.PollID,
+		ChatJID:      vote.ChatJID,
+		VoterJID:     vote.Voter,
+		Options:      names,
+		OptionHashes: vote.Options,
+	}
+	if poll != nil {
+		legacy.Payload.Question = poll.Question
+		data.Question = poll.Question
+	}
+
+	return domainWebhook.Event{
+		Type:      domainWebhook.EventPoll,
+		ChatJID:   vote.ChatJID,
+		Timestamp: vote.Timestamp,
+		Legacy:    legacy,
+		Data:      data,
+	}
+}
+
+// forwardPollVoteToWebhook forwards a vote on a poll to the subscribed webhook endpoints
+func forwardPollVoteToWebhook(ctx context.Context, poll *domainChatStorage.Poll, vote *domainChatStorage.PollVote) error {
+	if err := submitWebhook(ctx, createPollVoteEvent(poll, vote)); err != nil {
+		return err
+	}
+
+	logrus.Info("Poll vote event forwarded to webhook")
+	return nil
+}
@@ -0,0 +1,48 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// createPresenceEvent creates a webhook event for presence (online/offline) events
+func createPresenceEvent(evt *events.Presence, now time.Time) domainWebhook.Event {
+	legacy := &domainWebhook.PresencePayloadV1{
+		Event:     domainWebhook.EventNamePresence,
+		Timestamp: now.Format(time.RFC3339),
+		Payload: domainWebhook.PresenceV1{
+			From:        evt.From.String(),
+			Unavailable: evt.Unavailable,
+		},
+	}
+	data := &domainWebhook.PresenceData{
+		JID:         evt.From.String(),
+		Unavailable: evt.Unavailable,
+	}
+	if !evt.LastSeen.IsZero() {
+		legacy.Payload.LastSeen = evt.LastSeen.Format(time.RFC3339)
+		data.LastSeen = evt.LastSeen.UTC().Format(time.RFC3339)
+	}
+
+	return domainWebhook.Event{
+		Type:      domainWebhook.EventPresence,
+		ChatJID:   evt.From.String(),
+		Timestamp: now,
+		Legacy:    legacy,
+		Data:      data,
+	}
+}
+
+// forwardPresenceToWebhook forwards presence events to the subscribed webhook endpoints
+func forwardPresenceToWebhook(ctx context.Context, evt *events.Presence) error {
+	if err := submitWebhook(ctx, createPresenceEvent(evt, time.Now())); err != nil {
+		return err
+	}
+
+	logrus.Info("Presence event forwarded to webhook")
+	return nil
+}
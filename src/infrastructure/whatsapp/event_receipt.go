@@ -4,7 +4,8 @@ import (
 	"context"
 	"time"
 
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
@@ -34,51 +35,98 @@ func getReceiptTypeDescription(evt types.ReceiptType) string {
 	}
 }
 
-// createReceiptPayload creates a webhook payload for message acknowledgement (receipt) events
-func createReceiptPayload(evt *events.Receipt) map[string]any {
-	body := make(map[string]any)
-
-	// Create payload structure matching the expected format
-	payload := make(map[string]any)
-
-	// Add message ID (use first message ID if multiple)
-	if len(evt.MessageIDs) > 0 {
-		payload["ids"] = evt.MessageIDs
+// createReceiptEvent creates a webhook event for message acknowledgement (receipt) events
+func createReceiptEvent(evt *events.Receipt) domainWebhook.Event {
+	receiptType := string(evt.Type)
+	if evt.Type == types.ReceiptTypeDelivered {
+		receiptType = "delivered"
 	}
 
-	// Add from field (the chat where the message was sent)
-	payload["chat_id"] = evt.Chat
-	payload["sender_id"] = evt.Sender
-	payload["from"] = evt.SourceString()
+	messageIDs := evt.MessageIDs
+	if messageIDs == nil {
+		messageIDs = []string{} // Return empty array instead of nil for consistent JSON
+	}
 
-	if evt.Type == types.ReceiptTypeDelivered {
-		payload["receipt_type"] = "delivered"
-	} else {
-		payload["receipt_type"] = evt.Type
+	return domainWebhook.Event{
+		Type:      domainWebhook.EventReceipt,
+		ChatJID:   evt.Chat.String(),
+		Timestamp: evt.Timestamp,
+		Legacy: &domainWebhook.ReceiptPayloadV1{
+			Event:     domainWebhook.EventNameMessageAck,
+			Timestamp: evt.Timestamp.Format(time.RFC3339),
+			Payload: domainWebhook.ReceiptV1{
+				IDs:                    evt.MessageIDs,
+				ChatID:                 evt.Chat.String(),
+				SenderID:               evt.Sender.String(),
+				From:                   evt.SourceString(),
+				ReceiptType:            receiptType,
+				ReceiptTypeDescription: getReceiptTypeDescription(evt.Type),
+			},
+		},
+		Data: &domainWebhook.ReceiptData{
+			MessageIDs:  messageIDs,
+			ChatJID:     evt.Chat.String(),
+			SenderJID:   evt.Sender.String(),
+			ReceiptType: receiptType,
+		},
 	}
-	payload["receipt_type_description"] = getReceiptTypeDescription(evt.Type)
+}
 
-	// Wrap in payload structure
-	body["payload"] = payload
+// storeReceipt keeps the delivered, read and played receipts of the recipients of our messages, one per participant
+// of a group message. Receipts of our other devices say nothing about the recipients.
+func storeReceipt(evt *events.Receipt, chatStorageRepo domainChatStorage.IChatStorageRepository) error {
+	if evt.IsFromMe || len(evt.MessageIDs) == 0 {
+		return nil
+	}
 
-	// Add metadata for webhook processing
-	body["event"] = "message.ack"
-	body["timestamp"] = evt.Timestamp.Format(time.RFC3339)
+	timestamp := evt.Timestamp.UTC()
+	receipts := make([]*domainChatStorage.Receipt, 0, len(evt.MessageIDs))
+	for _, id := range evt.MessageIDs {
+		receipt := &domainChatStorage.Receipt{
+			MessageID:   id,
+			ChatJID:     evt.Chat.String(),
+			Participant: evt.Sender.ToNonAD().String(),
+		}
+		switch evt.Type {
+		case types.ReceiptTypeDelivered:
+			receipt.DeliveredAt = &timestamp
+		case types.ReceiptTypeRead:
+			receipt.ReadAt = &timestamp
+		case types.ReceiptTypePlayed:
+			receipt.PlayedAt = &timestamp
+		default:
+			return nil
+		}
+		receipts = append(receipts, receipt)
+	}
 
-	return body
+	return chatStorageRepo.StoreReceipts(receipts)
 }
 
 // forwardReceiptToWebhook forwards message acknowledgement events to the configured webhook URLs
 func forwardReceiptToWebhook(ctx context.Context, evt *events.Receipt) error {
-	logrus.Infof("Forwarding message ack event to %d configured webhook(s)", len(config.WhatsappWebhook))
-	payload := createReceiptPayload(evt)
-
-	for _, url := range config.WhatsappWebhook {
-		if err := submitWebhook(ctx, payload, url); err != nil {
-			return err
-		}
+	if err := submitWebhook(ctx, createReceiptEvent(evt)); err != nil {
+		return err
 	}
 
 	logrus.Info("Message ack event forwarded to webhook")
 	return nil
 }
+
+// ReceiptListener is notified of the delivered and read receipts of every device
+type ReceiptListener func(ctx context.Context, evt *events.Receipt)
+
+var receiptListeners []ReceiptListener
+
+// AddReceiptListener registers a listener for the receipts, like the campaigns rolling up their delivery state.
+// Listeners are registered at startup and run outside of the event handler.
+func AddReceiptListener(listener ReceiptListener) {
+	receiptListeners = append(receiptListeners, listener)
+}
+
+// notifyReceiptListeners passes a receipt to every registered listener
+func notifyReceiptListeners(ctx context.Context, evt *events.Receipt) {
+	for _, listener := range receiptListeners {
+		listener(ctx, evt)
+	}
+}
@@ -2,6 +2,7 @@ package whatsapp
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,23 +11,23 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.mau.fi/whatsmeow/proto/waCommon"
 	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
-	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	waLog "go.mau.fi/whatsmeow/util/log"
-	"google.golang.org/protobuf/proto"
 )
 
 // Type definitions
@@ -102,7 +103,7 @@ func syncKeysDevice(ctx context.Context, db, keysDB *sqlstore.Container) {
 
 // InitWaCLI initializes the WhatsApp client
 func InitWaCLI(ctx context.Context, storeContainer, keysStoreContainer *sqlstore.Container, chatStorageRepo domainChatStorage.IChatStorageRepository) *whatsmeow.Client {
-	device, err := storeContainer.GetFirstDevice(ctx)
+	device, err := defaultDevice(ctx, storeContainer)
 	if err != nil {
 		log.Errorf("Failed to get device: %v", err)
 		panic(err)
@@ -147,6 +148,24 @@ func InitWaCLI(ctx context.Context, storeContainer, keysStoreContainer *sqlstore
 	return cli
 }
 
+// defaultDevice returns the first stored device that is not served by an additional session
+func defaultDevice(ctx context.Context, storeContainer *sqlstore.Container) (*store.Device, error) {
+	if SessionCount() == 0 {
+		return storeContainer.GetFirstDevice(ctx)
+	}
+
+	devices, err := storeContainer.GetAllDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		if _, ok := GetSession(device.ID.String()); !ok {
+			return device, nil
+		}
+	}
+	return storeContainer.NewDevice(), nil
+}
+
 // UpdateGlobalClient updates the global cli variable with a new client instance
 // This is needed when reinitializing the client after logout to ensure all
 // infrastructure code uses the new client instance
@@ -166,17 +185,18 @@ func GetDB() *sqlstore.Container {
 	return db
 }
 
-// GetConnectionStatus returns the current connection status of the global client
-func GetConnectionStatus() (isConnected bool, isLoggedIn bool, deviceID string) {
-	if cli == nil {
+// GetConnectionStatus returns the current connection status of the client selected in the context
+func GetConnectionStatus(ctx context.Context) (isConnected bool, isLoggedIn bool, deviceID string) {
+	client := ClientFromContext(ctx)
+	if client == nil {
 		return false, false, ""
 	}
 
-	isConnected = cli.IsConnected()
-	isLoggedIn = cli.IsLoggedIn()
+	isConnected = client.IsConnected()
+	isLoggedIn = client.IsLoggedIn()
 
-	if cli.Store != nil && cli.Store.ID != nil {
-		deviceID = cli.Store.ID.String()
+	if client.Store != nil && client.Store.ID != nil {
+		deviceID = client.Store.ID.String()
 	}
 
 	return isConnected, isLoggedIn, deviceID
@@ -281,15 +301,29 @@ func PerformCompleteCleanup(ctx context.Context, logPrefix string, chatStorageRe
 		}
 	}
 
-	// Clean up database
-	if err := CleanupDatabase(); err != nil {
-		return nil, nil, fmt.Errorf("database cleanup failed: %v", err)
-	}
+	var newDB *sqlstore.Container
+	var newCli *whatsmeow.Client
+	if SessionCount() > 0 {
+		// The device store is shared with additional sessions, so only the default device is removed
+		if cli != nil && cli.Store.ID != nil {
+			if err := cli.Store.Delete(ctx); err != nil {
+				logrus.Errorf("[%s] Failed to delete default device: %v", logPrefix, err)
+			}
+		}
+		newDB = db
+		newCli = InitWaCLI(ctx, db, keysDB, chatStorageRepo)
+	} else {
+		// Clean up database
+		if err := CleanupDatabase(); err != nil {
+			return nil, nil, fmt.Errorf("database cleanup failed: %v", err)
+		}
 
-	// Reinitialize components
-	newDB, newCli, err := ReinitializeWhatsAppComponents(ctx, chatStorageRepo)
-	if err != nil {
-		return nil, nil, fmt.Errorf("reinitialization failed: %v", err)
+		// Reinitialize components
+		var err error
+		newDB, newCli, err = ReinitializeWhatsAppComponents(ctx, chatStorageRepo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reinitialization failed: %v", err)
+		}
 	}
 
 	// Clean up temporary files
@@ -361,7 +395,7 @@ func handler(ctx context.Context, rawEvt any, chatStorageRepo domainChatStorage.
 	case *events.Message:
 		handleMessage(ctx, evt, chatStorageRepo)
 	case *events.Receipt:
-		handleReceipt(ctx, evt)
+		handleReceipt(ctx, evt, chatStorageRepo)
 	case *events.Presence:
 		handlePresence(ctx, evt)
 	case *events.HistorySync:
@@ -378,6 +412,10 @@ func handler(ctx context.Context, rawEvt any, chatStorageRepo domainChatStorage.
 func handleDeleteForMe(ctx context.Context, evt *events.DeleteForMe, chatStorageRepo domainChatStorage.IChatStorageRepository) {
 	log.Infof("Deleted message %s for %s", evt.MessageID, evt.SenderJID.String())
 
+	if chatStorageRepo == nil {
+		return
+	}
+
 	// Find the message to get its chat JID
 	message, err := chatStorageRepo.GetMessageByID(evt.MessageID)
 	if err != nil {
@@ -398,7 +436,7 @@ func handleDeleteForMe(ctx context.Context, evt *events.DeleteForMe, chatStorage
 	}
 
 	// Send webhook notification for delete event
-	if len(config.WhatsappWebhook) > 0 {
+	if publishesEvent(domainWebhook.EventDelete) {
 		go func() {
 			if err := forwardDeleteToWebhook(ctx, evt, message); err != nil {
 				log.Errorf("Failed to forward delete event to webhook: %v", err)
@@ -407,9 +445,10 @@ func handleDeleteForMe(ctx context.Context, evt *events.DeleteForMe, chatStorage
 	}
 }
 
-func handleAppStateSyncComplete(_ context.Context, evt *events.AppStateSyncComplete) {
-	if len(cli.Store.PushName) > 0 && evt.Name == appstate.WAPatchCriticalBlock {
-		if err := cli.SendPresence(types.PresenceAvailable); err != nil {
+func handleAppStateSyncComplete(ctx context.Context, evt *events.AppStateSyncComplete) {
+	client := ClientFromContext(ctx)
+	if len(client.Store.PushName) > 0 && evt.Name == appstate.WAPatchCriticalBlock {
+		if err := client.SendPresence(types.PresenceAvailable); err != nil {
 			log.Warnf("Failed to send available presence: %v", err)
 		} else {
 			log.Infof("Marked self as available")
@@ -418,11 +457,12 @@ func handleAppStateSyncComplete(_ context.Context, evt *events.AppStateSyncCompl
 }
 
 func handlePairSuccess(ctx context.Context, evt *events.PairSuccess) {
-	websocket.Broadcast <- websocket.BroadcastMessage{
-		Code:    "LOGIN_SUCCESS",
-		Message: fmt.Sprintf("Successfully pair with %s", evt.ID.String()),
+	broadcastDeviceEvent(ctx, "LOGIN_SUCCESS", fmt.Sprintf("Successfully pair with %s", evt.ID.String()))
+
+	// The keys database only accelerates the default device
+	if IsDefaultDevice(ctx) {
+		syncKeysDevice(ctx, db, keysDB)
 	}
-	syncKeysDevice(ctx, db, keysDB)
 }
 
 func handleLoggedOut(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository) {
@@ -432,28 +472,30 @@ func handleLoggedOut(ctx context.Context, chatStorageRepo domainChatStorage.ICha
 	handleRemoteLogout(ctx, chatStorageRepo)
 
 	// Broadcast final notification that cleanup is complete and ready for new login
-	websocket.Broadcast <- websocket.BroadcastMessage{
-		Code:    "LOGOUT_COMPLETE",
-		Message: "Remote logout cleanup completed - ready for new login",
-		Result:  nil,
-	}
+	broadcastDeviceEvent(ctx, "LOGOUT_COMPLETE", "Remote logout cleanup completed - ready for new login")
 }
 
-func handleConnectionEvents(_ context.Context) {
-	if len(cli.Store.PushName) == 0 {
+func handleConnectionEvents(ctx context.Context) {
+	client := ClientFromContext(ctx)
+	if len(client.Store.PushName) == 0 {
 		return
 	}
 
 	// Send presence available when connecting and when the pushname is changed.
 	// This makes sure that outgoing messages always have the right pushname.
-	if err := cli.SendPresence(types.PresenceAvailable); err != nil {
+	if err := client.SendPresence(types.PresenceAvailable); err != nil {
 		log.Warnf("Failed to send available presence: %v", err)
 	} else {
 		log.Infof("Marked self as available")
 	}
 }
 
-func handleStreamReplaced(_ context.Context) {
+func handleStreamReplaced(ctx context.Context) {
+	// Additional sessions must not take the whole process down with them
+	if !IsDefaultDevice(ctx) {
+		log.Warnf("Stream replaced for device %s, session disconnected", DeviceIDFromContext(ctx))
+		return
+	}
 	os.Exit(0)
 }
 
@@ -467,9 +509,17 @@ func handleMessage(ctx context.Context, evt *events.Message, chatStorageRepo dom
 		evt.Message,
 	)
 
-	if err := chatStorageRepo.CreateMessage(ctx, evt); err != nil {
-		// Log storage errors to avoid silent failures that could lead to data loss
-		log.Errorf("Failed to store incoming message %s: %v", evt.Info.ID, err)
+	if chatStorageRepo != nil {
+		if err := chatStorageRepo.CreateMessage(ctx, evt); err != nil {
+			// Log storage errors to avoid silent failures that could lead to data loss
+			log.Errorf("Failed to store incoming message %s: %v", evt.Info.ID, err)
+		}
+		if err := StorePoll(chatStorageRepo, evt.Info.ID, evt.Info.Chat, evt.Info.Sender, evt.Message, evt.Info.Timestamp); err != nil {
+			log.Errorf("Failed to store poll %s: %v", evt.Info.ID, err)
+		}
+		if evt.Message.GetPollUpdateMessage() != nil {
+			handlePollVote(ctx, evt, chatStorageRepo)
+		}
 	}
 
 	// Handle image message if present
@@ -478,13 +528,33 @@ func handleMessage(ctx context.Context, evt *events.Message, chatStorageRepo dom
 	// Auto-mark message as read if configured
 	handleAutoMarkRead(ctx, evt)
 
-	// Handle auto-reply if configured
-	handleAutoReply(ctx, evt, chatStorageRepo)
+	// Let the auto-reply rules and other listeners react to the message
+	if len(messageListeners) > 0 {
+		go notifyMessageListeners(ctx, evt)
+	}
 
 	// Forward to webhook if configured
 	handleWebhookForward(ctx, evt)
 }
 
+func handlePollVote(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	poll, vote, err := storePollVote(ctx, evt, chatStorageRepo)
+	if err != nil {
+		log.Errorf("Failed to store poll vote %s from %s: %v", evt.Info.ID, evt.Info.SourceString(), err)
+		return
+	}
+	log.Infof("%s voted %d option(s) on poll %s", vote.Voter, len(vote.Options), vote.PollID)
+
+	// Votes are only forwarded to endpoints that explicitly subscribe to them
+	if publishesEvent(domainWebhook.EventPoll) {
+		go func() {
+			if err := forwardPollVoteToWebhook(ctx, poll, vote); err != nil {
+				logrus.Errorf("Failed to forward poll vote event to webhook: %v", err)
+			}
+		}()
+	}
+}
+
 func buildMessageMetaParts(evt *events.Message) []string {
 	metaParts := []string{
 		fmt.Sprintf("pushname: %s", evt.Info.PushName),
@@ -504,7 +574,7 @@ func buildMessageMetaParts(evt *events.Message) []string {
 
 func handleImageMessage(ctx context.Context, evt *events.Message) {
 	if img := evt.Message.GetImageMessage(); img != nil {
-		if path, err := utils.ExtractMedia(ctx, cli, config.PathStorages, img); err != nil {
+		if path, err := utils.ExtractMedia(ctx, ClientFromContext(ctx), config.PathStorages, img); err != nil {
 			log.Errorf("Failed to download image: %v", err)
 		} else {
 			log.Infof("Image downloaded to %s", path)
@@ -512,7 +582,7 @@ func handleImageMessage(ctx context.Context, evt *events.Message) {
 	}
 }
 
-func handleAutoMarkRead(_ context.Context, evt *events.Message) {
+func handleAutoMarkRead(ctx context.Context, evt *events.Message) {
 	// Only mark read if auto-mark read is enabled and message is incoming
 	if !config.WhatsappAutoMarkRead || evt.Info.IsFromMe {
 		return
@@ -524,119 +594,13 @@ func handleAutoMarkRead(_ context.Context, evt *events.Message) {
 	chat := evt.Info.Chat
 	sender := evt.Info.Sender
 
-	if err := cli.MarkRead(messageIDs, timestamp, chat, sender); err != nil {
+	if err := ClientFromContext(ctx).MarkRead(messageIDs, timestamp, chat, sender); err != nil {
 		log.Warnf("Failed to mark message %s as read: %v", evt.Info.ID, err)
 	} else {
 		log.Debugf("Marked message %s as read", evt.Info.ID)
 	}
 }
 
-func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository) {
-	if config.WhatsappAutoReplyMessage == "" {
-		return
-	}
-
-	// Skip groups, broadcasts, and self messages
-	if utils.IsGroupJID(evt.Info.Chat.String()) || evt.Info.IsIncomingBroadcast() || evt.Info.IsFromMe {
-		return
-	}
-
-	// Only reply to direct 1:1 chats (e.g., *@s.whatsapp.net)
-	if evt.Info.Chat.Server != types.DefaultUserServer {
-		return
-	}
-
-	// Extra safety: skip any broadcast/status contexts
-	source := evt.Info.SourceString()
-	if strings.Contains(source, "broadcast") ||
-		strings.HasSuffix(evt.Info.Chat.String(), "@broadcast") ||
-		strings.HasPrefix(evt.Info.Chat.String(), "status@") {
-		return
-	}
-
-	// Require actual typed text (not captions or synthetic labels)
-	hasText := false
-
-	// Unwrap FutureProof wrappers to access the inner message content first
-	innerMsg := evt.Message
-	for i := 0; i < 3; i++ { // safeguard against excessively nested wrappers
-		if vm := innerMsg.GetViewOnceMessage(); vm != nil && vm.GetMessage() != nil {
-			innerMsg = vm.GetMessage()
-			continue
-		}
-		if em := innerMsg.GetEphemeralMessage(); em != nil && em.GetMessage() != nil {
-			innerMsg = em.GetMessage()
-			continue
-		}
-		if vm2 := innerMsg.GetViewOnceMessageV2(); vm2 != nil && vm2.GetMessage() != nil {
-			innerMsg = vm2.GetMessage()
-			continue
-		}
-		if vm2e := innerMsg.GetViewOnceMessageV2Extension(); vm2e != nil && vm2e.GetMessage() != nil {
-			innerMsg = vm2e.GetMessage()
-			continue
-		}
-		break
-	}
-
-	// Check for genuine typed text on the unwrapped content
-	if conv := innerMsg.GetConversation(); conv != "" {
-		hasText = true
-	} else if ext := innerMsg.GetExtendedTextMessage(); ext != nil && ext.GetText() != "" {
-		hasText = true
-	} else if protoMsg := innerMsg.GetProtocolMessage(); protoMsg != nil {
-		if edited := protoMsg.GetEditedMessage(); edited != nil {
-			if ext := edited.GetExtendedTextMessage(); ext != nil && ext.GetText() != "" {
-				hasText = true
-			} else if conv := edited.GetConversation(); conv != "" {
-				hasText = true
-			}
-		}
-	}
-	if !hasText {
-		return
-	}
-
-	// Format recipient JID
-	recipientJID := utils.FormatJID(evt.Info.Sender.String())
-
-	// Send the auto-reply message
-	response, err := cli.SendMessage(
-		ctx,
-		recipientJID,
-		&waE2E.Message{Conversation: proto.String(config.WhatsappAutoReplyMessage)},
-	)
-
-	if err != nil {
-		log.Errorf("Failed to send auto-reply message: %v", err)
-		return
-	}
-
-	// Store the auto-reply message in chat storage if send was successful
-	if chatStorageRepo != nil {
-		// Get our own JID as sender
-		senderJID := ""
-		if cli.Store.ID != nil {
-			senderJID = cli.Store.ID.String()
-		}
-
-		// Store the sent auto-reply message
-		if err := chatStorageRepo.StoreSentMessageWithContext(
-			ctx,
-			response.ID,                     // Message ID from WhatsApp response
-			senderJID,                       // Our JID as sender
-			recipientJID.String(),           // Recipient JID
-			config.WhatsappAutoReplyMessage, // Auto-reply content
-			response.Timestamp,              // Timestamp from response
-		); err != nil {
-			// Log storage error but don't fail the auto-reply
-			log.Errorf("Failed to store auto-reply message in chat storage: %v", err)
-		} else {
-			log.Debugf("Auto-reply message %s stored successfully in chat storage", response.ID)
-		}
-	}
-}
-
 func handleWebhookForward(ctx context.Context, evt *events.Message) {
 	// Skip webhook for specific protocol messages that shouldn't trigger webhooks
 	if protocolMessage := evt.Message.GetProtocolMessage(); protocolMessage != nil {
@@ -648,7 +612,7 @@ func handleWebhookForward(ctx context.Context, evt *events.Message) {
 		}
 	}
 
-	if len(config.WhatsappWebhook) > 0 &&
+	if publishesEvent(domainWebhook.EventMessage) &&
 		!strings.Contains(evt.Info.SourceString(), "broadcast") {
 		go func(evt *events.Message) {
 			if err := forwardMessageToWebhook(ctx, evt); err != nil {
@@ -658,7 +622,7 @@ func handleWebhookForward(ctx context.Context, evt *events.Message) {
 	}
 }
 
-func handleReceipt(ctx context.Context, evt *events.Receipt) {
+func handleReceipt(ctx context.Context, evt *events.Receipt, chatStorageRepo domainChatStorage.IChatStorageRepository) {
 	sendReceipt := false
 	switch evt.Type {
 	case types.ReceiptTypeRead, types.ReceiptTypeReadSelf:
@@ -667,11 +631,23 @@ func handleReceipt(ctx context.Context, evt *events.Receipt) {
 	case types.ReceiptTypeDelivered:
 		sendReceipt = true
 		log.Infof("%s was delivered to %s at %s: %+v", evt.MessageIDs[0], evt.SourceString(), evt.Timestamp, evt)
+	case types.ReceiptTypePlayed:
+		log.Infof("%v was played by %s at %s", evt.MessageIDs, evt.SourceString(), evt.Timestamp)
+	}
+
+	if chatStorageRepo != nil {
+		if err := storeReceipt(evt, chatStorageRepo); err != nil {
+			log.Errorf("Failed to store %s receipt of %v: %v", evt.Type, evt.MessageIDs, err)
+		}
+	}
+
+	if sendReceipt && len(receiptListeners) > 0 {
+		go notifyReceiptListeners(ctx, evt)
 	}
 
 	// Forward receipt (ack) event to webhook if configured
 	// Note: Receipt events are not rate limited as they are critical for message delivery status
-	if len(config.WhatsappWebhook) > 0 && sendReceipt {
+	if publishesEvent(domainWebhook.EventReceipt) && sendReceipt {
 		go func(e *events.Receipt) {
 			if err := forwardReceiptToWebhook(ctx, e); err != nil {
 				logrus.Errorf("Failed to forward ack event to webhook: %v", err)
@@ -680,7 +656,7 @@ func handleReceipt(ctx context.Context, evt *events.Receipt) {
 	}
 }
 
-func handlePresence(_ context.Context, evt *events.Presence) {
+func handlePresence(ctx context.Context, evt *events.Presence) {
 	if evt.Unavailable {
 		if evt.LastSeen.IsZero() {
 			log.Infof("%s is now offline", evt.From)
@@ -690,6 +666,15 @@ func handlePresence(_ context.Context, evt *events.Presence) {
 	} else {
 		log.Infof("%s is now online", evt.From)
 	}
+
+	// Presence is only forwarded to endpoints that explicitly subscribe to it
+	if publishesEvent(domainWebhook.EventPresence) {
+		go func(e *events.Presence) {
+			if err := forwardPresenceToWebhook(ctx, e); err != nil {
+				logrus.Errorf("Failed to forward presence event to webhook: %v", err)
+			}
+		}(evt)
+	}
 }
 
 func handleHistorySync(ctx context.Context, evt *events.HistorySync, chatStorageRepo domainChatStorage.IChatStorageRepository) {
@@ -697,7 +682,7 @@ func handleHistorySync(ctx context.Context, evt *events.HistorySync, chatStorage
 	fileName := fmt.Sprintf("%s/history-%d-%s-%d-%s.json",
 		config.PathStorages,
 		startupTime,
-		cli.Store.ID.String(),
+		ClientFromContext(ctx).Store.ID.String(),
 		id,
 		evt.Data.SyncType.String(),
 	)
@@ -754,10 +739,12 @@ func processHistorySync(ctx context.Context, data *waHistorySync.HistorySync, ch
 }
 
 // processConversationMessages processes and stores conversation messages from history sync
-func processConversationMessages(_ context.Context, data *waHistorySync.HistorySync, chatStorageRepo domainChatStorage.IChatStorageRepository) error {
+func processConversationMessages(ctx context.Context, data *waHistorySync.HistorySync, chatStorageRepo domainChatStorage.IChatStorageRepository) error {
 	conversations := data.GetConversations()
 	log.Infof("Processing %d conversations from history sync", len(conversations))
 
+	client := ClientFromContext(ctx)
+
 	for _, conv := range conversations {
 		chatJID := conv.GetID()
 		if chatJID == "" {
@@ -783,8 +770,9 @@ func processConversationMessages(_ context.Context, data *waHistorySync.HistoryS
 		messages := conv.GetMessages()
 		log.Debugf("Processing %d messages for chat %s", len(messages), chatJID)
 
-		// Collect messages for batch processing
+		// Collect messages and reactions for batch processing
 		var messageBatch []*domainChatStorage.Message
+		var reactionBatch []*domainChatStorage.Reaction
 		var latestTimestamp time.Time
 
 		for _, histMsg := range messages {
@@ -804,6 +792,11 @@ func processConversationMessages(_ context.Context, data *waHistorySync.HistoryS
 				continue
 			}
 
+			reactionBatch = append(reactionBatch, historyReactions(jid, msg, client.Store.ID)...)
+			if err := storeHistoryPoll(chatStorageRepo, jid, msg, client.Store.ID); err != nil {
+				log.Warnf("Failed to store poll %s of chat %s: %v", messageID, chatJID, err)
+			}
+
 			// Extract message content and media info
 			content := utils.ExtractMessageTextFromProto(msg.GetMessage())
 			mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength := utils.ExtractMediaInfo(msg.GetMessage())
@@ -818,8 +811,8 @@ func processConversationMessages(_ context.Context, data *waHistorySync.HistoryS
 			isFromMe := msgKey.GetFromMe()
 			if isFromMe {
 				// For self-messages, use the full JID format to match regular message processing
-				if cli.Store.ID != nil {
-					sender = cli.Store.ID.String() // Use full JID instead of just User part
+				if client.Store.ID != nil {
+					sender = client.Store.ID.String() // Use full JID instead of just User part
 				} else {
 					// Skip messages where we can't determine the sender to avoid NOT NULL violations
 					log.Warnf("Skipping self-message %s: client ID unavailable", messageID)
@@ -897,11 +890,116 @@ func processConversationMessages(_ context.Context, data *waHistorySync.HistoryS
 				log.Debugf("Stored %d messages for chat %s", len(messageBatch), chatJID)
 			}
 		}
+
+		if len(reactionBatch) > 0 {
+			if err := chatStorageRepo.StoreReactions(reactionBatch); err != nil {
+				log.Warnf("Failed to store reactions batch for chat %s: %v", chatJID, err)
+			} else {
+				log.Debugf("Stored %d reactions for chat %s", len(reactionBatch), chatJID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// historyReactions returns the reactions to a message of the history sync, or the reaction the message itself is
+func historyReactions(chat types.JID, msg *waWeb.WebMessageInfo, ownJID *types.JID) []*domainChatStorage.Reaction {
+	var reactions []*domainChatStorage.Reaction
+	add := func(messageID string, key *waCommon.MessageKey, emoji string, timestampMS int64) {
+		sender := historySender(chat, key, ownJID)
+		if messageID == "" || sender == "" {
+			return
+		}
+		reactions = append(reactions, &domainChatStorage.Reaction{
+			MessageID: messageID,
+			ChatJID:   chat.String(),
+			Sender:    sender,
+			Emoji:     emoji,
+			IsFromMe:  key.GetFromMe(),
+			Timestamp: time.UnixMilli(timestampMS),
+		})
 	}
 
+	for _, reaction := range msg.GetReactions() {
+		add(msg.GetKey().GetID(), reaction.GetKey(), reaction.GetText(), reaction.GetSenderTimestampMS())
+	}
+
+	if reactionMessage := msg.GetMessage().GetReactionMessage(); reactionMessage != nil {
+		timestampMS := reactionMessage.GetSenderTimestampMS()
+		if timestampMS == 0 {
+			// WhatsApp history sync timestamps are in seconds
+			timestampMS = int64(msg.GetMessageTimestamp()) * 1000
+		}
+		add(reactionMessage.GetKey().GetID(), msg.GetKey(), reactionMessage.GetText(), timestampMS)
+	}
+
+	return reactions
+}
+
+// storeHistoryPoll stores a poll of the history sync with the votes on it, which come already decrypted
+func storeHistoryPoll(repo domainChatStorage.IChatStorageRepository, chat types.JID, msg *waWeb.WebMessageInfo, ownJID *types.JID) error {
+	sender, err := types.ParseJID(historySender(chat, msg.GetKey(), ownJID))
+	if pollCreation(msg.GetMessage()) == nil || err != nil || sender.IsEmpty() {
+		return nil
+	}
+
+	// WhatsApp history sync timestamps are in seconds
+	timestamp := time.Unix(int64(msg.GetMessageTimestamp()), 0)
+	if err := StorePoll(repo, msg.GetKey().GetID(), chat, sender, msg.GetMessage(), timestamp); err != nil {
+		return err
+	}
+	for _, vote := range historyPollVotes(chat, msg, ownJID) {
+		if err := repo.StorePollVote(vote); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// historyPollVotes returns the votes on a poll of the history sync
+func historyPollVotes(chat types.JID, msg *waWeb.WebMessageInfo, ownJID *types.JID) []*domainChatStorage.PollVote {
+	var votes []*domainChatStorage.PollVote
+	for _, update := range msg.GetPollUpdates() {
+		voter := historySender(chat, update.GetPollUpdateMessageKey(), ownJID)
+		if voter == "" {
+			continue
+		}
+
+		vote := &domainChatStorage.PollVote{
+			PollID:    msg.GetKey().GetID(),
+			ChatJID:   chat.String(),
+			Voter:     voter,
+			Options:   make([]string, 0, len(update.GetVote().GetSelectedOptions())),
+			Timestamp: time.UnixMilli(update.GetSenderTimestampMS()),
+		}
+		for _, hash := range update.GetVote().GetSelectedOptions() {
+			vote.Options = append(vote.Options, hex.EncodeToString(hash))
+		}
+		votes = append(votes, vote)
+	}
+	return votes
+}
+
+// historySender returns who sent a message of the history sync by its key, empty when it cannot be determined
+func historySender(chat types.JID, key *waCommon.MessageKey, ownJID *types.JID) string {
+	switch {
+	case key.GetFromMe():
+		if ownJID == nil {
+			return ""
+		}
+		return ownJID.ToNonAD().String()
+	case key.GetParticipant() != "":
+		if jid, err := types.ParseJID(key.GetParticipant()); err == nil {
+			return jid.ToNonAD().String()
+		}
+		return key.GetParticipant()
+	default:
+		// In individual chats the other side is the chat itself
+		return chat.ToNonAD().String()
+	}
+}
+
 // processPushNames processes push names from history sync to update chat names
 func processPushNames(_ context.Context, data *waHistorySync.HistorySync, chatStorageRepo domainChatStorage.IChatStorageRepository) error {
 	pushnames := data.GetPushnames()
@@ -960,7 +1058,7 @@ func handleGroupInfo(ctx context.Context, evt *events.GroupInfo) {
 	}
 
 	// Forward group info event to webhook if configured
-	if len(config.WhatsappWebhook) > 0 {
+	if publishesEvent(domainWebhook.EventGroup) {
 		go func(e *events.GroupInfo) {
 			if err := forwardGroupInfoToWebhook(ctx, e); err != nil {
 				logrus.Errorf("Failed to forward group info event to webhook: %v", err)
@@ -0,0 +1,65 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHistoryReactions(t *testing.T) {
+	own := types.NewADJID("628111111111", 0, 5)
+	group := types.NewJID("120363024512399999", types.GroupServer)
+	reactedAt := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	message := &waWeb.WebMessageInfo{
+		Key: &waCommon.MessageKey{ID: proto.String("3EB0AAA"), RemoteJID: proto.String(group.String())},
+		Reactions: []*waWeb.Reaction{
+			{
+				Key:               &waCommon.MessageKey{Participant: proto.String("628222222222:3@s.whatsapp.net")},
+				Text:              proto.String("👍"),
+				SenderTimestampMS: proto.Int64(reactedAt.UnixMilli()),
+			},
+			{
+				Key:               &waCommon.MessageKey{FromMe: proto.Bool(true)},
+				Text:              proto.String("🔥"),
+				SenderTimestampMS: proto.Int64(reactedAt.UnixMilli()),
+			},
+		},
+	}
+	reactions := historyReactions(group, message, &own)
+	require.Len(t, reactions, 2)
+	assert.Equal(t, "3EB0AAA", reactions[0].MessageID)
+	assert.Equal(t, group.String(), reactions[0].ChatJID)
+	assert.Equal(t, "628222222222@s.whatsapp.net", reactions[0].Sender)
+	assert.Equal(t, "👍", reactions[0].Emoji)
+	assert.False(t, reactions[0].IsFromMe)
+	assert.True(t, reactedAt.Equal(reactions[0].Timestamp))
+	assert.Equal(t, "628111111111@s.whatsapp.net", reactions[1].Sender)
+	assert.True(t, reactions[1].IsFromMe)
+
+	// A reaction message of an individual chat is a reaction of the chat to another message
+	chat := types.NewJID("628333333333", types.DefaultUserServer)
+	reactionMessage := &waWeb.WebMessageInfo{
+		Key:              &waCommon.MessageKey{ID: proto.String("3EB0BBB"), RemoteJID: proto.String(chat.String())},
+		MessageTimestamp: proto.Uint64(uint64(reactedAt.Unix())),
+		Message: &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+			Key:  &waCommon.MessageKey{ID: proto.String("3EB0CCC"), FromMe: proto.Bool(true)},
+			Text: proto.String("❤️"),
+		}},
+	}
+	reactions = historyReactions(chat, reactionMessage, &own)
+	require.Len(t, reactions, 1)
+	assert.Equal(t, "3EB0CCC", reactions[0].MessageID)
+	assert.Equal(t, chat.String(), reactions[0].Sender)
+	assert.True(t, reactedAt.Equal(reactions[0].Timestamp))
+
+	// Without our own JID our reactions cannot be attributed
+	assert.Len(t, historyReactions(group, message, nil), 1)
+}
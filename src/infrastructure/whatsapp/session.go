@@ -0,0 +1,463 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// DefaultDeviceID is the identifier used for the primary session that owns the global client
+const DefaultDeviceID = "default"
+
+// ChatStorageFactory creates a chat storage repository scoped to a single device.
+// The key is the phone number (JID user) of the paired device.
+type ChatStorageFactory func(key string) (domainChatStorage.IChatStorageRepository, error)
+
+// Session is an additional WhatsApp account served by this process
+type Session struct {
+	// Token is the stable handle assigned when the session is created (before pairing the JID is unknown)
+	Token     string
+	Client    *whatsmeow.Client
+	CreatedAt time.Time
+
+	mu          sync.RWMutex
+	chatStorage domainChatStorage.IChatStorageRepository
+}
+
+// SessionInfo is the public view of a session used by the REST and websocket layers
+type SessionInfo struct {
+	ID          string `json:"id"`
+	Token       string `json:"token"`
+	Name        string `json:"name"`
+	IsDefault   bool   `json:"is_default"`
+	IsConnected bool   `json:"is_connected"`
+	IsLoggedIn  bool   `json:"is_logged_in"`
+}
+
+type deviceContextKey struct{}
+
+var (
+	sessionsMu         sync.RWMutex
+	sessions           = make(map[string]*Session)
+	chatStorageFactory ChatStorageFactory
+	// sessionStorage keeps the tokens of the paired sessions, it is the chat storage of the default device
+	sessionStorage domainChatStorage.IChatStorageRepository
+)
+
+// DeviceID returns the device JID when paired, otherwise the session token
+func (s *Session) DeviceID() string {
+	if s.Client != nil && s.Client.Store != nil && s.Client.Store.ID != nil {
+		return s.Client.Store.ID.String()
+	}
+	return s.Token
+}
+
+// ChatStorage returns the chat storage bound to this session (nil until the device is paired)
+func (s *Session) ChatStorage() domainChatStorage.IChatStorageRepository {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chatStorage
+}
+
+// bindChatStorage lazily creates the device-scoped chat storage once the phone number is known
+func (s *Session) bindChatStorage(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chatStorage != nil || chatStorageFactory == nil || key == "" {
+		return nil
+	}
+
+	repo, err := chatStorageFactory(key)
+	if err != nil {
+		return err
+	}
+	if err := repo.InitializeSchema(); err != nil {
+		return fmt.Errorf("failed to initialize chat storage for %s: %w", key, err)
+	}
+	s.chatStorage = repo
+	return nil
+}
+
+// SetChatStorageFactory registers the factory used to create per-device chat storage
+func SetChatStorageFactory(factory ChatStorageFactory) {
+	chatStorageFactory = factory
+}
+
+// ContextWithDevice returns a context that selects the given device for subsequent client lookups
+func ContextWithDevice(ctx context.Context, deviceID string) context.Context {
+	return context.WithValue(ctx, deviceContextKey{}, deviceID)
+}
+
+// DeviceIDFromContext returns the device selected in the context, or an empty string for the default device
+func DeviceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	deviceID, _ := ctx.Value(deviceContextKey{}).(string)
+	return deviceID
+}
+
+// IsDefaultDevice reports whether the context targets the default (global) client
+func IsDefaultDevice(ctx context.Context) bool {
+	deviceID := DeviceIDFromContext(ctx)
+	if deviceID == "" || deviceID == DefaultDeviceID {
+		return true
+	}
+	_, ok := GetSession(deviceID)
+	return !ok && matchesClient(cli, deviceID)
+}
+
+// DeviceAliases returns every identifier that selects the device of the context: the empty and default identifiers
+// of the default device, the token of a session, and the JID and phone number once paired. Records keep the
+// identifier the request selected the device with, so they are matched against all of them.
+func DeviceAliases(ctx context.Context) []string {
+	client := ClientFromContext(ctx)
+	var aliases []string
+	if session, ok := GetSession(DeviceIDFromContext(ctx)); ok {
+		aliases = append(aliases, session.Token)
+	} else if IsDefaultDevice(ctx) {
+		aliases = append(aliases, "", DefaultDeviceID)
+	} else {
+		return nil
+	}
+	if client != nil && client.Store != nil && client.Store.ID != nil {
+		aliases = append(aliases, client.Store.ID.String(), client.Store.ID.User, client.Store.ID.ToNonAD().String())
+	}
+	return aliases
+}
+
+// ClientFromContext returns the client of the device selected in the context, the global client when no device
+// is selected, and nil when the selected device is no longer served (a removed session must not fall back to the
+// global client, it is another account)
+func ClientFromContext(ctx context.Context) *whatsmeow.Client {
+	if session, ok := GetSession(DeviceIDFromContext(ctx)); ok {
+		return session.Client
+	}
+	if IsDefaultDevice(ctx) {
+		return cli
+	}
+	return nil
+}
+
+// RecoverSend turns a panic of a send into the error of the caller, deferred as RecoverSend(&err). The usecases
+// panic when the device logged out or was removed after the caller selected it.
+func RecoverSend(err *error) {
+	if recovered := recover(); recovered != nil {
+		if recoveredErr, ok := recovered.(error); ok {
+			*err = recoveredErr
+		} else {
+			*err = fmt.Errorf("%v", recovered)
+		}
+	}
+}
+
+// ChatStorageFromContext returns the chat storage of the device selected in the context.
+// The fallback is the storage of the default device; sessions that are not paired yet have no storage and
+// devices that are no longer served fail with ErrDeviceNotFound.
+func ChatStorageFromContext(ctx context.Context, fallback domainChatStorage.IChatStorageRepository) (domainChatStorage.IChatStorageRepository, error) {
+	deviceID := DeviceIDFromContext(ctx)
+	session, ok := GetSession(deviceID)
+	if !ok {
+		if !DeviceExists(deviceID) {
+			return nil, pkgError.ErrDeviceNotFound
+		}
+		return fallback, nil
+	}
+	if repo := session.ChatStorage(); repo != nil {
+		return repo, nil
+	}
+	return nil, pkgError.ErrNotLoggedIn
+}
+
+// matchesClient checks whether an identifier refers to the given client by full JID or phone number
+func matchesClient(client *whatsmeow.Client, id string) bool {
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return false
+	}
+	return client.Store.ID.String() == id || client.Store.ID.User == id || client.Store.ID.ToNonAD().String() == id
+}
+
+// GetSession looks up an additional session by token, device JID or phone number
+func GetSession(id string) (*Session, bool) {
+	if id == "" || id == DefaultDeviceID {
+		return nil, false
+	}
+
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	if session, ok := sessions[id]; ok {
+		return session, true
+	}
+	for _, session := range sessions {
+		if matchesClient(session.Client, id) {
+			return session, true
+		}
+	}
+	return nil, false
+}
+
+// DeviceExists reports whether the identifier refers to the default client or a registered session
+func DeviceExists(id string) bool {
+	if id == "" || id == DefaultDeviceID || matchesClient(cli, id) {
+		return true
+	}
+	_, ok := GetSession(id)
+	return ok
+}
+
+// SessionCount returns the number of additional sessions
+func SessionCount() int {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+	return len(sessions)
+}
+
+// ListSessions returns the default session followed by all additional sessions
+func ListSessions() []SessionInfo {
+	result := []SessionInfo{sessionInfo(DefaultDeviceID, cli, true)}
+
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	var extra []SessionInfo
+	for token, session := range sessions {
+		extra = append(extra, sessionInfo(token, session.Client, false))
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Token < extra[j].Token })
+
+	return append(result, extra...)
+}
+
+func sessionInfo(token string, client *whatsmeow.Client, isDefault bool) SessionInfo {
+	info := SessionInfo{ID: token, Token: token, IsDefault: isDefault}
+	if client == nil {
+		return info
+	}
+	info.IsConnected = client.IsConnected()
+	info.IsLoggedIn = client.IsLoggedIn()
+	if client.Store != nil {
+		if client.Store.ID != nil {
+			info.ID = client.Store.ID.String()
+		}
+		info.Name = client.Store.PushName
+		if info.Name == "" {
+			info.Name = client.Store.BusinessName
+		}
+	}
+	return info
+}
+
+// newSessionClient creates a client for a device and wires the session-scoped event handler
+func newSessionClient(ctx context.Context, session *Session, device *store.Device) {
+	// Additional sessions keep their keys in the main store; the keys database only mirrors the default device
+	client := whatsmeow.NewClient(device, waLog.Stdout("Client-"+session.Token, config.WhatsappLogLevel, true))
+	client.EnableAutoReconnect = true
+	client.AutoTrustIdentity = true
+	session.Client = client
+
+	sessionCtx := ContextWithDevice(ctx, session.Token)
+	client.AddEventHandler(func(rawEvt any) {
+		sessionHandler(sessionCtx, session, rawEvt)
+	})
+}
+
+// AddSession creates a new, unpaired session. It must be paired through the login endpoints afterwards.
+func AddSession(ctx context.Context) (*Session, error) {
+	if db == nil {
+		return nil, pkgError.ErrWaCLI
+	}
+
+	session := &Session{
+		Token:     uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+	newSessionClient(ctx, session, db.NewDevice())
+
+	sessionsMu.Lock()
+	sessions[session.Token] = session
+	sessionsMu.Unlock()
+
+	logrus.Infof("[SESSION] Created new session %s", session.Token)
+	return session, nil
+}
+
+// LoadSessions restores every paired device other than the default one as an additional session. A session keeps
+// the token it was created with, the repository remembers it once the device is paired.
+func LoadSessions(ctx context.Context, repo domainChatStorage.IChatStorageRepository) {
+	sessionStorage = repo
+	if db == nil {
+		return
+	}
+
+	devices, err := db.GetAllDevices(ctx)
+	if err != nil {
+		logrus.Errorf("[SESSION] Failed to load devices: %v", err)
+		return
+	}
+
+	tokens := map[string]string{}
+	if repo != nil {
+		if tokens, err = repo.GetSessionTokens(); err != nil {
+			logrus.Errorf("[SESSION] Failed to load session tokens: %v", err)
+		}
+	}
+
+	for _, device := range devices {
+		if device.ID == nil || matchesClient(cli, device.ID.String()) {
+			continue
+		}
+		if _, ok := GetSession(device.ID.String()); ok {
+			continue
+		}
+
+		// Devices paired before their tokens were kept are known by their phone number
+		token, ok := tokens[device.ID.String()]
+		if !ok {
+			token = device.ID.User
+			storeSessionToken(device.ID.String(), token)
+		}
+
+		session := &Session{
+			Token:     token,
+			CreatedAt: time.Now(),
+		}
+		newSessionClient(ctx, session, device)
+		if err := session.bindChatStorage(device.ID.User); err != nil {
+			logrus.Errorf("[SESSION] Failed to bind chat storage for %s: %v", device.ID.String(), err)
+		}
+
+		sessionsMu.Lock()
+		sessions[session.Token] = session
+		sessionsMu.Unlock()
+
+		logrus.Infof("[SESSION] Restored session %s (%s)", session.Token, device.ID.String())
+	}
+}
+
+// storeSessionToken keeps the token of the session of a paired device
+func storeSessionToken(deviceJID, token string) {
+	if sessionStorage == nil {
+		return
+	}
+	if err := sessionStorage.StoreSessionToken(deviceJID, token); err != nil {
+		logrus.Errorf("[SESSION] Failed to store the token of session %s: %v", token, err)
+	}
+}
+
+// ConnectSessions connects every additional session that has a stored identity
+func ConnectSessions() {
+	sessionsMu.RLock()
+	defer sessionsMu.RUnlock()
+
+	for _, session := range sessions {
+		if session.Client.Store.ID == nil || session.Client.IsConnected() {
+			continue
+		}
+		go func(s *Session) {
+			if err := s.Client.Connect(); err != nil {
+				logrus.Errorf("[SESSION] Failed to connect session %s: %v", s.DeviceID(), err)
+			}
+		}(session)
+	}
+}
+
+// RemoveSession logs out (when paired) and removes an additional session
+func RemoveSession(ctx context.Context, id string) error {
+	session, ok := GetSession(id)
+	if !ok {
+		return pkgError.ErrDeviceNotFound
+	}
+
+	if session.Client.Store.ID != nil {
+		if err := session.Client.Logout(ctx); err != nil {
+			logrus.Warnf("[SESSION] Logout for %s failed, deleting device store: %v", session.DeviceID(), err)
+			if err := session.Client.Store.Delete(ctx); err != nil {
+				logrus.Errorf("[SESSION] Failed to delete device store for %s: %v", session.DeviceID(), err)
+			}
+		}
+	}
+	session.Client.Disconnect()
+	dropSession(session, "MANUAL_REMOVE")
+
+	return nil
+}
+
+// dropSession forgets a session and truncates its chat storage
+func dropSession(session *Session, logPrefix string) {
+	sessionsMu.Lock()
+	delete(sessions, session.Token)
+	sessionsMu.Unlock()
+
+	if sessionStorage != nil {
+		if err := sessionStorage.DeleteSessionToken(session.Token); err != nil {
+			logrus.Errorf("[%s] Failed to delete the token of session %s: %v", logPrefix, session.Token, err)
+		}
+	}
+
+	if repo := session.ChatStorage(); repo != nil {
+		if err := repo.TruncateAllDataWithLogging(logPrefix); err != nil {
+			logrus.Errorf("[%s] Failed to truncate chat storage for %s: %v", logPrefix, session.DeviceID(), err)
+		}
+	}
+	logrus.Infof("[%s] Session %s removed", logPrefix, session.Token)
+}
+
+// sessionHandler routes events of an additional session through the shared handler
+func sessionHandler(ctx context.Context, session *Session, rawEvt any) {
+	switch evt := rawEvt.(type) {
+	case *events.PairSuccess:
+		storeSessionToken(evt.ID.String(), session.Token)
+		if err := session.bindChatStorage(evt.ID.User); err != nil {
+			logrus.Errorf("[SESSION] Failed to bind chat storage for %s: %v", evt.ID.String(), err)
+		}
+	case *events.LoggedOut:
+		logrus.Warnf("[REMOTE_LOGOUT] Session %s logged out from phone", session.DeviceID())
+		dropSession(session, "REMOTE_LOGOUT")
+		broadcastDeviceEvent(ctx, "LOGOUT_COMPLETE", fmt.Sprintf("Session %s logged out", session.DeviceID()))
+		return
+	}
+
+	handler(ctx, rawEvt, session.ChatStorage())
+}
+
+// deviceIDForEvent returns the identifier published with events of the device selected in the context
+func deviceIDForEvent(ctx context.Context) string {
+	client := ClientFromContext(ctx)
+	if client != nil && client.Store != nil && client.Store.ID != nil {
+		return client.Store.ID.String()
+	}
+	if deviceID := DeviceIDFromContext(ctx); deviceID != "" {
+		return deviceID
+	}
+	return DefaultDeviceID
+}
+
+// broadcastDeviceEvent publishes a websocket event tagged with the device selected in the context
+func broadcastDeviceEvent(ctx context.Context, code, message string) {
+	BroadcastDeviceResult(ctx, code, message, nil)
+}
+
+// BroadcastDeviceResult publishes a websocket event with a result, tagged with the device selected in the context.
+// It never blocks, the event is dropped when no hub receives it, like when only the MCP server runs.
+func BroadcastDeviceResult(ctx context.Context, code, message string, result any) {
+	websocket.Publish(websocket.BroadcastMessage{
+		Code:     code,
+		Message:  message,
+		Result:   result,
+		DeviceID: deviceIDForEvent(ctx),
+	})
+}
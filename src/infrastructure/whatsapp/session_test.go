@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverSend(t *testing.T) {
+	send := func(panicWith any) (err error) {
+		defer RecoverSend(&err)
+		if panicWith != nil {
+			panic(panicWith)
+		}
+		return nil
+	}
+
+	assert.NoError(t, send(nil))
+	assert.True(t, errors.Is(send(pkgError.ErrNotLoggedIn), pkgError.ErrNotLoggedIn))
+	assert.EqualError(t, send("client is nil"), "client is nil")
+}
+
+func TestRemovedDeviceDoesNotFallBackToDefault(t *testing.T) {
+	removed := ContextWithDevice(context.Background(), "removed-session-token")
+	assert.Nil(t, ClientFromContext(removed))
+
+	var fallback domainChatStorage.IChatStorageRepository = &struct {
+		domainChatStorage.IChatStorageRepository
+	}{}
+	repo, err := ChatStorageFromContext(removed, fallback)
+	assert.Nil(t, repo)
+	assert.True(t, errors.Is(err, pkgError.ErrDeviceNotFound))
+
+	repo, err = ChatStorageFromContext(ContextWithDevice(context.Background(), DefaultDeviceID), fallback)
+	assert.NoError(t, err)
+	assert.Same(t, fallback, repo)
+}
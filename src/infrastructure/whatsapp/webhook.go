@@ -1,64 +1,55 @@
 package whatsapp
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
 
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
-	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
-	"github.com/sirupsen/logrus"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
 )
 
-func submitWebhook(ctx context.Context, payload map[string]any, url string) error {
-	client := &http.Client{Timeout: 10 * time.Second}
+// EventListener is notified of every event published to the webhooks, whether an endpoint subscribes to it or not
+type EventListener func(ctx context.Context, event domainWebhook.Event)
 
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return pkgError.WebhookError(fmt.Sprintf("Failed to marshal body: %v", err))
-	}
+type eventListener struct {
+	wants  func(eventType string) bool
+	notify EventListener
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
-	if err != nil {
-		return pkgError.WebhookError(fmt.Sprintf("error when create http object %v", err))
-	}
+var eventListeners []eventListener
 
-	secretKey := []byte(config.WhatsappWebhookSecret)
-	signature, err := utils.GetMessageDigestOrSignature(postBody, secretKey)
-	if err != nil {
-		return pkgError.WebhookError(fmt.Sprintf("error when create signature %v", err))
-	}
+// AddEventListener registers a listener for the webhook events of the types it wants, like the plugins. The wanted
+// types may change at runtime. Listeners are registered at startup and must not block, they run on the goroutine
+// publishing the event.
+func AddEventListener(wants func(eventType string) bool, listener EventListener) {
+	eventListeners = append(eventListeners, eventListener{wants: wants, notify: listener})
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Hub-Signature-256", fmt.Sprintf("sha256=%s", signature))
+// publishesEvent reports whether an event of the type goes to a webhook endpoint or a listener.
+// Events nobody wants are not built, so the media of a message is not downloaded for nothing.
+func publishesEvent(eventType string) bool {
+	for _, listener := range eventListeners {
+		if listener.wants(eventType) {
+			return true
+		}
+	}
+	return webhook.HasSubscribers(eventType)
+}
 
-	var attempt int
-	var maxAttempts = 5
-	var sleepDuration = 1 * time.Second
+// submitWebhook passes the event to the listeners and stores it in the webhook outbox for every endpoint subscribed
+// to the event of the chat, the dispatcher delivers it with retries
+func submitWebhook(ctx context.Context, event domainWebhook.Event) error {
+	// Let receivers tell apart events of the different sessions served by this process
+	event.DeviceID = deviceIDForEvent(ctx)
 
-	for attempt = 0; attempt < maxAttempts; attempt++ {
-		// Create new request body for each attempt
-		req.Body = io.NopCloser(bytes.NewBuffer(postBody))
-		resp, err := client.Do(req)
-		if err == nil {
-			defer resp.Body.Close()
-			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				logrus.Infof("Successfully submitted webhook on attempt %d", attempt+1)
-				return nil
-			}
-			err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
-		}
-		logrus.Warnf("Attempt %d to submit webhook failed: %v", attempt+1, err)
-		if attempt < maxAttempts-1 {
-			time.Sleep(sleepDuration)
-			sleepDuration *= 2
+	for _, listener := range eventListeners {
+		if listener.wants(event.Type) {
+			listener.notify(ctx, event)
 		}
 	}
+	return webhook.Publish(ctx, event)
+}
 
-	return pkgError.WebhookError(fmt.Sprintf("error when submit webhook after %d attempts: %v", attempt, err))
+// SubmitWebhook submits an event raised outside of the WhatsApp event handlers, like the outcome of a send job
+func SubmitWebhook(ctx context.Context, event domainWebhook.Event) error {
+	return submitWebhook(ctx, event)
 }
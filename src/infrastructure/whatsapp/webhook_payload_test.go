@@ -0,0 +1,222 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// Run `go test ./infrastructure/whatsapp -run TestWebhookPayloadGolden -update` after an intended change of a payload
+var update = flag.Bool("update", false, "update the golden files of the webhook payloads")
+
+func TestWebhookPayloadGolden(t *testing.T) {
+	timestamp := time.Date(2025, 10, 15, 10, 30, 0, 0, time.UTC)
+	sender := types.NewJID("628123456789", types.DefaultUserServer)
+	chat := types.NewJID("628987654321", types.DefaultUserServer)
+	group := types.NewJID("120363024512399999", types.GroupServer)
+
+	messageEvent := func(message *waE2E.Message) *events.Message {
+		return &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{Chat: chat, Sender: sender},
+				ID:            "3EB0C127D7BACC83D6A1",
+				PushName:      "John Doe",
+				Timestamp:     timestamp,
+			},
+			Message: message,
+		}
+	}
+	mustMessageEvent := func(evt *events.Message) domainWebhook.Event {
+		event, err := createMessageEvent(context.Background(), evt)
+		require.NoError(t, err)
+		return event
+	}
+
+	tests := []struct {
+		name  string
+		event domainWebhook.Event
+	}{
+		{
+			name: "message_text",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				Conversation: proto.String("Hello, how are you?"),
+			})),
+		},
+		{
+			name: "message_reply",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+					Text: proto.String("I'm doing great, thanks!"),
+					ContextInfo: &waE2E.ContextInfo{
+						StanzaID:      proto.String("3EB0C127D7BACC83D6A0"),
+						QuotedMessage: &waE2E.Message{Conversation: proto.String("Hello, how are you?")},
+						IsForwarded:   proto.Bool(true),
+					},
+				},
+			})),
+		},
+		{
+			name: "message_reaction",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				ReactionMessage: &waE2E.ReactionMessage{
+					Text: proto.String("👍"),
+					Key:  &waCommon.MessageKey{ID: proto.String("3EB0C127D7BACC83D6A0")},
+				},
+			})),
+		},
+		{
+			name: "message_revoked",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				ProtocolMessage: &waE2E.ProtocolMessage{
+					Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+					Key: &waCommon.MessageKey{
+						ID:        proto.String("3EB0C127D7BACC83D6A0"),
+						FromMe:    proto.Bool(false),
+						RemoteJID: proto.String(chat.String()),
+					},
+				},
+			})),
+		},
+		{
+			name: "message_edited",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				ProtocolMessage: &waE2E.ProtocolMessage{
+					Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+					Key:           &waCommon.MessageKey{ID: proto.String("3EB0C127D7BACC83D6A0")},
+					EditedMessage: &waE2E.Message{Conversation: proto.String("Hello, how are you doing?")},
+				},
+			})),
+		},
+		{
+			name: "message_location",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				LocationMessage: &waE2E.LocationMessage{
+					DegreesLatitude:  proto.Float64(-6.2088),
+					DegreesLongitude: proto.Float64(106.8456),
+					Name:             proto.String("Monas"),
+					Address:          proto.String("Jakarta"),
+				},
+			})),
+		},
+		{
+			name: "message_contact",
+			event: mustMessageEvent(messageEvent(&waE2E.Message{
+				ContactMessage: &waE2E.ContactMessage{
+					DisplayName: proto.String("Jane Doe"),
+					Vcard:       proto.String("BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nTEL:+628111111111\nEND:VCARD"),
+				},
+			})),
+		},
+		{
+			name: "receipt_read",
+			event: createReceiptEvent(&events.Receipt{
+				MessageSource: types.MessageSource{Chat: chat, Sender: sender},
+				MessageIDs:    []types.MessageID{"3EB0C127D7BACC83D6A1"},
+				Timestamp:     timestamp,
+				Type:          types.ReceiptTypeRead,
+			}),
+		},
+		{
+			name: "receipt_delivered",
+			event: createReceiptEvent(&events.Receipt{
+				MessageSource: types.MessageSource{Chat: group, Sender: sender, IsGroup: true},
+				MessageIDs:    []types.MessageID{"3EB0C127D7BACC83D6A1", "3EB0C127D7BACC83D6A2"},
+				Timestamp:     timestamp,
+				Type:          types.ReceiptTypeDelivered,
+			}),
+		},
+		{
+			name: "group_join",
+			event: createGroupInfoEvent(&events.GroupInfo{JID: group, Timestamp: timestamp},
+				"join", []types.JID{sender, chat}),
+		},
+		{
+			name: "delete_for_me",
+			event: createDeleteEvent(&events.DeleteForMe{
+				ChatJID:   chat,
+				SenderJID: sender,
+				MessageID: "3EB0C127D7BACC83D6A1",
+			}, nil, timestamp),
+		},
+		{
+			name: "delete_for_me_stored",
+			event: createDeleteEvent(&events.DeleteForMe{
+				ChatJID:   chat,
+				SenderJID: sender,
+				MessageID: "3EB0C127D7BACC83D6A1",
+			}, &domainChatStorage.Message{
+				ChatJID:   chat.String(),
+				Sender:    sender.String(),
+				Content:   "Hello, how are you?",
+				Timestamp: timestamp.Add(-time.Hour),
+				MediaType: "image",
+				Filename:  "photo.jpg",
+			}, timestamp),
+		},
+		{
+			name: "presence",
+			event: createPresenceEvent(&events.Presence{
+				From:        sender,
+				Unavailable: true,
+				LastSeen:    timestamp.Add(-5 * time.Minute),
+			}, timestamp),
+		},
+		{
+			name: "poll_vote",
+			event: createPollVoteEvent(&domainChatStorage.Poll{
+				MessageID: "3EB0C127D7BACC83D6A0",
+				ChatJID:   group.String(),
+				Sender:    chat.String(),
+				Question:  "Lunch?",
+				Options: []domainChatStorage.PollOption{
+					{Name: "Noodles", Hash: "9d9157d11ad95e561b1a353e5e4dc9f679fabaf4ff347cd9172295b88d4403be"},
+					{Name: "Rice", Hash: "1b9bc7b7376e6c5c0f1042dfdd42ac68017d8cee72988967d7c670d40c466c85"},
+				},
+			}, &domainChatStorage.PollVote{
+				PollID:    "3EB0C127D7BACC83D6A0",
+				ChatJID:   group.String(),
+				Voter:     sender.String(),
+				Options:   []string{"1b9bc7b7376e6c5c0f1042dfdd42ac68017d8cee72988967d7c670d40c466c85"},
+				Timestamp: timestamp,
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		for _, version := range domainWebhook.PayloadVersions {
+			tt.event.DeviceID = "628555555555:12@s.whatsapp.net"
+
+			name := fmt.Sprintf("%s.v%d", tt.name, version)
+			t.Run(name, func(t *testing.T) {
+				got, err := json.MarshalIndent(tt.event.Payload(version), "", "  ")
+				require.NoError(t, err)
+				got = append(got, '\n')
+
+				golden := filepath.Join("testdata", "webhook", name+".json")
+				if *update {
+					require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0755))
+					require.NoError(t, os.WriteFile(golden, got, 0644))
+				}
+
+				want, err := os.ReadFile(golden)
+				require.NoError(t, err, "run with -update to create the golden file")
+				assert.JSONEq(t, string(want), string(got))
+			})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var (
+	responseSender   domainSend.ISendUsecase
+	responseMessages domainMessage.IMessageUsecase
+)
+
+// InitWebhookResponses lets webhook receivers answer a message event with actions in their response, so a bot
+// needs no second request to reply. Every action runs through the send and message services, like the same
+// action sent through the API.
+func InitWebhookResponses(sendService domainSend.ISendUsecase, messageService domainMessage.IMessageUsecase) {
+	responseSender = sendService
+	responseMessages = messageService
+	webhook.SetResponseHandler(runWebhookResponse)
+}
+
+// runWebhookResponse runs the actions of a response in order against the chat of the message the delivery was about,
+// from the action at index first
+func runWebhookResponse(ctx context.Context, delivery *domainWebhook.Delivery, actions []domainWebhook.ResponseAction, first int) {
+	origin := delivery.Origin
+	if !DeviceExists(origin.Device) {
+		logrus.Warnf("Ignoring the response to webhook %d: device %s was removed", delivery.ID, origin.Device)
+		return
+	}
+	chat, err := types.ParseJID(origin.ChatJID)
+	if err != nil {
+		logrus.Warnf("Ignoring the response to webhook %d: invalid chat %s: %v", delivery.ID, origin.ChatJID, err)
+		return
+	}
+
+	// Sends are attributed to the endpoint, and keyed so a replayed delivery does not send the replies twice
+	ctx = ContextWithDevice(ctx, origin.Device)
+	ctx = domainAuth.ContextWithPrincipal(ctx, domainAuth.Principal{Username: "webhook:" + delivery.EndpointID})
+	for i := first; i < len(actions); i++ {
+		action := actions[i]
+		actionCtx := domainSend.ContextWithIdempotencyKey(ctx, fmt.Sprintf("webhook:%d:%d", delivery.ID, i))
+		if err := runWebhookAction(actionCtx, action, chat, origin.MessageID); err != nil {
+			logrus.Errorf("Webhook %d response failed to %s message %s: %v", delivery.ID, action.Type, origin.MessageID, err)
+		}
+	}
+}
+
+// runWebhookAction runs a single action of a webhook response
+func runWebhookAction(ctx context.Context, action domainWebhook.ResponseAction, chat types.JID, messageID string) (err error) {
+	defer RecoverSend(&err)
+
+	var quoted *string
+	if action.Quote {
+		quoted = &messageID
+	}
+
+	switch action.Type {
+	case domainWebhook.ResponseActionReply:
+		_, err = responseSender.SendText(ctx, domainSend.MessageRequest{
+			BaseRequest:    domainSend.BaseRequest{Phone: chat.String()},
+			Message:        action.Text,
+			ReplyMessageID: quoted,
+		})
+	case domainWebhook.ResponseActionReplyMedia:
+		err = replyMedia(ctx, action, chat, quoted)
+	case domainWebhook.ResponseActionReact:
+		_, err = responseMessages.ReactMessage(ctx, domainMessage.ReactionRequest{MessageID: messageID, Phone: chat.String(), Emoji: action.Emoji})
+	case domainWebhook.ResponseActionMarkRead:
+		_, err = responseMessages.MarkAsRead(ctx, domainMessage.MarkAsReadRequest{MessageID: messageID, Phone: chat.String()})
+	case domainWebhook.ResponseActionTyping:
+		err = typing(ctx, action, chat)
+	default:
+		err = fmt.Errorf("unknown action %q", action.Type)
+	}
+	return err
+}
+
+// replyMedia sends the media of a URL to the chat, quoting the message when quoted is set
+func replyMedia(ctx context.Context, action domainWebhook.ResponseAction, chat types.JID, quoted *string) (err error) {
+	base := domainSend.BaseRequest{Phone: chat.String()}
+	switch action.MediaType {
+	case domainWebhook.MediaTypeImage:
+		_, err = responseSender.SendImage(ctx, domainSend.ImageRequest{BaseRequest: base, ImageURL: &action.MediaURL, Caption: action.Caption, ReplyMessageID: quoted})
+	case domainWebhook.MediaTypeVideo:
+		_, err = responseSender.SendVideo(ctx, domainSend.VideoRequest{BaseRequest: base, VideoURL: &action.MediaURL, Caption: action.Caption, ReplyMessageID: quoted})
+	case domainWebhook.MediaTypeAudio:
+		_, err = responseSender.SendAudio(ctx, domainSend.AudioRequest{BaseRequest: base, AudioURL: &action.MediaURL, ReplyMessageID: quoted})
+	default:
+		err = fmt.Errorf("unsupported media type %q", action.MediaType)
+	}
+	return err
+}
+
+// typing shows the typing or recording indicator in the chat, or stops it, and waits the seconds of the action
+// before the next action runs
+func typing(ctx context.Context, action domainWebhook.ResponseAction, chat types.JID) error {
+	presence := "start"
+	switch action.State {
+	case domainWebhook.TypingRecording:
+		presence = "record"
+	case domainWebhook.TypingPaused:
+		presence = "stop"
+	}
+	if _, err := responseSender.SendChatPresence(ctx, domainSend.ChatPresenceRequest{Phone: chat.String(), Action: presence}); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(action.Seconds) * time.Second):
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// recordingUsecases records the requests of the actions, the methods they do not override panic
+type recordingUsecases struct {
+	domainSend.ISendUsecase
+	domainMessage.IMessageUsecase
+	requests []any
+}
+
+func (r *recordingUsecases) SendText(_ context.Context, request domainSend.MessageRequest) (domainSend.GenericResponse, error) {
+	r.requests = append(r.requests, request)
+	return domainSend.GenericResponse{}, nil
+}
+
+func (r *recordingUsecases) SendImage(_ context.Context, request domainSend.ImageRequest) (domainSend.GenericResponse, error) {
+	r.requests = append(r.requests, request)
+	return domainSend.GenericResponse{}, nil
+}
+
+func (r *recordingUsecases) SendChatPresence(_ context.Context, request domainSend.ChatPresenceRequest) (domainSend.GenericResponse, error) {
+	r.requests = append(r.requests, request)
+	return domainSend.GenericResponse{}, nil
+}
+
+func (r *recordingUsecases) ReactMessage(_ context.Context, request domainMessage.ReactionRequest) (domainMessage.GenericResponse, error) {
+	r.requests = append(r.requests, request)
+	return domainMessage.GenericResponse{}, nil
+}
+
+func (r *recordingUsecases) MarkAsRead(_ context.Context, request domainMessage.MarkAsReadRequest) (domainMessage.GenericResponse, error) {
+	r.requests = append(r.requests, request)
+	return domainMessage.GenericResponse{}, nil
+}
+
+func TestRunWebhookActionUsesUsecases(t *testing.T) {
+	usecases := &recordingUsecases{}
+	responseSender, responseMessages = usecases, usecases
+	t.Cleanup(func() { responseSender, responseMessages = nil, nil })
+
+	chat := types.NewJID("6289876543210", types.DefaultUserServer)
+	messageID := "3EB0B430B6F8F1D0E053AC120E0A9E5C"
+	base := domainSend.BaseRequest{Phone: chat.String()}
+	imageURL := "https://example.com/receipt.png"
+
+	actions := []domainWebhook.ResponseAction{
+		{Type: domainWebhook.ResponseActionMarkRead},
+		{Type: domainWebhook.ResponseActionTyping, State: domainWebhook.TypingRecording},
+		{Type: domainWebhook.ResponseActionReply, Text: "Hi"},
+		{Type: domainWebhook.ResponseActionReply, Text: "On its way", Quote: true},
+		{Type: domainWebhook.ResponseActionReplyMedia, MediaType: domainWebhook.MediaTypeImage, MediaURL: imageURL, Caption: "Receipt", Quote: true},
+		{Type: domainWebhook.ResponseActionReact, Emoji: "👍"},
+	}
+	for _, action := range actions {
+		require.NoError(t, runWebhookAction(context.Background(), action, chat, messageID))
+	}
+
+	assert.Equal(t, []any{
+		domainMessage.MarkAsReadRequest{MessageID: messageID, Phone: chat.String()},
+		domainSend.ChatPresenceRequest{Phone: chat.String(), Action: "record"},
+		domainSend.MessageRequest{BaseRequest: base, Message: "Hi"},
+		domainSend.MessageRequest{BaseRequest: base, Message: "On its way", ReplyMessageID: &messageID},
+		domainSend.ImageRequest{BaseRequest: base, ImageURL: &imageURL, Caption: "Receipt", ReplyMessageID: &messageID},
+		domainMessage.ReactionRequest{MessageID: messageID, Phone: chat.String(), Emoji: "👍"},
+	}, usecases.requests)
+}
@@ -1,6 +1,11 @@
 package error
 
-import "net/http"
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
 
 // GenericError represent as the contract of generic error
 type GenericError interface {
@@ -42,3 +47,84 @@ func (e ContextError) ErrCode() string {
 func (e ContextError) StatusCode() int {
 	return http.StatusRequestTimeout
 }
+
+type NotFoundError string
+
+// Error for complying the error interface
+func (e NotFoundError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e NotFoundError) ErrCode() string {
+	return "NOT_FOUND"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e NotFoundError) StatusCode() int {
+	return http.StatusNotFound
+}
+
+type ForbiddenError string
+
+// Error for complying the error interface
+func (e ForbiddenError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e ForbiddenError) ErrCode() string {
+	return "FORBIDDEN"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+type ConflictError string
+
+// Error for complying the error interface
+func (e ConflictError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e ConflictError) ErrCode() string {
+	return "CONFLICT"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e ConflictError) StatusCode() int {
+	return http.StatusConflict
+}
+
+// RateLimitError is returned when a rate limit or send quota is exceeded
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration // how long the caller has to wait before the call is accepted again
+}
+
+// Error for complying the error interface
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %ds", e.Message, e.RetryAfterSeconds())
+}
+
+// ErrCode will return the error code based on the error data type
+func (e RateLimitError) ErrCode() string {
+	return "TOO_MANY_REQUESTS"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e RateLimitError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// RetryAfterSeconds returns the value of the Retry-After header, rounded up to whole seconds
+func (e RateLimitError) RetryAfterSeconds() int {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
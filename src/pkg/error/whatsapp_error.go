@@ -70,8 +70,26 @@ func (e WaUploadMediaError) StatusCode() int {
 	return http.StatusInternalServerError
 }
 
+type DeviceNotFoundError string
+
+// Error for complying the error interface
+func (e DeviceNotFoundError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e DeviceNotFoundError) ErrCode() string {
+	return "DEVICE_NOT_FOUND"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e DeviceNotFoundError) StatusCode() int {
+	return http.StatusNotFound
+}
+
 const (
 	ErrInvalidJID        = InvalidJID("your JID is invalid")
 	ErrUserNotRegistered = InvalidJID("user is not registered")
 	ErrWaCLI             = WaCliError("your WhatsApp CLI is invalid or empty")
+	ErrDeviceNotFound    = DeviceNotFoundError("device not found")
 )
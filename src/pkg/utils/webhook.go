@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every webhook request
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// DefaultWebhookTolerance is the maximum age of a webhook request accepted by VerifyWebhookRequest
+	DefaultWebhookTolerance = 5 * time.Minute
+
+	webhookSignatureVersion = "v1"
+)
+
+var (
+	ErrWebhookMissingHeaders   = errors.New("webhook request is missing the id, timestamp or signature header")
+	ErrWebhookInvalidTime      = errors.New("webhook timestamp is invalid")
+	ErrWebhookExpired          = errors.New("webhook timestamp is outside the tolerance window")
+	ErrWebhookInvalidSignature = errors.New("webhook signature does not match")
+)
+
+// SignWebhook returns the signature of a webhook request, e.g. "v1=5257a869...".
+// The HMAC SHA256 covers "<delivery id>.<unix timestamp>.<body>" so a captured request cannot be replayed later
+// or with another id.
+func SignWebhook(secret string, deliveryID string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(deliveryID))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature header of a webhook request against the secret.
+// The header may hold several space separated signatures while a secret is being rotated; one match is enough.
+func VerifyWebhookSignature(secret string, deliveryID string, timestamp string, signatureHeader string, body []byte, tolerance time.Duration) error {
+	if deliveryID == "" || timestamp == "" || signatureHeader == "" {
+		return ErrWebhookMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookInvalidTime
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrWebhookExpired
+		}
+	}
+
+	expected := []byte(SignWebhook(secret, deliveryID, unix, body))
+	for _, signature := range strings.Fields(signatureHeader) {
+		if hmac.Equal([]byte(signature), expected) {
+			return nil
+		}
+	}
+	return ErrWebhookInvalidSignature
+}
+
+// VerifyWebhookRequest verifies an incoming webhook request and returns its body.
+// The request body is restored so it can still be decoded by the caller.
+//
+//	body, err := utils.VerifyWebhookRequest(r, secret, utils.DefaultWebhookTolerance)
+func VerifyWebhookRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = VerifyWebhookSignature(
+		secret,
+		r.Header.Get(WebhookIDHeader),
+		r.Header.Get(WebhookTimestampHeader),
+		r.Header.Get(WebhookSignatureHeader),
+		body,
+		tolerance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,134 @@
+package utils_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+}
+
+func (suite *WebhookTestSuite) TestSignWebhook() {
+	signature := utils.SignWebhook("super-secret-key", "42", 1700000000, []byte(`{"event":"message"}`))
+
+	assert.True(suite.T(), strings.HasPrefix(signature, "v1="))
+	assert.Len(suite.T(), signature, len("v1=")+64)
+	// Any change of id, timestamp or body changes the signature
+	assert.NotEqual(suite.T(), signature, utils.SignWebhook("super-secret-key", "43", 1700000000, []byte(`{"event":"message"}`)))
+	assert.NotEqual(suite.T(), signature, utils.SignWebhook("super-secret-key", "42", 1700000001, []byte(`{"event":"message"}`)))
+	assert.NotEqual(suite.T(), signature, utils.SignWebhook("super-secret-key", "42", 1700000000, []byte(`{"event":"receipt"}`)))
+}
+
+func (suite *WebhookTestSuite) TestVerifyWebhookSignature() {
+	body := []byte(`{"event":"message"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	valid := utils.SignWebhook("new-secret", "42", now, body)
+	old := utils.SignWebhook("old-secret", "42", now, body)
+	stale := time.Now().Add(-10 * time.Minute).Unix()
+
+	tests := []struct {
+		name      string
+		secret    string
+		id        string
+		timestamp string
+		signature string
+		want      error
+	}{
+		{
+			name:      "should success with valid signature",
+			secret:    "new-secret",
+			id:        "42",
+			timestamp: timestamp,
+			signature: valid,
+			want:      nil,
+		},
+		{
+			name:      "should success with old secret during rotation",
+			secret:    "old-secret",
+			id:        "42",
+			timestamp: timestamp,
+			signature: valid + " " + old,
+			want:      nil,
+		},
+		{
+			name:      "should error with wrong secret",
+			secret:    "other-secret",
+			id:        "42",
+			timestamp: timestamp,
+			signature: valid,
+			want:      utils.ErrWebhookInvalidSignature,
+		},
+		{
+			name:      "should error when id is replaced",
+			secret:    "new-secret",
+			id:        "43",
+			timestamp: timestamp,
+			signature: valid,
+			want:      utils.ErrWebhookInvalidSignature,
+		},
+		{
+			name:      "should error with stale timestamp",
+			secret:    "new-secret",
+			id:        "42",
+			timestamp: strconv.FormatInt(stale, 10),
+			signature: utils.SignWebhook("new-secret", "42", stale, body),
+			want:      utils.ErrWebhookExpired,
+		},
+		{
+			name:      "should error with invalid timestamp",
+			secret:    "new-secret",
+			id:        "42",
+			timestamp: "yesterday",
+			signature: valid,
+			want:      utils.ErrWebhookInvalidTime,
+		},
+		{
+			name:      "should error without signature",
+			secret:    "new-secret",
+			id:        "42",
+			timestamp: timestamp,
+			signature: "",
+			want:      utils.ErrWebhookMissingHeaders,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(t *testing.T) {
+			err := utils.VerifyWebhookSignature(tt.secret, tt.id, tt.timestamp, tt.signature, body, utils.DefaultWebhookTolerance)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func (suite *WebhookTestSuite) TestVerifyWebhookRequest() {
+	body := `{"event":"message"}`
+	now := time.Now().Unix()
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+	req.Header.Set(utils.WebhookIDHeader, "42")
+	req.Header.Set(utils.WebhookTimestampHeader, strconv.FormatInt(now, 10))
+	req.Header.Set(utils.WebhookSignatureHeader, utils.SignWebhook("secret-key", "42", now, []byte(body)))
+
+	got, err := utils.VerifyWebhookRequest(req, "secret-key", utils.DefaultWebhookTolerance)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), body, string(got))
+
+	// The body can still be read by the caller
+	restored, err := io.ReadAll(req.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), body, string(restored))
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
@@ -27,12 +27,14 @@ func (h *AppHandler) AddAppTools(mcpServer *server.MCPServer) {
 	mcpServer.AddTool(h.toolLoginWithCode(), h.handleLoginWithCode)
 	mcpServer.AddTool(h.toolLogout(), h.handleLogout)
 	mcpServer.AddTool(h.toolReconnect(), h.handleReconnect)
+	mcpServer.AddTool(h.toolListDevices(), h.handleListDevices)
 }
 
 func (h *AppHandler) toolConnectionStatus() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_connection_status",
 		mcp.WithDescription("Check whether the WhatsApp client is connected and logged in."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Connection Status"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -40,8 +42,8 @@ func (h *AppHandler) toolConnectionStatus() mcp.Tool {
 	)
 }
 
-func (h *AppHandler) handleConnectionStatus(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	isConnected, isLoggedIn, deviceID := whatsapp.GetConnectionStatus()
+func (h *AppHandler) handleConnectionStatus(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	isConnected, isLoggedIn, deviceID := whatsapp.GetConnectionStatus(ctx)
 
 	structured := map[string]any{
 		"is_connected": isConnected,
@@ -59,6 +61,7 @@ func (h *AppHandler) toolLoginWithQR() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_login_qr",
 		mcp.WithDescription("Initiate a QR code based login flow. Returns the QR image and pairing code."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Login With QR"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -94,6 +97,7 @@ func (h *AppHandler) toolLoginWithCode() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_login_with_code",
 		mcp.WithDescription("Generate a pairing code for WhatsApp multi-device login using a phone number."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Login With Pairing Code"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -130,6 +134,7 @@ func (h *AppHandler) toolLogout() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_logout",
 		mcp.WithDescription("Sign out the current WhatsApp session and clear stored credentials."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Logout"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -149,6 +154,7 @@ func (h *AppHandler) toolReconnect() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_reconnect",
 		mcp.WithDescription("Attempt to reconnect to WhatsApp using the stored session."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Reconnect"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -163,3 +169,24 @@ func (h *AppHandler) handleReconnect(ctx context.Context, _ mcp.CallToolRequest)
 
 	return mcp.NewToolResultText("Reconnect initiated"), nil
 }
+
+func (h *AppHandler) toolListDevices() mcp.Tool {
+	return mcp.NewTool(
+		"whatsapp_list_devices",
+		mcp.WithDescription("List the WhatsApp devices served by this server. Pass a device id as device_id to any tool (or the X-Device-Id header) to target it."),
+		mcp.WithTitleAnnotation("List Devices"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+func (h *AppHandler) handleListDevices(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	devices, err := h.appService.ListDeviceSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := fmt.Sprintf("%d device(s) available", len(devices))
+	return mcp.NewToolResultStructured(map[string]any{"devices": devices}, fallback), nil
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AuditToolMiddleware records the calls of every tool that is not annotated as read-only in the audit log.
+// It must be the outermost tool middleware, so calls rejected by the other middlewares are recorded as well.
+func AuditToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if isReadOnlyTool(ctx, request.Params.Name) {
+			return next(ctx, request)
+		}
+
+		result, err := next(ctx, request)
+
+		principal, _ := domainAuth.PrincipalFromContext(ctx)
+		arguments := request.GetArguments()
+		entry := domainAudit.Entry{
+			Actor:    principal.Username,
+			APIKeyID: principal.APIKeyID,
+			Source:   domainAudit.SourceMCP,
+			Action:   request.Params.Name,
+			DeviceID: request.GetString("device_id", whatsapp.DeviceIDFromContext(ctx)),
+			Target:   audit.Target(arguments),
+			Summary:  audit.Summarize(arguments),
+			Result:   domainAudit.ResultSuccess,
+		}
+		if messageID, ok := arguments["message_id"].(string); ok {
+			entry.MessageID = messageID
+		}
+
+		switch {
+		case err != nil:
+			entry.Result = domainAudit.ResultError
+			entry.Error = err.Error()
+		case result != nil && result.IsError:
+			entry.Result = domainAudit.ResultError
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					entry.Error = text.Text
+					break
+				}
+			}
+		case result != nil && result.StructuredContent != nil:
+			var structured struct {
+				MessageID string `json:"message_id"`
+			}
+			if body, marshalErr := json.Marshal(result.StructuredContent); marshalErr == nil {
+				_ = json.Unmarshal(body, &structured)
+			}
+			if structured.MessageID != "" {
+				entry.MessageID = structured.MessageID
+			}
+		}
+
+		audit.Record(ctx, entry)
+		return result, err
+	}
+}
+
+func isReadOnlyTool(ctx context.Context, name string) bool {
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return false
+	}
+	tool := mcpServer.GetTool(name)
+	return tool != nil && tool.Tool.Annotations.ReadOnlyHint != nil && *tool.Tool.Annotations.ReadOnlyHint
+}
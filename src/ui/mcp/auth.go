@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolPermissions maps the tools to the permission of the matching REST route group.
+// Tools that are not listed here require PermissionAll.
+var toolPermissions = map[string]string{
+	"whatsapp_connection_status": domainAuth.PermissionApp,
+	"whatsapp_login_qr":          domainAuth.PermissionApp,
+	"whatsapp_login_with_code":   domainAuth.PermissionApp,
+	"whatsapp_logout":            domainAuth.PermissionApp,
+	"whatsapp_reconnect":         domainAuth.PermissionApp,
+	"whatsapp_list_devices":      domainAuth.PermissionApp,
+
+	"whatsapp_list_contacts":          domainAuth.PermissionUserRead,
+	"whatsapp_list_chats":             domainAuth.PermissionChatRead,
+	"whatsapp_get_chat_messages":      domainAuth.PermissionChatRead,
+	"whatsapp_download_message_media": domainAuth.PermissionMessage,
+
+	"whatsapp_send_text":     domainAuth.PermissionSendText,
+	"whatsapp_send_contact":  domainAuth.PermissionSendContact,
+	"whatsapp_send_link":     domainAuth.PermissionSendLink,
+	"whatsapp_send_location": domainAuth.PermissionSendLocation,
+	"whatsapp_send_image":    domainAuth.PermissionSendImage,
+	"whatsapp_send_sticker":  domainAuth.PermissionSendSticker,
+
+	"whatsapp_group_create":               domainAuth.PermissionGroupManage,
+	"whatsapp_group_join_via_link":        domainAuth.PermissionGroupManage,
+	"whatsapp_group_leave":                domainAuth.PermissionGroupManage,
+	"whatsapp_group_participants":         domainAuth.PermissionGroupRead,
+	"whatsapp_group_manage_participants":  domainAuth.PermissionGroupManage,
+	"whatsapp_group_invite_link":          domainAuth.PermissionGroupRead,
+	"whatsapp_group_info":                 domainAuth.PermissionGroupRead,
+	"whatsapp_group_set_name":             domainAuth.PermissionGroupManage,
+	"whatsapp_group_set_topic":            domainAuth.PermissionGroupManage,
+	"whatsapp_group_set_locked":           domainAuth.PermissionGroupManage,
+	"whatsapp_group_set_announce":         domainAuth.PermissionGroupManage,
+	"whatsapp_group_join_requests":        domainAuth.PermissionGroupRead,
+	"whatsapp_group_manage_join_requests": domainAuth.PermissionGroupManage,
+}
+
+// ToolPermission returns the permission required to call a tool
+func ToolPermission(name string) string {
+	if permission, ok := toolPermissions[name]; ok {
+		return permission
+	}
+	return domainAuth.PermissionAll
+}
+
+// AuthContextFunc binds the caller to the context of each message request. Clients sending an API key as
+// bearer token act with the role of its owner limited to the key scopes, clients sending Basic Auth credentials
+// with the role of that user and clients without credentials with the configured MCP role, which is empty and
+// permits nothing unless the operator opts in with --role.
+func AuthContextFunc(users domainAuth.IUserRepository) server.SSEContextFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			// An invalid key leaves the caller without a role, so every tool is denied
+			principal, _ := domainAuth.AuthenticateAPIKey(ctx, users, strings.TrimSpace(key), time.Now())
+			return domainAuth.ContextWithPrincipal(ctx, principal)
+		}
+
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return domainAuth.ContextWithPrincipal(ctx, domainAuth.Principal{Role: config.McpRole})
+		}
+
+		// Invalid credentials leave the caller without a role, so every tool is denied
+		principal, _ := domainAuth.Authenticate(ctx, users, username, password)
+		return domainAuth.ContextWithPrincipal(ctx, principal)
+	}
+}
+
+// AuthToolMiddleware rejects tool calls whose role does not grant the permission of the tool
+func AuthToolMiddleware(policy *domainAuth.Policy) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			principal, _ := domainAuth.PrincipalFromContext(ctx)
+			if !policy.Permits(principal, ToolPermission(request.Params.Name)) {
+				return nil, pkgError.ForbiddenError(fmt.Sprintf("role %q is not allowed to call %s", principal.Role, request.Params.Name))
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// AuthToolFilter hides the tools the role of the caller is not allowed to call
+func AuthToolFilter(policy *domainAuth.Policy) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		principal, _ := domainAuth.PrincipalFromContext(ctx)
+		allowed := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if policy.Permits(principal, ToolPermission(tool.Name)) {
+				allowed = append(allowed, tool)
+			}
+		}
+		return allowed
+	}
+}
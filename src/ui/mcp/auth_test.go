@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolPermissionsCoverEveryTool(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+	InitMcpSend(nil).AddSendTools(mcpServer)
+	InitMcpQuery(nil, nil, nil).AddQueryTools(mcpServer)
+	InitMcpApp(nil).AddAppTools(mcpServer)
+	InitMcpGroup(nil).AddGroupTools(mcpServer)
+
+	tools := mcpServer.ListTools()
+	assert.NotEmpty(t, tools)
+	for name := range tools {
+		assert.Contains(t, toolPermissions, name, "tool %s has no permission, it is only callable by admins", name)
+	}
+}
+
+func TestAuthContextFuncAnonymous(t *testing.T) {
+	policy, err := domainAuth.NewPolicy(nil)
+	require.NoError(t, err)
+	request := httptest.NewRequest(http.MethodGet, "/sse", nil)
+
+	// Without credentials every tool is denied unless the operator opts in with a role
+	ctx := AuthContextFunc(nil)(context.Background(), request)
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	assert.False(t, policy.Permits(principal, ToolPermission("whatsapp_send_text")))
+	assert.Empty(t, AuthToolFilter(policy)(ctx, []mcp.Tool{{Name: "whatsapp_connection_status"}}))
+
+	defer func(role string) { config.McpRole = role }(config.McpRole)
+	config.McpRole = domainAuth.RoleAdmin
+	ctx = AuthContextFunc(nil)(context.Background(), request)
+	principal, _ = domainAuth.PrincipalFromContext(ctx)
+	assert.True(t, policy.Permits(principal, ToolPermission("whatsapp_send_text")))
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DeviceHeader is the HTTP header used by MCP clients to select the WhatsApp device
+const DeviceHeader = "X-Device-Id"
+
+// DeviceContextFunc selects the device for tool calls from the X-Device-Id header (or device_id query)
+// sent along with each message request.
+func DeviceContextFunc(ctx context.Context, r *http.Request) context.Context {
+	deviceID := strings.TrimSpace(r.Header.Get(DeviceHeader))
+	if deviceID == "" {
+		deviceID = strings.TrimSpace(r.URL.Query().Get("device_id"))
+	}
+	if deviceID == "" {
+		return ctx
+	}
+	return whatsapp.ContextWithDevice(ctx, deviceID)
+}
+
+// withDeviceID declares the optional device_id argument of the tools acting on a device, see DeviceToolMiddleware
+func withDeviceID() mcp.ToolOption {
+	return mcp.WithString("device_id",
+		mcp.Description("Device to act on, as listed by whatsapp_list_devices (optional, defaults to the X-Device-Id header or the main device)"),
+	)
+}
+
+// DeviceToolMiddleware lets the device_id argument of a tool override the header, and rejects calls for devices
+// that are not served by this process.
+func DeviceToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if deviceID := strings.TrimSpace(request.GetString("device_id", "")); deviceID != "" {
+			ctx = whatsapp.ContextWithDevice(ctx, deviceID)
+		}
+		if deviceID := whatsapp.DeviceIDFromContext(ctx); deviceID != "" && !whatsapp.DeviceExists(deviceID) {
+			return nil, pkgError.ErrDeviceNotFound
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,22 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceToolsDeclareDeviceID(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+	InitMcpSend(nil).AddSendTools(mcpServer)
+	InitMcpQuery(nil, nil, nil).AddQueryTools(mcpServer)
+	InitMcpApp(nil).AddAppTools(mcpServer)
+	InitMcpGroup(nil).AddGroupTools(mcpServer)
+
+	for name, tool := range mcpServer.ListTools() {
+		_, declared := tool.Tool.InputSchema.Properties["device_id"]
+		// Listing the devices is the only tool that does not act on one
+		assert.Equal(t, name != "whatsapp_list_devices", declared, "device_id of tool %s", name)
+	}
+}
@@ -40,6 +40,7 @@ func (h *GroupHandler) toolCreateGroup() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_create",
 		mcp.WithDescription("Create a new WhatsApp group with an optional participant list."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Create Group"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -93,6 +94,7 @@ func (h *GroupHandler) toolJoinGroup() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_join_via_link",
 		mcp.WithDescription("Join a group using an invite link."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Join Group"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -128,6 +130,7 @@ func (h *GroupHandler) toolLeaveGroup() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_leave",
 		mcp.WithDescription("Leave a WhatsApp group by its ID."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Leave Group"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -159,6 +162,7 @@ func (h *GroupHandler) toolGetParticipants() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_participants",
 		mcp.WithDescription("Retrieve the participant list for a group."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("List Participants"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -192,6 +196,7 @@ func (h *GroupHandler) toolManageParticipants() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_manage_participants",
 		mcp.WithDescription("Add, remove, promote, or demote group participants."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Manage Participants"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -268,6 +273,7 @@ func (h *GroupHandler) toolGetInviteLink() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_invite_link",
 		mcp.WithDescription("Fetch the invite link for a group, optionally resetting it."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Get Invite Link"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -319,6 +325,7 @@ func (h *GroupHandler) toolGroupInfo() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_info",
 		mcp.WithDescription("Retrieve detailed WhatsApp group information."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Group Info"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -352,6 +359,7 @@ func (h *GroupHandler) toolSetGroupName() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_set_name",
 		mcp.WithDescription("Update the group's display name."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Set Group Name"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -392,6 +400,7 @@ func (h *GroupHandler) toolSetGroupTopic() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_set_topic",
 		mcp.WithDescription("Update the group's topic or description."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Set Group Topic"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -432,6 +441,7 @@ func (h *GroupHandler) toolSetGroupLocked() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_set_locked",
 		mcp.WithDescription("Toggle whether only admins can edit group info."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Set Group Locked"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -487,6 +497,7 @@ func (h *GroupHandler) toolSetGroupAnnounce() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_set_announce",
 		mcp.WithDescription("Toggle announcement-only mode."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Set Group Announce"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -542,6 +553,7 @@ func (h *GroupHandler) toolListGroupJoinRequests() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_join_requests",
 		mcp.WithDescription("List pending requests to join a group."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("List Join Requests"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -575,6 +587,7 @@ func (h *GroupHandler) toolManageGroupJoinRequests() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_manage_join_requests",
 		mcp.WithDescription("Approve or reject pending group join requests."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Manage Join Requests"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
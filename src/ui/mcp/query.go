@@ -39,6 +39,7 @@ func (h *QueryHandler) toolListContacts() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_list_contacts",
 		mcp.WithDescription("Retrieve all contacts available in the connected WhatsApp account."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("List Contacts"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -60,6 +61,7 @@ func (h *QueryHandler) toolListChats() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_list_chats",
 		mcp.WithDescription("Retrieve recent chats with optional pagination and search filters."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("List Chats"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -120,6 +122,7 @@ func (h *QueryHandler) toolGetChatMessages() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_get_chat_messages",
 		mcp.WithDescription("Fetch messages from a specific chat, with optional pagination, search, and time filters."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Get Chat Messages"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -225,6 +228,7 @@ func (h *QueryHandler) toolDownloadMedia() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_download_message_media",
 		mcp.WithDescription("Download media associated with a specific message and return the local file path."),
+		withDeviceID(),
 		mcp.WithTitleAnnotation("Download Message Media"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -0,0 +1,22 @@
+package mcp
+
+import (
+	"context"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RateLimitToolMiddleware rejects tool calls exceeding the rate limits or send quotas of the caller.
+// It must run after AuthToolMiddleware, so rejected calls do not take tokens.
+func RateLimitToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		principal, _ := domainAuth.PrincipalFromContext(ctx)
+		if err := ratelimit.Allow(ctx, principal, ToolPermission(request.Params.Name), request.GetString("phone", "")); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
@@ -32,6 +32,7 @@ func (s *SendHandler) AddSendTools(mcpServer *server.MCPServer) {
 func (s *SendHandler) toolSendText() mcp.Tool {
 	sendTextTool := mcp.NewTool("whatsapp_send_text",
 		mcp.WithDescription("Send a text message to a WhatsApp contact or group."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send message to"),
@@ -43,6 +44,9 @@ func (s *SendHandler) toolSendText() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 		mcp.WithString("reply_message_id",
 			mcp.Description("Message ID to reply to (optional)"),
 		),
@@ -72,10 +76,13 @@ func (s *SendHandler) handleSendText(ctx context.Context, request mcp.CallToolRe
 		replyMessageId = ""
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	res, err := s.sendService.SendText(ctx, domainSend.MessageRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		Message:        message,
 		ReplyMessageID: &replyMessageId,
@@ -85,12 +92,13 @@ func (s *SendHandler) handleSendText(ctx context.Context, request mcp.CallToolRe
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Message sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Message sent successfully with ID %s", res.MessageID)), nil
 }
 
 func (s *SendHandler) toolSendContact() mcp.Tool {
 	sendContactTool := mcp.NewTool("whatsapp_send_contact",
 		mcp.WithDescription("Send a contact card to a WhatsApp contact or group."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send contact to"),
@@ -106,6 +114,9 @@ func (s *SendHandler) toolSendContact() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 	)
 
 	return sendContactTool
@@ -132,10 +143,13 @@ func (s *SendHandler) handleSendContact(ctx context.Context, request mcp.CallToo
 		isForwarded = false
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	res, err := s.sendService.SendContact(ctx, domainSend.ContactRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		ContactName:  contactName,
 		ContactPhone: contactPhone,
@@ -145,12 +159,13 @@ func (s *SendHandler) handleSendContact(ctx context.Context, request mcp.CallToo
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Contact sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Contact sent successfully with ID %s", res.MessageID)), nil
 }
 
 func (s *SendHandler) toolSendLink() mcp.Tool {
 	sendLinkTool := mcp.NewTool("whatsapp_send_link",
 		mcp.WithDescription("Send a link with caption to a WhatsApp contact or group."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send link to"),
@@ -166,6 +181,9 @@ func (s *SendHandler) toolSendLink() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 	)
 
 	return sendLinkTool
@@ -192,10 +210,13 @@ func (s *SendHandler) handleSendLink(ctx context.Context, request mcp.CallToolRe
 		isForwarded = false
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	res, err := s.sendService.SendLink(ctx, domainSend.LinkRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		Link:    link,
 		Caption: caption,
@@ -205,12 +226,13 @@ func (s *SendHandler) handleSendLink(ctx context.Context, request mcp.CallToolRe
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Link sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Link sent successfully with ID %s", res.MessageID)), nil
 }
 
 func (s *SendHandler) toolSendLocation() mcp.Tool {
 	sendLocationTool := mcp.NewTool("whatsapp_send_location",
 		mcp.WithDescription("Send a location coordinates to a WhatsApp contact or group."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send location to"),
@@ -226,6 +248,9 @@ func (s *SendHandler) toolSendLocation() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 	)
 
 	return sendLocationTool
@@ -252,10 +277,13 @@ func (s *SendHandler) handleSendLocation(ctx context.Context, request mcp.CallTo
 		isForwarded = false
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	res, err := s.sendService.SendLocation(ctx, domainSend.LocationRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		Latitude:  latitude,
 		Longitude: longitude,
@@ -265,12 +293,13 @@ func (s *SendHandler) handleSendLocation(ctx context.Context, request mcp.CallTo
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Location sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Location sent successfully with ID %s", res.MessageID)), nil
 }
 
 func (s *SendHandler) toolSendImage() mcp.Tool {
 	sendImageTool := mcp.NewTool("whatsapp_send_image",
 		mcp.WithDescription("Send an image to a WhatsApp contact or group."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send image to"),
@@ -290,6 +319,9 @@ func (s *SendHandler) toolSendImage() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 	)
 
 	return sendImageTool
@@ -326,11 +358,14 @@ func (s *SendHandler) handleSendImage(ctx context.Context, request mcp.CallToolR
 		isForwarded = false
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	// Create image request
 	imageRequest := domainSend.ImageRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		Caption:  caption,
 		ViewOnce: viewOnce,
@@ -345,12 +380,13 @@ func (s *SendHandler) handleSendImage(ctx context.Context, request mcp.CallToolR
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Image sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Image sent successfully with ID %s", res.MessageID)), nil
 }
 
 func (s *SendHandler) toolSendSticker() mcp.Tool {
 	sendStickerTool := mcp.NewTool("whatsapp_send_sticker",
 		mcp.WithDescription("Send a sticker to a WhatsApp contact or group. Images are automatically converted to WebP sticker format."),
+		withDeviceID(),
 		mcp.WithString("phone",
 			mcp.Required(),
 			mcp.Description("Phone number or group ID to send sticker to"),
@@ -361,6 +397,9 @@ func (s *SendHandler) toolSendSticker() mcp.Tool {
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this is a forwarded sticker"),
 		),
+		mcp.WithString("send_at",
+			mcp.Description("RFC3339 time to send the message at, e.g. 2030-01-02T09:00:00+07:00 (optional, sends right away when empty)"),
+		),
 	)
 
 	return sendStickerTool
@@ -382,10 +421,13 @@ func (s *SendHandler) handleSendSticker(ctx context.Context, request mcp.CallToo
 		isForwarded = val
 	}
 
+	sendAt, _ := request.GetArguments()["send_at"].(string)
+
 	stickerRequest := domainSend.StickerRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
 			IsForwarded: isForwarded,
+			SendAt:      sendAt,
 		},
 		StickerURL: &stickerURL,
 	}
@@ -395,5 +437,5 @@ func (s *SendHandler) handleSendSticker(ctx context.Context, request mcp.CallToo
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Sticker sent successfully with ID %s", res.MessageID)), nil
+	return mcp.NewToolResultStructured(res, fmt.Sprintf("Sticker sent successfully with ID %s", res.MessageID)), nil
 }
@@ -0,0 +1,190 @@
+package rest
+
+import (
+	"fmt"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Admin struct {
+	Service domainAuth.IAuthUsecase
+}
+
+func InitRestAdmin(app fiber.Router, service domainAuth.IAuthUsecase) Admin {
+	rest := Admin{Service: service}
+
+	// User management
+	app.Get("/admin/users", rest.ListUsers)
+	app.Post("/admin/users", rest.CreateUser)
+	app.Get("/admin/users/:username", rest.GetUser)
+	app.Put("/admin/users/:username", rest.UpdateUser)
+	app.Delete("/admin/users/:username", rest.DeleteUser)
+	app.Post("/admin/users/:username/reset-password", rest.ResetPassword)
+
+	// API key management
+	app.Get("/admin/api-keys", rest.ListAPIKeys)
+	app.Post("/admin/api-keys", rest.CreateAPIKey)
+	app.Get("/admin/api-keys/:id", rest.GetAPIKey)
+	app.Delete("/admin/api-keys/:id", rest.RevokeAPIKey)
+
+	return rest
+}
+
+func (controller *Admin) ListUsers(c *fiber.Ctx) error {
+	response, err := controller.Service.ListUsers(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get users",
+		Results: response,
+	})
+}
+
+func (controller *Admin) GetUser(c *fiber.Ctx) error {
+	var request domainAuth.UserRequest
+	request.Username = c.Params("username")
+
+	response, err := controller.Service.GetUser(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get user",
+		Results: response,
+	})
+}
+
+func (controller *Admin) CreateUser(c *fiber.Ctx) error {
+	var request domainAuth.CreateUserRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CreateUser(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("User %s created", response.Username),
+		Results: response,
+	})
+}
+
+func (controller *Admin) UpdateUser(c *fiber.Ctx) error {
+	var request domainAuth.UpdateUserRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.Username = c.Params("username")
+
+	response, err := controller.Service.UpdateUser(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("User %s updated", response.Username),
+		Results: response,
+	})
+}
+
+func (controller *Admin) DeleteUser(c *fiber.Ctx) error {
+	var request domainAuth.UserRequest
+	request.Username = c.Params("username")
+
+	err := controller.Service.DeleteUser(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("User %s deleted", request.Username),
+		Results: nil,
+	})
+}
+
+func (controller *Admin) ResetPassword(c *fiber.Ctx) error {
+	var request domainAuth.ResetPasswordRequest
+	// The body is optional, without a password a random one is generated
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	request.Username = c.Params("username")
+
+	response, err := controller.Service.ResetPassword(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Password of user %s reset", response.Username),
+		Results: response,
+	})
+}
+
+func (controller *Admin) ListAPIKeys(c *fiber.Ctx) error {
+	var request domainAuth.ListAPIKeysRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListAPIKeys(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get API keys",
+		Results: response,
+	})
+}
+
+func (controller *Admin) GetAPIKey(c *fiber.Ctx) error {
+	var request domainAuth.APIKeyRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetAPIKey(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get API key",
+		Results: response,
+	})
+}
+
+func (controller *Admin) CreateAPIKey(c *fiber.Ctx) error {
+	var request domainAuth.CreateAPIKeyRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CreateAPIKey(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("API key %s created, the key is only shown once", response.Name),
+		Results: response,
+	})
+}
+
+func (controller *Admin) RevokeAPIKey(c *fiber.Ctx) error {
+	var request domainAuth.APIKeyRequest
+	request.ID = c.Params("id")
+
+	err := controller.Service.RevokeAPIKey(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("API key %s revoked", request.ID),
+		Results: nil,
+	})
+}
@@ -92,7 +92,7 @@ func (handler *App) Devices(c *fiber.Ctx) error {
 }
 
 func (handler *App) ConnectionStatus(c *fiber.Ctx) error {
-	isConnected, isLoggedIn, deviceID := whatsapp.GetConnectionStatus()
+	isConnected, isLoggedIn, deviceID := whatsapp.GetConnectionStatus(c.UserContext())
 
 	return c.JSON(utils.ResponseData{
 		Status:  200,
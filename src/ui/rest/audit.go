@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"fmt"
+	"time"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Audit struct {
+	Service domainAudit.IAuditUsecase
+}
+
+func InitRestAudit(app fiber.Router, service domainAudit.IAuditUsecase) Audit {
+	rest := Audit{Service: service}
+
+	app.Get("/admin/audit", rest.ListEntries)
+	app.Get("/admin/audit/export", rest.ExportEntries)
+
+	return rest
+}
+
+func (controller *Audit) ListEntries(c *fiber.Ctx) error {
+	var request domainAudit.ListEntriesRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListEntries(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get audit entries",
+		Results: response,
+	})
+}
+
+func (controller *Audit) ExportEntries(c *fiber.Ctx) error {
+	var request domainAudit.ListEntriesRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="audit-%s.csv"`, time.Now().UTC().Format("20060102T150405Z")))
+
+	err = controller.Service.ExportEntries(c.UserContext(), request, c.Response().BodyWriter())
+	utils.PanicIfNeeded(err)
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"fmt"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutoReply struct {
+	Service domainAutoReply.IAutoReplyUsecase
+}
+
+func InitRestAutoReply(app fiber.Router, service domainAutoReply.IAutoReplyUsecase) AutoReply {
+	rest := AutoReply{Service: service}
+
+	app.Get("/auto-reply/rules", rest.ListRules)
+	app.Post("/auto-reply/rules", rest.CreateRule)
+	app.Get("/auto-reply/rules/:id", rest.GetRule)
+	app.Put("/auto-reply/rules/:id", rest.UpdateRule)
+	app.Delete("/auto-reply/rules/:id", rest.DeleteRule)
+
+	return rest
+}
+
+func (controller *AutoReply) ListRules(c *fiber.Ctx) error {
+	response, err := controller.Service.ListRules(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get auto-reply rules",
+		Results: response,
+	})
+}
+
+func (controller *AutoReply) GetRule(c *fiber.Ctx) error {
+	var request domainAutoReply.RuleRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get auto-reply rule",
+		Results: response,
+	})
+}
+
+func (controller *AutoReply) CreateRule(c *fiber.Ctx) error {
+	var request domainAutoReply.CreateRuleRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CreateRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Auto-reply rule %s created", response.Name),
+		Results: response,
+	})
+}
+
+func (controller *AutoReply) UpdateRule(c *fiber.Ctx) error {
+	var request domainAutoReply.UpdateRuleRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.UpdateRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Auto-reply rule %s updated", response.Name),
+		Results: response,
+	})
+}
+
+func (controller *AutoReply) DeleteRule(c *fiber.Ctx) error {
+	var request domainAutoReply.RuleRequest
+	request.ID = c.Params("id")
+
+	err := controller.Service.DeleteRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Auto-reply rule %s deleted", request.ID),
+	})
+}
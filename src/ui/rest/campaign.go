@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"fmt"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Campaign struct {
+	Service domainCampaign.ICampaignUsecase
+}
+
+func InitRestCampaign(app fiber.Router, service domainCampaign.ICampaignUsecase) Campaign {
+	rest := Campaign{Service: service}
+
+	app.Post("/campaigns", rest.CreateCampaign)
+	app.Get("/campaigns", rest.ListCampaigns)
+	app.Get("/campaigns/:id", rest.GetCampaign)
+	app.Get("/campaigns/:id/recipients", rest.ListRecipients)
+	app.Get("/campaigns/:id/report", rest.GetReport)
+	app.Post("/campaigns/:id/start", rest.StartCampaign)
+	app.Post("/campaigns/:id/pause", rest.PauseCampaign)
+	app.Post("/campaigns/:id/resume", rest.ResumeCampaign)
+	app.Post("/campaigns/:id/cancel", rest.CancelCampaign)
+
+	return rest
+}
+
+func (controller *Campaign) CreateCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CreateCampaignRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	file, err := c.FormFile("recipients_csv")
+	if err == nil {
+		request.RecipientsCSV = file
+	}
+
+	response, err := controller.Service.CreateCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign %s created with %d recipients", response.ID, response.Recipients),
+		Results: response,
+	})
+}
+
+func (controller *Campaign) ListCampaigns(c *fiber.Ctx) error {
+	var request domainCampaign.ListCampaignsRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListCampaigns(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get campaigns",
+		Results: response,
+	})
+}
+
+func (controller *Campaign) GetCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get campaign",
+		Results: response,
+	})
+}
+
+func (controller *Campaign) ListRecipients(c *fiber.Ctx) error {
+	var request domainCampaign.ListRecipientsRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.ListRecipients(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get campaign recipients",
+		Results: response,
+	})
+}
+
+func (controller *Campaign) GetReport(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetReport(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get campaign report",
+		Results: response,
+	})
+}
+
+func (controller *Campaign) StartCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.StartCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign %s started", response.ID),
+		Results: response,
+	})
+}
+
+func (controller *Campaign) PauseCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.PauseCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign %s paused", response.ID),
+		Results: response,
+	})
+}
+
+func (controller *Campaign) ResumeCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.ResumeCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign %s resumed", response.ID),
+		Results: response,
+	})
+}
+
+func (controller *Campaign) CancelCampaign(c *fiber.Ctx) error {
+	var request domainCampaign.CampaignRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.CancelCampaign(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign %s cancelled", response.ID),
+		Results: response,
+	})
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"net/url"
+
+	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Device struct {
+	Service domainApp.IAppUsecase
+}
+
+func InitRestDevice(app fiber.Router, service domainApp.IAppUsecase) Device {
+	rest := Device{Service: service}
+	app.Get("/devices", rest.ListDevices)
+	app.Post("/devices", rest.AddDevice)
+	app.Delete("/devices/:device_id", rest.RemoveDevice)
+
+	return rest
+}
+
+func (handler *Device) ListDevices(c *fiber.Ctx) error {
+	devices, err := handler.Service.ListDeviceSessions(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Fetch device sessions success",
+		Results: devices,
+	})
+}
+
+func (handler *Device) AddDevice(c *fiber.Ctx) error {
+	device, err := handler.Service.AddDevice(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device session created, pair it through /devices/" + device.Token + "/app/login",
+		Results: device,
+	})
+}
+
+func (handler *Device) RemoveDevice(c *fiber.Ctx) error {
+	deviceID, err := url.PathUnescape(c.Params("device_id"))
+	utils.PanicIfNeeded(err)
+
+	err = handler.Service.RemoveDevice(c.UserContext(), deviceID)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device session removed",
+		Results: nil,
+	})
+}
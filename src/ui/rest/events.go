@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// eventsKeepAlive is how often an idle stream gets a comment, so proxies do not close it and gone clients are noticed
+const eventsKeepAlive = 15 * time.Second
+
+type Events struct {
+	Service domainEventStream.IEventStreamUsecase
+}
+
+func InitRestEvents(app fiber.Router, service domainEventStream.IEventStreamUsecase) Events {
+	rest := Events{Service: service}
+
+	app.Get("/events", rest.Stream)
+
+	return rest
+}
+
+// Stream sends the events as Server-Sent Events. The filters are query parameters, the ID of every event is its
+// resume token, so EventSource clients resume with the Last-Event-ID header on their own. The device selected by
+// the path or the X-Device-Id header takes precedence over the device_id parameter.
+func (controller *Events) Stream(c *fiber.Ctx) error {
+	deviceID := whatsapp.DeviceIDFromContext(c.UserContext())
+	if deviceID == "" {
+		deviceID = c.Query("device_id")
+	}
+
+	request := domainEventStream.SubscribeRequest{
+		Events:      queryValues(c, "events"),
+		Chats:       queryValues(c, "chats"),
+		DeviceID:    deviceID,
+		ResumeToken: c.Get("Last-Event-ID", c.Query("resume_token")),
+	}
+
+	subscription, err := controller.Service.Subscribe(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		ticker := time.NewTicker(eventsKeepAlive)
+		defer ticker.Stop()
+
+		// Opens the stream before the first event arrives
+		fmt.Fprint(w, ": connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					writeStreamError(w, subscription.Err())
+					return
+				}
+				if id := event.ResumeToken(); id != "" {
+					fmt.Fprintf(w, "id: %s\n", id)
+				}
+				fmt.Fprintf(w, "data: %s\n\n", event.Payload)
+			case <-ticker.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+
+			if err := w.Flush(); err != nil {
+				logrus.Debugf("Event stream client disconnected: %v", err)
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// writeStreamError tells the client why the stream ends, as an error event
+func writeStreamError(w *bufio.Writer, err error) {
+	if err == nil {
+		return
+	}
+	code := "EVENTS_ERROR"
+	if errors.Is(err, domainEventStream.ErrLagged) {
+		code = "EVENTS_LAGGED"
+	}
+	data, _ := json.Marshal(fiber.Map{"code": code, "message": err.Error()})
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	_ = w.Flush()
+}
+
+// queryValues returns the values of a query parameter given repeatedly or comma-separated
+func queryValues(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, value := range strings.Split(string(raw), ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyAuth authenticates requests carrying an API key as bearer token and binds the owner of the key,
+// limited to the key scopes, to the request context. Requests without a bearer token are left to SQLBasicAuth.
+func APIKeyAuth(users domainAuth.IUserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok {
+			return c.Next()
+		}
+
+		principal, ok := domainAuth.AuthenticateAPIKey(c.UserContext(), users, strings.TrimSpace(key), time.Now())
+		if !ok {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer error=\"invalid_token\"")
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		c.SetUserContext(domainAuth.ContextWithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
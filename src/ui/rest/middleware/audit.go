@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditedGetRoutes change state although they are GET requests
+var auditedGetRoutes = []string{"/app/login", "/app/login-with-code", "/app/logout", "/app/reconnect"}
+
+// Audit records every state-changing request together with its user in the audit log. It must run before
+// Recovery, so requests that panic are recorded with the error response they were answered with.
+func Audit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !isAudited(requestMethod(c), strings.TrimPrefix(c.Path(), config.AppBasePath)) {
+			return c.Next()
+		}
+
+		err := c.Next()
+
+		principal, ok := domainAuth.PrincipalFromContext(c.UserContext())
+		if !ok {
+			// Unauthenticated requests were rejected before reaching a handler
+			return err
+		}
+
+		fields := requestFields(c)
+		entry := domainAudit.Entry{
+			Actor:    principal.Username,
+			APIKeyID: principal.APIKeyID,
+			Source:   domainAudit.SourceREST,
+			Action:   c.Method() + " " + routePath(c),
+			DeviceID: whatsapp.DeviceIDFromContext(c.UserContext()),
+			Target:   audit.Target(fields),
+			Summary:  audit.Summarize(fields),
+			Result:   domainAudit.ResultSuccess,
+		}
+		if messageID, ok := fields["message_id"].(string); ok {
+			entry.MessageID = messageID
+		}
+
+		var response struct {
+			Message string `json:"message"`
+			Results struct {
+				MessageID       string `json:"message_id"`
+				TemplateVersion int    `json:"template_version"`
+			} `json:"results"`
+		}
+		_ = json.Unmarshal(c.Response().Body(), &response)
+		if response.Results.MessageID != "" {
+			entry.MessageID = response.Results.MessageID
+		}
+		if response.Results.TemplateVersion != 0 {
+			// Record the version a template send was rendered from, the template may change afterwards
+			fields["template_version"] = response.Results.TemplateVersion
+			entry.Summary = audit.Summarize(fields)
+		}
+
+		var fiberErr *fiber.Error
+		switch {
+		case errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusBadRequest:
+		case err != nil:
+			entry.Result = domainAudit.ResultError
+			entry.Error = err.Error()
+		case c.Response().StatusCode() >= fiber.StatusBadRequest:
+			entry.Result = domainAudit.ResultError
+			entry.Error = response.Message
+		}
+
+		audit.Record(c.UserContext(), entry)
+		return err
+	}
+}
+
+func isAudited(method, path string) bool {
+	switch method {
+	case fiber.MethodGet:
+		path = unscopedPath(path)
+		for _, route := range auditedGetRoutes {
+			if path == route {
+				return true
+			}
+		}
+		return false
+	case fiber.MethodHead, fiber.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// routePath returns the registered route of the request without base path and device prefix,
+// e.g. "/chat/:chat_jid/pin", so entries of the same route can be filtered together.
+// Requests rejected by a middleware never matched their route and fall back to the request path.
+func routePath(c *fiber.Ctx) string {
+	path := strings.TrimPrefix(c.Route().Path, config.AppBasePath)
+	if path == "" || path == "/" {
+		return unscopedPath(strings.TrimPrefix(c.Path(), config.AppBasePath))
+	}
+	if scoped, ok := strings.CutPrefix(path, "/devices/:device_id"); ok && scoped != "" {
+		return scoped
+	}
+	return path
+}
+
+// requestFields collects the path parameters and the body, or query, fields of the request
+func requestFields(c *fiber.Ctx) map[string]any {
+	fields := make(map[string]any)
+
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+		_ = json.Unmarshal(c.Body(), &fields)
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		if form, err := c.MultipartForm(); err == nil {
+			for name, values := range form.Value {
+				fields[name] = strings.Join(values, ",")
+			}
+			for name, files := range form.File {
+				if len(files) > 0 {
+					fields[name] = "file:" + files[0].Filename
+				}
+			}
+		}
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationForm):
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			fields[string(key)] = string(value)
+		})
+	}
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		if _, ok := fields[string(key)]; !ok {
+			fields[string(key)] = string(value)
+		}
+	})
+
+	for _, name := range c.Route().Params {
+		if name != "device_id" && c.Params(name) != "" {
+			fields[name] = c.Params(name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAudited(t *testing.T) {
+	app := fiber.New()
+	app.All("/*", func(c *fiber.Ctx) error {
+		return c.SendString(strconv.FormatBool(isAudited(requestMethod(c), c.Path())))
+	})
+
+	tests := []struct {
+		method string
+		target string
+		want   bool
+	}{
+		{fiber.MethodGet, "/group/invite-link?group_id=120363025246125888@g.us", false},
+		{fiber.MethodGet, "/group/invite-link?group_id=120363025246125888@g.us&reset=true", true},
+		{fiber.MethodGet, "/devices/628123456789/group/invite-link?reset=true", true},
+		{fiber.MethodGet, "/app/logout", true},
+		{fiber.MethodGet, "/chats", false},
+		{fiber.MethodPost, "/send/message", true},
+		{fiber.MethodOptions, "/send/message", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.target, nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.FormatBool(tt.want), string(body))
+		})
+	}
+}
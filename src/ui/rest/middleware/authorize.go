@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/gofiber/fiber/v2"
+)
+
+// routePermission is the permission required by the routes under a path prefix
+type routePermission struct {
+	prefix string
+	read   string // permission of GET requests
+	write  string // permission of every other method
+}
+
+// routePermissions maps the route groups to permissions, the first matching prefix wins.
+// Routes that are not listed here and not public require PermissionAll.
+var routePermissions = []routePermission{
+	{"/chats", domainAuth.PermissionChatRead, domainAuth.PermissionChatWrite},
+	{"/chat/", domainAuth.PermissionChatRead, domainAuth.PermissionChatWrite},
+	{"/user/", domainAuth.PermissionUserRead, domainAuth.PermissionUserWrite},
+	{"/send/jobs", domainAuth.PermissionJobs, domainAuth.PermissionJobs},
+	{"/campaigns", domainAuth.PermissionCampaign, domainAuth.PermissionCampaign},
+	{"/templates", domainAuth.PermissionTemplateRead, domainAuth.PermissionTemplateWrite},
+	{"/auto-reply/", domainAuth.PermissionAutoReply, domainAuth.PermissionAutoReply},
+	{"/send/message", domainAuth.PermissionSendText, domainAuth.PermissionSendText},
+	{"/send/image", domainAuth.PermissionSendImage, domainAuth.PermissionSendImage},
+	{"/send/file", domainAuth.PermissionSendFile, domainAuth.PermissionSendFile},
+	{"/send/video", domainAuth.PermissionSendVideo, domainAuth.PermissionSendVideo},
+	{"/send/sticker", domainAuth.PermissionSendSticker, domainAuth.PermissionSendSticker},
+	{"/send/contact", domainAuth.PermissionSendContact, domainAuth.PermissionSendContact},
+	{"/send/link", domainAuth.PermissionSendLink, domainAuth.PermissionSendLink},
+	{"/send/location", domainAuth.PermissionSendLocation, domainAuth.PermissionSendLocation},
+	{"/send/audio", domainAuth.PermissionSendAudio, domainAuth.PermissionSendAudio},
+	{"/send/poll", domainAuth.PermissionSendPoll, domainAuth.PermissionSendPoll},
+	{"/send/presence", domainAuth.PermissionSendPresence, domainAuth.PermissionSendPresence},
+	{"/send/chat-presence", domainAuth.PermissionSendChatPresence, domainAuth.PermissionSendChatPresence},
+	{"/send/", domainAuth.PermissionSend, domainAuth.PermissionSend},
+	{"/message/", domainAuth.PermissionMessage, domainAuth.PermissionMessage},
+	{"/group", domainAuth.PermissionGroupRead, domainAuth.PermissionGroupManage},
+	{"/newsletter/", domainAuth.PermissionNewsletter, domainAuth.PermissionNewsletter},
+	{"/app/", domainAuth.PermissionApp, domainAuth.PermissionApp},
+	{"/devices", domainAuth.PermissionApp, domainAuth.PermissionApp},
+	{"/ws", domainAuth.PermissionEvents, domainAuth.PermissionEvents},
+	{"/events", domainAuth.PermissionEvents, domainAuth.PermissionEvents},
+	{"/webhook/", domainAuth.PermissionWebhook, domainAuth.PermissionWebhook},
+	{"/admin/users", domainAuth.PermissionUsers, domainAuth.PermissionUsers},
+	{"/admin/api-keys", domainAuth.PermissionUsers, domainAuth.PermissionUsers},
+	{"/admin/audit", domainAuth.PermissionAudit, domainAuth.PermissionAudit},
+	{"/admin/usage", domainAuth.PermissionUsers, domainAuth.PermissionUsers},
+}
+
+// stateChangingQueries are the query parameters that make a GET route change state, e.g. /group/invite-link?reset=true
+// revokes the current link. Such requests need the write permission of their route and are audited.
+var stateChangingQueries = map[string]string{"/group/invite-link": "reset"}
+
+// publicRoutes only require an authenticated user
+var publicRoutes = []string{"/", "/docs", "/openapi.yaml"}
+
+// Authorize rejects requests whose user role, or API key scopes, do not grant the permission of the route.
+// It must run after APIKeyAuth and SQLBasicAuth.
+func Authorize(policy *domainAuth.Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, _ := domainAuth.PrincipalFromContext(c.UserContext())
+		permission := RoutePermission(requestMethod(c), strings.TrimPrefix(c.Path(), config.AppBasePath))
+		if !policy.Permits(principal, permission) {
+			panic(pkgError.ForbiddenError(fmt.Sprintf("role %q is not allowed to access %s %s", principal.Role, c.Method(), c.Path())))
+		}
+		return c.Next()
+	}
+}
+
+// requestMethod returns the method a request is authorized, limited and audited as: GET requests that change state
+// through a query parameter count as POST
+func requestMethod(c *fiber.Ctx) string {
+	if c.Method() == fiber.MethodGet && ChangesState(strings.TrimPrefix(c.Path(), config.AppBasePath), c.Query) {
+		return fiber.MethodPost
+	}
+	return c.Method()
+}
+
+// ChangesState reports whether a GET request to the path relative to the base path changes state through one of
+// the stateChangingQueries
+func ChangesState(path string, query func(key string, defaultValue ...string) string) bool {
+	key, ok := stateChangingQueries[unscopedPath(path)]
+	if !ok {
+		return false
+	}
+	changes, _ := strconv.ParseBool(query(key))
+	return changes
+}
+
+// RoutePermission returns the permission required for a request to the path relative to the base path.
+// Device scoped routes (/devices/:device_id/...) require the permission of the route they are scoped to.
+func RoutePermission(method, path string) string {
+	path = unscopedPath(path)
+
+	for _, public := range publicRoutes {
+		if path == public {
+			return ""
+		}
+	}
+	for _, route := range routePermissions {
+		if strings.HasPrefix(path, route.prefix) {
+			if method == fiber.MethodGet || method == fiber.MethodHead {
+				return route.read
+			}
+			return route.write
+		}
+	}
+	return domainAuth.PermissionAll
+}
+
+// unscopedPath strips the device scope from a path relative to the base path,
+// e.g. /devices/628123456789/send/message becomes /send/message
+func unscopedPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/devices/"); ok {
+		if _, scoped, found := strings.Cut(rest, "/"); found && scoped != "" {
+			path = "/" + scoped
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path
+}
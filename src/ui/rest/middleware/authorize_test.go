@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"testing"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutePermission(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{fiber.MethodGet, "/", ""},
+		{fiber.MethodGet, "/docs", ""},
+		{fiber.MethodGet, "/chats", domainAuth.PermissionChatRead},
+		{fiber.MethodGet, "/chat/628123456789@s.whatsapp.net/messages", domainAuth.PermissionChatRead},
+		{fiber.MethodPost, "/chat/628123456789@s.whatsapp.net/pin", domainAuth.PermissionChatWrite},
+		{fiber.MethodGet, "/user/info", domainAuth.PermissionUserRead},
+		{fiber.MethodPost, "/user/pushname", domainAuth.PermissionUserWrite},
+		{fiber.MethodPost, "/send/message", domainAuth.PermissionSendText},
+		{fiber.MethodPost, "/send/chat-presence", domainAuth.PermissionSendChatPresence},
+		{fiber.MethodGet, "/send/jobs/4f1c", domainAuth.PermissionJobs},
+		{fiber.MethodDelete, "/send/jobs/4f1c", domainAuth.PermissionJobs},
+		{fiber.MethodPatch, "/send/jobs/4f1c", domainAuth.PermissionJobs},
+		{fiber.MethodPost, "/campaigns", domainAuth.PermissionCampaign},
+		{fiber.MethodGet, "/campaigns/4f1c/report", domainAuth.PermissionCampaign},
+		{fiber.MethodGet, "/templates/4f1c/versions", domainAuth.PermissionTemplateRead},
+		{fiber.MethodPut, "/templates/4f1c", domainAuth.PermissionTemplateWrite},
+		{fiber.MethodGet, "/auto-reply/rules", domainAuth.PermissionAutoReply},
+		{fiber.MethodDelete, "/auto-reply/rules/4f1c", domainAuth.PermissionAutoReply},
+		{fiber.MethodPost, "/message/3EB0/revoke", domainAuth.PermissionMessage},
+		{fiber.MethodPost, "/group", domainAuth.PermissionGroupManage},
+		{fiber.MethodGet, "/group/participants", domainAuth.PermissionGroupRead},
+		{fiber.MethodPost, "/group/participants/remove", domainAuth.PermissionGroupManage},
+		{fiber.MethodGet, "/app/logout", domainAuth.PermissionApp},
+		{fiber.MethodGet, "/devices", domainAuth.PermissionApp},
+		{fiber.MethodDelete, "/devices/628123456789", domainAuth.PermissionApp},
+		{fiber.MethodGet, "/ws", domainAuth.PermissionEvents},
+		{fiber.MethodGet, "/events", domainAuth.PermissionEvents},
+		{fiber.MethodGet, "/devices/628123456789/events", domainAuth.PermissionEvents},
+		{fiber.MethodPost, "/webhook/endpoints", domainAuth.PermissionWebhook},
+		{fiber.MethodDelete, "/admin/users/john", domainAuth.PermissionUsers},
+		{fiber.MethodPost, "/admin/api-keys", domainAuth.PermissionUsers},
+		{fiber.MethodGet, "/admin/audit/export", domainAuth.PermissionAudit},
+		{fiber.MethodGet, "/admin/usage", domainAuth.PermissionUsers},
+		{fiber.MethodPost, "/devices/628123456789/send/message", domainAuth.PermissionSendText},
+		{fiber.MethodGet, "/devices/628123456789/app/logout", domainAuth.PermissionApp},
+		{fiber.MethodGet, "/devices/628123456789/chats", domainAuth.PermissionChatRead},
+		{fiber.MethodGet, "/unknown", domainAuth.PermissionAll},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, RoutePermission(tt.method, tt.path))
+		})
+	}
+}
+
+func TestChangesState(t *testing.T) {
+	tests := []struct {
+		path  string
+		query map[string]string
+		want  bool
+	}{
+		{"/group/invite-link", map[string]string{"reset": "true"}, true},
+		{"/devices/628123456789/group/invite-link", map[string]string{"reset": "1"}, true},
+		{"/group/invite-link", map[string]string{"reset": "false"}, false},
+		{"/group/invite-link", nil, false},
+		{"/group/info", map[string]string{"reset": "true"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			query := func(key string, _ ...string) string { return tt.query[key] }
+			assert.Equal(t, tt.want, ChangesState(tt.path, query))
+		})
+	}
+
+	// A changing request is authorized as a write, which read-only roles are not granted
+	assert.Equal(t, domainAuth.PermissionGroupManage, RoutePermission(fiber.MethodPost, "/group/invite-link"))
+}
@@ -11,7 +11,7 @@ func BasicAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		token := string(c.Request().Header.Peek("Authorization"))
 		if token != "" {
-			ctx := context.WithValue(c.Context(), AuthorizationValue("BASIC_AUTH"), token)
+			ctx := context.WithValue(c.UserContext(), AuthorizationValue("BASIC_AUTH"), token)
 			c.SetUserContext(ctx)
 		}
 
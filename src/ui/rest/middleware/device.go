@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeviceHeader is the request header used to select the WhatsApp device a request is served by
+const DeviceHeader = "X-Device-Id"
+
+// DeviceSelector binds the device chosen by the /devices/:device_id prefix or the X-Device-Id header
+// to the request context. Requests without a device are served by the default device.
+func DeviceSelector() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		deviceID, err := url.PathUnescape(c.Params("device_id"))
+		if err != nil {
+			panic(pkgError.ValidationError("device_id: invalid path escape"))
+		}
+		if deviceID == "" {
+			deviceID = strings.TrimSpace(c.Get(DeviceHeader))
+		}
+		if deviceID == "" {
+			return c.Next()
+		}
+
+		if !whatsapp.DeviceExists(deviceID) {
+			panic(pkgError.ErrDeviceNotFound)
+		}
+
+		c.SetUserContext(whatsapp.ContextWithDevice(c.UserContext(), deviceID))
+		return c.Next()
+	}
+}
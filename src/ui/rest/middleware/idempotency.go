@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strings"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/gofiber/fiber/v2"
+)
+
+// IdempotencyHeader is the request header that makes a retried send return the job queued by the first call
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength keeps the keys, stored with every queued job, reasonably small
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyKey binds the Idempotency-Key header to the request context. It only has an effect on sends
+// while the send queue is enabled.
+func IdempotencyKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(IdempotencyHeader))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			panic(pkgError.ValidationError("Idempotency-Key: the length must be no more than 255."))
+		}
+
+		c.SetUserContext(domainSend.ContextWithIdempotencyKey(c.UserContext(), key))
+		return c.Next()
+	}
+}
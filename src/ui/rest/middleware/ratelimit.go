@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit rejects requests exceeding the rate limit of their route class or the send quotas of the caller
+// with 429 and Retry-After. It must run after Authorize, so rejected requests do not take tokens, and after
+// IdempotencyKey: the tokens are only charged for a 2xx response that did not replay an Idempotency-Key, the
+// requests that fail or send nothing get them back.
+func RateLimit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, _ := domainAuth.PrincipalFromContext(c.UserContext())
+		permission := RoutePermission(requestMethod(c), strings.TrimPrefix(c.Path(), config.AppBasePath))
+
+		var recipient string
+		if domainRateLimit.SendsMessage(permission) {
+			recipient, _ = requestFields(c)["phone"].(string)
+		}
+
+		reservation, err := ratelimit.Reserve(c.UserContext(), principal, permission, recipient)
+		if err != nil {
+			panic(err)
+		}
+		// Also returns the tokens when the handler panics, a committed reservation is not cancelled
+		defer reservation.Cancel()
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status >= 200 && status < 300 && !domainSend.IsIdempotentReplay(c.UserContext()) {
+			reservation.Commit(c.UserContext())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyRateLimitRepository stores nothing, the limiter then only uses its cache
+type emptyRateLimitRepository struct{}
+
+func (emptyRateLimitRepository) GetBucket(context.Context, string, string) (*domainRateLimit.Bucket, error) {
+	return nil, nil
+}
+
+func (emptyRateLimitRepository) GetBuckets(context.Context, string) ([]*domainRateLimit.Bucket, error) {
+	return nil, nil
+}
+
+func (emptyRateLimitRepository) SaveBucket(context.Context, *domainRateLimit.Bucket) error {
+	return nil
+}
+
+func (emptyRateLimitRepository) PurgeBucketsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (emptyRateLimitRepository) GetUsage(context.Context, string, string) (int64, error) {
+	return 0, nil
+}
+
+func (emptyRateLimitRepository) GetUsages(context.Context, string, string) ([]*domainRateLimit.Usage, error) {
+	return nil, nil
+}
+
+func (emptyRateLimitRepository) IncrementUsage(context.Context, string, string, time.Time) error {
+	return nil
+}
+
+func (emptyRateLimitRepository) PurgeUsageBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRateLimitChargesSuccessfulCalls(t *testing.T) {
+	previousLimits := config.AppRateLimits
+	t.Cleanup(func() {
+		config.AppRateLimits = previousLimits
+		require.NoError(t, ratelimit.Init(nil))
+	})
+	config.AppRateLimits = []string{"send=1/1h"}
+	require.NoError(t, ratelimit.Init(emptyRateLimitRepository{}))
+
+	app := fiber.New()
+	app.Use(Recovery())
+	app.Use(IdempotencyKey())
+	app.Use(RateLimit())
+	app.Post("/send/message", func(c *fiber.Ctx) error {
+		switch c.Query("outcome") {
+		case "error":
+			return c.Status(fiber.StatusInternalServerError).SendString("failed")
+		case "panic":
+			panic("send failed")
+		case "replay":
+			domainSend.MarkIdempotentReplay(c.UserContext())
+		}
+		return c.SendString("sent")
+	})
+
+	send := func(outcome string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/send/message?outcome="+outcome, nil)
+		req.Header.Set(IdempotencyHeader, "retry-1")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// The failed calls and the retry that returned the queued job do not use up the only token
+	assert.Equal(t, fiber.StatusInternalServerError, send("error"))
+	assert.Equal(t, fiber.StatusInternalServerError, send("panic"))
+	assert.Equal(t, fiber.StatusOK, send("replay"))
+	assert.Equal(t, fiber.StatusOK, send("sent"))
+	assert.Equal(t, fiber.StatusTooManyRequests, send("sent"))
+}
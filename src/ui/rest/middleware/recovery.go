@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"strconv"
 
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
@@ -28,6 +29,9 @@ func Recovery() fiber.Handler {
 					res.Code = errValidation.ErrCode()
 					res.Message = errValidation.Error()
 				}
+				if limited, ok := err.(pkgError.RateLimitError); ok {
+					ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(limited.RetryAfterSeconds()))
+				}
 
 				_ = ctx.Status(res.Status).JSON(res)
 			}
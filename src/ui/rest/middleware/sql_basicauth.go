@@ -1,39 +1,38 @@
 package middleware
 
 import (
-    "database/sql"
-    "encoding/base64"
-    "strings"
+	"encoding/base64"
+	"strings"
 
-    "github.com/gofiber/fiber/v2"
-    "golang.org/x/crypto/bcrypt"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	"github.com/gofiber/fiber/v2"
 )
 
-// SQLBasicAuth enforces HTTP Basic Authentication using credentials stored in SQL (app_users table).
-// It expects a table schema: app_users(username TEXT PRIMARY KEY, password_hash TEXT, enabled BOOLEAN).
-func SQLBasicAuth(db *sql.DB) fiber.Handler {
-    return func(c *fiber.Ctx) error {
-        auth := c.Get("Authorization")
-        if strings.HasPrefix(auth, "Basic ") {
-            payload := strings.TrimPrefix(auth, "Basic ")
-            decoded, err := base64.StdEncoding.DecodeString(payload)
-            if err == nil {
-                parts := strings.SplitN(string(decoded), ":", 2)
-                if len(parts) == 2 {
-                    username, password := parts[0], parts[1]
-                    var hash string
-                    var enabled bool
-                    err := db.QueryRow("SELECT password_hash, enabled FROM app_users WHERE username = ?", username).Scan(&hash, &enabled)
-                    if err == nil && enabled {
-                        if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
-                            return c.Next()
-                        }
-                    }
-                }
-            }
-        }
+// SQLBasicAuth enforces HTTP Basic Authentication using credentials stored in SQL (app_users table)
+// and binds the authenticated user with its role to the request context. Requests already authenticated
+// by APIKeyAuth are passed through.
+func SQLBasicAuth(users domainAuth.IUserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := domainAuth.PrincipalFromContext(c.UserContext()); ok {
+			return c.Next()
+		}
 
-        c.Set(fiber.HeaderWWWAuthenticate, "Basic realm=\"Restricted\"")
-        return c.SendStatus(fiber.StatusUnauthorized)
-    }
-}
\ No newline at end of file
+		auth := c.Get("Authorization")
+		if strings.HasPrefix(auth, "Basic ") {
+			payload := strings.TrimPrefix(auth, "Basic ")
+			decoded, err := base64.StdEncoding.DecodeString(payload)
+			if err == nil {
+				parts := strings.SplitN(string(decoded), ":", 2)
+				if len(parts) == 2 {
+					if principal, ok := domainAuth.Authenticate(c.UserContext(), users, parts[0], parts[1]); ok {
+						c.SetUserContext(domainAuth.ContextWithPrincipal(c.UserContext(), principal))
+						return c.Next()
+					}
+				}
+			}
+		}
+
+		c.Set(fiber.HeaderWWWAuthenticate, "Basic realm=\"Restricted\"")
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RateLimit struct {
+	Service domainRateLimit.IRateLimitUsecase
+}
+
+func InitRestRateLimit(app fiber.Router, service domainRateLimit.IRateLimitUsecase) RateLimit {
+	rest := RateLimit{Service: service}
+
+	app.Get("/admin/usage", rest.GetUsage)
+
+	return rest
+}
+
+func (controller *RateLimit) GetUsage(c *fiber.Ctx) error {
+	var request domainRateLimit.UsageRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.GetUsage(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get rate limit usage",
+		Results: response,
+	})
+}
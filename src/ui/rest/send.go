@@ -1,7 +1,10 @@
 package rest
 
 import (
+	"encoding/json"
+
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -31,6 +34,7 @@ func (controller *Send) SendText(c *fiber.Ctx) error {
 	var request domainSend.MessageRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	parseFormVariables(c, &request.BaseRequest)
 
 	utils.SanitizePhone(&request.Phone)
 
@@ -51,6 +55,7 @@ func (controller *Send) SendImage(c *fiber.Ctx) error {
 
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	parseFormVariables(c, &request.BaseRequest)
 
 	file, err := c.FormFile("image")
 	if err == nil {
@@ -74,6 +79,7 @@ func (controller *Send) SendFile(c *fiber.Ctx) error {
 	var request domainSend.FileRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	parseFormVariables(c, &request.BaseRequest)
 
 	file, err := c.FormFile("file")
 	utils.PanicIfNeeded(err)
@@ -96,6 +102,7 @@ func (controller *Send) SendVideo(c *fiber.Ctx) error {
 	var request domainSend.VideoRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	parseFormVariables(c, &request.BaseRequest)
 
 	// Try to get file but ignore error if not provided
 	if videoFile, errFile := c.FormFile("video"); errFile == nil {
@@ -160,6 +167,7 @@ func (controller *Send) SendLink(c *fiber.Ctx) error {
 	var request domainSend.LinkRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	parseFormVariables(c, &request.BaseRequest)
 
 	utils.SanitizePhone(&request.Phone)
 
@@ -266,3 +274,15 @@ func (controller *Send) SendChatPresence(c *fiber.Ctx) error {
 		Results: response,
 	})
 }
+
+// parseFormVariables reads the template variables of a form request, they are sent as a JSON object in the
+// variables field
+func parseFormVariables(c *fiber.Ctx, request *domainSend.BaseRequest) {
+	value := c.FormValue("variables")
+	if value == "" || request.Variables != nil {
+		return
+	}
+	if err := json.Unmarshal([]byte(value), &request.Variables); err != nil {
+		panic(pkgError.ValidationError("variables: must be a JSON object."))
+	}
+}
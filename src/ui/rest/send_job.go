@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"fmt"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SendJob struct {
+	Service domainSend.ISendJobUsecase
+}
+
+func InitRestSendJob(app fiber.Router, service domainSend.ISendJobUsecase) SendJob {
+	rest := SendJob{Service: service}
+
+	app.Get("/send/jobs", rest.ListJobs)
+	app.Get("/send/jobs/:id", rest.GetJob)
+	app.Patch("/send/jobs/:id", rest.UpdateJob)
+	app.Delete("/send/jobs/:id", rest.CancelJob)
+
+	return rest
+}
+
+func (controller *SendJob) ListJobs(c *fiber.Ctx) error {
+	var request domainSend.ListJobsRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListJobs(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get send jobs",
+		Results: response,
+	})
+}
+
+func (controller *SendJob) GetJob(c *fiber.Ctx) error {
+	var request domainSend.JobRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetJob(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get send job",
+		Results: response,
+	})
+}
+
+func (controller *SendJob) CancelJob(c *fiber.Ctx) error {
+	var request domainSend.JobRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.CancelJob(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Send job %s cancelled", response.ID),
+		Results: response,
+	})
+}
+
+func (controller *SendJob) UpdateJob(c *fiber.Ctx) error {
+	var request domainSend.UpdateJobRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.UpdateJob(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Send job %s scheduled for %s", response.ID, response.ScheduledAt),
+		Results: response,
+	})
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"fmt"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Template struct {
+	Service domainTemplate.ITemplateUsecase
+}
+
+func InitRestTemplate(app fiber.Router, service domainTemplate.ITemplateUsecase) Template {
+	rest := Template{Service: service}
+
+	app.Post("/templates", rest.CreateTemplate)
+	app.Get("/templates", rest.ListTemplates)
+	app.Get("/templates/:id", rest.GetTemplate)
+	app.Put("/templates/:id", rest.UpdateTemplate)
+	app.Delete("/templates/:id", rest.DeleteTemplate)
+	app.Get("/templates/:id/versions", rest.ListVersions)
+	app.Get("/templates/:id/versions/:version", rest.GetVersion)
+	app.Post("/templates/:id/render", rest.RenderTemplate)
+
+	return rest
+}
+
+func (controller *Template) CreateTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.CreateTemplateRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CreateTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Template %s created", response.Name),
+		Results: response,
+	})
+}
+
+func (controller *Template) ListTemplates(c *fiber.Ctx) error {
+	var request domainTemplate.ListTemplatesRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListTemplates(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get templates",
+		Results: response,
+	})
+}
+
+func (controller *Template) GetTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.TemplateRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get template",
+		Results: response,
+	})
+}
+
+func (controller *Template) UpdateTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.UpdateTemplateRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.UpdateTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Template %s is at version %d", response.Name, response.Version),
+		Results: response,
+	})
+}
+
+func (controller *Template) DeleteTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.TemplateRequest
+	request.ID = c.Params("id")
+
+	err := controller.Service.DeleteTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Template %s deleted", request.ID),
+	})
+}
+
+func (controller *Template) ListVersions(c *fiber.Ctx) error {
+	var request domainTemplate.TemplateRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.ListVersions(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get template versions",
+		Results: response,
+	})
+}
+
+func (controller *Template) GetVersion(c *fiber.Ctx) error {
+	var request domainTemplate.VersionRequest
+	request.ID = c.Params("id")
+	request.Version, _ = c.ParamsInt("version")
+
+	response, err := controller.Service.GetVersion(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get template version",
+		Results: response,
+	})
+}
+
+func (controller *Template) RenderTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.RenderTemplateRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.RenderTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success render template",
+		Results: response,
+	})
+}
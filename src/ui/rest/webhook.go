@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"fmt"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Webhook struct {
+	Service domainWebhook.IWebhookUsecase
+}
+
+func InitRestWebhook(app fiber.Router, service domainWebhook.IWebhookUsecase) Webhook {
+	rest := Webhook{Service: service}
+
+	// Webhook endpoint management
+	app.Get("/webhook/endpoints", rest.ListEndpoints)
+	app.Post("/webhook/endpoints", rest.CreateEndpoint)
+	app.Get("/webhook/endpoints/:id", rest.GetEndpoint)
+	app.Put("/webhook/endpoints/:id", rest.UpdateEndpoint)
+	app.Delete("/webhook/endpoints/:id", rest.DeleteEndpoint)
+	app.Post("/webhook/endpoints/:id/rotate-secret", rest.RotateSecret)
+	app.Post("/webhook/endpoints/:id/circuit/reset", rest.ResetCircuit)
+	app.Get("/webhook/circuits", rest.ListCircuits)
+
+	// Webhook outbox endpoints
+	app.Get("/webhook/deliveries", rest.ListDeliveries)
+	app.Post("/webhook/deliveries/replay", rest.ReplayDeliveries)
+	app.Post("/webhook/deliveries/:id/replay", rest.ReplayDelivery)
+
+	return rest
+}
+
+func (controller *Webhook) ListEndpoints(c *fiber.Ctx) error {
+	response, err := controller.Service.ListEndpoints(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get webhook endpoints",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) GetEndpoint(c *fiber.Ctx) error {
+	var request domainWebhook.EndpointRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.GetEndpoint(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get webhook endpoint",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) CreateEndpoint(c *fiber.Ctx) error {
+	var request domainWebhook.CreateEndpointRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CreateEndpoint(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Webhook endpoint created",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) UpdateEndpoint(c *fiber.Ctx) error {
+	var request domainWebhook.UpdateEndpointRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.UpdateEndpoint(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Webhook endpoint updated",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) DeleteEndpoint(c *fiber.Ctx) error {
+	var request domainWebhook.EndpointRequest
+	request.ID = c.Params("id")
+
+	err := controller.Service.DeleteEndpoint(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Webhook endpoint deleted",
+		Results: nil,
+	})
+}
+
+func (controller *Webhook) RotateSecret(c *fiber.Ctx) error {
+	var request domainWebhook.RotateSecretRequest
+
+	// The body is optional, an empty body generates a new secret with the default overlap
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.RotateSecret(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Webhook secret rotated",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) ListCircuits(c *fiber.Ctx) error {
+	response, err := controller.Service.ListCircuits(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get webhook circuits",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) ResetCircuit(c *fiber.Ctx) error {
+	var request domainWebhook.EndpointRequest
+	request.ID = c.Params("id")
+
+	response, err := controller.Service.ResetCircuit(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Webhook circuit reset",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) ListDeliveries(c *fiber.Ctx) error {
+	var request domainWebhook.ListDeliveriesRequest
+
+	// Parse query parameters
+	request.EndpointID = c.Query("endpoint_id", "")
+	request.Status = c.Query("status", "")
+	request.Limit = c.QueryInt("limit", 25)
+	request.Offset = c.QueryInt("offset", 0)
+	if from := c.Query("from"); from != "" {
+		request.From = &from
+	}
+	if to := c.Query("to"); to != "" {
+		request.To = &to
+	}
+
+	response, err := controller.Service.ListDeliveries(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get webhook deliveries",
+		Results: response,
+	})
+}
+
+func (controller *Webhook) ReplayDelivery(c *fiber.Ctx) error {
+	var request domainWebhook.ReplayDeliveryRequest
+
+	// Parse path parameter
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid delivery id",
+			Results: nil,
+		})
+	}
+	request.ID = int64(id)
+
+	response, err := controller.Service.ReplayDelivery(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Webhook delivery %d scheduled for replay", response.ID),
+		Results: response,
+	})
+}
+
+func (controller *Webhook) ReplayDeliveries(c *fiber.Ctx) error {
+	var request domainWebhook.ReplayDeliveriesRequest
+
+	// The body is optional, an empty body replays the whole dead-letter queue
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Invalid request body",
+				Results: nil,
+			})
+		}
+	}
+
+	response, err := controller.Service.ReplayDeliveries(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("%d webhook deliveries scheduled for replay", response.Replayed),
+		Results: response,
+	})
+}
@@ -0,0 +1,238 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/gofiber/websocket/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sendBuffer     = 64               // messages waiting for a connection, a connection falling further behind is evicted
+	writeWait      = 10 * time.Second // time allowed to write a message
+	pongWait       = 60 * time.Second // time allowed to read the next pong
+	pingPeriod     = pongWait * 9 / 10
+	maxCommandSize = 64 << 10
+)
+
+// client is a connection of the hub. Only its writer goroutine writes to the connection, everyone else queues
+// messages on send.
+type client struct {
+	conn       *websocket.Conn
+	remoteAddr string
+	// ctx is the context of the upgrade request, it carries the authenticated caller and the selected device
+	ctx context.Context
+	// deviceID limits the events pushed to this connection to a single device (empty for all devices)
+	deviceID string
+	// deviceScoped tells the device was selected with the X-Device-Id header, subscriptions cannot select another one
+	deviceScoped bool
+	// eventsOnly clients may subscribe to the event stream but do not receive the device broadcasts, like the
+	// login QR codes, that require the app permission
+	eventsOnly bool
+	send       chan []byte
+
+	done      chan struct{} // closed to stop the writer and close the connection
+	closeOnce sync.Once
+
+	// subscription streams the webhook events the client subscribed to, only used by the goroutine reading the connection
+	subscription domainEventStream.Subscription
+}
+
+func newClient(conn *websocket.Conn, policy *domainAuth.Policy) *client {
+	c := &client{
+		conn:       conn,
+		remoteAddr: conn.RemoteAddr().String(),
+		ctx:        context.Background(),
+		deviceID:   conn.Query("device_id"),
+		send:       make(chan []byte, sendBuffer),
+		done:       make(chan struct{}),
+	}
+	if ctx, ok := conn.Locals(localContext).(context.Context); ok {
+		c.ctx = ctx
+	}
+	if deviceID, _ := conn.Locals(localDevice).(string); deviceID != "" {
+		c.deviceID, c.deviceScoped = deviceID, true
+	}
+	if policy != nil {
+		principal, _ := domainAuth.PrincipalFromContext(c.ctx)
+		c.eventsOnly = !policy.Permits(principal, domainAuth.PermissionApp)
+	}
+	return c
+}
+
+// accepts reports whether the message belongs to the device this connection subscribed to.
+// Devices can be matched by full JID or by phone number.
+func (c *client) accepts(message BroadcastMessage) bool {
+	return !c.eventsOnly && domainEventStream.MatchesDevice(c.deviceID, message.DeviceID)
+}
+
+// offer queues a message without blocking, false when the queue is full
+func (c *client) offer(message []byte) bool {
+	select {
+	case c.send <- message:
+		return true
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// queue waits for room in the send queue, false when the connection is closed in the meantime
+func (c *client) queue(message []byte) bool {
+	select {
+	case c.send <- message:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// reply queues a message for this connection only
+func (c *client) reply(message BroadcastMessage) bool {
+	marshalMessage, err := json.Marshal(message)
+	if err != nil {
+		logrus.Println("marshal error:", err)
+		return false
+	}
+	return c.queue(marshalMessage)
+}
+
+// close stops the writer, which closes the connection and so ends the reader
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// serve runs the writer and reads the commands of the client until the connection is closed by either side
+func (c *client) serve(handle func(Command)) {
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.writePump()
+	}()
+
+	c.readPump(handle)
+	c.close()
+	// The connection must not be used once the handler returns
+	<-writerDone
+}
+
+func (c *client) readPump(handle func(Command)) {
+	c.conn.SetReadLimit(maxCommandSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		messageType, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logrus.Println("read error:", err)
+			}
+			return
+		}
+
+		if messageType != websocket.TextMessage {
+			logrus.Println("unsupported message type:", messageType)
+			continue
+		}
+
+		var command Command
+		if err := json.Unmarshal(message, &command); err != nil {
+			logrus.Println("unmarshal error:", err)
+			return
+		}
+		handle(command)
+	}
+}
+
+// writePump writes the queued messages and pings the client, so dead connections are noticed by the reader
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				logrus.Println("write error:", err)
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe replaces the event subscription of the connection. The events of the device selected with the device_id
+// query parameter are streamed unless the command selects another device, the device of the X-Device-Id header is
+// always streamed.
+func (c *client) subscribe(ctx context.Context, service domainEventStream.IEventStreamUsecase, request domainEventStream.SubscribeRequest) {
+	c.unsubscribe()
+	if request.DeviceID == "" || c.deviceScoped {
+		request.DeviceID = c.deviceID
+	}
+
+	subscription, err := service.Subscribe(ctx, request)
+	if err != nil {
+		c.reply(BroadcastMessage{Code: codeEventsError, Message: err.Error()})
+		return
+	}
+	c.subscription = subscription
+
+	if !c.reply(BroadcastMessage{Code: codeEventsSubscribed, Message: "Subscribed to events", Result: request}) {
+		subscription.Close()
+		return
+	}
+	go c.forward(subscription)
+}
+
+func (c *client) unsubscribe() {
+	if c.subscription != nil {
+		c.subscription.Close()
+		c.subscription = nil
+	}
+}
+
+// forward queues the events of the subscription until it ends. It waits for room in the send queue, a client that
+// does not keep up lags behind its subscription and is told to resume.
+func (c *client) forward(subscription domainEventStream.Subscription) {
+	for event := range subscription.Events() {
+		sent := c.reply(BroadcastMessage{
+			Code:        codeEvent,
+			Message:     event.Name,
+			Result:      event.Payload,
+			DeviceID:    event.DeviceID,
+			ResumeToken: event.ResumeToken(),
+		})
+		if !sent {
+			subscription.Close()
+		}
+	}
+
+	err := subscription.Err()
+	switch {
+	case errors.Is(err, domainEventStream.ErrLagged):
+		c.reply(BroadcastMessage{Code: codeEventsLagged, Message: err.Error()})
+	case err != nil:
+		c.reply(BroadcastMessage{Code: codeEventsError, Message: err.Error()})
+	}
+}
@@ -0,0 +1,123 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	dialer "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAccepts(t *testing.T) {
+	tests := []struct {
+		name       string
+		deviceID   string
+		eventsOnly bool
+		message    BroadcastMessage
+		want       bool
+	}{
+		{"every device", "", false, BroadcastMessage{DeviceID: "628123456789:12@s.whatsapp.net"}, true},
+		{"message of no device", "628123456789", false, BroadcastMessage{}, true},
+		{"device by phone number", "628123456789", false, BroadcastMessage{DeviceID: "628123456789:12@s.whatsapp.net"}, true},
+		{"device by JID", "628123456789:12@s.whatsapp.net", false, BroadcastMessage{DeviceID: "628123456789:12@s.whatsapp.net"}, true},
+		{"another device", "628123456789", false, BroadcastMessage{DeviceID: "6281234567890:3@s.whatsapp.net"}, false},
+		{"events only", "", true, BroadcastMessage{Code: "LOGIN_SUCCESS"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{deviceID: tt.deviceID, eventsOnly: tt.eventsOnly}
+			assert.Equal(t, tt.want, c.accepts(tt.message))
+		})
+	}
+}
+
+// serveHub serves connections registered with the running hub, like /ws without the commands. It returns the URL,
+// the clients of the connections and the clients whose connection ended.
+func serveHub(t *testing.T) (string, <-chan *client, <-chan *client) {
+	t.Helper()
+	startHub(t)
+
+	served, ended := make(chan *client, 1), make(chan *client, 1)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", websocket.New(func(conn *websocket.Conn) {
+		c := newClient(conn, nil)
+		register <- c
+		served <- c
+		defer func() {
+			unregister <- c
+			ended <- c
+		}()
+		c.serve(func(Command) {})
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	return "ws://" + listener.Addr().String() + "/ws", served, ended
+}
+
+// receive waits for a value of the channel
+func receive(t *testing.T, values <-chan *client) *client {
+	t.Helper()
+	select {
+	case value := <-values:
+		return value
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the connection")
+		return nil
+	}
+}
+
+func TestServeWritesBroadcasts(t *testing.T) {
+	url, served, ended := serveHub(t)
+
+	conn, _, err := dialer.DefaultDialer.Dial(url+"?device_id=628123456789", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	c := receive(t, served)
+
+	Publish(BroadcastMessage{Code: "OTHER_DEVICE", DeviceID: "628999999999:1@s.whatsapp.net"})
+	for i := range 3 {
+		Publish(BroadcastMessage{Code: strconv.Itoa(i), DeviceID: "628123456789:12@s.whatsapp.net"})
+	}
+
+	// The writer sends the messages of the selected device in order
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	for i := range 3 {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var message BroadcastMessage
+		require.NoError(t, json.Unmarshal(data, &message))
+		assert.Equal(t, strconv.Itoa(i), message.Code)
+	}
+
+	// The reader notices the client going away and stops the writer
+	require.NoError(t, conn.Close())
+	assert.Same(t, c, receive(t, ended))
+	assert.True(t, closed(c))
+}
+
+func TestServeClosesEvictedConnection(t *testing.T) {
+	url, served, ended := serveHub(t)
+
+	conn, _, err := dialer.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	c := receive(t, served)
+
+	// Closing the client, as the hub does when it evicts it, sends a close message and ends the connection
+	c.close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeError *dialer.CloseError
+	assert.ErrorAs(t, err, &closeError)
+	assert.Same(t, c, receive(t, ended))
+}
@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// broadcastBuffer is the number of messages waiting for the hub, newer messages are dropped while it is full
+const broadcastBuffer = 256
+
+var (
+	running atomic.Bool
+
+	// clients is only used by the goroutine running the hub
+	clients    = make(map[*client]struct{})
+	register   = make(chan *client)
+	unregister = make(chan *client)
+	broadcast  = make(chan BroadcastMessage, broadcastBuffer)
+)
+
+// Publish queues a message for every connection without ever blocking the caller, like the WhatsApp event
+// handlers. The message is dropped when the hub does not keep up or does not run, like when only the MCP server runs.
+func Publish(message BroadcastMessage) {
+	if !running.Load() {
+		logrus.Debugf("Websocket message %s dropped, no websocket hub is running", message.Code)
+		return
+	}
+	select {
+	case broadcast <- message:
+	default:
+		logrus.Warnf("Websocket message %s dropped, the websocket hub is not keeping up", message.Code)
+	}
+}
+
+// RunHub tracks the connections and passes the published messages to their send queues. A connection whose queue
+// is full is evicted instead of slowing down the others.
+func RunHub() {
+	runHub(nil)
+}
+
+// runHub runs the hub until stop is closed, a nil stop runs it forever
+func runHub(stop <-chan struct{}) {
+	running.Store(true)
+	defer running.Store(false)
+	for {
+		select {
+		case <-stop:
+			return
+
+		case c := <-register:
+			clients[c] = struct{}{}
+			logrus.Println("connection registered")
+
+		case c := <-unregister:
+			if _, ok := clients[c]; ok {
+				delete(clients, c)
+				logrus.Println("connection unregistered")
+			}
+			c.close()
+
+		case message := <-broadcast:
+			logrus.Debugln("message received:", message)
+			broadcastMessage(message)
+		}
+	}
+}
+
+func broadcastMessage(message BroadcastMessage) {
+	marshalMessage, err := json.Marshal(message)
+	if err != nil {
+		logrus.Println("marshal error:", err)
+		return
+	}
+
+	var evicted []*client
+	for c := range clients {
+		if !c.accepts(message) {
+			continue
+		}
+		if !c.offer(marshalMessage) {
+			evicted = append(evicted, c)
+		}
+	}
+
+	// The map is not changed while it is ranged
+	for _, c := range evicted {
+		logrus.Warnf("Websocket client %s is not keeping up, closing its connection", c.remoteAddr)
+		delete(clients, c)
+		c.close()
+	}
+}
@@ -0,0 +1,139 @@
+package websocket
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHub runs the hub until the test ends
+func startHub(t *testing.T) {
+	t.Helper()
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runHub(stop)
+	}()
+	require.Eventually(t, running.Load, time.Second, time.Millisecond)
+
+	t.Cleanup(func() {
+		close(stop)
+		<-stopped
+		clients = make(map[*client]struct{})
+	})
+}
+
+func testClient(buffer int) *client {
+	return &client{remoteAddr: "test", send: make(chan []byte, buffer), done: make(chan struct{})}
+}
+
+// drain reads the send queue of the client until it is closed and returns the number of messages queued
+func drain(c *client) <-chan int {
+	count := make(chan int, 1)
+	go func() {
+		received := 0
+		for {
+			select {
+			case <-c.send:
+				received++
+			case <-c.done:
+				count <- received + len(c.send)
+				return
+			}
+		}
+	}()
+	return count
+}
+
+func closed(c *client) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPublishNeverBlocks(t *testing.T) {
+	// A running hub that does not read the broadcasts
+	running.Store(true)
+	t.Cleanup(func() {
+		running.Store(false)
+		for len(broadcast) > 0 {
+			<-broadcast
+		}
+	})
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := range broadcastBuffer * 2 {
+			Publish(BroadcastMessage{Code: strconv.Itoa(i)})
+		}
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full broadcast buffer")
+	}
+	assert.Equal(t, broadcastBuffer, len(broadcast))
+}
+
+func TestSlowClientEvicted(t *testing.T) {
+	startHub(t)
+
+	slow := testClient(1)
+	fast := testClient(sendBuffer)
+	received := drain(fast)
+	register <- slow
+	register <- fast
+
+	const messages = 10
+	for i := range messages {
+		Publish(BroadcastMessage{Code: strconv.Itoa(i)})
+	}
+
+	// The slow client never reads its queue, it is closed while the fast one gets every message
+	require.Eventually(t, func() bool { return closed(slow) }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(broadcast) == 0 }, time.Second, time.Millisecond)
+	unregister <- fast
+	assert.Equal(t, messages, <-received)
+}
+
+func TestUnregisterDuringBroadcast(t *testing.T) {
+	startHub(t)
+
+	connected := make([]*client, 50)
+	for i := range connected {
+		connected[i] = testClient(sendBuffer)
+		drain(connected[i])
+		register <- connected[i]
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			Publish(BroadcastMessage{Code: strconv.Itoa(i)})
+		}
+	}()
+	for _, c := range connected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unregister <- c
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range connected {
+		assert.True(t, closed(c))
+	}
+}
@@ -1,125 +1,89 @@
 package websocket
 
 import (
-	"context"
-	"encoding/json"
-
-	"github.com/sirupsen/logrus"
+	"strings"
 
 	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 )
 
-type client struct{}
-
-type BroadcastMessage struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Result  any    `json:"result"`
-}
-
-var (
-	Clients    = make(map[*websocket.Conn]client)
-	Register   = make(chan *websocket.Conn)
-	Broadcast  = make(chan BroadcastMessage)
-	Unregister = make(chan *websocket.Conn)
+// Codes of the event stream, see SUBSCRIBE_EVENTS
+const (
+	codeSubscribeEvents    = "SUBSCRIBE_EVENTS"
+	codeUnsubscribeEvents  = "UNSUBSCRIBE_EVENTS"
+	codeEventsSubscribed   = "EVENTS_SUBSCRIBED"
+	codeEventsUnsubscribed = "EVENTS_UNSUBSCRIBED"
+	codeEvent              = "EVENT"
+	codeEventsLagged       = "EVENTS_LAGGED"
+	codeEventsError        = "EVENTS_ERROR"
 )
 
-func handleRegister(conn *websocket.Conn) {
-	Clients[conn] = client{}
-	logrus.Println("connection registered")
-}
-
-func handleUnregister(conn *websocket.Conn) {
-	delete(Clients, conn)
-	logrus.Println("connection unregistered")
-}
-
-func broadcastMessage(message BroadcastMessage) {
-	marshalMessage, err := json.Marshal(message)
-	if err != nil {
-		logrus.Println("marshal error:", err)
-		return
-	}
+// Locals of the upgrade request read by the connection
+const (
+	localContext = "websocket_context"
+	localDevice  = "websocket_device"
+)
 
-	for conn := range Clients {
-		if err := conn.WriteMessage(websocket.TextMessage, marshalMessage); err != nil {
-			logrus.Println("write error:", err)
-			closeConnection(conn)
-		}
-	}
-}
+// deviceHeader selects the device of the connection, it is validated by the device selector middleware
+// (middleware.DeviceHeader, which cannot be imported here)
+const deviceHeader = "X-Device-Id"
 
-func closeConnection(conn *websocket.Conn) {
-	if err := conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
-		logrus.Println("write close message error:", err)
-	}
-	if err := conn.Close(); err != nil {
-		logrus.Println("close connection error:", err)
-	}
-	delete(Clients, conn)
+type BroadcastMessage struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Result      any    `json:"result"`
+	DeviceID    string `json:"device_id,omitempty"`
+	ResumeToken string `json:"resume_token,omitempty"` // of EVENT messages, resume from it to receive the events after this one
 }
 
-func RunHub() {
-	for {
-		select {
-		case conn := <-Register:
-			handleRegister(conn)
-
-		case conn := <-Unregister:
-			handleUnregister(conn)
-
-		case message := <-Broadcast:
-			logrus.Println("message received:", message)
-			broadcastMessage(message)
-		}
-	}
+// Command is a message sent by a client. SUBSCRIBE_EVENTS carries the events, chats and resume token to subscribe to.
+type Command struct {
+	Code string `json:"code"`
+	domainEventStream.SubscribeRequest
 }
 
-func RegisterRoutes(app fiber.Router, service domainApp.IAppUsecase) {
+// RegisterRoutes registers /ws. Callers whose role grants the events permission but not app only use the event stream.
+func RegisterRoutes(app fiber.Router, service domainApp.IAppUsecase, eventService domainEventStream.IEventStreamUsecase, policy *domainAuth.Policy) {
 	app.Use("/ws", func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
+			// The connection outlives the request, keep the caller and the selected device for its commands
+			c.Locals(localContext, c.UserContext())
+			c.Locals(localDevice, strings.TrimSpace(c.Get(deviceHeader)))
 			return c.Next()
 		}
 		return c.SendStatus(fiber.StatusUpgradeRequired)
 	})
 
 	app.Get("/ws", websocket.New(func(conn *websocket.Conn) {
+		c := newClient(conn, policy)
+		register <- c
 		defer func() {
-			Unregister <- conn
-			_ = conn.Close()
+			c.unsubscribe()
+			unregister <- c
 		}()
 
-		Register <- conn
-
-		for {
-			messageType, message, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					logrus.Println("read error:", err)
-				}
-				return
-			}
-
-			if messageType == websocket.TextMessage {
-				var messageData BroadcastMessage
-				if err := json.Unmarshal(message, &messageData); err != nil {
-					logrus.Println("unmarshal error:", err)
+		c.serve(func(command Command) {
+			switch command.Code {
+			case "FETCH_DEVICES":
+				if c.eventsOnly {
+					c.reply(BroadcastMessage{Code: codeEventsError, Message: "FETCH_DEVICES requires the app permission"})
 					return
 				}
-
-				if messageData.Code == "FETCH_DEVICES" {
-					devices, _ := service.FetchDevices(context.Background())
-					Broadcast <- BroadcastMessage{
-						Code:    "LIST_DEVICES",
-						Message: "Device found",
-						Result:  devices,
-					}
-				}
-			} else {
-				logrus.Println("unsupported message type:", messageType)
+				devices, _ := service.FetchDevices(c.ctx)
+				Publish(BroadcastMessage{
+					Code:    "LIST_DEVICES",
+					Message: "Device found",
+					Result:  devices,
+				})
+			case codeSubscribeEvents:
+				c.subscribe(c.ctx, eventService, command.SubscribeRequest)
+			case codeUnsubscribeEvents:
+				c.unsubscribe()
+				c.reply(BroadcastMessage{Code: codeEventsUnsubscribed, Message: "Unsubscribed from events"})
 			}
-		}
+		})
 	}))
 }
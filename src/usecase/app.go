@@ -31,8 +31,8 @@ func NewAppService(chatStorageRepo domainChatStorage.IChatStorageRepository) dom
 	}
 }
 
-func (service *serviceApp) Login(_ context.Context) (response domainApp.LoginResponse, err error) {
-	client := whatsapp.GetClient()
+func (service *serviceApp) Login(ctx context.Context) (response domainApp.LoginResponse, err error) {
+	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
 		return response, pkgError.ErrWaCLI
 	}
@@ -119,7 +119,9 @@ func (service *serviceApp) Login(_ context.Context) (response domainApp.LoginRes
 		client.IsConnected(), client.IsLoggedIn())
 
 	// Ensure global client is synchronized with service client
-	whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	if whatsapp.IsDefaultDevice(ctx) {
+		whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	}
 
 	return response, nil
 }
@@ -130,7 +132,10 @@ func (service *serviceApp) LoginWithCode(ctx context.Context, phoneNumber string
 		return loginCode, err
 	}
 
-	client := whatsapp.GetClient()
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return loginCode, pkgError.ErrWaCLI
+	}
 	// detect is already logged in
 	if client.Store.ID != nil {
 		logrus.Warn("User is already logged in")
@@ -152,13 +157,20 @@ func (service *serviceApp) LoginWithCode(ctx context.Context, phoneNumber string
 		client.IsConnected(), client.IsLoggedIn())
 
 	// Ensure global client is synchronized with service client
-	whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	if whatsapp.IsDefaultDevice(ctx) {
+		whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	}
 
 	logrus.Infof("Successfully paired phone with code: %s", loginCode)
 	return loginCode, nil
 }
 
 func (service *serviceApp) Logout(ctx context.Context) (err error) {
+	// Additional sessions are logged out and removed without touching the default device
+	if !whatsapp.IsDefaultDevice(ctx) {
+		return whatsapp.RemoveSession(ctx, whatsapp.DeviceIDFromContext(ctx))
+	}
+
 	// [DEBUG] Log database state before logout
 	logrus.Info("[DEBUG] Starting logout process...")
 	devices, dbErr := whatsapp.GetDB().GetAllDevices(ctx)
@@ -173,7 +185,7 @@ func (service *serviceApp) Logout(ctx context.Context) (err error) {
 
 	// [DEBUG] Call WhatsApp client logout first to disconnect from server
 	logrus.Info("[DEBUG] Calling WhatsApp client logout...")
-	err = whatsapp.GetClient().Logout(ctx)
+	err = whatsapp.ClientFromContext(ctx).Logout(ctx)
 	if err != nil {
 		logrus.Errorf("[DEBUG] WhatsApp logout failed: %v", err)
 		// Continue with cleanup even if logout fails
@@ -203,10 +215,13 @@ func (service *serviceApp) Logout(ctx context.Context) (err error) {
 	return nil
 }
 
-func (service *serviceApp) Reconnect(_ context.Context) (err error) {
+func (service *serviceApp) Reconnect(ctx context.Context) (err error) {
 	logrus.Info("[DEBUG] Starting reconnect process...")
 
-	client := whatsapp.GetClient()
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
 	client.Disconnect()
 	err = client.Connect()
 
@@ -220,27 +235,29 @@ func (service *serviceApp) Reconnect(_ context.Context) (err error) {
 		client.IsConnected(), client.IsLoggedIn())
 
 	// Ensure global client is synchronized with service client
-	whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	if whatsapp.IsDefaultDevice(ctx) {
+		whatsapp.UpdateGlobalClient(client, whatsapp.GetDB())
+	}
 
 	logrus.Info("[DEBUG] Reconnect process completed successfully")
 	return err
 }
 
 func (service *serviceApp) FirstDevice(ctx context.Context) (response domainApp.DevicesResponse, err error) {
-	if whatsapp.GetClient() == nil {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
 		return response, pkgError.ErrWaCLI
 	}
 
-	devices, err := whatsapp.GetDB().GetFirstDevice(ctx)
-	if err != nil {
-		return response, err
+	// Every client reports its own device; the first device in the store need not be the one the default
+	// client is logged in with
+	if client.Store.ID == nil {
+		return response, pkgError.ErrNotLoggedIn
 	}
-
-	response.Device = devices.ID.String()
-	if devices.PushName != "" {
-		response.Name = devices.PushName
-	} else {
-		response.Name = devices.BusinessName
+	response.Device = client.Store.ID.String()
+	response.Name = client.Store.PushName
+	if response.Name == "" {
+		response.Name = client.Store.BusinessName
 	}
 
 	return response, nil
@@ -270,3 +287,52 @@ func (service *serviceApp) FetchDevices(ctx context.Context) (response []domainA
 
 	return response, nil
 }
+
+func (service *serviceApp) AddDevice(ctx context.Context) (response domainApp.DeviceSessionResponse, err error) {
+	session, err := whatsapp.AddSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	for _, info := range whatsapp.ListSessions() {
+		if info.Token == session.Token {
+			return toDeviceSessionResponse(info), nil
+		}
+	}
+
+	return response, pkgError.ErrDeviceNotFound
+}
+
+func (service *serviceApp) ListDeviceSessions(_ context.Context) (response []domainApp.DeviceSessionResponse, err error) {
+	for _, info := range whatsapp.ListSessions() {
+		response = append(response, toDeviceSessionResponse(info))
+	}
+
+	return response, nil
+}
+
+func (service *serviceApp) RemoveDevice(ctx context.Context, deviceID string) (err error) {
+	if err = validations.ValidateRemoveDevice(ctx, deviceID); err != nil {
+		return err
+	}
+
+	if _, ok := whatsapp.GetSession(deviceID); !ok {
+		if whatsapp.DeviceExists(deviceID) {
+			return pkgError.ValidationError("the default device cannot be removed, use /app/logout instead")
+		}
+		return pkgError.ErrDeviceNotFound
+	}
+
+	return whatsapp.RemoveSession(ctx, deviceID)
+}
+
+func toDeviceSessionResponse(info whatsapp.SessionInfo) domainApp.DeviceSessionResponse {
+	return domainApp.DeviceSessionResponse{
+		ID:          info.ID,
+		Token:       info.Token,
+		Name:        info.Name,
+		IsDefault:   info.IsDefault,
+		IsConnected: info.IsConnected,
+		IsLoggedIn:  info.IsLoggedIn,
+	}
+}
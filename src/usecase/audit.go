@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/sirupsen/logrus"
+)
+
+// auditExportBatch is the number of entries read per query while exporting
+const auditExportBatch = 500
+
+type serviceAudit struct {
+	auditRepo domainAudit.IAuditRepository
+}
+
+func NewAuditService(auditRepo domainAudit.IAuditRepository) domainAudit.IAuditUsecase {
+	return &serviceAudit{
+		auditRepo: auditRepo,
+	}
+}
+
+func (service serviceAudit) ListEntries(ctx context.Context, request domainAudit.ListEntriesRequest) (response domainAudit.ListEntriesResponse, err error) {
+	if err = validations.ValidateListAuditEntries(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter, err := auditFilter(request)
+	if err != nil {
+		return response, err
+	}
+
+	entries, err := service.auditRepo.GetEntries(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get audit entries")
+		return response, err
+	}
+
+	totalCount, err := service.auditRepo.CountEntries(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count audit entries")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainAudit.EntryInfo, 0, len(entries))
+	for _, entry := range entries {
+		response.Data = append(response.Data, toAuditEntryInfo(entry))
+	}
+	response.Pagination = domainAudit.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceAudit) ExportEntries(ctx context.Context, request domainAudit.ListEntriesRequest, w io.Writer) (err error) {
+	request.Limit, request.Offset = 0, 0
+	if err = validations.ValidateListAuditEntries(ctx, &request); err != nil {
+		return err
+	}
+
+	filter, err := auditFilter(request)
+	if err != nil {
+		return err
+	}
+	// Entries written during the export would shift the pages, so the export stops at its start time
+	if filter.To == nil {
+		now := time.Now().UTC()
+		filter.To = &now
+	}
+	filter.Limit = auditExportBatch
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write([]string{"id", "created_at", "actor", "api_key_id", "source", "action", "device_id", "target", "result", "error", "message_id", "summary"}); err != nil {
+		return err
+	}
+
+	for filter.Offset = 0; ; filter.Offset += auditExportBatch {
+		entries, err := service.auditRepo.GetEntries(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			info := toAuditEntryInfo(entry)
+			if err = writer.Write([]string{
+				strconv.FormatInt(info.ID, 10), info.CreatedAt, info.Actor, info.APIKeyID, info.Source, info.Action,
+				info.DeviceID, info.Target, info.Result, info.Error, info.MessageID, info.Summary,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(entries) < auditExportBatch {
+			break
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func auditFilter(request domainAudit.ListEntriesRequest) (*domainAudit.EntryFilter, error) {
+	filter := &domainAudit.EntryFilter{
+		Actor:  request.Actor,
+		Action: request.Action,
+		Source: request.Source,
+		Target: request.Target,
+		Result: request.Result,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+
+	var err error
+	filter.From, filter.To, err = parseTimeRange(request.From, request.To)
+	return filter, err
+}
+
+func toAuditEntryInfo(entry *domainAudit.Entry) domainAudit.EntryInfo {
+	return domainAudit.EntryInfo{
+		ID:        entry.ID,
+		Actor:     entry.Actor,
+		APIKeyID:  entry.APIKeyID,
+		Source:    entry.Source,
+		Action:    entry.Action,
+		DeviceID:  entry.DeviceID,
+		Target:    entry.Target,
+		Summary:   entry.Summary,
+		Result:    entry.Result,
+		Error:     entry.Error,
+		MessageID: entry.MessageID,
+		CreatedAt: entry.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,347 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type serviceAuth struct {
+	userRepo domainAuth.IUserRepository
+	policy   *domainAuth.Policy
+}
+
+func NewAuthService(userRepo domainAuth.IUserRepository, policy *domainAuth.Policy) domainAuth.IAuthUsecase {
+	return &serviceAuth{
+		userRepo: userRepo,
+		policy:   policy,
+	}
+}
+
+func (service serviceAuth) ListUsers(ctx context.Context) (response []domainAuth.UserInfo, err error) {
+	users, err := service.userRepo.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response = make([]domainAuth.UserInfo, 0, len(users))
+	for _, user := range users {
+		response = append(response, toUserInfo(user))
+	}
+	return response, nil
+}
+
+func (service serviceAuth) GetUser(ctx context.Context, request domainAuth.UserRequest) (response domainAuth.UserInfo, err error) {
+	user, err := service.storedUser(ctx, request.Username)
+	if err != nil {
+		return response, err
+	}
+	return toUserInfo(user), nil
+}
+
+func (service serviceAuth) CreateUser(ctx context.Context, request domainAuth.CreateUserRequest) (response domainAuth.UserInfo, err error) {
+	if err = validations.ValidateCreateUser(ctx, &request); err != nil {
+		return response, err
+	}
+	if err = service.validateRole(request.Role); err != nil {
+		return response, err
+	}
+
+	existing, err := service.userRepo.GetUser(ctx, request.Username)
+	if err != nil {
+		return response, err
+	}
+	if existing != nil {
+		return response, pkgError.ValidationError(fmt.Sprintf("user %s already exists", request.Username))
+	}
+
+	password, generated, err := passwordOrGenerate(request.Password)
+	if err != nil {
+		return response, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return response, err
+	}
+
+	user := &domainAuth.User{
+		Username:     request.Username,
+		PasswordHash: hash,
+		Role:         request.Role,
+		Enabled:      request.Enabled == nil || *request.Enabled,
+	}
+	if err = service.userRepo.CreateUser(ctx, user); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("User %s created with role %s", user.Username, user.Role)
+	response = toUserInfo(user)
+	if generated {
+		response.Password = password
+	}
+	return response, nil
+}
+
+func (service serviceAuth) UpdateUser(ctx context.Context, request domainAuth.UpdateUserRequest) (response domainAuth.UserInfo, err error) {
+	if err = validations.ValidateUpdateUser(ctx, &request); err != nil {
+		return response, err
+	}
+
+	user, err := service.storedUser(ctx, request.Username)
+	if err != nil {
+		return response, err
+	}
+
+	if request.Role != "" {
+		if err = service.validateRole(request.Role); err != nil {
+			return response, err
+		}
+		user.Role = request.Role
+	}
+	if request.Enabled != nil {
+		user.Enabled = *request.Enabled
+	}
+	if err = service.ensureAdminRemains(ctx, user.Username, user); err != nil {
+		return response, err
+	}
+
+	if err = service.userRepo.UpdateUser(ctx, user); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("User %s updated, role %s, enabled %t", user.Username, user.Role, user.Enabled)
+	return toUserInfo(user), nil
+}
+
+func (service serviceAuth) ResetPassword(ctx context.Context, request domainAuth.ResetPasswordRequest) (response domainAuth.UserInfo, err error) {
+	if err = validations.ValidateResetPassword(ctx, &request); err != nil {
+		return response, err
+	}
+
+	user, err := service.storedUser(ctx, request.Username)
+	if err != nil {
+		return response, err
+	}
+
+	password, generated, err := passwordOrGenerate(request.Password)
+	if err != nil {
+		return response, err
+	}
+	if user.PasswordHash, err = hashPassword(password); err != nil {
+		return response, err
+	}
+	if err = service.userRepo.UpdateUser(ctx, user); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Password of user %s reset", user.Username)
+	response = toUserInfo(user)
+	if generated {
+		response.Password = password
+	}
+	return response, nil
+}
+
+func (service serviceAuth) DeleteUser(ctx context.Context, request domainAuth.UserRequest) (err error) {
+	if _, err = service.storedUser(ctx, request.Username); err != nil {
+		return err
+	}
+	if err = service.ensureAdminRemains(ctx, request.Username, nil); err != nil {
+		return err
+	}
+
+	if err = service.userRepo.DeleteUser(ctx, request.Username); err != nil {
+		return err
+	}
+
+	logrus.Infof("User %s deleted", request.Username)
+	return nil
+}
+
+func (service serviceAuth) ListAPIKeys(ctx context.Context, request domainAuth.ListAPIKeysRequest) (response []domainAuth.APIKeyInfo, err error) {
+	keys, err := service.userRepo.GetAPIKeys(ctx, request.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	response = make([]domainAuth.APIKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyInfo(key))
+	}
+	return response, nil
+}
+
+func (service serviceAuth) GetAPIKey(ctx context.Context, request domainAuth.APIKeyRequest) (response domainAuth.APIKeyInfo, err error) {
+	key, err := service.storedAPIKey(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	return toAPIKeyInfo(key), nil
+}
+
+func (service serviceAuth) CreateAPIKey(ctx context.Context, request domainAuth.CreateAPIKeyRequest) (response domainAuth.APIKeyInfo, err error) {
+	if err = validations.ValidateCreateAPIKey(ctx, &request); err != nil {
+		return response, err
+	}
+	if _, err = service.storedUser(ctx, request.Username); err != nil {
+		return response, err
+	}
+
+	random := make([]byte, 32)
+	if _, err = rand.Read(random); err != nil {
+		return response, pkgError.InternalServerError(fmt.Sprintf("failed to generate API key: %v", err))
+	}
+	secret := domainAuth.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := &domainAuth.APIKey{
+		ID:       uuid.NewString(),
+		Name:     request.Name,
+		Username: request.Username,
+		KeyHash:  domainAuth.HashAPIKey(secret),
+		Prefix:   secret[:len(domainAuth.APIKeyPrefix)+6],
+		Scopes:   request.Scopes,
+	}
+	if request.ExpiresAt != "" {
+		expiresAt, _ := time.Parse(time.RFC3339, request.ExpiresAt)
+		expiresAt = expiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+	if err = service.userRepo.CreateAPIKey(ctx, key); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("API key %s (%s) created for user %s with scopes %v", key.ID, key.Name, key.Username, key.Scopes)
+	response = toAPIKeyInfo(key)
+	response.Key = secret
+	return response, nil
+}
+
+func (service serviceAuth) RevokeAPIKey(ctx context.Context, request domainAuth.APIKeyRequest) (err error) {
+	if _, err = service.storedAPIKey(ctx, request.ID); err != nil {
+		return err
+	}
+
+	if err = service.userRepo.DeleteAPIKey(ctx, request.ID); err != nil {
+		return err
+	}
+
+	logrus.Infof("API key %s revoked", request.ID)
+	return nil
+}
+
+func (service serviceAuth) storedAPIKey(ctx context.Context, id string) (*domainAuth.APIKey, error) {
+	key, err := service.userRepo.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("API key %s not found", id))
+	}
+	return key, nil
+}
+
+func (service serviceAuth) storedUser(ctx context.Context, username string) (*domainAuth.User, error) {
+	user, err := service.userRepo.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("user %s not found", username))
+	}
+	return user, nil
+}
+
+func (service serviceAuth) validateRole(role string) error {
+	if !service.policy.HasRole(role) {
+		return pkgError.ValidationError(fmt.Sprintf("role: must be one of %v.", service.policy.Roles()))
+	}
+	return nil
+}
+
+// ensureAdminRemains rejects a change of the user that would leave no enabled user able to manage users,
+// changed is the user after the change or nil when it is deleted
+func (service serviceAuth) ensureAdminRemains(ctx context.Context, username string, changed *domainAuth.User) error {
+	users, err := service.userRepo.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.Username == username {
+			if changed == nil {
+				continue
+			}
+			user = changed
+		}
+		if user.Enabled && service.policy.Allows(user.Role, domainAuth.PermissionUsers) {
+			return nil
+		}
+	}
+	return pkgError.ValidationError("at least one enabled user must keep a role that can manage users")
+}
+
+func toUserInfo(user *domainAuth.User) domainAuth.UserInfo {
+	info := domainAuth.UserInfo{
+		Username: user.Username,
+		Role:     user.Role,
+		Enabled:  user.Enabled,
+	}
+	if !user.CreatedAt.IsZero() {
+		info.CreatedAt = user.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if !user.UpdatedAt.IsZero() {
+		info.UpdatedAt = user.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+func toAPIKeyInfo(key *domainAuth.APIKey) domainAuth.APIKeyInfo {
+	info := domainAuth.APIKeyInfo{
+		ID:       key.ID,
+		Name:     key.Name,
+		Username: key.Username,
+		Prefix:   key.Prefix,
+		Scopes:   key.Scopes,
+	}
+	if key.ExpiresAt != nil {
+		info.ExpiresAt = key.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if key.LastUsedAt != nil {
+		info.LastUsedAt = key.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	if !key.CreatedAt.IsZero() {
+		info.CreatedAt = key.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// passwordOrGenerate returns the requested password or a random one when it is empty
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", false, pkgError.InternalServerError(fmt.Sprintf("failed to generate password: %v", err))
+	}
+	// URL-safe base64 without padding for readability
+	return base64.RawURLEncoding.EncodeToString(random), true, nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", pkgError.InternalServerError(fmt.Sprintf("failed to hash password: %v", err))
+	}
+	return string(hash), nil
+}
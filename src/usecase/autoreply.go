@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/autoreply"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type serviceAutoReply struct {
+	autoReplyRepo domainAutoReply.IAutoReplyRepository
+	templateRepo  domainTemplate.ITemplateRepository
+}
+
+func NewAutoReplyService(autoReplyRepo domainAutoReply.IAutoReplyRepository, templateRepo domainTemplate.ITemplateRepository) domainAutoReply.IAutoReplyUsecase {
+	return &serviceAutoReply{
+		autoReplyRepo: autoReplyRepo,
+		templateRepo:  templateRepo,
+	}
+}
+
+func (service serviceAutoReply) ListRules(_ context.Context) (response []domainAutoReply.RuleInfo, err error) {
+	rules := autoreply.Rules()
+	response = make([]domainAutoReply.RuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, toRuleInfo(rule))
+	}
+	return response, nil
+}
+
+func (service serviceAutoReply) GetRule(_ context.Context, request domainAutoReply.RuleRequest) (response domainAutoReply.RuleInfo, err error) {
+	rule := autoreply.GetRule(request.ID)
+	if rule == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("auto-reply rule %s not found", request.ID))
+	}
+	return toRuleInfo(rule), nil
+}
+
+func (service serviceAutoReply) CreateRule(ctx context.Context, request domainAutoReply.CreateRuleRequest) (response domainAutoReply.RuleInfo, err error) {
+	if err = validations.ValidateCreateRule(ctx, &request); err != nil {
+		return response, err
+	}
+	if err = service.checkReferences(ctx, request); err != nil {
+		return response, err
+	}
+
+	rule := &domainAutoReply.Rule{ID: uuid.NewString()}
+	applyRuleRequest(ctx, rule, request)
+	if err = service.autoReplyRepo.CreateRule(ctx, rule); err != nil {
+		return response, err
+	}
+	if err = autoreply.ReloadRules(ctx); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Auto-reply rule %s (%s) created", rule.Name, rule.ID)
+	return toRuleInfo(rule), nil
+}
+
+func (service serviceAutoReply) UpdateRule(ctx context.Context, request domainAutoReply.UpdateRuleRequest) (response domainAutoReply.RuleInfo, err error) {
+	if err = validations.ValidateUpdateRule(ctx, &request); err != nil {
+		return response, err
+	}
+
+	rule, err := service.storedRule(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	if err = service.checkReferences(ctx, request.CreateRuleRequest); err != nil {
+		return response, err
+	}
+
+	applyRuleRequest(ctx, rule, request.CreateRuleRequest)
+	if err = service.autoReplyRepo.UpdateRule(ctx, rule); err != nil {
+		return response, err
+	}
+	if err = autoreply.ReloadRules(ctx); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Auto-reply rule %s (%s) updated", rule.Name, rule.ID)
+	return toRuleInfo(rule), nil
+}
+
+func (service serviceAutoReply) DeleteRule(ctx context.Context, request domainAutoReply.RuleRequest) error {
+	if _, err := service.storedRule(ctx, request.ID); err != nil {
+		return err
+	}
+
+	if _, err := service.autoReplyRepo.DeleteRule(ctx, request.ID); err != nil {
+		return err
+	}
+	if err := autoreply.ReloadRules(ctx); err != nil {
+		return err
+	}
+
+	logrus.Infof("Auto-reply rule %s deleted", request.ID)
+	return nil
+}
+
+// storedRule loads a rule that can be changed through the API
+func (service serviceAutoReply) storedRule(ctx context.Context, id string) (*domainAutoReply.Rule, error) {
+	if id == autoreply.LegacyRuleID {
+		return nil, pkgError.ValidationError(fmt.Sprintf("auto-reply rule %s comes from the --autoreply configuration and cannot be changed through the API", id))
+	}
+
+	rule, err := service.autoReplyRepo.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("auto-reply rule %s not found", id))
+	}
+	return rule, nil
+}
+
+// checkReferences rejects a rule naming a device, template or webhook endpoint that does not exist, so the mistake
+// shows now instead of in the logs of the first matching message
+func (service serviceAutoReply) checkReferences(ctx context.Context, request domainAutoReply.CreateRuleRequest) error {
+	if request.DeviceID != "" && !whatsapp.DeviceExists(request.DeviceID) {
+		return pkgError.ValidationError(fmt.Sprintf("device_id: device %s not found.", request.DeviceID))
+	}
+
+	for i, action := range request.Actions {
+		if action.TemplateID != "" {
+			template, err := service.templateRepo.GetTemplate(ctx, action.TemplateID)
+			if err != nil {
+				return err
+			}
+			if template == nil {
+				return pkgError.ValidationError(fmt.Sprintf("actions: action %d: template %s not found.", i+1, action.TemplateID))
+			}
+		}
+		if action.WebhookID != "" && webhook.GetEndpoint(action.WebhookID) == nil {
+			return pkgError.ValidationError(fmt.Sprintf("actions: action %d: webhook endpoint %s not found.", i+1, action.WebhookID))
+		}
+	}
+	return nil
+}
+
+// applyRuleRequest copies the settings of a request onto a rule, replies are sent as the caller
+func applyRuleRequest(ctx context.Context, rule *domainAutoReply.Rule, request domainAutoReply.CreateRuleRequest) {
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+
+	rule.Name = request.Name
+	rule.Enabled = request.Enabled == nil || *request.Enabled
+	rule.Priority = request.Priority
+	rule.DeviceID = strings.TrimSpace(request.DeviceID)
+	rule.Conditions = request.Conditions
+	rule.Actions = request.Actions
+	rule.Cooldown = time.Duration(request.CooldownSeconds) * time.Second
+	rule.Continue = request.Continue
+	rule.Actor = principal.Username
+}
+
+func toRuleInfo(rule *domainAutoReply.Rule) domainAutoReply.RuleInfo {
+	info := domainAutoReply.RuleInfo{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		Enabled:         rule.Enabled,
+		Priority:        rule.Priority,
+		DeviceID:        rule.DeviceID,
+		Conditions:      rule.Conditions,
+		Actions:         rule.Actions,
+		CooldownSeconds: int(rule.Cooldown / time.Second),
+		Continue:        rule.Continue,
+		ReadOnly:        rule.ReadOnly,
+		Actor:           rule.Actor,
+	}
+	if !rule.CreatedAt.IsZero() {
+		info.CreatedAt = rule.CreatedAt.UTC().Format(time.RFC3339)
+		info.UpdatedAt = rule.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	campaignRunner "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/campaign"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types"
+)
+
+type serviceCampaign struct {
+	campaignRepo domainCampaign.ICampaignRepository
+	jobRepo      domainSend.ISendJobRepository
+	groupService domainGroup.IGroupUsecase
+}
+
+func NewCampaignService(campaignRepo domainCampaign.ICampaignRepository, jobRepo domainSend.ISendJobRepository, groupService domainGroup.IGroupUsecase) domainCampaign.ICampaignUsecase {
+	return &serviceCampaign{
+		campaignRepo: campaignRepo,
+		jobRepo:      jobRepo,
+		groupService: groupService,
+	}
+}
+
+func (service serviceCampaign) CreateCampaign(ctx context.Context, request domainCampaign.CreateCampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	if err = validations.ValidateCreateCampaign(ctx, &request); err != nil {
+		return response, err
+	}
+
+	recipients, err := service.collectRecipients(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	if err = validations.ValidateCampaignRecipients(request.Message, recipients); err != nil {
+		return response, err
+	}
+
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	campaign := &domainCampaign.Campaign{
+		ID:        uuid.NewString(),
+		DeviceID:  whatsapp.DeviceIDFromContext(ctx),
+		Actor:     principal.Username,
+		Name:      request.Name,
+		Message:   request.Message,
+		MediaType: request.MediaType,
+		MediaURL:  request.MediaURL,
+		Rate:      request.Rate,
+		Status:    domainCampaign.StatusDraft,
+	}
+	if request.Start {
+		startedAt := time.Now().UTC()
+		campaign.Status, campaign.StartedAt = domainCampaign.StatusRunning, &startedAt
+	}
+
+	stored := make([]*domainCampaign.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		stored = append(stored, &domainCampaign.Recipient{Phone: recipient.Phone, Variables: recipient.Variables})
+	}
+	if err = service.campaignRepo.CreateCampaign(ctx, campaign, stored); err != nil {
+		logrus.WithError(err).Error("Failed to create campaign")
+		return response, pkgError.InternalServerError(fmt.Sprintf("failed to create campaign: %v", err))
+	}
+
+	logrus.Infof("Campaign %s created with %d recipients, status %s", campaign.ID, campaign.Recipients, campaign.Status)
+	if request.Start {
+		campaignRunner.Wakeup()
+	}
+	return toCampaignInfo(campaign), nil
+}
+
+// collectRecipients merges the recipients of the request, the CSV and the group. A phone number listed more than
+// once keeps the first entry.
+func (service serviceCampaign) collectRecipients(ctx context.Context, request domainCampaign.CreateCampaignRequest) ([]domainCampaign.RecipientRequest, error) {
+	recipients := request.Recipients
+
+	if request.RecipientsCSV != nil {
+		file, err := request.RecipientsCSV.Open()
+		if err != nil {
+			return nil, pkgError.ValidationError(fmt.Sprintf("failed to open recipients_csv: %v", err))
+		}
+		defer file.Close()
+
+		imported, err := domainCampaign.ParseRecipientsCSV(file)
+		if err != nil {
+			return nil, pkgError.ValidationError(fmt.Sprintf("recipients_csv: %v", err))
+		}
+		recipients = append(recipients, imported...)
+	}
+
+	if request.GroupID != "" {
+		group, err := service.groupService.GetGroupParticipants(ctx, domainGroup.GetGroupParticipantsRequest{GroupID: request.GroupID})
+		if err != nil {
+			return nil, err
+		}
+
+		var own string
+		if client := whatsapp.ClientFromContext(ctx); client != nil && client.Store.ID != nil {
+			own = client.Store.ID.User
+		}
+		for _, participant := range group.Participants {
+			phone := participantPhone(participant)
+			if phone != "" && phone != own {
+				recipients = append(recipients, domainCampaign.RecipientRequest{Phone: phone})
+			}
+		}
+	}
+
+	unique := make([]domainCampaign.RecipientRequest, 0, len(recipients))
+	seen := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		recipient.Phone = strings.TrimPrefix(strings.TrimSpace(recipient.Phone), "+")
+		if seen[recipient.Phone] {
+			continue
+		}
+		seen[recipient.Phone] = true
+		unique = append(unique, recipient)
+	}
+	return unique, nil
+}
+
+// participantPhone returns the phone number of a group participant, groups with hidden numbers only have it in
+// PhoneNumber
+func participantPhone(participant domainGroup.GroupParticipant) string {
+	for _, candidate := range []string{participant.PhoneNumber, participant.JID} {
+		jid, err := types.ParseJID(candidate)
+		if err == nil && jid.Server == types.DefaultUserServer && jid.User != "" {
+			return jid.User
+		}
+	}
+	return ""
+}
+
+func (service serviceCampaign) ListCampaigns(ctx context.Context, request domainCampaign.ListCampaignsRequest) (response domainCampaign.ListCampaignsResponse, err error) {
+	if err = validations.ValidateListCampaigns(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter := &domainCampaign.CampaignFilter{
+		Status: request.Status,
+		Actor:  request.Actor,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+
+	campaigns, err := service.campaignRepo.GetCampaigns(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get campaigns")
+		return response, err
+	}
+
+	totalCount, err := service.campaignRepo.CountCampaigns(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count campaigns")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainCampaign.CampaignInfo, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		response.Data = append(response.Data, toCampaignInfo(campaign))
+	}
+	response.Pagination = domainCampaign.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceCampaign) GetCampaign(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	campaign, err := service.storedCampaign(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	return toCampaignInfo(campaign), nil
+}
+
+func (service serviceCampaign) ListRecipients(ctx context.Context, request domainCampaign.ListRecipientsRequest) (response domainCampaign.ListRecipientsResponse, err error) {
+	if err = validations.ValidateListCampaignRecipients(ctx, &request); err != nil {
+		return response, err
+	}
+	if _, err = service.storedCampaign(ctx, request.ID); err != nil {
+		return response, err
+	}
+
+	filter := &domainCampaign.RecipientFilter{
+		Status: request.Status,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+
+	recipients, err := service.campaignRepo.GetRecipients(ctx, request.ID, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get campaign recipients")
+		return response, err
+	}
+
+	totalCount, err := service.campaignRepo.CountRecipients(ctx, request.ID, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count campaign recipients")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainCampaign.RecipientInfo, 0, len(recipients))
+	for _, recipient := range recipients {
+		response.Data = append(response.Data, toRecipientInfo(recipient))
+	}
+	response.Pagination = domainCampaign.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceCampaign) GetReport(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.ReportResponse, err error) {
+	campaign, err := service.storedCampaign(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	counts, err := service.campaignRepo.CountRecipientsByStatus(ctx, campaign.ID)
+	if err != nil {
+		return response, err
+	}
+
+	response.Campaign = toCampaignInfo(campaign)
+	response.Statuses = make(map[string]int, len(domainCampaign.RecipientStatuses))
+	for _, status := range domainCampaign.RecipientStatuses {
+		response.Statuses[status] = counts[status]
+		response.Total += counts[status]
+	}
+	response.Read = counts[domainCampaign.RecipientRead]
+	response.Delivered = counts[domainCampaign.RecipientDelivered] + response.Read
+	response.Sent = counts[domainCampaign.RecipientSent] + response.Delivered
+	response.Failed = counts[domainCampaign.RecipientFailed]
+	if response.Sent > 0 {
+		response.DeliveryRate = roundRate(response.Delivered, response.Sent)
+		response.ReadRate = roundRate(response.Read, response.Sent)
+	}
+
+	return response, nil
+}
+
+func (service serviceCampaign) StartCampaign(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	return service.transition(ctx, request.ID, "started", domainCampaign.StatusRunning, domainCampaign.StatusDraft)
+}
+
+func (service serviceCampaign) PauseCampaign(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	return service.transition(ctx, request.ID, "paused", domainCampaign.StatusPaused, domainCampaign.StatusRunning)
+}
+
+func (service serviceCampaign) ResumeCampaign(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	return service.transition(ctx, request.ID, "resumed", domainCampaign.StatusRunning, domainCampaign.StatusPaused)
+}
+
+func (service serviceCampaign) CancelCampaign(ctx context.Context, request domainCampaign.CampaignRequest) (response domainCampaign.CampaignInfo, err error) {
+	response, err = service.transition(ctx, request.ID, "cancelled", domainCampaign.StatusCancelled,
+		domainCampaign.StatusDraft, domainCampaign.StatusRunning, domainCampaign.StatusPaused)
+	if err != nil {
+		return response, err
+	}
+
+	skipped, err := service.campaignRepo.SkipPendingRecipients(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	// Messages still waiting in the send queue are cancelled too, the runner skips their recipients
+	queued, err := service.campaignRepo.GetRecipients(ctx, request.ID, &domainCampaign.RecipientFilter{Status: domainCampaign.RecipientQueued})
+	if err != nil {
+		return response, err
+	}
+	for _, recipient := range queued {
+		if _, err := service.jobRepo.CancelJob(ctx, recipient.JobID); err != nil {
+			logrus.Errorf("Failed to cancel send job %s of campaign %s: %v", recipient.JobID, request.ID, err)
+		}
+	}
+
+	logrus.Infof("Campaign %s cancelled, %d recipients skipped", request.ID, skipped)
+	return response, nil
+}
+
+// transition changes the status of a campaign that has one of the from statuses and wakes the runner up
+func (service serviceCampaign) transition(ctx context.Context, id, action, status string, from ...string) (response domainCampaign.CampaignInfo, err error) {
+	campaign, err := service.storedCampaign(ctx, id)
+	if err != nil {
+		return response, err
+	}
+
+	changed, err := service.campaignRepo.SetCampaignStatus(ctx, campaign.ID, status, from...)
+	if err != nil {
+		return response, err
+	}
+	if !changed {
+		// The runner or another request changed it in the meantime, report the current status
+		if campaign, err = service.storedCampaign(ctx, campaign.ID); err != nil {
+			return response, err
+		}
+		return response, pkgError.ConflictError(fmt.Sprintf("campaign %s is %s and can not be %s", campaign.ID, campaign.Status, action))
+	}
+
+	logrus.Infof("Campaign %s %s", campaign.ID, action)
+	campaignRunner.Wakeup()
+	return service.GetCampaign(ctx, domainCampaign.CampaignRequest{ID: campaign.ID})
+}
+
+func (service serviceCampaign) storedCampaign(ctx context.Context, id string) (*domainCampaign.Campaign, error) {
+	campaign, err := service.campaignRepo.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("campaign %s not found", id))
+	}
+	return campaign, nil
+}
+
+// roundRate returns part / total rounded to four decimals
+func roundRate(part, total int) float64 {
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+func toCampaignInfo(campaign *domainCampaign.Campaign) domainCampaign.CampaignInfo {
+	info := domainCampaign.CampaignInfo{
+		ID:         campaign.ID,
+		DeviceID:   campaign.DeviceID,
+		Actor:      campaign.Actor,
+		Name:       campaign.Name,
+		Message:    campaign.Message,
+		MediaType:  campaign.MediaType,
+		MediaURL:   campaign.MediaURL,
+		Rate:       campaign.Rate,
+		Status:     campaign.Status,
+		Recipients: campaign.Recipients,
+		CreatedAt:  campaign.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:  campaign.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if info.Rate == "" {
+		info.Rate = config.WhatsappCampaignRate
+	}
+	if campaign.StartedAt != nil {
+		info.StartedAt = campaign.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if campaign.CompletedAt != nil {
+		info.CompletedAt = campaign.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+func toRecipientInfo(recipient *domainCampaign.Recipient) domainCampaign.RecipientInfo {
+	info := domainCampaign.RecipientInfo{
+		Phone:     recipient.Phone,
+		Variables: recipient.Variables,
+		Status:    recipient.Status,
+		MessageID: recipient.MessageID,
+		JobID:     recipient.JobID,
+		Error:     recipient.Error,
+	}
+	if recipient.SentAt != nil {
+		info.SentAt = recipient.SentAt.UTC().Format(time.RFC3339)
+	}
+	if recipient.DeliveredAt != nil {
+		info.DeliveredAt = recipient.DeliveredAt.UTC().Format(time.RFC3339)
+	}
+	if recipient.ReadAt != nil {
+		info.ReadAt = recipient.ReadAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
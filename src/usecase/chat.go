@@ -29,6 +29,11 @@ func (service serviceChat) ListChats(ctx context.Context, request domainChat.Lis
 		return response, err
 	}
 
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return response, err
+	}
+
 	// Create filter from request
 	filter := &domainChatStorage.ChatFilter{
 		Limit:      request.Limit,
@@ -38,14 +43,14 @@ func (service serviceChat) ListChats(ctx context.Context, request domainChat.Lis
 	}
 
 	// Get chats from storage
-	chats, err := service.chatStorageRepo.GetChats(filter)
+	chats, err := chatStorageRepo.GetChats(filter)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get chats from storage")
 		return response, err
 	}
 
 	// Get total count for pagination
-	totalCount, err := service.chatStorageRepo.GetTotalChatCount()
+	totalCount, err := chatStorageRepo.GetTotalChatCount()
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get total chat count")
 		// Continue with partial data
@@ -90,8 +95,13 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 		return response, err
 	}
 
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return response, err
+	}
+
 	// Get chat info first
-	chat, err := service.chatStorageRepo.GetChat(request.ChatJID)
+	chat, err := chatStorageRepo.GetChat(request.ChatJID)
 	if err != nil {
 		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get chat info")
 		return response, err
@@ -130,14 +140,14 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 	var messages []*domainChatStorage.Message
 	if request.Search != "" {
 		// Use search functionality if search query is provided
-		messages, err = service.chatStorageRepo.SearchMessages(request.ChatJID, request.Search, request.Limit)
+		messages, err = chatStorageRepo.SearchMessages(request.ChatJID, request.Search, request.Limit)
 		if err != nil {
 			logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to search messages")
 			return response, err
 		}
 	} else {
 		// Use regular filter
-		messages, err = service.chatStorageRepo.GetMessages(filter)
+		messages, err = chatStorageRepo.GetMessages(filter)
 		if err != nil {
 			logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get messages")
 			return response, err
@@ -145,13 +155,57 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 	}
 
 	// Get total message count for pagination
-	totalCount, err := service.chatStorageRepo.GetChatMessageCount(request.ChatJID)
+	totalCount, err := chatStorageRepo.GetChatMessageCount(request.ChatJID)
 	if err != nil {
 		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get message count")
 		// Continue with partial data
 		totalCount = 0
 	}
 
+	// Receipts of our own messages, for their delivery state
+	var messageIDs, sentIDs, editedIDs []string
+	for _, message := range messages {
+		messageIDs = append(messageIDs, message.ID)
+		if message.IsFromMe {
+			sentIDs = append(sentIDs, message.ID)
+		}
+		if message.EditedAt != nil {
+			editedIDs = append(editedIDs, message.ID)
+		}
+	}
+	receipts, err := chatStorageRepo.GetReceipts(request.ChatJID, sentIDs...)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get message receipts")
+		return response, err
+	}
+	receiptsByMessage := make(map[string][]*domainChatStorage.Receipt)
+	for _, receipt := range receipts {
+		receiptsByMessage[receipt.MessageID] = append(receiptsByMessage[receipt.MessageID], receipt)
+	}
+
+	reactions, err := chatStorageRepo.GetReactions(request.ChatJID, messageIDs...)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get message reactions")
+		return response, err
+	}
+	reactionsByMessage := make(map[string][]*domainChatStorage.Reaction)
+	for _, reaction := range reactions {
+		reactionsByMessage[reaction.MessageID] = append(reactionsByMessage[reaction.MessageID], reaction)
+	}
+
+	edits, err := chatStorageRepo.GetMessageEdits(request.ChatJID, editedIDs...)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get message edits")
+		return response, err
+	}
+	editsByMessage := make(map[string][]domainChat.MessageEdit)
+	for _, edit := range edits {
+		editsByMessage[edit.MessageID] = append(editsByMessage[edit.MessageID], domainChat.MessageEdit{
+			Content:  edit.Content,
+			EditedAt: edit.EditedAt.Format(time.RFC3339),
+		})
+	}
+
 	// Convert entities to domain objects
 	messageInfos := make([]domainChat.MessageInfo, 0, len(messages))
 	for _, message := range messages {
@@ -169,6 +223,18 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 			CreatedAt:  message.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:  message.UpdatedAt.Format(time.RFC3339),
 		}
+		if message.IsFromMe {
+			messageInfo.DeliveryState = domainChatStorage.DeliveryState(receiptsByMessage[message.ID])
+		}
+		messageInfo.Reactions = aggregateReactions(reactionsByMessage[message.ID])
+		if message.EditedAt != nil {
+			messageInfo.EditedAt = message.EditedAt.Format(time.RFC3339)
+			messageInfo.Edits = editsByMessage[message.ID]
+		}
+		if message.RevokedAt != nil {
+			messageInfo.IsRevoked = true
+			messageInfo.RevokedAt = message.RevokedAt.Format(time.RFC3339)
+		}
 		messageInfos = append(messageInfos, messageInfo)
 	}
 
@@ -209,7 +275,7 @@ func (service serviceChat) PinChat(ctx context.Context, request domainChat.PinCh
 	}
 
 	// Validate JID and ensure connection
-	targetJID, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.ChatJID)
+	targetJID, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.ChatJID)
 	if err != nil {
 		return response, err
 	}
@@ -218,7 +284,7 @@ func (service serviceChat) PinChat(ctx context.Context, request domainChat.PinCh
 	patchInfo := appstate.BuildPin(targetJID, request.Pinned)
 
 	// Send app state update
-	if err = whatsapp.GetClient().SendAppState(ctx, patchInfo); err != nil {
+	if err = whatsapp.ClientFromContext(ctx).SendAppState(ctx, patchInfo); err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"chat_jid": request.ChatJID,
 			"pinned":   request.Pinned,
@@ -244,3 +310,20 @@ func (service serviceChat) PinChat(ctx context.Context, request domainChat.PinCh
 
 	return response, nil
 }
+
+// aggregateReactions groups the reactions to a message by emoji, in the order the emojis were first used
+func aggregateReactions(reactions []*domainChatStorage.Reaction) []domainChat.ReactionInfo {
+	var infos []domainChat.ReactionInfo
+	index := make(map[string]int)
+	for _, reaction := range reactions {
+		i, ok := index[reaction.Emoji]
+		if !ok {
+			i = len(infos)
+			index[reaction.Emoji] = i
+			infos = append(infos, domainChat.ReactionInfo{Emoji: reaction.Emoji})
+		}
+		infos[i].Count++
+		infos[i].Senders = append(infos[i].Senders, reaction.Sender)
+	}
+	return infos
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/eventstream"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+)
+
+type serviceEventStream struct{}
+
+func NewEventStreamService() domainEventStream.IEventStreamUsecase {
+	return &serviceEventStream{}
+}
+
+func (service serviceEventStream) Subscribe(ctx context.Context, request domainEventStream.SubscribeRequest) (domainEventStream.Subscription, error) {
+	if err := validations.ValidateSubscribeEvents(ctx, &request); err != nil {
+		return nil, err
+	}
+
+	resume := request.ResumeToken != ""
+	if resume && !eventstream.Retained() {
+		return nil, pkgError.ValidationError("resume_token: events are not kept, set --event-retention to catch up on missed events")
+	}
+	afterID, _ := domainEventStream.ParseResumeToken(request.ResumeToken)
+
+	filter := domainEventStream.Filter{
+		Events:   request.Events,
+		Chats:    request.Chats,
+		DeviceID: request.DeviceID,
+	}
+	return eventstream.Subscribe(filter, afterID, resume), nil
+}
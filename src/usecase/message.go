@@ -11,6 +11,7 @@ import (
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/sirupsen/logrus"
@@ -37,13 +38,21 @@ func (service serviceMessage) MarkAsRead(ctx context.Context, request domainMess
 	if err = validations.ValidateMarkAsRead(ctx, request); err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
+	// In groups the receipt names the sender of the message, which is only known for stored messages
+	sender := *whatsapp.ClientFromContext(ctx).Store.ID
+	if message := service.storedMessage(ctx, request.MessageID); message != nil && !message.IsFromMe {
+		if messageSender, err := types.ParseJID(message.Sender); err == nil {
+			sender = messageSender
+		}
+	}
+
 	ids := []types.MessageID{request.MessageID}
-	if err = whatsapp.GetClient().MarkRead(ids, time.Now(), dataWaRecipient, *whatsapp.GetClient().Store.ID); err != nil {
+	if err = whatsapp.ClientFromContext(ctx).MarkRead(ids, time.Now(), dataWaRecipient, sender); err != nil {
 		return response, err
 	}
 
@@ -51,7 +60,7 @@ func (service serviceMessage) MarkAsRead(ctx context.Context, request domainMess
 		"phone":      request.Phone,
 		"message_id": request.MessageID,
 		"chat":       dataWaRecipient.String(),
-		"sender":     whatsapp.GetClient().Store.ID.String(),
+		"sender":     sender.String(),
 	})
 
 	response.MessageID = request.MessageID
@@ -63,27 +72,54 @@ func (service serviceMessage) ReactMessage(ctx context.Context, request domainMe
 	if err = validations.ValidateReactMessage(ctx, request); err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
+	key := &waCommon.MessageKey{
+		FromMe:    proto.Bool(true),
+		ID:        proto.String(request.MessageID),
+		RemoteJID: proto.String(dataWaRecipient.String()),
+	}
+	// A reaction to a received message must name it as not ours, and in groups name its sender
+	if message := service.storedMessage(ctx, request.MessageID); message != nil && !message.IsFromMe {
+		key.FromMe = proto.Bool(false)
+		if dataWaRecipient.Server != types.DefaultUserServer && dataWaRecipient.Server != types.HiddenUserServer {
+			key.Participant = proto.String(message.Sender)
+		}
+	}
+
+	reactedAt := time.Now()
 	msg := &waE2E.Message{
 		ReactionMessage: &waE2E.ReactionMessage{
-			Key: &waCommon.MessageKey{
-				FromMe:    proto.Bool(true),
-				ID:        proto.String(request.MessageID),
-				RemoteJID: proto.String(dataWaRecipient.String()),
-			},
+			Key:               key,
 			Text:              proto.String(request.Emoji),
-			SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
+			SenderTimestampMS: proto.Int64(reactedAt.UnixMilli()),
 		},
 	}
-	ts, err := whatsapp.GetClient().SendMessage(ctx, dataWaRecipient, msg)
+	ts, err := whatsapp.ClientFromContext(ctx).SendMessage(ctx, dataWaRecipient, msg)
 	if err != nil {
 		return response, err
 	}
 
+	// Keep our reaction next to the ones of the other participants, the reaction was sent either way
+	if chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo); err != nil {
+		logrus.Warnf("Skipping storage of reaction to %s: %v", request.MessageID, err)
+	} else if ownJID := whatsapp.ClientFromContext(ctx).Store.ID; ownJID != nil {
+		reaction := &domainChatStorage.Reaction{
+			MessageID: request.MessageID,
+			ChatJID:   dataWaRecipient.String(),
+			Sender:    ownJID.ToNonAD().String(),
+			Emoji:     request.Emoji,
+			IsFromMe:  true,
+			Timestamp: reactedAt,
+		}
+		if err := chatStorageRepo.StoreReactions([]*domainChatStorage.Reaction{reaction}); err != nil {
+			logrus.Warnf("Failed to store reaction to %s: %v", request.MessageID, err)
+		}
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Reaction sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
@@ -93,16 +129,22 @@ func (service serviceMessage) RevokeMessage(ctx context.Context, request domainM
 	if err = validations.ValidateRevokeMessage(ctx, request); err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
-	ts, err := whatsapp.GetClient().SendMessage(context.Background(), dataWaRecipient, whatsapp.GetClient().BuildRevoke(dataWaRecipient, types.EmptyJID, request.MessageID))
+	ts, err := whatsapp.ClientFromContext(ctx).SendMessage(context.Background(), dataWaRecipient, whatsapp.ClientFromContext(ctx).BuildRevoke(dataWaRecipient, types.EmptyJID, request.MessageID))
 	if err != nil {
 		return response, err
 	}
 
+	if chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo); err != nil {
+		logrus.Warnf("Skipping storage of revoke of %s: %v", request.MessageID, err)
+	} else if err := chatStorageRepo.RevokeMessage(request.MessageID, dataWaRecipient.String(), ts.Timestamp); err != nil {
+		logrus.Warnf("Failed to store revoke of %s: %v", request.MessageID, err)
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Revoke success %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
@@ -112,7 +154,7 @@ func (service serviceMessage) DeleteMessage(ctx context.Context, request domainM
 	if err = validations.ValidateDeleteMessage(ctx, request); err != nil {
 		return err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return err
 	}
@@ -126,7 +168,7 @@ func (service serviceMessage) DeleteMessage(ctx context.Context, request domainM
 		Timestamp: time.Now(),
 		Type:      appstate.WAPatchRegularHigh,
 		Mutations: []appstate.MutationInfo{{
-			Index: []string{appstate.IndexDeleteMessageForMe, dataWaRecipient.String(), request.MessageID, isFromMe, whatsapp.GetClient().Store.ID.String()},
+			Index: []string{appstate.IndexDeleteMessageForMe, dataWaRecipient.String(), request.MessageID, isFromMe, whatsapp.ClientFromContext(ctx).Store.ID.String()},
 			Value: &waSyncAction.SyncActionValue{
 				DeleteMessageForMeAction: &waSyncAction.DeleteMessageForMeAction{
 					DeleteMedia:      proto.Bool(true),
@@ -136,7 +178,7 @@ func (service serviceMessage) DeleteMessage(ctx context.Context, request domainM
 		}},
 	}
 
-	if err = whatsapp.GetClient().SendAppState(ctx, patchInfo); err != nil {
+	if err = whatsapp.ClientFromContext(ctx).SendAppState(ctx, patchInfo); err != nil {
 		return err
 	}
 	return nil
@@ -147,17 +189,24 @@ func (service serviceMessage) UpdateMessage(ctx context.Context, request domainM
 		return response, err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
 	msg := &waE2E.Message{Conversation: proto.String(request.Message)}
-	ts, err := whatsapp.GetClient().SendMessage(context.Background(), dataWaRecipient, whatsapp.GetClient().BuildEdit(dataWaRecipient, request.MessageID, msg))
+	ts, err := whatsapp.ClientFromContext(ctx).SendMessage(context.Background(), dataWaRecipient, whatsapp.ClientFromContext(ctx).BuildEdit(dataWaRecipient, request.MessageID, msg))
 	if err != nil {
 		return response, err
 	}
 
+	// Keep the edit like the edits of the other participants, the message was edited either way
+	if chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo); err != nil {
+		logrus.Warnf("Skipping storage of edit of %s: %v", request.MessageID, err)
+	} else if err := chatStorageRepo.EditMessage(request.MessageID, dataWaRecipient.String(), request.Message, ts.Timestamp); err != nil {
+		logrus.Warnf("Failed to store edit of %s: %v", request.MessageID, err)
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Update message success %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
@@ -169,7 +218,7 @@ func (service serviceMessage) StarMessage(ctx context.Context, request domainMes
 		return err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return err
 	}
@@ -179,9 +228,9 @@ func (service serviceMessage) StarMessage(ctx context.Context, request domainMes
 		isFromMe = false
 	}
 
-	patchInfo := appstate.BuildStar(dataWaRecipient.ToNonAD(), *whatsapp.GetClient().Store.ID, request.MessageID, isFromMe, request.IsStarred)
+	patchInfo := appstate.BuildStar(dataWaRecipient.ToNonAD(), *whatsapp.ClientFromContext(ctx).Store.ID, request.MessageID, isFromMe, request.IsStarred)
 
-	if err = whatsapp.GetClient().SendAppState(ctx, patchInfo); err != nil {
+	if err = whatsapp.ClientFromContext(ctx).SendAppState(ctx, patchInfo); err != nil {
 		return err
 	}
 	return nil
@@ -193,13 +242,18 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 		return response, err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
 	if err != nil {
 		return response, err
 	}
 
 	// Query the message from chat storage
-	message, err := service.chatStorageRepo.GetMessageByID(request.MessageID)
+	message, err := chatStorageRepo.GetMessageByID(request.MessageID)
 	if err != nil {
 		return response, fmt.Errorf("message not found: %v", err)
 	}
@@ -277,7 +331,7 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 	}
 
 	// Download the media using existing utils.ExtractMedia function
-	extractedMedia, err := utils.ExtractMedia(ctx, whatsapp.GetClient(), dateDir, downloadableMsg.(whatsmeow.DownloadableMessage))
+	extractedMedia, err := utils.ExtractMedia(ctx, whatsapp.ClientFromContext(ctx), dateDir, downloadableMsg.(whatsmeow.DownloadableMessage))
 	if err != nil {
 		return response, fmt.Errorf("failed to download media: %v", err)
 	}
@@ -309,3 +363,126 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 
 	return response, nil
 }
+
+func (service serviceMessage) GetMessageStatus(ctx context.Context, request domainMessage.MessageStatusRequest) (response domainMessage.MessageStatusResponse, err error) {
+	if err = validations.ValidateMessageStatus(ctx, request); err != nil {
+		return response, err
+	}
+
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return response, err
+	}
+
+	chatJID := dataWaRecipient.String()
+	receipts, err := chatStorageRepo.GetReceipts(chatJID, request.MessageID)
+	if err != nil {
+		return response, fmt.Errorf("failed to get receipts: %v", err)
+	}
+
+	// Receipts can arrive for messages sent before the chat storage kept them
+	if len(receipts) == 0 {
+		message, err := chatStorageRepo.GetMessageByID(request.MessageID)
+		if err != nil {
+			return response, fmt.Errorf("message not found: %v", err)
+		}
+		if message == nil || message.ChatJID != chatJID {
+			return response, pkgError.NotFoundError(fmt.Sprintf("message %s not found in chat %s", request.MessageID, chatJID))
+		}
+	}
+
+	response.MessageID = request.MessageID
+	response.ChatJID = chatJID
+	response.State = domainChatStorage.DeliveryState(receipts)
+	response.Receipts = make([]domainMessage.MessageReceipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		response.Receipts = append(response.Receipts, domainMessage.MessageReceipt{
+			ParticipantJID: receipt.Participant,
+			State:          receipt.State(),
+			DeliveredAt:    formatReceiptTime(receipt.DeliveredAt),
+			ReadAt:         formatReceiptTime(receipt.ReadAt),
+			PlayedAt:       formatReceiptTime(receipt.PlayedAt),
+		})
+	}
+
+	return response, nil
+}
+
+func (service serviceMessage) GetPollResults(ctx context.Context, request domainMessage.PollResultsRequest) (response domainMessage.PollResultsResponse, err error) {
+	if err = validations.ValidatePollResults(ctx, request); err != nil {
+		return response, err
+	}
+
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return response, err
+	}
+
+	chatJID := dataWaRecipient.String()
+	poll, err := chatStorageRepo.GetPoll(chatJID, request.MessageID)
+	if err != nil {
+		return response, fmt.Errorf("failed to get poll: %v", err)
+	}
+	if poll == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("poll %s not found in chat %s", request.MessageID, chatJID))
+	}
+
+	votes, err := chatStorageRepo.GetPollVotes(chatJID, request.MessageID)
+	if err != nil {
+		return response, fmt.Errorf("failed to get poll votes: %v", err)
+	}
+
+	response.MessageID = poll.MessageID
+	response.ChatJID = poll.ChatJID
+	response.Question = poll.Question
+	response.SelectableCount = poll.SelectableCount
+	response.Options = make([]domainMessage.PollResult, 0, len(poll.Options))
+	for i, voters := range poll.Voters(votes) {
+		if voters == nil {
+			voters = []string{}
+		}
+		response.Options = append(response.Options, domainMessage.PollResult{
+			Name:   poll.Options[i].Name,
+			Count:  len(voters),
+			Voters: voters,
+		})
+	}
+	for _, vote := range votes {
+		if len(vote.Options) > 0 {
+			response.TotalVoters++
+		}
+	}
+
+	return response, nil
+}
+
+// formatReceiptTime formats the time a recipient reached a state, empty when it did not
+// storedMessage looks up a message in the chat storage of the device selected in the context, nil when it is not stored
+func (service serviceMessage) storedMessage(ctx context.Context, messageID string) *domainChatStorage.Message {
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return nil
+	}
+	message, err := chatStorageRepo.GetMessageByID(messageID)
+	if err != nil {
+		return nil
+	}
+	return message
+}
+
+func formatReceiptTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -20,10 +20,10 @@ func (service serviceNewsletter) Unfollow(ctx context.Context, request domainNew
 		return err
 	}
 
-	JID, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.NewsletterID)
+	JID, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.NewsletterID)
 	if err != nil {
 		return err
 	}
 
-	return whatsapp.GetClient().UnfollowNewsletter(JID)
+	return whatsapp.ClientFromContext(ctx).UnfollowNewsletter(JID)
 }
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/ratelimit"
+)
+
+type serviceRateLimit struct {
+	rateLimitRepo domainRateLimit.IRateLimitRepository
+	userRepo      domainAuth.IUserRepository
+}
+
+func NewRateLimitService(rateLimitRepo domainRateLimit.IRateLimitRepository, userRepo domainAuth.IUserRepository) domainRateLimit.IRateLimitUsecase {
+	return &serviceRateLimit{
+		rateLimitRepo: rateLimitRepo,
+		userRepo:      userRepo,
+	}
+}
+
+func (service serviceRateLimit) GetUsage(ctx context.Context, request domainRateLimit.UsageRequest) (response domainRateLimit.UsageResponse, err error) {
+	now := time.Now().UTC()
+	response.Limits = make(map[string]string)
+	for class, limit := range ratelimit.ClassLimits() {
+		response.Limits[class] = limit.String()
+	}
+	if limit := ratelimit.RecipientLimit(); limit != nil {
+		response.RecipientLimit = limit.String()
+	}
+	response.DailyQuota = config.AppSendQuotaDaily
+	response.MonthlyQuota = config.AppSendQuotaMonthly
+
+	subjects := make(map[string]*domainRateLimit.SubjectUsage)
+	subjectUsage := func(subject string) *domainRateLimit.SubjectUsage {
+		if usage, ok := subjects[subject]; ok {
+			return usage
+		}
+		usage := &domainRateLimit.SubjectUsage{Subject: subject, Buckets: []domainRateLimit.BucketInfo{}}
+		subjects[subject] = usage
+		return usage
+	}
+
+	day, _ := domainRateLimit.Period(domainRateLimit.PeriodDay, now)
+	usages, err := service.rateLimitRepo.GetUsages(ctx, "", day)
+	if err != nil {
+		return response, err
+	}
+	for _, usage := range usages {
+		subjectUsage(usage.Subject).DailySends = usage.Count
+	}
+
+	month, _ := domainRateLimit.Period(domainRateLimit.PeriodMonth, now)
+	if usages, err = service.rateLimitRepo.GetUsages(ctx, "", month); err != nil {
+		return response, err
+	}
+	for _, usage := range usages {
+		subjectUsage(usage.Subject).MonthlySends = usage.Count
+	}
+
+	buckets, err := service.rateLimitRepo.GetBuckets(ctx, "")
+	if err != nil {
+		return response, err
+	}
+	for _, bucket := range buckets {
+		limit, ok := ratelimit.ClassLimit(bucket.Class)
+		if !ok {
+			continue
+		}
+		usage := subjectUsage(bucket.Subject)
+		usage.Buckets = append(usage.Buckets, domainRateLimit.BucketInfo{
+			Class:     bucket.Class,
+			Limit:     limit.String(),
+			Remaining: math.Floor(bucket.Refill(limit, now)*100) / 100,
+		})
+	}
+
+	response.Subjects = make([]domainRateLimit.SubjectUsage, 0, len(subjects))
+	for _, usage := range subjects {
+		if err = service.resolveSubject(ctx, usage); err != nil {
+			return response, err
+		}
+		if request.Username != "" && usage.Username != request.Username {
+			continue
+		}
+		response.Subjects = append(response.Subjects, *usage)
+	}
+	sort.Slice(response.Subjects, func(i, j int) bool {
+		return response.Subjects[i].Subject < response.Subjects[j].Subject
+	})
+
+	return response, nil
+}
+
+// resolveSubject fills in the user, and API key, a subject is counted for
+func (service serviceRateLimit) resolveSubject(ctx context.Context, usage *domainRateLimit.SubjectUsage) error {
+	if username, ok := strings.CutPrefix(usage.Subject, "user:"); ok {
+		usage.Username = username
+		return nil
+	}
+
+	id, ok := strings.CutPrefix(usage.Subject, "key:")
+	if !ok {
+		return nil
+	}
+	usage.APIKeyID = id
+	key, err := service.userRepo.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		usage.Username = key.Username
+		usage.APIKeyName = key.Name
+	}
+	return nil
+}
@@ -14,8 +14,11 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/sendqueue"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
@@ -23,6 +26,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/disintegration/imaging"
 	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 	"go.mau.fi/whatsmeow"
@@ -34,26 +38,101 @@ import (
 type serviceSend struct {
 	appService      app.IAppUsecase
 	chatStorageRepo domainChatStorage.IChatStorageRepository
+	templateRepo    domainTemplate.ITemplateRepository
 }
 
-func NewSendService(appService app.IAppUsecase, chatStorageRepo domainChatStorage.IChatStorageRepository) domainSend.ISendUsecase {
+func NewSendService(appService app.IAppUsecase, chatStorageRepo domainChatStorage.IChatStorageRepository, templateRepo domainTemplate.ITemplateRepository) domainSend.ISendUsecase {
 	return &serviceSend{
 		appService:      appService,
 		chatStorageRepo: chatStorageRepo,
+		templateRepo:    templateRepo,
 	}
 }
 
-// wrapSendMessage wraps the message sending process with message ID saving
-func (service serviceSend) wrapSendMessage(ctx context.Context, recipient types.JID, msg *waE2E.Message, content string) (whatsmeow.SendResponse, error) {
-	ts, err := whatsapp.GetClient().SendMessage(ctx, recipient, msg)
+// renderedTemplate is the template of a send request rendered with the variables of the request
+type renderedTemplate struct {
+	*domainTemplate.Version
+	Name string
+	Text string
+}
+
+// renderSendTemplate renders the template of a send request, nil when the request has none. The rendered text
+// replaces the text field of the request, so the request must leave it empty. Rendering errors are validation
+// errors and nothing is sent.
+func (service serviceSend) renderSendTemplate(ctx context.Context, request domainSend.BaseRequest, textField, text string) (*renderedTemplate, error) {
+	if request.TemplateID == "" {
+		return nil, nil
+	}
+	if text != "" {
+		return nil, pkgError.ValidationError(fmt.Sprintf("%s and template_id can not be used together", textField))
+	}
+
+	template, err := service.templateRepo.GetTemplate(ctx, request.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, pkgError.ValidationError(fmt.Sprintf("template %s not found", request.TemplateID))
+	}
+
+	version, rendered, err := renderTemplateVersion(ctx, service.templateRepo, template, request.TemplateVersion, request.Variables)
 	if err != nil {
-		return whatsmeow.SendResponse{}, err
+		return nil, err
+	}
+	return &renderedTemplate{Version: version, Name: template.Name, Text: rendered}, nil
+}
+
+// mediaURL returns the media of the template for a send of the media type. The template can not be sent with
+// a different type of media, a template without media leaves the media to the request.
+func (rendered *renderedTemplate) mediaURL(mediaType string) (*string, error) {
+	if rendered.MediaType == "" {
+		return nil, nil
+	}
+	if rendered.MediaType != mediaType {
+		return nil, pkgError.ValidationError(fmt.Sprintf("template %s has %s media and can not be sent as %s", rendered.Name, rendered.MediaType, mediaType))
+	}
+	return &rendered.MediaURL, nil
+}
+
+// withTemplate records the template version a response was rendered from
+func withTemplate(response domainSend.GenericResponse, rendered *renderedTemplate) domainSend.GenericResponse {
+	if rendered != nil {
+		response.TemplateID = rendered.TemplateID
+		response.TemplateVersion = rendered.Version.Version
+	}
+	return response
+}
+
+// sendResult is the outcome of wrapSendMessage. A queued message has a job and no server timestamp yet.
+type sendResult struct {
+	whatsmeow.SendResponse
+	Job       *domainSend.Job
+	Duplicate bool // the Idempotency-Key matched a job queued before
+}
+
+// wrapSendMessage wraps the message sending process with message ID saving.
+// Scheduled messages, and every message with the send queue enabled, are queued instead and sent by the queue worker.
+func (service serviceSend) wrapSendMessage(ctx context.Context, recipient types.JID, msg *waE2E.Message, content, sendAt string) (sendResult, error) {
+	if sendAt != "" || sendqueue.Enabled() {
+		return service.enqueueMessage(ctx, recipient, msg, content, sendAt)
+	}
+
+	ts, err := whatsapp.ClientFromContext(ctx).SendMessage(ctx, recipient, msg)
+	if err != nil {
+		return sendResult{}, err
 	}
 
 	// Store the sent message using chatstorage
 	senderJID := ""
-	if whatsapp.GetClient().Store.ID != nil {
-		senderJID = whatsapp.GetClient().Store.ID.String()
+	ownJID := whatsapp.ClientFromContext(ctx).Store.ID
+	if ownJID != nil {
+		senderJID = ownJID.String()
+	}
+
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		logrus.Warnf("Skipping storage of sent message %s: %v", ts.ID, err)
+		return sendResult{SendResponse: ts}, nil
 	}
 
 	// Store message asynchronously with timeout
@@ -62,24 +141,106 @@ func (service serviceSend) wrapSendMessage(ctx context.Context, recipient types.
 		storeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 
-		if err := service.chatStorageRepo.StoreSentMessageWithContext(storeCtx, ts.ID, senderJID, recipient.String(), content, ts.Timestamp); err != nil {
+		if err := chatStorageRepo.StoreSentMessageWithContext(storeCtx, ts.ID, senderJID, recipient.String(), content, ts.Timestamp); err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				logrus.Warn("Timeout storing sent message")
 			} else {
 				logrus.Warnf("Failed to store sent message: %v", err)
 			}
 		}
+		if ownJID != nil {
+			if err := whatsapp.StorePoll(chatStorageRepo, ts.ID, recipient, *ownJID, msg, ts.Timestamp); err != nil {
+				logrus.Warnf("Failed to store sent poll %s: %v", ts.ID, err)
+			}
+		}
 	}()
 
-	return ts, nil
+	return sendResult{SendResponse: ts}, nil
+}
+
+// enqueueMessage stores the prepared message in the send queue, due at sendAt when it is set. The message ID is
+// generated up front, so the caller gets it right away and a retry of the worker reuses it.
+func (service serviceSend) enqueueMessage(ctx context.Context, recipient types.JID, msg *waE2E.Message, content, sendAt string) (sendResult, error) {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return sendResult{}, pkgError.InternalServerError(fmt.Sprintf("failed to encode message: %v", err))
+	}
+
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	job := &domainSend.Job{
+		ID:             uuid.NewString(),
+		DeviceID:       whatsapp.DeviceIDFromContext(ctx),
+		Actor:          principal.Username,
+		IdempotencyKey: domainSend.IdempotencyKeyFromContext(ctx),
+		Recipient:      recipient.String(),
+		Message:        body,
+		Content:        content,
+		MessageID:      whatsapp.ClientFromContext(ctx).GenerateMessageID(),
+	}
+	if sendAt != "" {
+		// Validated by the request validation
+		scheduledAt, _ := time.Parse(time.RFC3339, sendAt)
+		scheduledAt = scheduledAt.UTC()
+		job.ScheduledAt = &scheduledAt
+		job.NextAttemptAt = scheduledAt
+	}
+
+	job, created, err := sendqueue.Enqueue(ctx, job)
+	if err != nil {
+		return sendResult{}, err
+	}
+	if !created {
+		domainSend.MarkIdempotentReplay(ctx)
+	}
+
+	return sendResult{SendResponse: whatsmeow.SendResponse{ID: job.MessageID}, Job: job, Duplicate: !created}, nil
+}
+
+// queuedResponse is the response of a send that was queued instead of sent right away
+func queuedResponse(result sendResult) domainSend.GenericResponse {
+	status := fmt.Sprintf("Message to %s queued as job %s", result.Job.Recipient, result.Job.ID)
+	if result.Job.ScheduledAt != nil {
+		status = fmt.Sprintf("Message to %s scheduled for %s as job %s", result.Job.Recipient, result.Job.ScheduledAt.UTC().Format(time.RFC3339), result.Job.ID)
+	}
+	if result.Duplicate {
+		status = fmt.Sprintf("Message to %s was already queued as job %s, status %s", result.Job.Recipient, result.Job.ID, result.Job.Status)
+	}
+	return domainSend.GenericResponse{
+		MessageID: result.Job.MessageID,
+		Status:    status,
+		JobID:     result.Job.ID,
+	}
 }
 
 func (service serviceSend) SendText(ctx context.Context, request domainSend.MessageRequest) (response domainSend.GenericResponse, err error) {
+	rendered, err := service.renderSendTemplate(ctx, request.BaseRequest, "message", request.Message)
+	if err != nil {
+		return response, err
+	}
+	if rendered != nil {
+		// A template with media is sent as that media with the text as caption
+		base := request.BaseRequest
+		base.TemplateID = ""
+		switch rendered.MediaType {
+		case domainTemplate.MediaImage:
+			response, err = service.SendImage(ctx, domainSend.ImageRequest{BaseRequest: base, Caption: rendered.Text, ImageURL: &rendered.MediaURL, Compress: true})
+		case domainTemplate.MediaVideo:
+			response, err = service.SendVideo(ctx, domainSend.VideoRequest{BaseRequest: base, Caption: rendered.Text, VideoURL: &rendered.MediaURL})
+		}
+		if rendered.MediaType != "" {
+			if err != nil {
+				return response, err
+			}
+			return withTemplate(response, rendered), nil
+		}
+		request.Message = rendered.Text
+	}
+
 	err = validations.ValidateSendMessage(ctx, request)
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -102,7 +263,7 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 	if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
 		msg.ExtendedTextMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	} else {
-		msg.ExtendedTextMessage.ContextInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(request.BaseRequest.Phone))
+		msg.ExtendedTextMessage.ContextInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(ctx, request.BaseRequest.Phone))
 	}
 
 	parsedMentions := service.getMentionFromText(ctx, request.Message)
@@ -112,7 +273,7 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 
 	// Reply message
 	if request.ReplyMessageID != nil && *request.ReplyMessageID != "" {
-		message, err := service.chatStorageRepo.GetMessageByID(*request.ReplyMessageID)
+		message, err := service.getMessageByID(ctx, *request.ReplyMessageID)
 		if err != nil {
 			logrus.Warnf("Error retrieving reply message ID %s: %v, continuing without reply context", *request.ReplyMessageID, err)
 		} else if message != nil { // Only set reply context if we found the message
@@ -141,7 +302,7 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 			if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
 				ctxInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 			} else {
-				ctxInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(participantJID))
+				ctxInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(ctx, participantJID))
 			}
 
 			// Preserve mentions
@@ -158,22 +319,41 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 		}
 	}
 
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, request.Message)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, request.Message, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return withTemplate(queuedResponse(ts), rendered), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Message sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp.String())
-	return response, nil
+	return withTemplate(response, rendered), nil
 }
 
 func (service serviceSend) SendImage(ctx context.Context, request domainSend.ImageRequest) (response domainSend.GenericResponse, err error) {
+	rendered, err := service.renderSendTemplate(ctx, request.BaseRequest, "caption", request.Caption)
+	if err != nil {
+		return response, err
+	}
+	if rendered != nil {
+		request.Caption = rendered.Text
+		mediaURL, err := rendered.mediaURL(domainTemplate.MediaImage)
+		if err != nil {
+			return response, err
+		}
+		if mediaURL != nil && request.Image == nil && (request.ImageURL == nil || *request.ImageURL == "") {
+			request.ImageURL = mediaURL
+		}
+	}
+
 	err = validations.ValidateSendImage(ctx, request)
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -310,11 +490,14 @@ func (service serviceSend) SendImage(ctx context.Context, request domainSend.Ima
 		msg.ImageMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
+	// Reply message
+	msg.ImageMessage.ContextInfo = service.quoteMessage(ctx, request.ReplyMessageID, msg.ImageMessage.ContextInfo)
+
 	caption := "🖼️ Image"
 	if request.Caption != "" {
 		caption = "🖼️ " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption, request.SendAt)
 	go func() {
 		errDelete := utils.RemoveFile(0, deletedItems...)
 		if errDelete != nil {
@@ -325,17 +508,32 @@ func (service serviceSend) SendImage(ctx context.Context, request domainSend.Ima
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return withTemplate(queuedResponse(ts), rendered), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Message sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return withTemplate(response, rendered), nil
 }
 
 func (service serviceSend) SendFile(ctx context.Context, request domainSend.FileRequest) (response domainSend.GenericResponse, err error) {
+	rendered, err := service.renderSendTemplate(ctx, request.BaseRequest, "caption", request.Caption)
+	if err != nil {
+		return response, err
+	}
+	if rendered != nil {
+		request.Caption = rendered.Text
+		if rendered.MediaType != "" {
+			return response, pkgError.ValidationError(fmt.Sprintf("template %s has %s media and can not be sent with a file", rendered.Name, rendered.MediaType))
+		}
+	}
+
 	err = validations.ValidateSendFile(ctx, request)
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -381,22 +579,41 @@ func (service serviceSend) SendFile(ctx context.Context, request domainSend.File
 	if request.Caption != "" {
 		caption = "📄 " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return withTemplate(queuedResponse(ts), rendered), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Document sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return withTemplate(response, rendered), nil
 }
 
 func (service serviceSend) SendVideo(ctx context.Context, request domainSend.VideoRequest) (response domainSend.GenericResponse, err error) {
+	rendered, err := service.renderSendTemplate(ctx, request.BaseRequest, "caption", request.Caption)
+	if err != nil {
+		return response, err
+	}
+	if rendered != nil {
+		request.Caption = rendered.Text
+		mediaURL, err := rendered.mediaURL(domainTemplate.MediaVideo)
+		if err != nil {
+			return response, err
+		}
+		if mediaURL != nil && request.Video == nil && (request.VideoURL == nil || *request.VideoURL == "") {
+			request.VideoURL = mediaURL
+		}
+	}
+
 	err = validations.ValidateSendVideo(ctx, request)
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -552,18 +769,25 @@ func (service serviceSend) SendVideo(ctx context.Context, request domainSend.Vid
 		msg.VideoMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
+	// Reply message
+	msg.VideoMessage.ContextInfo = service.quoteMessage(ctx, request.ReplyMessageID, msg.VideoMessage.ContextInfo)
+
 	caption := "🎥 Video"
 	if request.Caption != "" {
 		caption = "🎥 " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, caption, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return withTemplate(queuedResponse(ts), rendered), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Video sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return withTemplate(response, rendered), nil
 }
 
 func (service serviceSend) SendContact(ctx context.Context, request domainSend.ContactRequest) (response domainSend.GenericResponse, err error) {
@@ -571,7 +795,7 @@ func (service serviceSend) SendContact(ctx context.Context, request domainSend.C
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -599,22 +823,37 @@ func (service serviceSend) SendContact(ctx context.Context, request domainSend.C
 
 	content := "👤 " + request.ContactName
 
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return queuedResponse(ts), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Contact sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
 	return response, nil
 }
 
 func (service serviceSend) SendLink(ctx context.Context, request domainSend.LinkRequest) (response domainSend.GenericResponse, err error) {
+	rendered, err := service.renderSendTemplate(ctx, request.BaseRequest, "caption", request.Caption)
+	if err != nil {
+		return response, err
+	}
+	if rendered != nil {
+		request.Caption = rendered.Text
+		if rendered.MediaType != "" {
+			return response, pkgError.ValidationError(fmt.Sprintf("template %s has %s media and can not be sent with a link", rendered.Name, rendered.MediaType))
+		}
+	}
+
 	err = validations.ValidateSendLink(ctx, request)
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -674,14 +913,18 @@ func (service serviceSend) SendLink(ctx context.Context, request domainSend.Link
 	if request.Caption != "" {
 		content = "🔗 " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return withTemplate(queuedResponse(ts), rendered), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Link sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return withTemplate(response, rendered), nil
 }
 
 func (service serviceSend) SendLocation(ctx context.Context, request domainSend.LocationRequest) (response domainSend.GenericResponse, err error) {
@@ -689,7 +932,7 @@ func (service serviceSend) SendLocation(ctx context.Context, request domainSend.
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -719,11 +962,15 @@ func (service serviceSend) SendLocation(ctx context.Context, request domainSend.
 	content := "📍 " + request.Latitude + ", " + request.Longitude
 
 	// Send WhatsApp Message Proto
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return queuedResponse(ts), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Send location success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
 	return response, nil
@@ -736,7 +983,7 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 		return response, err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -791,13 +1038,20 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 		msg.AudioMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
+	// Reply message
+	msg.AudioMessage.ContextInfo = service.quoteMessage(ctx, request.ReplyMessageID, msg.AudioMessage.ContextInfo)
+
 	content := "🎵 Audio"
 
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return queuedResponse(ts), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Send audio success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
 	return response, nil
@@ -808,14 +1062,14 @@ func (service serviceSend) SendPoll(ctx context.Context, request domainSend.Poll
 	if err != nil {
 		return response, err
 	}
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.BaseRequest.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.BaseRequest.Phone)
 	if err != nil {
 		return response, err
 	}
 
 	content := "📊 " + request.Question
 
-	msg := whatsapp.GetClient().BuildPollCreation(request.Question, request.Options, request.MaxAnswer)
+	msg := whatsapp.ClientFromContext(ctx).BuildPollCreation(request.Question, request.Options, request.MaxAnswer)
 
 	if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
 		if msg.PollCreationMessage.ContextInfo == nil {
@@ -824,11 +1078,15 @@ func (service serviceSend) SendPoll(ctx context.Context, request domainSend.Poll
 		msg.PollCreationMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return queuedResponse(ts), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Send poll success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
 	return response, nil
@@ -840,7 +1098,7 @@ func (service serviceSend) SendPresence(ctx context.Context, request domainSend.
 		return response, err
 	}
 
-	err = whatsapp.GetClient().SendPresence(types.Presence(request.Type))
+	err = whatsapp.ClientFromContext(ctx).SendPresence(types.Presence(request.Type))
 	if err != nil {
 		return response, err
 	}
@@ -856,12 +1114,13 @@ func (service serviceSend) SendChatPresence(ctx context.Context, request domainS
 		return response, err
 	}
 
-	userJid, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	userJid, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
 	var presenceType types.ChatPresence
+	var presenceMedia types.ChatPresenceMedia
 	var messageID string
 	var statusMessage string
 
@@ -870,15 +1129,19 @@ func (service serviceSend) SendChatPresence(ctx context.Context, request domainS
 		presenceType = types.ChatPresenceComposing
 		messageID = "chat-presence-start"
 		statusMessage = fmt.Sprintf("Send chat presence start typing success %s", request.Phone)
+	case "record":
+		presenceType, presenceMedia = types.ChatPresenceComposing, types.ChatPresenceMediaAudio
+		messageID = "chat-presence-record"
+		statusMessage = fmt.Sprintf("Send chat presence start recording success %s", request.Phone)
 	case "stop":
 		presenceType = types.ChatPresencePaused
 		messageID = "chat-presence-stop"
 		statusMessage = fmt.Sprintf("Send chat presence stop typing success %s", request.Phone)
 	default:
-		return response, fmt.Errorf("invalid action: %s. Must be 'start' or 'stop'", request.Action)
+		return response, fmt.Errorf("invalid action: %s. Must be 'start', 'record' or 'stop'", request.Action)
 	}
 
-	err = whatsapp.GetClient().SendChatPresence(userJid, presenceType, "")
+	err = whatsapp.ClientFromContext(ctx).SendChatPresence(userJid, presenceType, presenceMedia)
 	if err != nil {
 		return response, err
 	}
@@ -888,11 +1151,11 @@ func (service serviceSend) SendChatPresence(ctx context.Context, request domainS
 	return response, nil
 }
 
-func (service serviceSend) getMentionFromText(_ context.Context, messages string) (result []string) {
+func (service serviceSend) getMentionFromText(ctx context.Context, messages string) (result []string) {
 	mentions := utils.ContainsMention(messages)
 	for _, mention := range mentions {
 		// Get JID from phone number
-		if dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), mention); err == nil {
+		if dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), mention); err == nil {
 			result = append(result, dataWaRecipient.String())
 		}
 	}
@@ -906,7 +1169,7 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		return response, err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
@@ -1072,11 +1335,15 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 	content := "🎨 Sticker"
 
 	// Send the sticker message
-	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, dataWaRecipient, msg, content, request.SendAt)
 	if err != nil {
 		return response, err
 	}
 
+	if ts.Job != nil {
+		return queuedResponse(ts), nil
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Sticker sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp.String())
 	return response, nil
@@ -1084,20 +1351,55 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 
 func (service serviceSend) uploadMedia(ctx context.Context, mediaType whatsmeow.MediaType, media []byte, recipient types.JID) (uploaded whatsmeow.UploadResponse, err error) {
 	if recipient.Server == types.NewsletterServer {
-		uploaded, err = whatsapp.GetClient().UploadNewsletter(ctx, media, mediaType)
+		uploaded, err = whatsapp.ClientFromContext(ctx).UploadNewsletter(ctx, media, mediaType)
 	} else {
-		uploaded, err = whatsapp.GetClient().Upload(ctx, media, mediaType)
+		uploaded, err = whatsapp.ClientFromContext(ctx).Upload(ctx, media, mediaType)
 	}
 	return uploaded, err
 }
 
-func (service serviceSend) getDefaultEphemeralExpiration(jid string) (expiration uint32) {
+// quoteMessage adds the message replied to to the context info of a media message. The message is looked up in
+// the chat storage, a reply to a message that is not stored is sent without quote like a text reply.
+func (service serviceSend) quoteMessage(ctx context.Context, replyMessageID *string, ctxInfo *waE2E.ContextInfo) *waE2E.ContextInfo {
+	if replyMessageID == nil || *replyMessageID == "" {
+		return ctxInfo
+	}
+	message, err := service.getMessageByID(ctx, *replyMessageID)
+	if err != nil || message == nil {
+		logrus.Warnf("Reply message ID %s not found in storage (%v), continuing without reply context", *replyMessageID, err)
+		return ctxInfo
+	}
+
+	if ctxInfo == nil {
+		ctxInfo = &waE2E.ContextInfo{}
+	}
+	ctxInfo.StanzaID = replyMessageID
+	ctxInfo.Participant = proto.String(message.Sender)
+	ctxInfo.QuotedMessage = &waE2E.Message{Conversation: proto.String(message.Content)}
+	return ctxInfo
+}
+
+// getMessageByID looks up a stored message in the chat storage of the device selected in the context
+func (service serviceSend) getMessageByID(ctx context.Context, id string) (*domainChatStorage.Message, error) {
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return nil, err
+	}
+	return chatStorageRepo.GetMessageByID(id)
+}
+
+func (service serviceSend) getDefaultEphemeralExpiration(ctx context.Context, jid string) (expiration uint32) {
 	expiration = 0
 	if jid == "" {
 		return expiration
 	}
 
-	chat, err := service.chatStorageRepo.GetChat(jid)
+	chatStorageRepo, err := whatsapp.ChatStorageFromContext(ctx, service.chatStorageRepo)
+	if err != nil {
+		return expiration
+	}
+
+	chat, err := chatStorageRepo.GetChat(jid)
 	if err != nil {
 		return expiration
 	}
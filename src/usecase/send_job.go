@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/sendqueue"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/sirupsen/logrus"
+)
+
+type serviceSendJob struct {
+	jobRepo domainSend.ISendJobRepository
+	policy  *domainAuth.Policy
+}
+
+func NewSendJobService(jobRepo domainSend.ISendJobRepository, policy *domainAuth.Policy) domainSend.ISendJobUsecase {
+	return &serviceSendJob{
+		jobRepo: jobRepo,
+		policy:  policy,
+	}
+}
+
+func (service serviceSendJob) ListJobs(ctx context.Context, request domainSend.ListJobsRequest) (response domainSend.ListJobsResponse, err error) {
+	if err = validations.ValidateListSendJobs(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter := &domainSend.JobFilter{
+		Actor:     request.Actor,
+		Status:    request.Status,
+		Recipient: request.Recipient,
+		Scheduled: request.Scheduled,
+		Limit:     request.Limit,
+		Offset:    request.Offset,
+	}
+	if principal, ok := service.scope(ctx); !ok {
+		if request.Actor != "" && request.Actor != principal.Username {
+			return response, pkgError.ForbiddenError("the send jobs of other users require the users permission")
+		}
+		if filter.DeviceIDs = whatsapp.DeviceAliases(ctx); len(filter.DeviceIDs) == 0 {
+			return response, pkgError.ErrDeviceNotFound
+		}
+		filter.Actor = principal.Username
+	}
+
+	jobs, err := service.jobRepo.GetJobs(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get send jobs")
+		return response, err
+	}
+
+	totalCount, err := service.jobRepo.CountJobs(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count send jobs")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainSend.JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		response.Data = append(response.Data, toJobInfo(job))
+	}
+	response.Pagination = domainSend.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceSendJob) GetJob(ctx context.Context, request domainSend.JobRequest) (response domainSend.JobInfo, err error) {
+	job, err := service.storedJob(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	return toJobInfo(job), nil
+}
+
+func (service serviceSendJob) CancelJob(ctx context.Context, request domainSend.JobRequest) (response domainSend.JobInfo, err error) {
+	job, err := service.storedJob(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	cancelled, err := service.jobRepo.CancelJob(ctx, job.ID)
+	if err != nil {
+		return response, err
+	}
+	if !cancelled {
+		// The worker claimed it in the meantime, report the current status
+		if job, err = service.storedJob(ctx, job.ID); err != nil {
+			return response, err
+		}
+		return response, pkgError.ConflictError(fmt.Sprintf("send job %s is %s and can no longer be cancelled", job.ID, job.Status))
+	}
+
+	logrus.Infof("Send job %s to %s cancelled", job.ID, job.Recipient)
+	return service.GetJob(ctx, request)
+}
+
+func (service serviceSendJob) UpdateJob(ctx context.Context, request domainSend.UpdateJobRequest) (response domainSend.JobInfo, err error) {
+	if err = validations.ValidateUpdateSendJob(ctx, &request); err != nil {
+		return response, err
+	}
+
+	job, err := service.storedJob(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	sendAt, _ := time.Parse(time.RFC3339, request.SendAt)
+	rescheduled, err := service.jobRepo.RescheduleJob(ctx, job.ID, sendAt.UTC())
+	if err != nil {
+		return response, err
+	}
+	if !rescheduled {
+		if job, err = service.storedJob(ctx, job.ID); err != nil {
+			return response, err
+		}
+		return response, pkgError.ConflictError(fmt.Sprintf("send job %s is %s and can no longer be rescheduled", job.ID, job.Status))
+	}
+
+	logrus.Infof("Send job %s to %s rescheduled to %s", job.ID, job.Recipient, sendAt.UTC().Format(time.RFC3339))
+	// The worker may have been waiting for the previous send time
+	sendqueue.Wakeup()
+	return service.GetJob(ctx, domainSend.JobRequest{ID: job.ID})
+}
+
+// storedJob loads a job the caller may see, the jobs of other users and devices are reported as not found
+func (service serviceSendJob) storedJob(ctx context.Context, id string) (*domainSend.Job, error) {
+	job, err := service.jobRepo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || !service.visible(ctx, job) {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("send job %s not found", id))
+	}
+	return job, nil
+}
+
+// scope returns the caller, and whether the caller manages users and therefore sees the jobs of every user and device
+func (service serviceSendJob) scope(ctx context.Context) (domainAuth.Principal, bool) {
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	return principal, service.policy != nil && service.policy.Permits(principal, domainAuth.PermissionUsers)
+}
+
+// visible reports whether the caller may see a job: jobs belong to the user who queued them on the selected device
+func (service serviceSendJob) visible(ctx context.Context, job *domainSend.Job) bool {
+	principal, all := service.scope(ctx)
+	if all {
+		return true
+	}
+	return job.Actor == principal.Username && slices.Contains(whatsapp.DeviceAliases(ctx), job.DeviceID)
+}
+
+func toJobInfo(job *domainSend.Job) domainSend.JobInfo {
+	info := domainSend.JobInfo{
+		ID:             job.ID,
+		DeviceID:       job.DeviceID,
+		Actor:          job.Actor,
+		IdempotencyKey: job.IdempotencyKey,
+		Recipient:      job.Recipient,
+		MessageID:      job.MessageID,
+		Status:         job.Status,
+		Attempts:       job.Attempts,
+		LastError:      job.LastError,
+		CreatedAt:      job.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:      job.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if !job.Finished() {
+		info.NextAttemptAt = job.NextAttemptAt.UTC().Format(time.RFC3339)
+	}
+	if job.ScheduledAt != nil {
+		info.ScheduledAt = job.ScheduledAt.UTC().Format(time.RFC3339)
+	}
+	if job.SentAt != nil {
+		info.SentAt = job.SentAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
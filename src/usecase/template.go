@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type serviceTemplate struct {
+	templateRepo domainTemplate.ITemplateRepository
+}
+
+func NewTemplateService(templateRepo domainTemplate.ITemplateRepository) domainTemplate.ITemplateUsecase {
+	return &serviceTemplate{
+		templateRepo: templateRepo,
+	}
+}
+
+func (service serviceTemplate) CreateTemplate(ctx context.Context, request domainTemplate.CreateTemplateRequest) (response domainTemplate.TemplateInfo, err error) {
+	if err = validations.ValidateCreateTemplate(ctx, &request); err != nil {
+		return response, err
+	}
+	if err = service.ensureNameAvailable(ctx, request.Name, ""); err != nil {
+		return response, err
+	}
+
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	template := &domainTemplate.Template{
+		ID:          uuid.NewString(),
+		Name:        request.Name,
+		Description: request.Description,
+		Body:        request.Body,
+		MediaType:   request.MediaType,
+		MediaURL:    request.MediaURL,
+		Defaults:    request.Defaults,
+		Actor:       principal.Username,
+	}
+	if err = service.templateRepo.CreateTemplate(ctx, template); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Template %s (%s) created", template.Name, template.ID)
+	return toTemplateInfo(template), nil
+}
+
+func (service serviceTemplate) ListTemplates(ctx context.Context, request domainTemplate.ListTemplatesRequest) (response domainTemplate.ListTemplatesResponse, err error) {
+	if err = validations.ValidateListTemplates(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter := &domainTemplate.TemplateFilter{
+		Search: request.Search,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+
+	templates, err := service.templateRepo.GetTemplates(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get templates")
+		return response, err
+	}
+
+	totalCount, err := service.templateRepo.CountTemplates(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count templates")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainTemplate.TemplateInfo, 0, len(templates))
+	for _, template := range templates {
+		response.Data = append(response.Data, toTemplateInfo(template))
+	}
+	response.Pagination = domainTemplate.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceTemplate) GetTemplate(ctx context.Context, request domainTemplate.TemplateRequest) (response domainTemplate.TemplateInfo, err error) {
+	template, err := service.storedTemplate(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	return toTemplateInfo(template), nil
+}
+
+func (service serviceTemplate) UpdateTemplate(ctx context.Context, request domainTemplate.UpdateTemplateRequest) (response domainTemplate.TemplateInfo, err error) {
+	if err = validations.ValidateUpdateTemplate(ctx, &request); err != nil {
+		return response, err
+	}
+
+	template, err := service.storedTemplate(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	if request.Version != 0 && request.Version != template.Version {
+		return response, pkgError.ConflictError(fmt.Sprintf("template %s is at version %d, not %d", template.ID, template.Version, request.Version))
+	}
+	if err = service.ensureNameAvailable(ctx, request.Name, template.ID); err != nil {
+		return response, err
+	}
+
+	updated := *template
+	updated.Name = request.Name
+	updated.Description = request.Description
+	updated.Body = request.Body
+	updated.MediaType = request.MediaType
+	updated.MediaURL = request.MediaURL
+	updated.Defaults = request.Defaults
+	if len(updated.Defaults) == 0 {
+		updated.Defaults = nil
+	}
+	if reflect.DeepEqual(&updated, template) {
+		// Nothing changed, so there is no new version to keep
+		return toTemplateInfo(template), nil
+	}
+
+	principal, _ := domainAuth.PrincipalFromContext(ctx)
+	updated.Actor = principal.Username
+
+	changed, err := service.templateRepo.UpdateTemplate(ctx, &updated, template.Version)
+	if err != nil {
+		return response, err
+	}
+	if !changed {
+		return response, pkgError.ConflictError(fmt.Sprintf("template %s was changed by another request, fetch it and try again", template.ID))
+	}
+
+	logrus.Infof("Template %s (%s) updated to version %d", updated.Name, updated.ID, updated.Version)
+	return toTemplateInfo(&updated), nil
+}
+
+func (service serviceTemplate) DeleteTemplate(ctx context.Context, request domainTemplate.TemplateRequest) error {
+	deleted, err := service.templateRepo.DeleteTemplate(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return pkgError.NotFoundError(fmt.Sprintf("template %s not found", request.ID))
+	}
+
+	logrus.Infof("Template %s deleted", request.ID)
+	return nil
+}
+
+func (service serviceTemplate) ListVersions(ctx context.Context, request domainTemplate.TemplateRequest) (response []domainTemplate.VersionInfo, err error) {
+	versions, err := service.templateRepo.GetVersions(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	if len(versions) == 0 {
+		return response, pkgError.NotFoundError(fmt.Sprintf("template %s not found", request.ID))
+	}
+
+	response = make([]domainTemplate.VersionInfo, 0, len(versions))
+	for _, version := range versions {
+		response = append(response, toVersionInfo(version))
+	}
+	return response, nil
+}
+
+func (service serviceTemplate) GetVersion(ctx context.Context, request domainTemplate.VersionRequest) (response domainTemplate.VersionInfo, err error) {
+	version, err := service.templateRepo.GetVersion(ctx, request.ID, request.Version)
+	if err != nil {
+		return response, err
+	}
+	if version == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("template %s has no version %d", request.ID, request.Version))
+	}
+	return toVersionInfo(version), nil
+}
+
+func (service serviceTemplate) RenderTemplate(ctx context.Context, request domainTemplate.RenderTemplateRequest) (response domainTemplate.RenderResponse, err error) {
+	template, err := service.storedTemplate(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	version, text, err := renderTemplateVersion(ctx, service.templateRepo, template, request.Version, request.Variables)
+	if err != nil {
+		return response, err
+	}
+
+	return domainTemplate.RenderResponse{
+		TemplateID: template.ID,
+		Version:    version.Version,
+		Text:       text,
+		MediaType:  version.MediaType,
+		MediaURL:   version.MediaURL,
+	}, nil
+}
+
+// ensureNameAvailable rejects a name another template already has
+func (service serviceTemplate) ensureNameAvailable(ctx context.Context, name, id string) error {
+	existing, err := service.templateRepo.GetTemplateByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != id {
+		return pkgError.ConflictError(fmt.Sprintf("template %s already exists", name))
+	}
+	return nil
+}
+
+func (service serviceTemplate) storedTemplate(ctx context.Context, id string) (*domainTemplate.Template, error) {
+	template, err := service.templateRepo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("template %s not found", id))
+	}
+	return template, nil
+}
+
+// renderTemplateVersion renders a version of a template, the current one when version is 0. A version that does
+// not exist and a variable that is missing are validation errors, so nothing is sent with them.
+func renderTemplateVersion(ctx context.Context, templateRepo domainTemplate.ITemplateRepository, template *domainTemplate.Template, version int, variables map[string]any) (*domainTemplate.Version, string, error) {
+	content := template.Current()
+	if version != 0 && version != template.Version {
+		var err error
+		if content, err = templateRepo.GetVersion(ctx, template.ID, version); err != nil {
+			return nil, "", err
+		}
+		if content == nil {
+			return nil, "", pkgError.ValidationError(fmt.Sprintf("template %s has no version %d", template.Name, version))
+		}
+	}
+
+	text, err := domainTemplate.Render(content.Body, variables, content.Defaults)
+	if err != nil {
+		return nil, "", pkgError.ValidationError(fmt.Sprintf("template %s version %d: %s", template.Name, content.Version, err.Error()))
+	}
+	return content, text, nil
+}
+
+func toTemplateInfo(template *domainTemplate.Template) domainTemplate.TemplateInfo {
+	return domainTemplate.TemplateInfo{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		Body:        template.Body,
+		MediaType:   template.MediaType,
+		MediaURL:    template.MediaURL,
+		Defaults:    template.Defaults,
+		Variables:   domainTemplate.Variables(template.Body),
+		Version:     template.Version,
+		Actor:       template.Actor,
+		CreatedAt:   template.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   template.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toVersionInfo(version *domainTemplate.Version) domainTemplate.VersionInfo {
+	return domainTemplate.VersionInfo{
+		TemplateID: version.TemplateID,
+		Version:    version.Version,
+		Body:       version.Body,
+		MediaType:  version.MediaType,
+		MediaURL:   version.MediaURL,
+		Defaults:   version.Defaults,
+		Variables:  domainTemplate.Variables(version.Body),
+		Actor:      version.Actor,
+		CreatedAt:  version.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
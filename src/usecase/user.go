@@ -33,13 +33,13 @@ func (service serviceUser) Info(ctx context.Context, request domainUser.InfoRequ
 		return response, err
 	}
 	var jids []types.JID
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
 	jids = append(jids, dataWaRecipient)
-	resp, err := whatsapp.GetClient().GetUserInfo(jids)
+	resp, err := whatsapp.ClientFromContext(ctx).GetUserInfo(jids)
 	if err != nil {
 		return response, err
 	}
@@ -81,11 +81,11 @@ func (service serviceUser) Avatar(ctx context.Context, request domainUser.Avatar
 		if err != nil {
 			chanErr <- err
 		}
-		dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+		dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 		if err != nil {
 			chanErr <- err
 		}
-		pic, err := whatsapp.GetClient().GetProfilePictureInfo(dataWaRecipient, &whatsmeow.GetProfilePictureParams{
+		pic, err := whatsapp.ClientFromContext(ctx).GetProfilePictureInfo(dataWaRecipient, &whatsmeow.GetProfilePictureParams{
 			Preview:     request.IsPreview,
 			IsCommunity: request.IsCommunity,
 		})
@@ -118,9 +118,9 @@ func (service serviceUser) Avatar(ctx context.Context, request domainUser.Avatar
 }
 
 func (service serviceUser) MyListGroups(ctx context.Context) (response domainUser.MyListGroupsResponse, err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
-	groups, err := whatsapp.GetClient().GetJoinedGroups(ctx)
+	groups, err := whatsapp.ClientFromContext(ctx).GetJoinedGroups(ctx)
 	if err != nil {
 		return
 	}
@@ -131,10 +131,10 @@ func (service serviceUser) MyListGroups(ctx context.Context) (response domainUse
 	return response, nil
 }
 
-func (service serviceUser) MyListNewsletter(_ context.Context) (response domainUser.MyListNewsletterResponse, err error) {
-	utils.MustLogin(whatsapp.GetClient())
+func (service serviceUser) MyListNewsletter(ctx context.Context) (response domainUser.MyListNewsletterResponse, err error) {
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
-	datas, err := whatsapp.GetClient().GetSubscribedNewsletters()
+	datas, err := whatsapp.ClientFromContext(ctx).GetSubscribedNewsletters()
 	if err != nil {
 		return
 	}
@@ -146,9 +146,9 @@ func (service serviceUser) MyListNewsletter(_ context.Context) (response domainU
 }
 
 func (service serviceUser) MyPrivacySetting(ctx context.Context) (response domainUser.MyPrivacySettingResponse, err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
-	resp, err := whatsapp.GetClient().TryFetchPrivacySettings(ctx, true)
+	resp, err := whatsapp.ClientFromContext(ctx).TryFetchPrivacySettings(ctx, true)
 	if err != nil {
 		return
 	}
@@ -161,9 +161,9 @@ func (service serviceUser) MyPrivacySetting(ctx context.Context) (response domai
 }
 
 func (service serviceUser) MyListContacts(ctx context.Context) (response domainUser.MyListContactsResponse, err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
-	contacts, err := whatsapp.GetClient().Store.Contacts.GetAllContacts(ctx)
+	contacts, err := whatsapp.ClientFromContext(ctx).Store.Contacts.GetAllContacts(ctx)
 	if err != nil {
 		return
 	}
@@ -179,7 +179,7 @@ func (service serviceUser) MyListContacts(ctx context.Context) (response domainU
 }
 
 func (service serviceUser) ChangeAvatar(ctx context.Context, request domainUser.ChangeAvatarRequest) (err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
 	file, err := request.Avatar.Open()
 	if err != nil {
@@ -224,7 +224,7 @@ func (service serviceUser) ChangeAvatar(ctx context.Context, request domainUser.
 		return fmt.Errorf("failed to encode image: %v", err)
 	}
 
-	_, err = whatsapp.GetClient().SetGroupPhoto(types.JID{}, buf.Bytes())
+	_, err = whatsapp.ClientFromContext(ctx).SetGroupPhoto(types.JID{}, buf.Bytes())
 	if err != nil {
 		return err
 	}
@@ -233,9 +233,9 @@ func (service serviceUser) ChangeAvatar(ctx context.Context, request domainUser.
 }
 
 func (service serviceUser) ChangePushName(ctx context.Context, request domainUser.ChangePushNameRequest) (err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
-	err = whatsapp.GetClient().SendAppState(ctx, appstate.BuildSettingPushName(request.PushName))
+	err = whatsapp.ClientFromContext(ctx).SendAppState(ctx, appstate.BuildSettingPushName(request.PushName))
 	if err != nil {
 		return err
 	}
@@ -243,11 +243,11 @@ func (service serviceUser) ChangePushName(ctx context.Context, request domainUse
 }
 
 func (service serviceUser) IsOnWhatsApp(ctx context.Context, request domainUser.CheckRequest) (response domainUser.CheckResponse, err error) {
-	utils.MustLogin(whatsapp.GetClient())
+	utils.MustLogin(whatsapp.ClientFromContext(ctx))
 
 	utils.SanitizePhone(&request.Phone)
 
-	response.IsOnWhatsApp = utils.IsOnWhatsapp(whatsapp.GetClient(), request.Phone)
+	response.IsOnWhatsApp = utils.IsOnWhatsapp(whatsapp.ClientFromContext(ctx), request.Phone)
 
 	return response, nil
 }
@@ -258,12 +258,12 @@ func (service serviceUser) BusinessProfile(ctx context.Context, request domainUs
 		return response, err
 	}
 
-	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.GetClient(), request.Phone)
+	dataWaRecipient, err := utils.ValidateJidWithLogin(whatsapp.ClientFromContext(ctx), request.Phone)
 	if err != nil {
 		return response, err
 	}
 
-	profile, err := whatsapp.GetClient().GetBusinessProfile(dataWaRecipient)
+	profile, err := whatsapp.ClientFromContext(ctx).GetBusinessProfile(dataWaRecipient)
 	if err != nil {
 		return response, err
 	}
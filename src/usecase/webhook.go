@@ -0,0 +1,409 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/webhook"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type serviceWebhook struct {
+	webhookRepo domainWebhook.IWebhookRepository
+}
+
+func NewWebhookService(webhookRepo domainWebhook.IWebhookRepository) domainWebhook.IWebhookUsecase {
+	return &serviceWebhook{
+		webhookRepo: webhookRepo,
+	}
+}
+
+func (service serviceWebhook) ListEndpoints(_ context.Context) (response []domainWebhook.EndpointInfo, err error) {
+	endpoints := webhook.Endpoints()
+	response = make([]domainWebhook.EndpointInfo, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		response = append(response, toEndpointInfo(endpoint))
+	}
+	return response, nil
+}
+
+func (service serviceWebhook) GetEndpoint(_ context.Context, request domainWebhook.EndpointRequest) (response domainWebhook.EndpointInfo, err error) {
+	endpoint := webhook.GetEndpoint(request.ID)
+	if endpoint == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("webhook endpoint %s not found", request.ID))
+	}
+	return toEndpointInfo(endpoint), nil
+}
+
+func (service serviceWebhook) CreateEndpoint(ctx context.Context, request domainWebhook.CreateEndpointRequest) (response domainWebhook.EndpointInfo, err error) {
+	if err = validations.ValidateCreateEndpoint(ctx, &request); err != nil {
+		return response, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return response, err
+		}
+	}
+
+	endpoint := &domainWebhook.Endpoint{
+		ID:             uuid.NewString(),
+		URL:            request.URL,
+		Secret:         secret,
+		Events:         request.Events,
+		AllowChats:     request.AllowChats,
+		DenyChats:      request.DenyChats,
+		PayloadVersion: request.PayloadVersion,
+		Enabled:        request.Enabled == nil || *request.Enabled,
+	}
+	if err = service.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		return response, err
+	}
+	if err = webhook.ReloadEndpoints(ctx); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Webhook endpoint %s created for %s", endpoint.ID, endpoint.URL)
+	response = toEndpointInfo(endpoint)
+	response.Secret = endpoint.Secret
+	return response, nil
+}
+
+func (service serviceWebhook) UpdateEndpoint(ctx context.Context, request domainWebhook.UpdateEndpointRequest) (response domainWebhook.EndpointInfo, err error) {
+	if err = validations.ValidateUpdateEndpoint(ctx, &request); err != nil {
+		return response, err
+	}
+
+	endpoint, err := service.storedEndpoint(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	endpoint.URL = request.URL
+	endpoint.Events = request.Events
+	endpoint.AllowChats = request.AllowChats
+	endpoint.DenyChats = request.DenyChats
+	if request.Secret != "" && request.Secret != endpoint.Secret {
+		// Setting the secret directly replaces it without an overlap window
+		endpoint.Secret = request.Secret
+		endpoint.PreviousSecret = ""
+		endpoint.PreviousSecretExpiresAt = nil
+	}
+	if request.PayloadVersion != 0 {
+		endpoint.PayloadVersion = request.PayloadVersion
+	}
+	if request.Enabled != nil {
+		endpoint.Enabled = *request.Enabled
+	}
+
+	if err = service.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return response, err
+	}
+	if err = webhook.ReloadEndpoints(ctx); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Webhook endpoint %s updated", endpoint.ID)
+	return toEndpointInfo(endpoint), nil
+}
+
+func (service serviceWebhook) DeleteEndpoint(ctx context.Context, request domainWebhook.EndpointRequest) (err error) {
+	if _, err = service.storedEndpoint(ctx, request.ID); err != nil {
+		return err
+	}
+
+	if err = service.webhookRepo.DeleteEndpoint(ctx, request.ID); err != nil {
+		return err
+	}
+	if err = webhook.ReloadEndpoints(ctx); err != nil {
+		return err
+	}
+
+	logrus.Infof("Webhook endpoint %s deleted", request.ID)
+	return nil
+}
+
+func (service serviceWebhook) RotateSecret(ctx context.Context, request domainWebhook.RotateSecretRequest) (response domainWebhook.EndpointInfo, err error) {
+	if err = validations.ValidateRotateSecret(ctx, &request); err != nil {
+		return response, err
+	}
+	overlap, err := time.ParseDuration(request.Overlap)
+	if err != nil {
+		return response, pkgError.ValidationError(fmt.Sprintf("invalid overlap: %v", err))
+	}
+
+	endpoint, err := service.storedEndpoint(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return response, err
+		}
+	}
+	if secret == endpoint.Secret {
+		return response, pkgError.ValidationError("secret: must differ from the current secret.")
+	}
+
+	// The previous secret keeps signing deliveries until the overlap ends, a zero overlap drops it right away
+	endpoint.PreviousSecret = ""
+	endpoint.PreviousSecretExpiresAt = nil
+	if overlap > 0 {
+		expiresAt := time.Now().UTC().Add(overlap)
+		endpoint.PreviousSecret = endpoint.Secret
+		endpoint.PreviousSecretExpiresAt = &expiresAt
+	}
+	endpoint.Secret = secret
+
+	if err = service.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return response, err
+	}
+	if err = webhook.ReloadEndpoints(ctx); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Secret of webhook endpoint %s rotated with an overlap of %s", endpoint.ID, overlap)
+	response = toEndpointInfo(endpoint)
+	response.Secret = endpoint.Secret
+	return response, nil
+}
+
+func (service serviceWebhook) ListCircuits(_ context.Context) (response []domainWebhook.CircuitInfo, err error) {
+	endpoints := webhook.Endpoints()
+	response = make([]domainWebhook.CircuitInfo, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		circuit := webhook.Circuit(endpoint.ID)
+		circuit.URL = endpoint.URL
+		response = append(response, circuit)
+	}
+	return response, nil
+}
+
+func (service serviceWebhook) ResetCircuit(ctx context.Context, request domainWebhook.EndpointRequest) (response domainWebhook.CircuitInfo, err error) {
+	endpoint := webhook.GetEndpoint(request.ID)
+	if endpoint == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("webhook endpoint %s not found", request.ID))
+	}
+
+	if err = webhook.ResetCircuit(ctx, endpoint.ID); err != nil {
+		return response, err
+	}
+	logrus.Infof("Circuit of webhook endpoint %s reset", endpoint.ID)
+
+	response = webhook.Circuit(endpoint.ID)
+	response.URL = endpoint.URL
+	return response, nil
+}
+
+// storedEndpoint loads an endpoint that can be changed through the API
+func (service serviceWebhook) storedEndpoint(ctx context.Context, id string) (*domainWebhook.Endpoint, error) {
+	if strings.HasPrefix(id, webhook.LegacyEndpointPrefix) {
+		return nil, pkgError.ValidationError(fmt.Sprintf("webhook endpoint %s comes from the --webhook configuration and cannot be changed through the API", id))
+	}
+
+	endpoint, err := service.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("webhook endpoint %s not found", id))
+	}
+	return endpoint, nil
+}
+
+func (service serviceWebhook) ListDeliveries(ctx context.Context, request domainWebhook.ListDeliveriesRequest) (response domainWebhook.ListDeliveriesResponse, err error) {
+	if err = validations.ValidateListDeliveries(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter := &domainWebhook.DeliveryFilter{
+		EndpointID: request.EndpointID,
+		Status:     request.Status,
+		Limit:      request.Limit,
+		Offset:     request.Offset,
+	}
+	if filter.From, filter.To, err = parseTimeRange(request.From, request.To); err != nil {
+		return response, err
+	}
+
+	deliveries, err := service.webhookRepo.GetDeliveries(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get webhook deliveries")
+		return response, err
+	}
+
+	totalCount, err := service.webhookRepo.CountDeliveries(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count webhook deliveries")
+		// Continue with partial data
+		totalCount = 0
+	}
+
+	response.Data = make([]domainWebhook.DeliveryInfo, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response.Data = append(response.Data, toDeliveryInfo(delivery))
+	}
+	response.Pagination = domainWebhook.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(totalCount),
+	}
+
+	return response, nil
+}
+
+func (service serviceWebhook) ReplayDelivery(ctx context.Context, request domainWebhook.ReplayDeliveryRequest) (response domainWebhook.DeliveryInfo, err error) {
+	if err = validations.ValidateReplayDelivery(ctx, &request); err != nil {
+		return response, err
+	}
+
+	delivery, err := service.webhookRepo.GetDelivery(ctx, request.ID)
+	if err != nil {
+		return response, err
+	}
+	if delivery == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("webhook delivery %d not found", request.ID))
+	}
+
+	now := time.Now().UTC()
+	replayed, err := service.webhookRepo.ReplayDelivery(ctx, request.ID, now, now.Add(config.WhatsappWebhookRetryHorizon))
+	if err != nil {
+		return response, err
+	}
+	if !replayed {
+		// A pending delivery is still being attempted, replaying it would reset its attempts
+		return response, pkgError.ConflictError(fmt.Sprintf("webhook delivery %d is %s, only failed and delivered deliveries can be replayed", request.ID, delivery.Status))
+	}
+	webhook.Wakeup()
+
+	if delivery, err = service.webhookRepo.GetDelivery(ctx, request.ID); err != nil {
+		return response, err
+	}
+
+	logrus.Infof("Webhook delivery %d scheduled for replay", request.ID)
+	return toDeliveryInfo(delivery), nil
+}
+
+func (service serviceWebhook) ReplayDeliveries(ctx context.Context, request domainWebhook.ReplayDeliveriesRequest) (response domainWebhook.ReplayDeliveriesResponse, err error) {
+	if err = validations.ValidateReplayDeliveries(ctx, &request); err != nil {
+		return response, err
+	}
+
+	filter := &domainWebhook.DeliveryFilter{EndpointID: request.EndpointID, Status: request.Status}
+	if filter.From, filter.To, err = parseTimeRange(request.From, request.To); err != nil {
+		return response, err
+	}
+
+	now := time.Now().UTC()
+	replayed, err := service.webhookRepo.ReplayDeliveries(ctx, filter, now, now.Add(config.WhatsappWebhookRetryHorizon))
+	if err != nil {
+		return response, err
+	}
+	webhook.Wakeup()
+
+	logrus.Infof("%d webhook deliveries scheduled for replay", replayed)
+	response.Replayed = replayed
+	return response, nil
+}
+
+// parseTimeRange parses optional RFC3339 bounds of a delivery filter
+func parseTimeRange(from, to *string) (*time.Time, *time.Time, error) {
+	var fromTime, toTime *time.Time
+
+	if from != nil && *from != "" {
+		parsed, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return nil, nil, pkgError.ValidationError(fmt.Sprintf("invalid from format: %v", err))
+		}
+		fromTime = &parsed
+	}
+
+	if to != nil && *to != "" {
+		parsed, err := time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return nil, nil, pkgError.ValidationError(fmt.Sprintf("invalid to format: %v", err))
+		}
+		toTime = &parsed
+	}
+
+	return fromTime, toTime, nil
+}
+
+func toDeliveryInfo(delivery *domainWebhook.Delivery) domainWebhook.DeliveryInfo {
+	info := domainWebhook.DeliveryInfo{
+		ID:            delivery.ID,
+		EndpointID:    delivery.EndpointID,
+		URL:           delivery.URL,
+		Event:         delivery.Event,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		LastError:     delivery.LastError,
+		NextAttemptAt: delivery.NextAttemptAt.Format(time.RFC3339),
+		CreatedAt:     delivery.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     delivery.UpdatedAt.Format(time.RFC3339),
+	}
+	if delivery.DeliveredAt != nil {
+		info.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+
+	var payload any
+	if err := json.Unmarshal([]byte(delivery.Payload), &payload); err == nil {
+		info.Payload = payload
+	}
+
+	return info
+}
+
+func toEndpointInfo(endpoint *domainWebhook.Endpoint) domainWebhook.EndpointInfo {
+	info := domainWebhook.EndpointInfo{
+		ID:             endpoint.ID,
+		URL:            endpoint.URL,
+		Events:         nonNilStrings(endpoint.Events),
+		AllowChats:     nonNilStrings(endpoint.AllowChats),
+		DenyChats:      nonNilStrings(endpoint.DenyChats),
+		PayloadVersion: endpoint.PayloadVersion,
+		Enabled:        endpoint.Enabled,
+		ReadOnly:       endpoint.ReadOnly,
+	}
+	if secrets := endpoint.Secrets(time.Now()); len(secrets) > 1 {
+		info.PreviousSecretExpiresAt = endpoint.PreviousSecretExpiresAt.UTC().Format(time.RFC3339)
+	}
+	circuit := webhook.Circuit(endpoint.ID)
+	circuit.EndpointID = ""
+	info.Circuit = &circuit
+	if !endpoint.CreatedAt.IsZero() {
+		info.CreatedAt = endpoint.CreatedAt.Format(time.RFC3339)
+		info.UpdatedAt = endpoint.UpdatedAt.Format(time.RFC3339)
+	}
+	return info
+}
+
+// nonNilStrings returns an empty slice instead of nil for consistent JSON
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// generateWebhookSecret creates a random secret for endpoints created without one
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", pkgError.InternalServerError(fmt.Sprintf("failed to generate webhook secret: %v", err))
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -19,3 +19,14 @@ func ValidateLoginWithCode(ctx context.Context, phoneNumber string) error {
 	}
 	return nil
 }
+
+func ValidateRemoveDevice(ctx context.Context, deviceID string) error {
+	err := validation.ValidateWithContext(ctx, &deviceID,
+		validation.Required,
+		validation.Match(regexp.MustCompile(`^[A-Za-z0-9@.:_-]{1,128}$`)),
+	)
+	if err != nil {
+		return pkgError.ValidationError(fmt.Sprintf("device_id(%s): %s", deviceID, err.Error()))
+	}
+	return nil
+}
@@ -59,3 +59,45 @@ func TestValidateLoginWithCode(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRemoveDevice(t *testing.T) {
+	tests := []struct {
+		name     string
+		deviceID string
+		wantErr  bool
+	}{
+		{
+			name:     "Session token",
+			deviceID: "0b9c8f6e-3f1d-4c55-9a7d-0d9a2b1c4e5f",
+			wantErr:  false,
+		},
+		{
+			name:     "Device JID",
+			deviceID: "6281234567890:12@s.whatsapp.net",
+			wantErr:  false,
+		},
+		{
+			name:     "Phone number",
+			deviceID: "6281234567890",
+			wantErr:  false,
+		},
+		{
+			name:     "Empty device ID",
+			deviceID: "",
+			wantErr:  true,
+		},
+		{
+			name:     "Device ID with path separator",
+			deviceID: "../6281234567890",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRemoveDevice(context.Background(), tt.deviceID); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRemoveDevice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package validations
+
+import (
+	"context"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func ValidateListAuditEntries(ctx context.Context, request *domainAudit.ListEntriesRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Source, validation.In(domainAudit.SourceREST, domainAudit.SourceMCP)),
+		validation.Field(&request.Result, validation.In(domainAudit.ResultSuccess, domainAudit.ResultError)),
+		validation.Field(&request.From, validation.By(validateRFC3339)),
+		validation.Field(&request.To, validation.By(validateRFC3339)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(500)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainAudit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/audit"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListAuditEntries(t *testing.T) {
+	validTime := "2024-01-01T00:00:00Z"
+	invalidTime := "yesterday"
+
+	tests := []struct {
+		name    string
+		request domainAudit.ListEntriesRequest
+		err     any
+	}{
+		{
+			name:    "should success with empty request (auto set default limit)",
+			request: domainAudit.ListEntriesRequest{},
+			err:     nil,
+		},
+		{
+			name: "should success with every filter",
+			request: domainAudit.ListEntriesRequest{
+				Actor:  "alice",
+				Action: "POST /group",
+				Source: domainAudit.SourceMCP,
+				Target: "120363025246125888@g.us",
+				Result: domainAudit.ResultError,
+				From:   &validTime,
+				To:     &validTime,
+				Limit:  500,
+			},
+			err: nil,
+		},
+		{
+			name:    "should error with unknown source",
+			request: domainAudit.ListEntriesRequest{Source: "cli"},
+			err:     pkgError.ValidationError("source: must be a valid value."),
+		},
+		{
+			name:    "should error with unknown result",
+			request: domainAudit.ListEntriesRequest{Result: "ok"},
+			err:     pkgError.ValidationError("result: must be a valid value."),
+		},
+		{
+			name:    "should error with invalid to",
+			request: domainAudit.ListEntriesRequest{To: &invalidTime},
+			err:     pkgError.ValidationError("to: must be a valid RFC3339 timestamp."),
+		},
+		{
+			name:    "should error with limit too high",
+			request: domainAudit.ListEntriesRequest{Limit: 501},
+			err:     pkgError.ValidationError("limit: must be no greater than 500."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListAuditEntries(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
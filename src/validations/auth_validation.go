@@ -0,0 +1,99 @@
+package validations
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Usernames are sent in Basic Auth credentials, so they cannot contain a colon
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+var usernameRules = []validation.Rule{validation.Required, validation.Length(1, 64), validation.Match(usernamePattern)}
+
+// Passwords are limited to 72 characters, the input limit of bcrypt
+var passwordRules = []validation.Rule{validation.Length(8, 72)}
+
+func ValidateCreateUser(ctx context.Context, request *domainAuth.CreateUserRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Username, usernameRules...),
+		validation.Field(&request.Password, passwordRules...),
+		validation.Field(&request.Role, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateUpdateUser(ctx context.Context, request *domainAuth.UpdateUserRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Username, usernameRules...),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateResetPassword(ctx context.Context, request *domainAuth.ResetPasswordRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Username, usernameRules...),
+		validation.Field(&request.Password, passwordRules...),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateCreateAPIKey(ctx context.Context, request *domainAuth.CreateAPIKeyRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&request.Username, usernameRules...),
+		validation.Field(&request.Scopes, validation.Required, validation.Each(validation.In(permissions()...))),
+		validation.Field(&request.ExpiresAt, validation.By(validateFutureTime)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// permissions returns the permissions as rule values
+func permissions() []any {
+	values := make([]any, len(domainAuth.Permissions))
+	for i, permission := range domainAuth.Permissions {
+		values[i] = permission
+	}
+	return values
+}
+
+// validateFutureTime accepts an empty value or an RFC3339 timestamp in the future
+func validateFutureTime(value any) error {
+	if err := validateRFC3339(value); err != nil {
+		return err
+	}
+
+	value, _ = validation.Indirect(value)
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if expiresAt, _ := time.Parse(time.RFC3339, s); !expiresAt.After(time.Now()) {
+		return validation.NewError("validation_expiry", "must be in the future")
+	}
+	return nil
+}
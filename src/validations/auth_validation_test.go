@@ -0,0 +1,158 @@
+package validations
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	domainAuth "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/auth"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainAuth.CreateUserRequest
+		err     any
+	}{
+		{
+			name:    "should success with valid request",
+			request: domainAuth.CreateUserRequest{Username: "ci-bot@example.com", Password: "super-secret", Role: domainAuth.RoleSender},
+			err:     nil,
+		},
+		{
+			name:    "should success without password",
+			request: domainAuth.CreateUserRequest{Username: "viewer", Role: domainAuth.RoleViewer},
+			err:     nil,
+		},
+		{
+			name:    "should error with empty username",
+			request: domainAuth.CreateUserRequest{Role: domainAuth.RoleViewer},
+			err:     pkgError.ValidationError("username: cannot be blank."),
+		},
+		{
+			name:    "should error with colon in username",
+			request: domainAuth.CreateUserRequest{Username: "john:doe", Role: domainAuth.RoleViewer},
+			err:     pkgError.ValidationError("username: must be in a valid format."),
+		},
+		{
+			name:    "should error with short password",
+			request: domainAuth.CreateUserRequest{Username: "john", Password: "secret", Role: domainAuth.RoleViewer},
+			err:     pkgError.ValidationError("password: the length must be between 8 and 72."),
+		},
+		{
+			name:    "should error with empty role",
+			request: domainAuth.CreateUserRequest{Username: "john"},
+			err:     pkgError.ValidationError("role: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateUser(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateUpdateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainAuth.UpdateUserRequest
+		err     any
+	}{
+		{
+			name:    "should success with valid request",
+			request: domainAuth.UpdateUserRequest{Username: "john", Role: domainAuth.RoleAdmin},
+			err:     nil,
+		},
+		{
+			name:    "should error with too long username",
+			request: domainAuth.UpdateUserRequest{Username: strings.Repeat("a", 65)},
+			err:     pkgError.ValidationError("username: the length must be between 1 and 64."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateUser(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateResetPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainAuth.ResetPasswordRequest
+		err     any
+	}{
+		{
+			name:    "should success without password",
+			request: domainAuth.ResetPasswordRequest{Username: "john"},
+			err:     nil,
+		},
+		{
+			name:    "should error with too long password",
+			request: domainAuth.ResetPasswordRequest{Username: "john", Password: strings.Repeat("a", 73)},
+			err:     pkgError.ValidationError("password: the length must be between 8 and 72."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResetPassword(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateCreateAPIKey(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		request domainAuth.CreateAPIKeyRequest
+		err     any
+	}{
+		{
+			name: "should success with valid request",
+			request: domainAuth.CreateAPIKeyRequest{
+				Name:      "ci",
+				Username:  "ci-bot",
+				Scopes:    []string{domainAuth.PermissionSendText, domainAuth.PermissionChatRead},
+				ExpiresAt: tomorrow,
+			},
+			err: nil,
+		},
+		{
+			name:    "should error without scopes",
+			request: domainAuth.CreateAPIKeyRequest{Name: "ci", Username: "ci-bot"},
+			err:     pkgError.ValidationError("scopes: cannot be blank."),
+		},
+		{
+			name:    "should error with unknown scope",
+			request: domainAuth.CreateAPIKeyRequest{Name: "ci", Username: "ci-bot", Scopes: []string{"send:fax"}},
+			err:     pkgError.ValidationError("scopes: (0: must be a valid value.)."),
+		},
+		{
+			name:    "should error with expiry in the past",
+			request: domainAuth.CreateAPIKeyRequest{Name: "ci", Username: "ci-bot", Scopes: []string{domainAuth.PermissionSend}, ExpiresAt: "2020-01-01T00:00:00Z"},
+			err:     pkgError.ValidationError("expires_at: must be in the future."),
+		},
+		{
+			name:    "should error with invalid expiry",
+			request: domainAuth.CreateAPIKeyRequest{Name: "ci", Username: "ci-bot", Scopes: []string{domainAuth.PermissionSend}, ExpiresAt: "tomorrow"},
+			err:     pkgError.ValidationError("expires_at: must be a valid RFC3339 timestamp."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateAPIKey(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
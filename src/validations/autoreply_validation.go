@@ -0,0 +1,107 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// maxAutoReplyCooldown is the longest cooldown of a rule, a week
+const maxAutoReplyCooldown = 7 * 24 * 60 * 60
+
+func ValidateCreateRule(ctx context.Context, request *domainAutoReply.CreateRuleRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&request.Conditions, validation.By(validateRuleConditions)),
+		validation.Field(&request.Actions, validation.Required, validation.Length(1, 10), validation.By(validateRuleActions)),
+		validation.Field(&request.CooldownSeconds, validation.Min(0), validation.Max(maxAutoReplyCooldown)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateUpdateRule(ctx context.Context, request *domainAutoReply.UpdateRuleRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return ValidateCreateRule(ctx, &request.CreateRuleRequest)
+}
+
+// validateRuleConditions checks the values of the conditions and that the pattern and schedule parse
+func validateRuleConditions(value any) error {
+	conditions, _ := value.(domainAutoReply.Conditions)
+
+	err := validation.ValidateStruct(&conditions,
+		validation.Field(&conditions.Keywords, validation.Each(validation.Required, validation.Length(1, 100))),
+		validation.Field(&conditions.Pattern, validation.Length(0, 500)),
+		validation.Field(&conditions.Senders, validation.Each(validation.Required)),
+		validation.Field(&conditions.ChatTypes, validation.Each(validation.In(stringValues(domainAutoReply.ChatTypes)...))),
+		validation.Field(&conditions.MediaTypes, validation.Each(validation.In(stringValues(domainAutoReply.MediaTypes)...))),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := domainAutoReply.NewMatcher(&domainAutoReply.Rule{Conditions: conditions}); err != nil {
+		return validation.NewError("validation_autoreply_conditions", err.Error())
+	}
+	return nil
+}
+
+// validateRuleActions checks that every action has the settings its type needs
+func validateRuleActions(value any) error {
+	actions, _ := value.([]domainAutoReply.Action)
+
+	for i, action := range actions {
+		var missing string
+		switch action.Type {
+		case domainAutoReply.ActionReply:
+			if action.Text == "" && action.TemplateID == "" {
+				missing = "text or template_id"
+			} else if action.Text != "" && action.TemplateID != "" {
+				return validation.NewError("validation_autoreply_action", fmt.Sprintf("action %d: text and template_id can not be used together", i+1))
+			}
+		case domainAutoReply.ActionReact:
+			if action.Emoji == "" {
+				missing = "emoji"
+			}
+		case domainAutoReply.ActionLabel:
+			if action.LabelID == "" {
+				missing = "label_id"
+			}
+		case domainAutoReply.ActionWebhook:
+			if action.WebhookID == "" {
+				missing = "webhook_id"
+			}
+		case domainAutoReply.ActionMarkRead:
+		default:
+			return validation.NewError("validation_autoreply_action", fmt.Sprintf("action %d: type must be one of %v", i+1, domainAutoReply.ActionTypes))
+		}
+
+		if missing != "" {
+			return validation.NewError("validation_autoreply_action", fmt.Sprintf("action %d: %s is required for a %s action", i+1, missing, action.Type))
+		}
+	}
+	return nil
+}
+
+// stringValues returns string values as rule values
+func stringValues(values []string) []any {
+	converted := make([]any, len(values))
+	for i, value := range values {
+		converted[i] = value
+	}
+	return converted
+}
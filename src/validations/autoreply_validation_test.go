@@ -0,0 +1,87 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateRule(t *testing.T) {
+	reply := []domainAutoReply.Action{{Type: domainAutoReply.ActionReply, Text: "We are closed, we reply tomorrow"}}
+
+	tests := []struct {
+		name    string
+		request domainAutoReply.CreateRuleRequest
+		err     any
+	}{
+		{
+			name: "should success with conditions and actions",
+			request: domainAutoReply.CreateRuleRequest{
+				Name: "after hours",
+				Conditions: domainAutoReply.Conditions{
+					ChatTypes: []string{domainAutoReply.ChatPrivate},
+					Schedule:  &domainAutoReply.Schedule{Timezone: "Asia/Jakarta", From: "09:00", To: "17:00", Outside: true},
+				},
+				Actions: []domainAutoReply.Action{
+					{Type: domainAutoReply.ActionReply, TemplateID: "4f1c", Quote: true},
+					{Type: domainAutoReply.ActionReact, Emoji: "🌙"},
+					{Type: domainAutoReply.ActionMarkRead},
+				},
+				CooldownSeconds: 3600,
+			},
+			err: nil,
+		},
+		{
+			name:    "should error without actions",
+			request: domainAutoReply.CreateRuleRequest{Name: "empty"},
+			err:     pkgError.ValidationError("actions: cannot be blank."),
+		},
+		{
+			name:    "should error with unknown chat type",
+			request: domainAutoReply.CreateRuleRequest{Name: "channels", Conditions: domainAutoReply.Conditions{ChatTypes: []string{"channel"}}, Actions: reply},
+			err:     pkgError.ValidationError("conditions: (chat_types: (0: must be a valid value.).)."),
+		},
+		{
+			name:    "should error with invalid pattern",
+			request: domainAutoReply.CreateRuleRequest{Name: "orders", Conditions: domainAutoReply.Conditions{Pattern: "order (\\d+"}, Actions: reply},
+			err:     pkgError.ValidationError("conditions: invalid pattern: error parsing regexp: missing closing ): `order (\\d+`."),
+		},
+		{
+			name:    "should error with invalid timezone",
+			request: domainAutoReply.CreateRuleRequest{Name: "hours", Conditions: domainAutoReply.Conditions{Schedule: &domainAutoReply.Schedule{Timezone: "Jakarta"}}, Actions: reply},
+			err:     pkgError.ValidationError(`conditions: invalid timezone "Jakarta".`),
+		},
+		{
+			name:    "should error with reply without text",
+			request: domainAutoReply.CreateRuleRequest{Name: "hi", Actions: []domainAutoReply.Action{{Type: domainAutoReply.ActionReply}}},
+			err:     pkgError.ValidationError("actions: action 1: text or template_id is required for a reply action."),
+		},
+		{
+			name: "should error with react without emoji",
+			request: domainAutoReply.CreateRuleRequest{Name: "hi", Actions: []domainAutoReply.Action{
+				{Type: domainAutoReply.ActionMarkRead}, {Type: domainAutoReply.ActionReact},
+			}},
+			err: pkgError.ValidationError("actions: action 2: emoji is required for a react action."),
+		},
+		{
+			name:    "should error with unknown action",
+			request: domainAutoReply.CreateRuleRequest{Name: "hi", Actions: []domainAutoReply.Action{{Type: "forward"}}},
+			err:     pkgError.ValidationError("actions: action 1: type must be one of [reply react mark_read label webhook]."),
+		},
+		{
+			name:    "should error with negative cooldown",
+			request: domainAutoReply.CreateRuleRequest{Name: "hi", Actions: reply, CooldownSeconds: -1},
+			err:     pkgError.ValidationError("cooldown_seconds: must be no less than 0."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateRule(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
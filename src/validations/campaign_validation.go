@@ -0,0 +1,121 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	domainRateLimit "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/ratelimit"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// maxCampaignRecipients is the most recipients a single campaign can have
+const maxCampaignRecipients = 10000
+
+func ValidateCreateCampaign(ctx context.Context, request *domainCampaign.CreateCampaignRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&request.Message, validation.When(request.MediaType == "", validation.Required)),
+		validation.Field(&request.MediaType, validation.In(domainCampaign.MediaImage, domainCampaign.MediaVideo)),
+		validation.Field(&request.MediaURL, validation.When(request.MediaType != "", validation.Required), is.URL),
+		validation.Field(&request.Rate, validation.By(validateRate)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if len(request.Recipients) == 0 && request.RecipientsCSV == nil && request.GroupID == "" {
+		return pkgError.ValidationError("recipients, recipients_csv or group_id must be provided")
+	}
+
+	return nil
+}
+
+// ValidateCampaignRecipients validates the recipients collected from every source of a campaign, and that the
+// message renders for each of them, so nothing is sent when one of them would fail
+func ValidateCampaignRecipients(message string, recipients []domainCampaign.RecipientRequest) error {
+	if len(recipients) == 0 {
+		return pkgError.ValidationError("the campaign has no recipients")
+	}
+	if len(recipients) > maxCampaignRecipients {
+		return pkgError.ValidationError(fmt.Sprintf("the campaign has %d recipients, at most %d are allowed", len(recipients), maxCampaignRecipients))
+	}
+
+	if err := domainTemplate.Parse(message); err != nil {
+		return pkgError.ValidationError(fmt.Sprintf("message: %s", err.Error()))
+	}
+
+	for _, recipient := range recipients {
+		if err := validatePhoneNumber(recipient.Phone); err != nil {
+			return pkgError.ValidationError(fmt.Sprintf("recipient %q: %s", recipient.Phone, err.Error()))
+		}
+		if _, err := domainTemplate.Render(message, domainCampaign.Variables(recipient.Phone, recipient.Variables), nil); err != nil {
+			return pkgError.ValidationError(fmt.Sprintf("recipient %s: %s", recipient.Phone, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func ValidateListCampaigns(ctx context.Context, request *domainCampaign.ListCampaignsRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Status, validation.In(
+			domainCampaign.StatusDraft, domainCampaign.StatusRunning, domainCampaign.StatusPaused,
+			domainCampaign.StatusCompleted, domainCampaign.StatusCancelled,
+		)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(500)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateListCampaignRecipients(ctx context.Context, request *domainCampaign.ListRecipientsRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 100
+	}
+
+	statuses := make([]any, 0, len(domainCampaign.RecipientStatuses))
+	for _, status := range domainCampaign.RecipientStatuses {
+		statuses = append(statuses, status)
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required),
+		validation.Field(&request.Status, validation.In(statuses...)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(1000)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// validateRate accepts an empty value or a rate such as 30/1m
+func validateRate(value any) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if _, err := domainRateLimit.ParseLimit(s); err != nil {
+		return validation.NewError("validation_rate", "must be a rate such as 30/1m")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainCampaign "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/campaign"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateCampaign(t *testing.T) {
+	recipients := []domainCampaign.RecipientRequest{{Phone: "628123456789"}}
+
+	tests := []struct {
+		name    string
+		request domainCampaign.CreateCampaignRequest
+		err     any
+	}{
+		{
+			name:    "should success with message and recipients",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", Message: "Hi {{.name}}", Recipients: recipients},
+			err:     nil,
+		},
+		{
+			name: "should success with media, rate and group",
+			request: domainCampaign.CreateCampaignRequest{
+				Name:      "Promo",
+				MediaType: domainCampaign.MediaImage,
+				MediaURL:  "https://example.com/promo.jpg",
+				Rate:      "10/1m",
+				GroupID:   "120363024512399999@g.us",
+			},
+			err: nil,
+		},
+		{
+			name:    "should error without name",
+			request: domainCampaign.CreateCampaignRequest{Message: "Hi", Recipients: recipients},
+			err:     pkgError.ValidationError("name: cannot be blank."),
+		},
+		{
+			name:    "should error without message and media",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", Recipients: recipients},
+			err:     pkgError.ValidationError("message: cannot be blank."),
+		},
+		{
+			name:    "should error with media type without url",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", MediaType: domainCampaign.MediaVideo, Recipients: recipients},
+			err:     pkgError.ValidationError("media_url: cannot be blank."),
+		},
+		{
+			name:    "should error with unknown media type",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", Message: "Hi", MediaType: "audio", MediaURL: "https://example.com/a.ogg", Recipients: recipients},
+			err:     pkgError.ValidationError("media_type: must be a valid value."),
+		},
+		{
+			name:    "should error with invalid rate",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", Message: "Hi", Rate: "fast", Recipients: recipients},
+			err:     pkgError.ValidationError("rate: must be a rate such as 30/1m."),
+		},
+		{
+			name:    "should error without recipient source",
+			request: domainCampaign.CreateCampaignRequest{Name: "Promo", Message: "Hi"},
+			err:     pkgError.ValidationError("recipients, recipients_csv or group_id must be provided"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateCampaign(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateCampaignRecipients(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		recipients []domainCampaign.RecipientRequest
+		err        any
+	}{
+		{
+			name:       "should success when every recipient has the variables",
+			message:    "Hi {{upper .name}}, this is {{.phone}}",
+			recipients: []domainCampaign.RecipientRequest{{Phone: "628123456789", Variables: map[string]string{"name": "Ana"}}},
+			err:        nil,
+		},
+		{
+			name:    "should error without recipients",
+			message: "Hi",
+			err:     pkgError.ValidationError("the campaign has no recipients"),
+		},
+		{
+			name:       "should error with local phone number",
+			message:    "Hi",
+			recipients: []domainCampaign.RecipientRequest{{Phone: "08123456789"}},
+			err:        pkgError.ValidationError(`recipient "08123456789": phone number must be in international format (should not start with 0). For Indonesian numbers, use 62xxx format instead of 08xxx`),
+		},
+		{
+			name:       "should error with missing variable",
+			message:    "Hi {{.name}}",
+			recipients: []domainCampaign.RecipientRequest{{Phone: "628123456789"}},
+			err:        pkgError.ValidationError(`recipient 628123456789: missing variable "name"`),
+		},
+		{
+			name:       "should error with invalid template",
+			message:    "Hi {{name}}",
+			recipients: []domainCampaign.RecipientRequest{{Phone: "628123456789", Variables: map[string]string{"name": "Ana"}}},
+			err:        pkgError.ValidationError(`message: body:1: function "name" not defined`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCampaignRecipients(tt.message, tt.recipients)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateListCampaignRecipients(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainCampaign.ListRecipientsRequest
+		err     any
+	}{
+		{
+			name:    "should success with status",
+			request: domainCampaign.ListRecipientsRequest{ID: "4f1c", Status: domainCampaign.RecipientRead},
+			err:     nil,
+		},
+		{
+			name:    "should error with unknown status",
+			request: domainCampaign.ListRecipientsRequest{ID: "4f1c", Status: "bounced"},
+			err:     pkgError.ValidationError("status: must be a valid value."),
+		},
+		{
+			name:    "should error with limit too high",
+			request: domainCampaign.ListRecipientsRequest{ID: "4f1c", Limit: 1001},
+			err:     pkgError.ValidationError("limit: must be no greater than 1000."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListCampaignRecipients(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package validations
+
+import (
+	"context"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func ValidateSubscribeEvents(ctx context.Context, request *domainEventStream.SubscribeRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Events, validation.Each(validation.In(stringValues(domainWebhook.EventTypes)...))),
+		validation.Field(&request.Chats, validation.Each(validation.Required)),
+		validation.Field(&request.ResumeToken, validation.By(validateResumeToken)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func validateResumeToken(value any) error {
+	token, _ := value.(string)
+	if _, err := domainEventStream.ParseResumeToken(token); err != nil {
+		return validation.NewError("validation_resume_token", err.Error())
+	}
+	return nil
+}
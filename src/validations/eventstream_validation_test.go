@@ -0,0 +1,55 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainEventStream "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/eventstream"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSubscribeEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainEventStream.SubscribeRequest
+		err     any
+	}{
+		{
+			name:    "should success with empty request (every event and chat)",
+			request: domainEventStream.SubscribeRequest{},
+			err:     nil,
+		},
+		{
+			name: "should success with events, chats and resume token",
+			request: domainEventStream.SubscribeRequest{
+				Events:      []string{"message", "receipt"},
+				Chats:       []string{"628123456789", "120363025246125888@g.us"},
+				ResumeToken: "42",
+			},
+			err: nil,
+		},
+		{
+			name:    "should error with unknown event",
+			request: domainEventStream.SubscribeRequest{Events: []string{"message", "typing"}},
+			err:     pkgError.ValidationError("events: (1: must be a valid value.)."),
+		},
+		{
+			name:    "should error with empty chat",
+			request: domainEventStream.SubscribeRequest{Chats: []string{""}},
+			err:     pkgError.ValidationError("chats: (0: cannot be blank.)."),
+		},
+		{
+			name:    "should error with invalid resume token",
+			request: domainEventStream.SubscribeRequest{ResumeToken: "abc"},
+			err:     pkgError.ValidationError("resume_token: invalid resume token."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSubscribeEvents(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
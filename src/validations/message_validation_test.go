@@ -122,13 +122,13 @@ func TestValidateReactMessage(t *testing.T) {
 			err: pkgError.ValidationError("message_id: cannot be blank."),
 		},
 		{
-			name: "should error with empty emoji",
+			name: "should success with empty emoji removing the reaction",
 			args: args{request: domainMessage.ReactionRequest{
 				Phone:     "6281234567890@s.whatsapp.net",
 				MessageID: "3EB0789ABC123456",
 				Emoji:     "",
 			}},
-			err: pkgError.ValidationError("emoji: cannot be blank."),
+			err: nil,
 		},
 		{
 			name: "should error with all empty fields",
@@ -137,7 +137,7 @@ func TestValidateReactMessage(t *testing.T) {
 				MessageID: "",
 				Emoji:     "",
 			}},
-			err: pkgError.ValidationError("emoji: cannot be blank; message_id: cannot be blank; phone: cannot be blank."),
+			err: pkgError.ValidationError("message_id: cannot be blank; phone: cannot be blank."),
 		},
 	}
 
@@ -150,7 +150,6 @@ func TestValidateReactMessage(t *testing.T) {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), "phone: cannot be blank")
 				assert.Contains(t, err.Error(), "message_id: cannot be blank")
-				assert.Contains(t, err.Error(), "emoji: cannot be blank")
 			} else {
 				assert.Equal(t, tt.err, err)
 			}
@@ -291,3 +290,87 @@ func TestValidateStarMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMessageStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainMessage.MessageStatusRequest
+		err     any
+	}{
+		{
+			name: "should success with valid phone and message id",
+			request: domainMessage.MessageStatusRequest{
+				Phone:     "6281234567890@s.whatsapp.net",
+				MessageID: "3EB0789ABC123456",
+			},
+			err: nil,
+		},
+		{
+			name: "should error with empty phone",
+			request: domainMessage.MessageStatusRequest{
+				MessageID: "3EB0789ABC123456",
+			},
+			err: pkgError.ValidationError("phone: cannot be blank."),
+		},
+		{
+			name: "should error with empty message id",
+			request: domainMessage.MessageStatusRequest{
+				Phone: "6281234567890@s.whatsapp.net",
+			},
+			err: pkgError.ValidationError("message_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessageStatus(context.Background(), tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidatePollResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainMessage.PollResultsRequest
+		err     any
+	}{
+		{
+			name: "should success with valid phone and message id",
+			request: domainMessage.PollResultsRequest{
+				Phone:     "120363024512399999@g.us",
+				MessageID: "3EB0789ABC123456",
+			},
+			err: nil,
+		},
+		{
+			name: "should error with empty phone",
+			request: domainMessage.PollResultsRequest{
+				MessageID: "3EB0789ABC123456",
+			},
+			err: pkgError.ValidationError("phone: cannot be blank."),
+		},
+		{
+			name: "should error with empty message id",
+			request: domainMessage.PollResultsRequest{
+				Phone: "120363024512399999@g.us",
+			},
+			err: pkgError.ValidationError("message_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePollResults(context.Background(), tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
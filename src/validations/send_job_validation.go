@@ -0,0 +1,43 @@
+package validations
+
+import (
+	"context"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func ValidateListSendJobs(ctx context.Context, request *domainSend.ListJobsRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Status, validation.In(
+			domainSend.JobStatusQueued, domainSend.JobStatusSending, domainSend.JobStatusSent,
+			domainSend.JobStatusFailed, domainSend.JobStatusCancelled,
+		)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(500)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateUpdateSendJob(ctx context.Context, request *domainSend.UpdateJobRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.SendAt, validation.Required, validation.By(validateFutureTime)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package validations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListSendJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainSend.ListJobsRequest
+		err     any
+	}{
+		{
+			name:    "should success with empty request (auto set default limit)",
+			request: domainSend.ListJobsRequest{},
+			err:     nil,
+		},
+		{
+			name: "should success with every filter",
+			request: domainSend.ListJobsRequest{
+				Actor:     "alice",
+				Status:    domainSend.JobStatusQueued,
+				Recipient: "628123456789@s.whatsapp.net",
+				Limit:     500,
+				Offset:    10,
+			},
+			err: nil,
+		},
+		{
+			name:    "should error with unknown status",
+			request: domainSend.ListJobsRequest{Status: "pending"},
+			err:     pkgError.ValidationError("status: must be a valid value."),
+		},
+		{
+			name:    "should error with limit too high",
+			request: domainSend.ListJobsRequest{Limit: 501},
+			err:     pkgError.ValidationError("limit: must be no greater than 500."),
+		},
+		{
+			name:    "should error with negative offset",
+			request: domainSend.ListJobsRequest{Offset: -1},
+			err:     pkgError.ValidationError("offset: must be no less than 0."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListSendJobs(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateUpdateSendJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainSend.UpdateJobRequest
+		err     any
+	}{
+		{
+			name:    "should success with send_at in the future",
+			request: domainSend.UpdateJobRequest{ID: "4f1c", SendAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+			err:     nil,
+		},
+		{
+			name:    "should error without send_at",
+			request: domainSend.UpdateJobRequest{ID: "4f1c"},
+			err:     pkgError.ValidationError("send_at: cannot be blank."),
+		},
+		{
+			name:    "should error with send_at in the past",
+			request: domainSend.UpdateJobRequest{ID: "4f1c", SendAt: time.Now().Add(-time.Minute).Format(time.RFC3339)},
+			err:     pkgError.ValidationError("send_at: must be in the future."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateSendJob(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -29,6 +29,14 @@ func validateDuration(dur *int) error {
 	return nil
 }
 
+// validateSendAt validates that the optional send time is an RFC3339 timestamp in the future.
+func validateSendAt(sendAt string) error {
+	if err := validateFutureTime(sendAt); err != nil {
+		return pkgError.ValidationError(fmt.Sprintf("send_at: %s.", err.Error()))
+	}
+	return nil
+}
+
 // validatePhoneNumber validates that the phone number is in international format (not starting with 0)
 func validatePhoneNumber(phone string) error {
 	if phone == "" {
@@ -68,6 +76,9 @@ func ValidateSendMessage(ctx context.Context, request domainSend.MessageRequest)
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -116,6 +127,9 @@ func ValidateSendImage(ctx context.Context, request domainSend.ImageRequest) err
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -171,6 +185,9 @@ func ValidateSendSticker(ctx context.Context, request domainSend.StickerRequest)
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -198,6 +215,9 @@ func ValidateSendFile(ctx context.Context, request domainSend.FileRequest) error
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -255,6 +275,9 @@ func ValidateSendVideo(ctx context.Context, request domainSend.VideoRequest) err
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -283,6 +306,9 @@ func ValidateSendContact(ctx context.Context, request domainSend.ContactRequest)
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -306,6 +332,9 @@ func ValidateSendLink(ctx context.Context, request domainSend.LinkRequest) error
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -329,6 +358,9 @@ func ValidateSendLocation(ctx context.Context, request domainSend.LocationReques
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -404,6 +436,9 @@ func ValidateSendAudio(ctx context.Context, request domainSend.AudioRequest) err
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -437,6 +472,9 @@ func ValidateSendPoll(ctx context.Context, request domainSend.PollRequest) error
 	if err := validateDuration(request.Duration); err != nil {
 		return err
 	}
+	if err := validateSendAt(request.SendAt); err != nil {
+		return err
+	}
 
 	// validate options should be unique each other
 	uniqueOptions := make(map[string]bool)
@@ -465,7 +503,7 @@ func ValidateSendPresence(ctx context.Context, request domainSend.PresenceReques
 func ValidateSendChatPresence(ctx context.Context, request domainSend.ChatPresenceRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
-		validation.Field(&request.Action, validation.Required, validation.In("start", "stop")),
+		validation.Field(&request.Action, validation.Required, validation.In("start", "record", "stop")),
 	)
 
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"mime/multipart"
 	"testing"
+	"time"
 
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
@@ -841,6 +842,14 @@ func TestValidateSendChatPresence(t *testing.T) {
 			}},
 			err: nil,
 		},
+		{
+			name: "should success with record action",
+			args: args{request: domainSend.ChatPresenceRequest{
+				Phone:  "1728937129312@s.whatsapp.net",
+				Action: "record",
+			}},
+			err: nil,
+		},
 		{
 			name: "should error with empty phone",
 			args: args{request: domainSend.ChatPresenceRequest{
@@ -962,6 +971,43 @@ func TestValidateSendMessage_WithDuration(t *testing.T) {
 	}
 }
 
+func TestValidateSendMessage_WithSendAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		sendAt string
+		err    any
+	}{
+		{
+			name:   "should success with send_at in the future",
+			sendAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+			err:    nil,
+		},
+		{
+			name:   "should error with send_at in the past",
+			sendAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			err:    pkgError.ValidationError("send_at: must be in the future."),
+		},
+		{
+			name:   "should error with invalid send_at",
+			sendAt: "tomorrow 9am",
+			err:    pkgError.ValidationError("send_at: must be a valid RFC3339 timestamp."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSendMessage(context.Background(), domainSend.MessageRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone:  "1728937129312@s.whatsapp.net",
+					SendAt: tt.sendAt,
+				},
+				Message: "Hello this is testing",
+			})
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestValidateSendImage_WithImageURL(t *testing.T) {
 	type args struct {
 		request domainSend.ImageRequest
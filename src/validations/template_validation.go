@@ -0,0 +1,109 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// maxTemplateBody is the longest template body, the longest text WhatsApp shows without a read more
+const maxTemplateBody = 4096
+
+// variableName matches the names a template can refer to as {{.name}}
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func ValidateCreateTemplate(ctx context.Context, request *domainTemplate.CreateTemplateRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&request.Description, validation.Length(0, 500)),
+		validation.Field(&request.Body,
+			validation.When(request.MediaType == "", validation.Required),
+			validation.Length(0, maxTemplateBody),
+			validation.By(validateTemplateBody),
+		),
+		validation.Field(&request.MediaType,
+			validation.When(request.MediaURL != "", validation.Required),
+			validation.In(domainTemplate.MediaImage, domainTemplate.MediaVideo),
+		),
+		validation.Field(&request.MediaURL, validation.When(request.MediaType != "", validation.Required), is.URL),
+		validation.Field(&request.Defaults, validation.By(validateTemplateDefaults)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateUpdateTemplate(ctx context.Context, request *domainTemplate.UpdateTemplateRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required),
+		validation.Field(&request.Version, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return ValidateCreateTemplate(ctx, &domainTemplate.CreateTemplateRequest{
+		Name:        request.Name,
+		Description: request.Description,
+		Body:        request.Body,
+		MediaType:   request.MediaType,
+		MediaURL:    request.MediaURL,
+		Defaults:    request.Defaults,
+	})
+}
+
+func ValidateListTemplates(ctx context.Context, request *domainTemplate.ListTemplatesRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Search, validation.Length(0, 100)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(500)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// validateTemplateBody checks that a template body parses, unknown helpers and unclosed blocks are errors
+func validateTemplateBody(value any) error {
+	body, _ := value.(string)
+	if err := domainTemplate.Parse(body); err != nil {
+		return validation.NewError("validation_template_body", err.Error())
+	}
+	return nil
+}
+
+// validateTemplateDefaults checks that every default can be referred to from a template body
+func validateTemplateDefaults(value any) error {
+	defaults, _ := value.(map[string]any)
+
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !variableName.MatchString(name) {
+			return validation.NewError("validation_template_variable", fmt.Sprintf("%q is not a valid variable name, use letters, digits and underscores", name))
+		}
+	}
+	return nil
+}
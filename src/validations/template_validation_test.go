@@ -0,0 +1,106 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainTemplate.CreateTemplateRequest
+		err     any
+	}{
+		{
+			name: "should success with body and defaults",
+			request: domainTemplate.CreateTemplateRequest{
+				Name:     "order-ready",
+				Body:     "Hi {{.name}}{{if .vip}} ⭐{{end}}, order {{.order}} is ready",
+				Defaults: map[string]any{"vip": false},
+			},
+			err: nil,
+		},
+		{
+			name:    "should success with media and no body",
+			request: domainTemplate.CreateTemplateRequest{Name: "promo", MediaType: domainTemplate.MediaImage, MediaURL: "https://example.com/promo.jpg"},
+			err:     nil,
+		},
+		{
+			name:    "should error without name",
+			request: domainTemplate.CreateTemplateRequest{Body: "Hi"},
+			err:     pkgError.ValidationError("name: cannot be blank."),
+		},
+		{
+			name:    "should error without body and media",
+			request: domainTemplate.CreateTemplateRequest{Name: "empty"},
+			err:     pkgError.ValidationError("body: cannot be blank."),
+		},
+		{
+			name:    "should error with invalid body",
+			request: domainTemplate.CreateTemplateRequest{Name: "broken", Body: "Hi {{.name"},
+			err:     pkgError.ValidationError("body: body:1: unclosed action."),
+		},
+		{
+			name:    "should error with unknown helper",
+			request: domainTemplate.CreateTemplateRequest{Name: "broken", Body: "Hi {{shout .name}}"},
+			err:     pkgError.ValidationError(`body: body:1: function "shout" not defined.`),
+		},
+		{
+			name:    "should error with media url without type",
+			request: domainTemplate.CreateTemplateRequest{Name: "promo", Body: "Hi", MediaURL: "https://example.com/promo.jpg"},
+			err:     pkgError.ValidationError("media_type: cannot be blank."),
+		},
+		{
+			name:    "should error with unsupported media type",
+			request: domainTemplate.CreateTemplateRequest{Name: "promo", Body: "Hi", MediaType: "audio", MediaURL: "https://example.com/a.ogg"},
+			err:     pkgError.ValidationError("media_type: must be a valid value."),
+		},
+		{
+			name:    "should error with invalid default name",
+			request: domainTemplate.CreateTemplateRequest{Name: "promo", Body: "Hi", Defaults: map[string]any{"first-name": "Ana"}},
+			err:     pkgError.ValidationError(`defaults: "first-name" is not a valid variable name, use letters, digits and underscores.`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateTemplate(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateUpdateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainTemplate.UpdateTemplateRequest
+		err     any
+	}{
+		{
+			name:    "should success with version",
+			request: domainTemplate.UpdateTemplateRequest{ID: "4f1c", Version: 2, Name: "promo", Body: "Hi {{.name}}"},
+			err:     nil,
+		},
+		{
+			name:    "should error with negative version",
+			request: domainTemplate.UpdateTemplateRequest{ID: "4f1c", Version: -1, Name: "promo", Body: "Hi"},
+			err:     pkgError.ValidationError("version: must be no less than 0."),
+		},
+		{
+			name:    "should error with invalid body",
+			request: domainTemplate.UpdateTemplateRequest{ID: "4f1c", Name: "promo", Body: "{{if .vip}}"},
+			err:     pkgError.ValidationError("body: body:1: unexpected EOF."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateTemplate(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
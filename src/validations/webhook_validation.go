@@ -0,0 +1,177 @@
+package validations
+
+import (
+	"context"
+	"time"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+var deliveryStatuses = []any{
+	domainWebhook.DeliveryStatusPending,
+	domainWebhook.DeliveryStatusDelivered,
+	domainWebhook.DeliveryStatusFailed,
+}
+
+func ValidateCreateEndpoint(ctx context.Context, request *domainWebhook.CreateEndpointRequest) error {
+	// New endpoints keep receiving the original payloads unless they opt into a newer version
+	if request.PayloadVersion == 0 {
+		request.PayloadVersion = domainWebhook.PayloadVersion1
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.URL, validation.Required, is.URL),
+		validation.Field(&request.Secret, validation.Length(16, 256)),
+		validation.Field(&request.Events, validation.Required, validation.Each(validation.In(eventTypes()...))),
+		validation.Field(&request.AllowChats, validation.Each(validation.Required)),
+		validation.Field(&request.DenyChats, validation.Each(validation.Required)),
+		validation.Field(&request.PayloadVersion, validation.In(payloadVersions()...)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateUpdateEndpoint(ctx context.Context, request *domainWebhook.UpdateEndpointRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required),
+		validation.Field(&request.URL, validation.Required, is.URL),
+		validation.Field(&request.Secret, validation.Length(16, 256)),
+		validation.Field(&request.Events, validation.Required, validation.Each(validation.In(eventTypes()...))),
+		validation.Field(&request.AllowChats, validation.Each(validation.Required)),
+		validation.Field(&request.DenyChats, validation.Each(validation.Required)),
+		validation.Field(&request.PayloadVersion, validation.In(payloadVersions()...)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateRotateSecret(ctx context.Context, request *domainWebhook.RotateSecretRequest) error {
+	// Receivers get a day to pick up the new secret unless another overlap is requested
+	if request.Overlap == "" {
+		request.Overlap = "24h"
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required),
+		validation.Field(&request.Secret, validation.Length(16, 256)),
+		validation.Field(&request.Overlap, validation.By(validateOverlap)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateListDeliveries(ctx context.Context, request *domainWebhook.ListDeliveriesRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 25
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Status, validation.In(deliveryStatuses...)),
+		validation.Field(&request.From, validation.By(validateRFC3339)),
+		validation.Field(&request.To, validation.By(validateRFC3339)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(100)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateReplayDelivery(ctx context.Context, request *domainWebhook.ReplayDeliveryRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ID, validation.Required, validation.Min(int64(1))),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateReplayDeliveries(ctx context.Context, request *domainWebhook.ReplayDeliveriesRequest) error {
+	// Replaying by range targets the dead-letter queue unless another status is requested
+	if request.Status == "" {
+		request.Status = domainWebhook.DeliveryStatusFailed
+	}
+
+	replayable := make([]any, len(domainWebhook.ReplayableStatuses))
+	for i, status := range domainWebhook.ReplayableStatuses {
+		replayable[i] = status
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Status, validation.In(replayable...)),
+		validation.Field(&request.From, validation.By(validateRFC3339)),
+		validation.Field(&request.To, validation.By(validateRFC3339)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// eventTypes returns the webhook event types as rule values
+func eventTypes() []any {
+	values := make([]any, len(domainWebhook.EventTypes))
+	for i, eventType := range domainWebhook.EventTypes {
+		values[i] = eventType
+	}
+	return values
+}
+
+// payloadVersions returns the webhook payload versions as rule values
+func payloadVersions() []any {
+	values := make([]any, len(domainWebhook.PayloadVersions))
+	for i, version := range domainWebhook.PayloadVersions {
+		values[i] = version
+	}
+	return values
+}
+
+// validateOverlap accepts a duration between zero and a week
+func validateOverlap(value any) error {
+	s, _ := value.(string)
+	overlap, err := time.ParseDuration(s)
+	if err != nil {
+		return validation.NewError("validation_duration", "must be a valid duration such as 24h")
+	}
+	if overlap < 0 || overlap > 7*24*time.Hour {
+		return validation.NewError("validation_overlap", "must be between 0s and 168h")
+	}
+	return nil
+}
+
+// validateRFC3339 accepts an empty value or a timestamp in RFC3339 format
+func validateRFC3339(value any) error {
+	value, _ = validation.Indirect(value)
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, s); err != nil {
+		return validation.NewError("validation_rfc3339", "must be a valid RFC3339 timestamp")
+	}
+	return nil
+}
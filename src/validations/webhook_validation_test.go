@@ -0,0 +1,255 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainWebhook "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/webhook"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListDeliveries(t *testing.T) {
+	validTime := "2024-01-01T00:00:00Z"
+	invalidTime := "2024-01-01 00:00:00"
+
+	type args struct {
+		request domainWebhook.ListDeliveriesRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with empty request (auto set default limit)",
+			args: args{request: domainWebhook.ListDeliveriesRequest{}},
+			err:  nil,
+		},
+		{
+			name: "should success with status and time range",
+			args: args{request: domainWebhook.ListDeliveriesRequest{
+				Status: domainWebhook.DeliveryStatusFailed,
+				From:   &validTime,
+				To:     &validTime,
+				Limit:  50,
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with unknown status",
+			args: args{request: domainWebhook.ListDeliveriesRequest{
+				Status: "unknown",
+			}},
+			err: pkgError.ValidationError("status: must be a valid value."),
+		},
+		{
+			name: "should error with invalid from",
+			args: args{request: domainWebhook.ListDeliveriesRequest{
+				From: &invalidTime,
+			}},
+			err: pkgError.ValidationError("from: must be a valid RFC3339 timestamp."),
+		},
+		{
+			name: "should error with limit too high",
+			args: args{request: domainWebhook.ListDeliveriesRequest{
+				Limit: 101,
+			}},
+			err: pkgError.ValidationError("limit: must be no greater than 100."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListDeliveries(context.Background(), &tt.args.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateReplayDelivery(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainWebhook.ReplayDeliveryRequest
+		err     any
+	}{
+		{
+			name:    "should success with valid id",
+			request: domainWebhook.ReplayDeliveryRequest{ID: 1},
+			err:     nil,
+		},
+		{
+			name:    "should error with empty id",
+			request: domainWebhook.ReplayDeliveryRequest{},
+			err:     pkgError.ValidationError("id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReplayDelivery(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateReplayDeliveries(t *testing.T) {
+	invalidTime := "yesterday"
+
+	tests := []struct {
+		name       string
+		request    domainWebhook.ReplayDeliveriesRequest
+		err        any
+		wantStatus string
+	}{
+		{
+			name:       "should default to failed deliveries",
+			request:    domainWebhook.ReplayDeliveriesRequest{},
+			err:        nil,
+			wantStatus: domainWebhook.DeliveryStatusFailed,
+		},
+		{
+			name:       "should error with pending deliveries",
+			request:    domainWebhook.ReplayDeliveriesRequest{Status: domainWebhook.DeliveryStatusPending},
+			err:        pkgError.ValidationError("status: must be a valid value."),
+			wantStatus: domainWebhook.DeliveryStatusPending,
+		},
+		{
+			name:       "should error with invalid to",
+			request:    domainWebhook.ReplayDeliveriesRequest{To: &invalidTime},
+			err:        pkgError.ValidationError("to: must be a valid RFC3339 timestamp."),
+			wantStatus: domainWebhook.DeliveryStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReplayDeliveries(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantStatus, tt.request.Status)
+		})
+	}
+}
+
+func TestValidateCreateEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainWebhook.CreateEndpointRequest
+		err     any
+	}{
+		{
+			name: "should success with valid request",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:        "https://example.com/webhook",
+				Events:     []string{domainWebhook.EventGroup},
+				AllowChats: []string{"120363024512399999@g.us"},
+			},
+			err: nil,
+		},
+		{
+			name: "should error without events",
+			request: domainWebhook.CreateEndpointRequest{
+				URL: "https://example.com/webhook",
+			},
+			err: pkgError.ValidationError("events: cannot be blank."),
+		},
+		{
+			name: "should error with unknown event",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:    "https://example.com/webhook",
+				Events: []string{"message", "typing"},
+			},
+			err: pkgError.ValidationError("events: (1: must be a valid value.)."),
+		},
+		{
+			name: "should error with invalid url",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:    "not a url",
+				Events: []string{domainWebhook.EventMessage},
+			},
+			err: pkgError.ValidationError("url: must be a valid URL."),
+		},
+		{
+			name: "should error with short secret",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:    "https://example.com/webhook",
+				Secret: "secret",
+				Events: []string{domainWebhook.EventMessage},
+			},
+			err: pkgError.ValidationError("secret: the length must be between 16 and 256."),
+		},
+		{
+			name: "should success with payload version 2",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:            "https://example.com/webhook",
+				Events:         []string{domainWebhook.EventMessage},
+				PayloadVersion: domainWebhook.PayloadVersion2,
+			},
+			err: nil,
+		},
+		{
+			name: "should error with unknown payload version",
+			request: domainWebhook.CreateEndpointRequest{
+				URL:            "https://example.com/webhook",
+				Events:         []string{domainWebhook.EventMessage},
+				PayloadVersion: 3,
+			},
+			err: pkgError.ValidationError("payload_version: must be a valid value."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateEndpoint(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateRotateSecret(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     domainWebhook.RotateSecretRequest
+		err         any
+		wantOverlap string
+	}{
+		{
+			name:        "should default overlap to a day",
+			request:     domainWebhook.RotateSecretRequest{ID: "endpoint-1"},
+			err:         nil,
+			wantOverlap: "24h",
+		},
+		{
+			name:        "should success without overlap",
+			request:     domainWebhook.RotateSecretRequest{ID: "endpoint-1", Secret: "0123456789abcdef", Overlap: "0s"},
+			err:         nil,
+			wantOverlap: "0s",
+		},
+		{
+			name:        "should error with invalid overlap",
+			request:     domainWebhook.RotateSecretRequest{ID: "endpoint-1", Overlap: "tomorrow"},
+			err:         pkgError.ValidationError("overlap: must be a valid duration such as 24h."),
+			wantOverlap: "tomorrow",
+		},
+		{
+			name:        "should error with overlap above a week",
+			request:     domainWebhook.RotateSecretRequest{ID: "endpoint-1", Overlap: "200h"},
+			err:         pkgError.ValidationError("overlap: must be between 0s and 168h."),
+			wantOverlap: "200h",
+		},
+		{
+			name:        "should error with short secret",
+			request:     domainWebhook.RotateSecretRequest{ID: "endpoint-1", Secret: "secret"},
+			err:         pkgError.ValidationError("secret: the length must be between 16 and 256."),
+			wantOverlap: "24h",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRotateSecret(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantOverlap, tt.request.Overlap)
+		})
+	}
+}
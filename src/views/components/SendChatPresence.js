@@ -81,6 +81,7 @@ export default {
                     <label>Action</label>
                     <select v-model="action" class="ui dropdown">
                         <option value="start">Start Typing</option>
+                        <option value="record">Start Recording</option>
                         <option value="stop">Stop Typing</option>
                     </select>
                 </div>